@@ -0,0 +1,463 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/RichardNooooh/parquet-go/metadata"
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+func runMeta(out *output, file *inputFile) error {
+	meta := file.meta
+	record := struct {
+		File             string            `json:"file"`
+		Version          int32             `json:"version"`
+		CreatedBy        string            `json:"created_by,omitempty"`
+		NumRows          int64             `json:"num_rows"`
+		NumRowGroups     int               `json:"num_row_groups"`
+		NumColumns       int               `json:"num_columns"`
+		KeyValueMetadata map[string]string `json:"key_value_metadata,omitempty"`
+		// The encryption of encrypted files, whose footer is either
+		// encrypted or signed.
+		EncryptionAlgorithm string `json:"encryption_algorithm,omitempty"`
+		Footer              string `json:"footer,omitempty"`
+		FooterKeyMetadata   string `json:"footer_key_metadata,omitempty"`
+	}{
+		File:             file.path,
+		Version:          meta.Version(),
+		CreatedBy:        meta.CreatedBy(),
+		NumRows:          meta.NumRows(),
+		NumRowGroups:     meta.NumRowGroups(),
+		NumColumns:       len(meta.Columns()),
+		KeyValueMetadata: meta.KeyValueMetadata(),
+	}
+	if encryption := file.reader.Encryption(); encryption != nil {
+		record.EncryptionAlgorithm = encryption.Algorithm.String()
+		record.Footer = "signed"
+		if encryption.EncryptedFooter {
+			record.Footer = "encrypted"
+		}
+		record.FooterKeyMetadata = bytesValue(encryption.FooterKeyMetadata)
+	}
+	if out.json {
+		return out.record(record)
+	}
+
+	t := out.table()
+	t.row("version:", record.Version)
+	t.row("created by:", record.CreatedBy)
+	t.row("rows:", record.NumRows)
+	t.row("row groups:", record.NumRowGroups)
+	t.row("columns:", record.NumColumns)
+	if record.EncryptionAlgorithm != "" {
+		t.row("encryption:", record.EncryptionAlgorithm)
+		t.row("footer:", record.Footer)
+		t.row("footer key metadata:", orDash(record.FooterKeyMetadata))
+	}
+	for _, key := range slices.Sorted(maps.Keys(record.KeyValueMetadata)) {
+		t.row(key+":", record.KeyValueMetadata[key])
+	}
+	return t.close()
+}
+
+// schemaNode is the JSON form of a schema element.
+type schemaNode struct {
+	Name          string        `json:"name"`
+	Repetition    string        `json:"repetition"`
+	Type          string        `json:"type,omitempty"`
+	TypeLength    int32         `json:"type_length,omitempty"`
+	LogicalType   string        `json:"logical_type,omitempty"`
+	ConvertedType string        `json:"converted_type,omitempty"`
+	FieldID       *int32        `json:"field_id,omitempty"`
+	Children      []*schemaNode `json:"children,omitempty"`
+}
+
+func newSchemaNode(element *schema.SchemaElement) *schemaNode {
+	node := &schemaNode{Name: element.Name, Repetition: element.Repetition.String(), FieldID: element.FieldID}
+	if element.IsLeaf() {
+		node.Type = element.Type.String()
+		if element.Type == schema.FixedLenByteArray {
+			node.TypeLength = element.TypeLength
+		}
+	}
+	if element.LogicalType != nil {
+		node.LogicalType = element.LogicalType.String()
+	}
+	if element.ConvertedType != schema.NoConvertedType {
+		node.ConvertedType = element.ConvertedType.String()
+	}
+	for _, child := range element.Children {
+		node.Children = append(node.Children, newSchemaNode(child))
+	}
+	return node
+}
+
+func runSchema(out *output, file *inputFile) error {
+	root := file.meta.Schema()
+	if out.json {
+		return out.record(struct {
+			File   string      `json:"file"`
+			Schema *schemaNode `json:"schema"`
+		}{file.path, newSchemaNode(root)})
+	}
+
+	fmt.Fprintf(out.w, "message %s {\n", root.Name)
+	for _, child := range root.Children {
+		printSchema(out, child, 1)
+	}
+	fmt.Fprintln(out.w, "}")
+	return nil
+}
+
+// printSchema prints an element in the message syntax of the Parquet
+// specification.
+func printSchema(out *output, element *schema.SchemaElement, depth int) {
+	indent := strings.Repeat("  ", depth)
+	line := indent + strings.ToLower(element.Repetition.String()) + " "
+	if element.IsLeaf() {
+		switch element.Type {
+		case schema.ByteArray:
+			line += "binary"
+		case schema.FixedLenByteArray:
+			line += fmt.Sprintf("fixed_len_byte_array(%d)", element.TypeLength)
+		default:
+			line += strings.ToLower(element.Type.String())
+		}
+	} else {
+		line += "group"
+	}
+	line += " " + element.Name
+	if element.LogicalType != nil {
+		line += " (" + element.LogicalType.String() + ")"
+	} else if element.ConvertedType != schema.NoConvertedType {
+		line += " (" + element.ConvertedType.String() + ")"
+	}
+	if element.FieldID != nil {
+		line += fmt.Sprintf(" = %d", *element.FieldID)
+	}
+
+	if element.IsLeaf() {
+		fmt.Fprintln(out.w, line+";")
+		return
+	}
+	fmt.Fprintln(out.w, line+" {")
+	for _, child := range element.Children {
+		printSchema(out, child, depth+1)
+	}
+	fmt.Fprintln(out.w, indent+"}")
+}
+
+func runRowGroups(out *output, file *inputFile) error {
+	type rowGroupRecord struct {
+		File           string `json:"file"`
+		RowGroup       int    `json:"row_group"`
+		NumRows        int64  `json:"num_rows"`
+		NumColumns     int    `json:"num_columns"`
+		TotalByteSize  int64  `json:"total_byte_size"`
+		CompressedSize int64  `json:"compressed_size"`
+	}
+
+	var t *table
+	if !out.json {
+		t = out.table("ROW GROUP", "ROWS", "COLUMNS", "TOTAL BYTE SIZE", "COMPRESSED SIZE")
+	}
+	for _, i := range file.rowGroups {
+		rowGroup := file.meta.RowGroup(i)
+		record := rowGroupRecord{
+			File:          file.path,
+			RowGroup:      i,
+			NumRows:       rowGroup.NumRows(),
+			NumColumns:    rowGroup.NumColumns(),
+			TotalByteSize: rowGroup.TotalByteSize(),
+		}
+		for j := range rowGroup.NumColumns() {
+			record.CompressedSize += rowGroup.Column(j).TotalCompressedSize()
+		}
+		if out.json {
+			if err := out.record(record); err != nil {
+				return err
+			}
+			continue
+		}
+		t.row(record.RowGroup, record.NumRows, record.NumColumns, record.TotalByteSize, record.CompressedSize)
+	}
+	if t != nil {
+		return t.close()
+	}
+	return nil
+}
+
+// chunks calls fn with the selected column chunks, ordered by row group and
+// then by column.
+func (file *inputFile) chunks(fn func(rowGroup int, chunk *metadata.ColumnChunkMeta) error) error {
+	for _, i := range file.rowGroups {
+		rowGroup := file.meta.RowGroup(i)
+		for _, column := range file.columns {
+			if err := fn(i, rowGroup.Column(column.Index)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func runColumns(out *output, file *inputFile) error {
+	type columnRecord struct {
+		File             string   `json:"file"`
+		RowGroup         int      `json:"row_group"`
+		Column           int      `json:"column"`
+		Path             string   `json:"path"`
+		Type             string   `json:"type"`
+		Codec            string   `json:"codec,omitempty"`
+		Encodings        []string `json:"encodings,omitempty"`
+		NumValues        int64    `json:"num_values"`
+		CompressedSize   int64    `json:"compressed_size"`
+		UncompressedSize int64    `json:"uncompressed_size"`
+	}
+
+	var t *table
+	if !out.json {
+		t = out.table("ROW GROUP", "COLUMN", "PATH", "TYPE", "CODEC", "ENCODINGS", "VALUES", "COMPRESSED SIZE", "UNCOMPRESSED SIZE")
+	}
+	err := file.chunks(func(rowGroup int, chunk *metadata.ColumnChunkMeta) error {
+		column := chunk.Column()
+		record := columnRecord{
+			File:             file.path,
+			RowGroup:         rowGroup,
+			Column:           column.Index,
+			Path:             column.PathString(),
+			Type:             column.Leaf.Type.String(),
+			NumValues:        chunk.NumValues(),
+			CompressedSize:   chunk.TotalCompressedSize(),
+			UncompressedSize: chunk.TotalUncompressedSize(),
+		}
+		// The metadata of columns encrypted with a key the reader does not
+		// have is missing.
+		if columnMetadata := chunk.Format().GetMetaData(); columnMetadata != nil {
+			record.Codec = columnMetadata.GetCodec().String()
+			for _, encoding := range columnMetadata.GetEncodings() {
+				record.Encodings = append(record.Encodings, encoding.String())
+			}
+		}
+		if out.json {
+			return out.record(record)
+		}
+		t.row(record.RowGroup, record.Column, record.Path, record.Type, orDash(record.Codec),
+			orDash(strings.Join(record.Encodings, ",")), record.NumValues, record.CompressedSize, record.UncompressedSize)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if t != nil {
+		return t.close()
+	}
+	return nil
+}
+
+func runPages(out *output, file *inputFile) error {
+	type pageRecord struct {
+		File           string `json:"file"`
+		RowGroup       int    `json:"row_group"`
+		Column         int    `json:"column"`
+		Path           string `json:"path"`
+		Page           int    `json:"page"`
+		Offset         int64  `json:"offset"`
+		CompressedSize int32  `json:"compressed_size"`
+		FirstRow       int64  `json:"first_row"`
+		NumRows        int64  `json:"num_rows"`
+		NullCount      *int64 `json:"null_count,omitempty"`
+		Min            any    `json:"min,omitempty"`
+		Max            any    `json:"max,omitempty"`
+	}
+
+	var t *table
+	if !out.json {
+		t = out.table("ROW GROUP", "COLUMN", "PATH", "PAGE", "OFFSET", "COMPRESSED SIZE", "FIRST ROW", "ROWS", "NULLS", "MIN", "MAX")
+	}
+	indexed := false
+	err := file.chunks(func(rowGroup int, chunk *metadata.ColumnChunkMeta) error {
+		column := chunk.Column()
+		offsetIndex, err := file.reader.OffsetIndex(rowGroup, column.Index)
+		if err != nil || offsetIndex == nil {
+			return err
+		}
+		columnIndex, err := file.reader.ColumnIndex(rowGroup, column.Index)
+		if err != nil {
+			return err
+		}
+		if columnIndex != nil && columnIndex.NumPages() != offsetIndex.NumPages() {
+			columnIndex = nil
+		}
+		indexed = true
+
+		numRows := file.meta.RowGroup(rowGroup).NumRows()
+		for i, location := range offsetIndex.PageLocations {
+			record := pageRecord{
+				File:           file.path,
+				RowGroup:       rowGroup,
+				Column:         column.Index,
+				Path:           column.PathString(),
+				Page:           i,
+				Offset:         location.Offset,
+				CompressedSize: location.CompressedPageSize,
+				FirstRow:       location.FirstRowIndex,
+				NumRows:        offsetIndex.LastRowIndex(i, numRows) - location.FirstRowIndex + 1,
+			}
+			if columnIndex != nil {
+				if columnIndex.HasNullCounts && i < len(columnIndex.NullCounts) {
+					record.NullCount = &columnIndex.NullCounts[i]
+				}
+				if !columnIndex.NullPages[i] {
+					record.Min = statValue(column.Leaf, columnIndex.Min[i])
+					record.Max = statValue(column.Leaf, columnIndex.Max[i])
+				}
+			}
+			if out.json {
+				record.Min, record.Max = jsonValue(record.Min), jsonValue(record.Max)
+				if err := out.record(record); err != nil {
+					return err
+				}
+				continue
+			}
+			t.row(record.RowGroup, record.Column, record.Path, record.Page, record.Offset, record.CompressedSize,
+				record.FirstRow, record.NumRows, optional(record.NullCount), optionalValue(record.Min), optionalValue(record.Max))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if t != nil {
+		if err := t.close(); err != nil {
+			return err
+		}
+	}
+	if !indexed && len(file.rowGroups) > 0 && len(file.columns) > 0 {
+		return fmt.Errorf("no page index for the selected column chunks")
+	}
+	return nil
+}
+
+func runStats(out *output, file *inputFile) error {
+	type statsRecord struct {
+		File          string `json:"file"`
+		RowGroup      int    `json:"row_group"`
+		Column        int    `json:"column"`
+		Path          string `json:"path"`
+		NullCount     *int64 `json:"null_count,omitempty"`
+		DistinctCount *int64 `json:"distinct_count,omitempty"`
+		Min           any    `json:"min,omitempty"`
+		Max           any    `json:"max,omitempty"`
+		MinExact      *bool  `json:"min_exact,omitempty"`
+		MaxExact      *bool  `json:"max_exact,omitempty"`
+	}
+
+	var t *table
+	if !out.json {
+		t = out.table("ROW GROUP", "COLUMN", "PATH", "NULLS", "DISTINCT", "MIN", "MAX")
+	}
+	err := file.chunks(func(rowGroup int, chunk *metadata.ColumnChunkMeta) error {
+		column := chunk.Column()
+		record := statsRecord{File: file.path, RowGroup: rowGroup, Column: column.Index, Path: column.PathString()}
+		if statistics := chunk.Statistics(); statistics != nil {
+			if statistics.HasNullCount {
+				record.NullCount = &statistics.NullCount
+			}
+			if statistics.HasDistinctCount {
+				record.DistinctCount = &statistics.DistinctCount
+			}
+			if statistics.HasMinMax {
+				record.Min = statValue(column.Leaf, statistics.Min)
+				record.Max = statValue(column.Leaf, statistics.Max)
+				record.MinExact, record.MaxExact = &statistics.IsMinExact, &statistics.IsMaxExact
+			}
+		}
+		if out.json {
+			record.Min, record.Max = jsonValue(record.Min), jsonValue(record.Max)
+			return out.record(record)
+		}
+		t.row(record.RowGroup, record.Column, record.Path, optional(record.NullCount), optional(record.DistinctCount),
+			optionalValue(record.Min), optionalValue(record.Max))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if t != nil {
+		return t.close()
+	}
+	return nil
+}
+
+func runCat(out *output, file *inputFile) error {
+	return printRows(out, file, -1)
+}
+
+func runHead(out *output, file *inputFile) error {
+	return printRows(out, file, file.limit)
+}
+
+// printRows prints up to limit rows of the selected row groups, or all of
+// them when limit is negative.
+func printRows(out *output, file *inputFile, limit int) error {
+	fields := file.reader.GetSchema().Children
+	printed := 0
+	for _, i := range file.rowGroups {
+		if limit >= 0 && printed >= limit {
+			break
+		}
+		rows, err := file.reader.ReadRowGroup(i)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			if limit >= 0 && printed >= limit {
+				break
+			}
+			printed++
+			if out.json {
+				if err := out.record(jsonValue(row)); err != nil {
+					return err
+				}
+				continue
+			}
+			if out.rows > 0 {
+				fmt.Fprintln(out.w)
+			}
+			out.rows++
+			t := out.table()
+			for _, field := range fields {
+				t.row(field.Name+":", textValue(row[field.Name]))
+			}
+			if err := t.close(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func optional(value *int64) any {
+	if value == nil {
+		return "-"
+	}
+	return *value
+}
+
+func optionalValue(value any) any {
+	if value == nil {
+		return "-"
+	}
+	return textValue(value)
+}
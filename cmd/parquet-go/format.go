@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"text/tabwriter"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/RichardNooooh/parquet-go/internal/float16"
+	"github.com/RichardNooooh/parquet-go/parquet"
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+// output writes the results of a command as text or as JSON lines.
+type output struct {
+	w    *bufio.Writer
+	json bool
+	// rows counts the rows printed as text since the last header, which are
+	// separated by blank lines.
+	rows int
+}
+
+func newOutput(w io.Writer, json bool) *output {
+	return &output{w: bufio.NewWriter(w), json: json}
+}
+
+// header introduces the text output of one of several files.
+func (o *output) header(path string, first bool) {
+	if !first {
+		fmt.Fprintln(o.w)
+	}
+	fmt.Fprintf(o.w, "%s:\n", path)
+	o.rows = 0
+}
+
+func (o *output) record(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	o.w.Write(data)
+	return o.w.WriteByte('\n')
+}
+
+func (o *output) flush() error { return o.w.Flush() }
+
+// table aligns text output in columns.
+type table struct {
+	w *tabwriter.Writer
+}
+
+func (o *output) table(header ...any) *table {
+	t := &table{w: tabwriter.NewWriter(o.w, 0, 0, 2, ' ', 0)}
+	if len(header) > 0 {
+		t.row(header...)
+	}
+	return t
+}
+
+func (t *table) row(cells ...any) {
+	for i, cell := range cells {
+		if i > 0 {
+			fmt.Fprint(t.w, "\t")
+		}
+		fmt.Fprint(t.w, cell)
+	}
+	fmt.Fprintln(t.w)
+}
+
+func (t *table) close() error { return t.w.Flush() }
+
+// statValue converts a physical min or max value of a leaf into a value
+// that prints legibly: FLOAT16 values as floats, and byte arrays as text
+// or hexadecimal.
+func statValue(leaf *schema.SchemaElement, value any) any {
+	switch v := value.(type) {
+	case []byte:
+		if leaf.LogicalType != nil && leaf.LogicalType.Kind == schema.LogicalFloat16 && len(v) == 2 {
+			return float16.ToFloat32(binary.LittleEndian.Uint16(v))
+		}
+		return bytesValue(v)
+	case [12]byte:
+		return bytesValue(v[:])
+	}
+	return value
+}
+
+// bytesValue returns printable UTF-8 as a string and anything else as
+// hexadecimal.
+func bytesValue(b []byte) string {
+	if utf8.Valid(b) {
+		printable := true
+		for _, r := range string(b) {
+			if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+				printable = false
+				break
+			}
+		}
+		if printable {
+			return string(b)
+		}
+	}
+	return "0x" + hex.EncodeToString(b)
+}
+
+// jsonValue converts a value read from a file into one that encoding/json
+// marshals without error and legibly: byte arrays become strings and
+// non-finite floats their names.
+func jsonValue(value any) any {
+	switch v := value.(type) {
+	case parquet.Row:
+		return jsonValue(map[string]any(v))
+	case map[string]any:
+		group := make(map[string]any, len(v))
+		for name, field := range v {
+			group[name] = jsonValue(field)
+		}
+		return group
+	case []any:
+		list := make([]any, len(v))
+		for i, element := range v {
+			list[i] = jsonValue(element)
+		}
+		return list
+	case []byte:
+		return bytesValue(v)
+	case [12]byte:
+		return bytesValue(v[:])
+	case float32:
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			return strconv.FormatFloat(float64(v), 'g', -1, 32)
+		}
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return strconv.FormatFloat(v, 'g', -1, 64)
+		}
+	case time.Time:
+		// JSON only holds RFC 3339 times, whose years have four digits.
+		if v.Year() < 0 || v.Year() > 9999 {
+			return v.String()
+		}
+		return v
+	case json.Marshaler:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	}
+	return value
+}
+
+// textValue formats a value for text output, with groups and lists as
+// compact JSON.
+func textValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case parquet.Row, map[string]any, []any:
+		data, err := json.Marshal(jsonValue(v))
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+	return fmt.Sprint(jsonValue(value))
+}
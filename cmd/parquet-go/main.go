@@ -1,91 +1,381 @@
+// Command parquet-go inspects Parquet files.
+//
+//	parquet-go <command> [flags] <file>...
+//
+// Commands print the metadata, schema, row groups, column chunks, pages,
+// rows or statistics of each file, as aligned text or, with -json, as one
+// JSON object per line. Encrypted files are read with the keys given with
+// -footer-key and -column-key.
 package main
 
 import (
-	"context"
-	"encoding/json"
+	"encoding/hex"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"maps"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 
-	"github.com/RichardNooooh/parquet-go/internal/file"
-	// "github.com/RichardNooooh/parquet-go/internal/metadata/gen-go/parquet"
+	"github.com/RichardNooooh/parquet-go/metadata"
+	"github.com/RichardNooooh/parquet-go/parquet"
+	"github.com/RichardNooooh/parquet-go/schema"
 )
 
-func run() error {
-	f, err := os.Open(os.Args[1])
+// Exit codes: failing to read a file is reported after the remaining files
+// have been processed, while usage errors stop before reading anything.
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+type command struct {
+	name    string
+	summary string
+	// rowGroups and columns report whether the command takes the -rowgroup
+	// and -column flags.
+	rowGroups bool
+	columns   bool
+	// rows reports whether the command reads rows, whose values are
+	// converted to their logical types and projected on the columns.
+	rows bool
+	run  func(out *output, file *inputFile) error
+}
+
+var commands = []*command{
+	{name: "meta", summary: "print the file metadata", run: runMeta},
+	{name: "schema", summary: "print the schema", run: runSchema},
+	{name: "rowgroups", summary: "list the row groups", rowGroups: true, run: runRowGroups},
+	{name: "columns", summary: "list the column chunks", rowGroups: true, columns: true, run: runColumns},
+	{name: "pages", summary: "list the pages of column chunks with a page index", rowGroups: true, columns: true, run: runPages},
+	{name: "cat", summary: "print all rows", rowGroups: true, columns: true, rows: true, run: runCat},
+	{name: "head", summary: "print the first rows", rowGroups: true, columns: true, rows: true, run: runHead},
+	{name: "stats", summary: "print the statistics of column chunks", rowGroups: true, columns: true, run: runStats},
+}
+
+func lookupCommand(name string) *command {
+	for _, c := range commands {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: parquet-go <command> [flags] <file>...")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `run "parquet-go <command> -h" for the flags of a command`)
+}
+
+// options are the flags of a command.
+type options struct {
+	json       bool
+	rowGroups  indexList
+	columns    stringList
+	limit      int
+	footerKey  keyFlag
+	columnKeys columnKeyList
+	aadPrefix  string
+}
+
+// keyFlag is a flag of an AES key in hexadecimal.
+type keyFlag []byte
+
+func (k *keyFlag) String() string { return hex.EncodeToString(*k) }
+
+func (k *keyFlag) Set(value string) error {
+	key, err := hex.DecodeString(value)
+	if err != nil {
+		return errors.New("invalid hexadecimal key")
+	}
+	*k = key
+	return nil
+}
+
+// columnKeyList is a flag of column keys, each a dotted path and an AES key
+// in hexadecimal separated by "=", that may be repeated or given as a
+// comma-separated list.
+type columnKeyList map[string][]byte
+
+func (l *columnKeyList) String() string {
+	parts := make([]string, 0, len(*l))
+	for _, path := range slices.Sorted(maps.Keys(*l)) {
+		parts = append(parts, path+"="+hex.EncodeToString((*l)[path]))
+	}
+	return strings.Join(parts, ",")
+}
+
+func (l *columnKeyList) Set(value string) error {
+	if *l == nil {
+		*l = make(columnKeyList)
+	}
+	for part := range strings.SplitSeq(value, ",") {
+		path, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok || path == "" {
+			return fmt.Errorf("invalid column key %q, expected path=key", part)
+		}
+		key, err := hex.DecodeString(value)
+		if err != nil {
+			return fmt.Errorf("invalid hexadecimal key for column %q", path)
+		}
+		(*l)[path] = key
+	}
+	return nil
+}
+
+// indexList is a flag of row group indexes that may be repeated or given as
+// a comma-separated list.
+type indexList []int
+
+func (l *indexList) String() string {
+	parts := make([]string, len(*l))
+	for i, index := range *l {
+		parts[i] = strconv.Itoa(index)
+	}
+	return strings.Join(parts, ",")
+}
+
+func (l *indexList) Set(value string) error {
+	for part := range strings.SplitSeq(value, ",") {
+		index, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || index < 0 {
+			return fmt.Errorf("invalid index %q", part)
+		}
+		*l = append(*l, index)
+	}
+	return nil
+}
+
+// stringList is a flag of columns, each an index or a dotted path, that may
+// be repeated or given as a comma-separated list.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(value string) error {
+	for part := range strings.SplitSeq(value, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			return errors.New("empty column")
+		}
+		*l = append(*l, part)
+	}
+	return nil
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command line args and returns the exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return exitUsage
+	}
+	if args[0] == "-h" || args[0] == "-help" || args[0] == "--help" || args[0] == "help" {
+		usage(stdout)
+		return exitOK
+	}
+	cmd := lookupCommand(args[0])
+	if cmd == nil {
+		fmt.Fprintf(stderr, "parquet-go: unknown command %q\n", args[0])
+		usage(stderr)
+		return exitUsage
+	}
+
+	var opts options
+	flags := flag.NewFlagSet("parquet-go "+cmd.name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "usage: parquet-go %s [flags] <file>...\n\n%s.\n", cmd.name, cmd.summary)
+		if hasFlags(flags) {
+			fmt.Fprintln(stderr, "\nflags:")
+			flags.PrintDefaults()
+		}
+	}
+	flags.BoolVar(&opts.json, "json", false, "print one JSON object per line")
+	if cmd.rowGroups {
+		flags.Var(&opts.rowGroups, "rowgroup", "only the row group at `index`, may be repeated")
+	}
+	if cmd.columns {
+		flags.Var(&opts.columns, "column", "only the column at `index or path`, may be repeated")
+	}
+	if cmd.name == "head" {
+		flags.IntVar(&opts.limit, "n", 10, "number of rows to print")
+	}
+	flags.Var(&opts.footerKey, "footer-key", "decrypt the footer and the columns encrypted with it with the hexadecimal `key`")
+	flags.Var(&opts.columnKeys, "column-key", "decrypt a column with `path=key`, a hexadecimal key, may be repeated")
+	flags.StringVar(&opts.aadPrefix, "aad-prefix", "", "AAD `prefix` of encrypted files written without it")
+	if err := flags.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if flags.NArg() == 0 {
+		fmt.Fprintf(stderr, "parquet-go %s: no input files\n", cmd.name)
+		flags.Usage()
+		return exitUsage
+	}
+	if opts.limit < 0 {
+		fmt.Fprintf(stderr, "parquet-go %s: invalid row count %d\n", cmd.name, opts.limit)
+		return exitUsage
+	}
+
+	out := newOutput(stdout, opts.json)
+	code := exitOK
+	for i, path := range flags.Args() {
+		if flags.NArg() > 1 && !opts.json && cmd.name != "cat" {
+			out.header(path, i == 0)
+		}
+		if err := runFile(cmd, out, path, &opts); err != nil {
+			if errors.Is(err, parquet.ErrMissingKey) {
+				err = fmt.Errorf("%w, pass the keys of encrypted files with -footer-key and -column-key", err)
+			}
+			fmt.Fprintf(stderr, "parquet-go %s: %s: %v\n", cmd.name, path, err)
+			code = exitFailure
+		}
+	}
+	if err := out.flush(); err != nil {
+		fmt.Fprintf(stderr, "parquet-go %s: %v\n", cmd.name, err)
+		return exitFailure
+	}
+	return code
+}
+
+func hasFlags(flags *flag.FlagSet) bool {
+	found := false
+	flags.VisitAll(func(*flag.Flag) { found = true })
+	return found
+}
+
+// inputFile is an open file together with the row groups and columns
+// selected by the flags.
+type inputFile struct {
+	path      string
+	reader    *parquet.ParquetReader
+	meta      *metadata.FileMeta
+	rowGroups []int
+	columns   []*schema.Column
+	limit     int
+}
+
+func runFile(cmd *command, out *output, path string, opts *options) error {
+	f, err := os.Open(path)
 	if err != nil {
 		return err
 	}
 	defer f.Close()
-	fileStat, err := f.Stat()
+	info, err := f.Stat()
 	if err != nil {
 		return err
 	}
-
-	fileReader := file.NewReader(f, fileStat.Size())
-	fileMetadata, err := file.GetFileMetadata(context.Background(), fileReader)
+	var readerOpts []parquet.ParquetReaderOption
+	if opts.footerKey != nil {
+		readerOpts = append(readerOpts, parquet.WithFooterKey(opts.footerKey))
+	}
+	for path, key := range opts.columnKeys {
+		readerOpts = append(readerOpts, parquet.WithColumnKey(path, key))
+	}
+	if opts.aadPrefix != "" {
+		readerOpts = append(readerOpts, parquet.WithAADPrefix([]byte(opts.aadPrefix)))
+	}
+	if cmd.rows {
+		readerOpts = append(readerOpts, parquet.WithLogicalTypes(true))
+	}
+	reader, err := parquet.Open(f, info.Size(), readerOpts...)
 	if err != nil {
 		return err
 	}
 
-	var output any
-
-	switch command := os.Args[2]; command {
-	case "fileVersion":
-		output = fileMetadata.GetVersion()
-	case "schema":
-		output = fileMetadata.GetSchema()
-	case "numRows":
-		output = fileMetadata.GetNumRows()
-	case "rowGroups":
-		output = fileMetadata.GetRowGroups()
-	case "firstRowGroup":
-		output = fileMetadata.GetRowGroups()[0]
-	case "firstRowGroupOffset":
-		output = fileMetadata.GetRowGroups()[0].GetFileOffset()
-	case "firstColumnChunk":
-		output = fileMetadata.GetRowGroups()[0].GetColumns()[0]
-	case "firstColumnMetadata":
-		output = fileMetadata.GetRowGroups()[0].GetColumns()[0].GetMetaData()
-	case "keyValues":
-		output = fileMetadata.GetKeyValueMetadata()
-	case "createdBy":
-		output = fileMetadata.GetCreatedBy()
-	case "columnOrders":
-		output = fileMetadata.GetColumnOrders()
-	case "encryptionAlgo":
-		output = fileMetadata.GetEncryptionAlgorithm()
-	case "signingKeyMetadata":
-		output = fileMetadata.GetFooterSigningKeyMetadata()
-	case "print":
-		outputString, _ := json.Marshal(fileMetadata)
-		fmt.Println(string(outputString))
-		return nil
-	default:
-		return fmt.Errorf("unknown command: %v\n", command)
-	}
-
-	fmt.Printf("%v\n", output)
-	// if os.Args[2] == "fileVersion" {
-	// 	output = fileMetadata.GetVersion()
-	// } else if os.Args[2] == "schema" {
-	// 	output = fileMetadata.GetSchema()
-	// } else if os.Args[2] == "numrows" {
-	// 	output = fileMetadata.GetNumRows()
-	// } else if
-
-	return nil
+	meta := reader.GetMeta()
+	rowGroups, err := selectRowGroups(meta, opts.rowGroups)
+	if err != nil {
+		return err
+	}
+	columns, err := selectColumns(meta, opts.columns)
+	if err != nil {
+		return err
+	}
+	if cmd.rows && len(opts.columns) > 0 {
+		// Columns selected by index are only known from the footer, so the
+		// rows are read by a reader of the same file projected on their paths.
+		paths := make([]string, len(columns))
+		for i, column := range columns {
+			paths[i] = column.PathString()
+		}
+		if reader, err = parquet.Open(f, info.Size(), append(readerOpts, parquet.WithColumns(paths...))...); err != nil {
+			return err
+		}
+	}
+	return cmd.run(out, &inputFile{
+		path:      path,
+		reader:    reader,
+		meta:      meta,
+		rowGroups: rowGroups,
+		columns:   columns,
+		limit:     opts.limit,
+	})
 }
 
-func main() {
+// selectRowGroups returns the indexes of the selected row groups, or of all
+// row groups when none are selected.
+func selectRowGroups(meta *metadata.FileMeta, selected []int) ([]int, error) {
+	if len(selected) == 0 {
+		rowGroups := make([]int, meta.NumRowGroups())
+		for i := range rowGroups {
+			rowGroups[i] = i
+		}
+		return rowGroups, nil
+	}
+	for _, index := range selected {
+		if index >= meta.NumRowGroups() {
+			return nil, fmt.Errorf("row group %d out of range, the file has %d", index, meta.NumRowGroups())
+		}
+	}
+	return selected, nil
+}
 
-	if len(os.Args) != 3 {
-		fmt.Fprintln(os.Stderr, "usage: parquet-go <file> <cmd>")
-		os.Exit(2)
+// selectColumns resolves the selected columns, each an index or a dotted
+// path, or returns all columns when none are selected.
+func selectColumns(meta *metadata.FileMeta, selected []string) ([]*schema.Column, error) {
+	all := meta.Columns()
+	if len(selected) == 0 {
+		return all, nil
+	}
+	columns := make([]*schema.Column, 0, len(selected))
+	for _, name := range selected {
+		column, err := lookupColumn(all, name)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
 	}
+	return columns, nil
+}
 
-	err := run()
+func lookupColumn(columns []*schema.Column, name string) (*schema.Column, error) {
+	for _, column := range columns {
+		if column.PathString() == name {
+			return column, nil
+		}
+	}
+	index, err := strconv.Atoi(name)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		return nil, fmt.Errorf("no column %q", name)
+	}
+	if index < 0 || index >= len(columns) {
+		return nil, fmt.Errorf("column %d out of range, the file has %d", index, len(columns))
 	}
+	return columns[index], nil
 }
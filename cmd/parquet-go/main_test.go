@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/RichardNooooh/parquet-go/parquet"
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+// writeFile writes rows of an id and an optional name in row groups of 3,
+// and returns the path of the file.
+func writeFile(t *testing.T, numRows int) string {
+	t.Helper()
+	name := schema.NewLeaf("name", schema.ByteArray, schema.Optional)
+	name.LogicalType = &schema.LogicalType{Kind: schema.LogicalString}
+	root := schema.NewSchema(schema.NewLeaf("id", schema.Int64, schema.Required), name)
+
+	path := filepath.Join(t.TempDir(), "test.parquet")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("unable to create file: %v", err)
+	}
+	defer f.Close()
+	writer, err := parquet.NewWriter(f, root, parquet.WithRowGroupSize(3), parquet.WithPageIndex(true))
+	if err != nil {
+		t.Fatalf("unable to create writer: %v", err)
+	}
+	for i := range numRows {
+		row := parquet.Row{"id": int64(i)}
+		if i%2 == 0 {
+			row["name"] = "row " + string(rune('a'+i))
+		}
+		if err := writer.Write(row); err != nil {
+			t.Fatalf("unable to write row: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("unable to close writer: %v", err)
+	}
+	return path
+}
+
+func runArgs(args ...string) (code int, stdout, stderr string) {
+	var out, errOut bytes.Buffer
+	code = run(args, &out, &errOut)
+	return code, out.String(), errOut.String()
+}
+
+func TestRun(t *testing.T) {
+	path := writeFile(t, 7)
+
+	testcases := map[string]struct {
+		args     []string
+		contains []string
+		lines    int
+	}{
+		"meta":              {args: []string{"meta", path}, contains: []string{"rows:", "7", "row groups:", "3"}},
+		"schema":            {args: []string{"schema", path}, contains: []string{"message schema {", "required int64 id;", "optional binary name (STRING);"}},
+		"rowgroups":         {args: []string{"rowgroups", path}, lines: 4},
+		"rowgroup selected": {args: []string{"rowgroups", "-rowgroup", "2", path}, lines: 2},
+		"columns":           {args: []string{"columns", path}, contains: []string{"UNCOMPRESSED", "name", "BYTE_ARRAY"}, lines: 7},
+		"column by path":    {args: []string{"columns", "-column", "name", path}, lines: 4},
+		"pages":             {args: []string{"pages", "-rowgroup", "0", "-column", "1", path}, contains: []string{"row a", "row c"}, lines: 2},
+		"stats":             {args: []string{"stats", "-rowgroup=1,2", "-column", "0", path}, contains: []string{"3", "5", "6"}, lines: 3},
+		"cat":               {args: []string{"cat", path}, contains: []string{"id:", "name:  null", "name:  row g"}},
+		"head json":         {args: []string{"head", "-json", "-n", "2", path}, contains: []string{`{"id":0,"name":"row a"}`, `{"id":1,"name":null}`}, lines: 2},
+		"cat projected":     {args: []string{"cat", "-json", "-rowgroup", "2", "-column", "id", path}, contains: []string{`{"id":6}`}, lines: 1},
+		"cat column index":  {args: []string{"cat", "-json", "-rowgroup", "2", "-column", "0", path}, contains: []string{`{"id":6}`}, lines: 1},
+		"several files":     {args: []string{"meta", path, path}, contains: []string{path + ":"}},
+		"several json":      {args: []string{"meta", "-json", path, path}, lines: 2},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			code, stdout, stderr := runArgs(test.args...)
+			if code != exitOK {
+				t.Fatalf("expected exit code %d, got %d: %s", exitOK, code, stderr)
+			}
+			for _, s := range test.contains {
+				if !strings.Contains(stdout, s) {
+					t.Errorf("expected output to contain %q, got:\n%s", s, stdout)
+				}
+			}
+			if lines := strings.Count(stdout, "\n"); test.lines > 0 && lines != test.lines {
+				t.Errorf("expected %d lines, got %d:\n%s", test.lines, lines, stdout)
+			}
+		})
+	}
+}
+
+func TestRunJSON(t *testing.T) {
+	path := writeFile(t, 4)
+	for _, command := range []string{"meta", "schema", "rowgroups", "columns", "pages", "stats", "cat"} {
+		t.Run(command, func(t *testing.T) {
+			code, stdout, stderr := runArgs(command, "-json", path)
+			if code != exitOK {
+				t.Fatalf("expected exit code %d, got %d: %s", exitOK, code, stderr)
+			}
+			for line := range strings.Lines(stdout) {
+				if !json.Valid([]byte(line)) {
+					t.Errorf("expected a JSON object, got %q", line)
+				}
+			}
+		})
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	path := writeFile(t, 4)
+	emptyFile := filepath.Join(t.TempDir(), "empty.parquet")
+	if err := os.WriteFile(emptyFile, nil, 0o644); err != nil {
+		t.Fatalf("unable to create file: %v", err)
+	}
+	noRows := writeFile(t, 0)
+
+	testcases := map[string]struct {
+		args   []string
+		code   int
+		stderr string
+	}{
+		"no arguments":        {args: nil, code: exitUsage, stderr: "usage:"},
+		"unknown command":     {args: []string{"firstRowGroup", path}, code: exitUsage, stderr: `unknown command "firstRowGroup"`},
+		"no files":            {args: []string{"meta"}, code: exitUsage, stderr: "no input files"},
+		"unknown flag":        {args: []string{"schema", "-rowgroup", "0", path}, code: exitUsage, stderr: "-rowgroup"},
+		"invalid index":       {args: []string{"stats", "-rowgroup", "x", path}, code: exitUsage, stderr: `invalid index "x"`},
+		"missing file":        {args: []string{"meta", "missing.parquet"}, code: exitFailure, stderr: "missing.parquet"},
+		"empty file":          {args: []string{"rowgroups", emptyFile}, code: exitFailure, stderr: "not a Parquet file"},
+		"row group too large": {args: []string{"columns", "-rowgroup", "5", path}, code: exitFailure, stderr: "row group 5 out of range"},
+		"no row groups":       {args: []string{"columns", "-rowgroup", "0", noRows}, code: exitFailure, stderr: "row group 0 out of range"},
+		"unknown column":      {args: []string{"cat", "-column", "missing", path}, code: exitFailure, stderr: `no column "missing"`},
+		"column too large":    {args: []string{"stats", "-column", "2", path}, code: exitFailure, stderr: "column 2 out of range"},
+		"one bad file":        {args: []string{"meta", path, emptyFile}, code: exitFailure, stderr: emptyFile},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			code, _, stderr := runArgs(test.args...)
+			if code != test.code {
+				t.Errorf("expected exit code %d, got %d", test.code, code)
+			}
+			if !strings.Contains(stderr, test.stderr) {
+				t.Errorf("expected error to contain %q, got %q", test.stderr, stderr)
+			}
+		})
+	}
+}
+
+func TestRunNoRowGroups(t *testing.T) {
+	path := writeFile(t, 0)
+	for _, command := range []string{"meta", "schema", "rowgroups", "columns", "pages", "cat", "head", "stats"} {
+		t.Run(command, func(t *testing.T) {
+			if code, _, stderr := runArgs(command, path); code != exitOK {
+				t.Errorf("expected exit code %d, got %d: %s", exitOK, code, stderr)
+			}
+		})
+	}
+}
+
+func TestRunEncrypted(t *testing.T) {
+	dir := filepath.Join("..", "..", "testdata", "encryption")
+	encryptedFooter := filepath.Join(dir, "encrypt_columns_and_footer.parquet.encrypted")
+	plaintextFooter := filepath.Join(dir, "encrypt_columns_plaintext_footer.parquet.encrypted")
+	footerKey := hex.EncodeToString([]byte("0123456789012345"))
+	columnKeys := "double_field=" + hex.EncodeToString([]byte("1234567890123450")) + ",float_field=" + hex.EncodeToString([]byte("1234567890123451"))
+
+	testcases := map[string]struct {
+		args     []string
+		code     int
+		contains []string
+		stderr   string
+	}{
+		"meta":               {args: []string{"meta", "-footer-key", footerKey, encryptedFooter}, contains: []string{"AES_GCM_V1", "encrypted", "kf"}},
+		"meta plaintext":     {args: []string{"meta", "-json", plaintextFooter}, contains: []string{`"encryption_algorithm":"AES_GCM_V1"`, `"footer":"signed"`}},
+		"cat":                {args: []string{"head", "-json", "-n", "1", "-footer-key", footerKey, "-column-key", columnKeys, encryptedFooter}, contains: []string{`"double_field":0`}},
+		"missing footer key": {args: []string{"meta", encryptedFooter}, code: exitFailure, stderr: "pass the keys of encrypted files with -footer-key"},
+		"missing column key": {args: []string{"cat", plaintextFooter}, code: exitFailure, stderr: "missing decryption key"},
+		"invalid key":        {args: []string{"meta", "-footer-key", "xyz", encryptedFooter}, code: exitUsage, stderr: "invalid hexadecimal key"},
+		"invalid column key": {args: []string{"cat", "-column-key", "double_field", encryptedFooter}, code: exitUsage, stderr: "expected path=key"},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			code, stdout, stderr := runArgs(test.args...)
+			if code != test.code {
+				t.Fatalf("expected exit code %d, got %d: %s", test.code, code, stderr)
+			}
+			for _, s := range test.contains {
+				if !strings.Contains(stdout, s) {
+					t.Errorf("expected output to contain %q, got:\n%s", s, stdout)
+				}
+			}
+			if !strings.Contains(stderr, test.stderr) {
+				t.Errorf("expected error to contain %q, got %q", test.stderr, stderr)
+			}
+		})
+	}
+}
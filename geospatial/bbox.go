@@ -0,0 +1,163 @@
+package geospatial
+
+import (
+	"math"
+	"slices"
+)
+
+// BoundingBox bounds the coordinates of geometries. In GEOGRAPHY columns X
+// is the longitude, and Xmin is greater than Xmax for boxes that cross the
+// antimeridian.
+type BoundingBox struct {
+	Xmin, Xmax, Ymin, Ymax float64
+	// Zmin and Zmax are only set when HasZ, and Mmin and Mmax when HasM.
+	Zmin, Zmax, Mmin, Mmax float64
+	HasZ, HasM             bool
+}
+
+// Intersects reports whether two boxes overlap, borders included. Z and M
+// are only compared when both boxes have them.
+func (b BoundingBox) Intersects(other BoundingBox) bool {
+	if !intersectsX(b, other) || b.Ymin > other.Ymax || other.Ymin > b.Ymax {
+		return false
+	}
+	if b.HasZ && other.HasZ && (b.Zmin > other.Zmax || other.Zmin > b.Zmax) {
+		return false
+	}
+	if b.HasM && other.HasM && (b.Mmin > other.Mmax || other.Mmin > b.Mmax) {
+		return false
+	}
+	return true
+}
+
+// intersectsX compares X ranges, either of which may wrap around.
+func intersectsX(a, b BoundingBox) bool {
+	aWraps, bWraps := a.Xmin > a.Xmax, b.Xmin > b.Xmax
+	switch {
+	case aWraps && bWraps:
+		return true
+	case aWraps:
+		return b.Xmax >= a.Xmin || b.Xmin <= a.Xmax
+	case bWraps:
+		return a.Xmax >= b.Xmin || a.Xmin <= b.Xmax
+	}
+	return a.Xmin <= b.Xmax && b.Xmin <= a.Xmax
+}
+
+// Bounds returns the bounding box of the coordinates of the geometry, and
+// false when it has none. NaN coordinates are ignored.
+func (g Geometry) Bounds() (BoundingBox, bool) {
+	var bounds bounds
+	bounds.addGeometry(g)
+	return bounds.box()
+}
+
+// bounds accumulates the ranges of coordinates.
+type bounds struct {
+	x, y, z, m interval
+}
+
+type interval struct {
+	min, max float64
+	set      bool
+}
+
+func (i *interval) add(v float64) {
+	if math.IsNaN(v) {
+		return
+	}
+	if !i.set {
+		i.min, i.max, i.set = v, v, true
+		return
+	}
+	i.min, i.max = min(i.min, v), max(i.max, v)
+}
+
+func (b *bounds) addGeometry(g Geometry) {
+	add := func(coordinates []Coordinate) {
+		for _, c := range coordinates {
+			b.x.add(c.X)
+			b.y.add(c.Y)
+			if g.Dimension.HasZ() {
+				b.z.add(c.Z)
+			}
+			if g.Dimension.HasM() {
+				b.m.add(c.M)
+			}
+		}
+	}
+	add(g.Coordinates)
+	for _, ring := range g.Rings {
+		add(ring)
+	}
+	for _, member := range g.Geometries {
+		b.addGeometry(member)
+	}
+}
+
+func (b *bounds) box() (BoundingBox, bool) {
+	if !b.x.set || !b.y.set {
+		return BoundingBox{}, false
+	}
+	return BoundingBox{
+		Xmin: b.x.min, Xmax: b.x.max,
+		Ymin: b.y.min, Ymax: b.y.max,
+		Zmin: b.z.min, Zmax: b.z.max, HasZ: b.z.set,
+		Mmin: b.m.min, Mmax: b.m.max, HasM: b.m.set,
+	}, true
+}
+
+// Statistics are the geospatial statistics of a column chunk.
+type Statistics struct {
+	// BoundingBox is nil when it is unknown.
+	BoundingBox *BoundingBox
+	// Types holds the sorted codes of the geometry types and dimensions,
+	// as returned by Geometry.Code. It is empty when they are unknown.
+	Types []int32
+}
+
+// Accumulator computes the statistics of WKB values.
+type Accumulator struct {
+	bounds  bounds
+	types   map[int32]struct{}
+	invalid bool
+}
+
+func NewAccumulator() *Accumulator {
+	return &Accumulator{types: make(map[int32]struct{})}
+}
+
+// Add records a WKB value. Statistics are unknown once a value is invalid.
+func (a *Accumulator) Add(wkb []byte) {
+	if a.invalid {
+		return
+	}
+	geometry, err := ParseWKB(wkb)
+	if err != nil {
+		a.invalid = true
+		return
+	}
+	a.AddGeometry(geometry)
+}
+
+func (a *Accumulator) AddGeometry(g Geometry) {
+	a.bounds.addGeometry(g)
+	a.types[g.Code()] = struct{}{}
+}
+
+// Statistics returns the statistics of the values added, or nil when they
+// are unknown. The bounding box is nil when there is no coordinate.
+func (a *Accumulator) Statistics() *Statistics {
+	if a.invalid {
+		return nil
+	}
+	statistics := &Statistics{}
+	if box, ok := a.bounds.box(); ok {
+		statistics.BoundingBox = &box
+	}
+	for code := range a.types {
+		statistics.Types = append(statistics.Types, code)
+	}
+	slices.Sort(statistics.Types)
+	return statistics
+}
@@ -0,0 +1,337 @@
+// Package geospatial decodes the well-known binary (WKB) values of GEOMETRY
+// and GEOGRAPHY columns into a small geometry model, and computes the
+// bounding boxes and geometry types of their statistics.
+package geospatial
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrInvalidWKB is returned for values that are not valid WKB.
+var ErrInvalidWKB = errors.New("invalid WKB")
+
+// GeometryType is the type of a geometry, numbered as in WKB.
+type GeometryType int
+
+const (
+	Point GeometryType = iota + 1
+	LineString
+	Polygon
+	MultiPoint
+	MultiLineString
+	MultiPolygon
+	GeometryCollection
+)
+
+var geometryTypeNames = [...]string{
+	"", "POINT", "LINESTRING", "POLYGON", "MULTIPOINT", "MULTILINESTRING",
+	"MULTIPOLYGON", "GEOMETRYCOLLECTION",
+}
+
+func (t GeometryType) String() string {
+	if t > 0 && int(t) < len(geometryTypeNames) {
+		return geometryTypeNames[t]
+	}
+	return fmt.Sprintf("GeometryType(%d)", int(t))
+}
+
+// Dimension is the set of coordinates of a geometry.
+type Dimension int
+
+const (
+	XY Dimension = iota
+	XYZ
+	XYM
+	XYZM
+)
+
+func (d Dimension) String() string {
+	if d >= XY && d <= XYZM {
+		return [...]string{"XY", "XYZ", "XYM", "XYZM"}[d]
+	}
+	return fmt.Sprintf("Dimension(%d)", int(d))
+}
+
+func (d Dimension) HasZ() bool { return d == XYZ || d == XYZM }
+
+func (d Dimension) HasM() bool { return d == XYM || d == XYZM }
+
+func (d Dimension) size() int {
+	return [...]int{2, 3, 3, 4}[d]
+}
+
+// Coordinate is a position. Z and M are only meaningful when the dimension
+// of its geometry has them.
+type Coordinate struct {
+	X, Y, Z, M float64
+}
+
+// Geometry is a simple feature geometry.
+type Geometry struct {
+	Type      GeometryType
+	Dimension Dimension
+	// Coordinates holds the coordinates of a line string, or of a point,
+	// which has none when it is empty.
+	Coordinates []Coordinate
+	// Rings holds the rings of a polygon, the exterior ring first.
+	Rings [][]Coordinate
+	// Geometries holds the members of multi geometries and collections.
+	Geometries []Geometry
+}
+
+// Code returns the ISO WKB code of the geometry type and dimension, which is
+// also its code in geospatial_types statistics.
+func (g Geometry) Code() int32 {
+	return int32(g.Type) + int32(g.Dimension)*1000
+}
+
+// maxWKBDepth bounds the nesting of geometry collections.
+const maxWKBDepth = 64
+
+// ParseWKB decodes an ISO WKB geometry. Extended WKB with Z and M flags but
+// without SRID is also accepted.
+func ParseWKB(b []byte) (Geometry, error) {
+	parser := &wkbParser{data: b}
+	geometry, err := parser.geometry(0)
+	if err != nil {
+		return Geometry{}, err
+	}
+	if parser.offset != len(b) {
+		return Geometry{}, fmt.Errorf("%w: %d trailing bytes", ErrInvalidWKB, len(b)-parser.offset)
+	}
+	return geometry, nil
+}
+
+type wkbParser struct {
+	data   []byte
+	offset int
+	order  binary.ByteOrder
+}
+
+func (p *wkbParser) uint32() (uint32, error) {
+	if p.offset+4 > len(p.data) {
+		return 0, fmt.Errorf("%w: truncated", ErrInvalidWKB)
+	}
+	v := p.order.Uint32(p.data[p.offset:])
+	p.offset += 4
+	return v, nil
+}
+
+// count reads a number of elements of at least minSize bytes each.
+func (p *wkbParser) count(minSize int) (int, error) {
+	n, err := p.uint32()
+	if err != nil {
+		return 0, err
+	}
+	if uint64(n)*uint64(minSize) > uint64(len(p.data)-p.offset) {
+		return 0, fmt.Errorf("%w: truncated", ErrInvalidWKB)
+	}
+	return int(n), nil
+}
+
+func (p *wkbParser) coordinate(dimension Dimension) (Coordinate, error) {
+	size := dimension.size() * 8
+	if p.offset+size > len(p.data) {
+		return Coordinate{}, fmt.Errorf("%w: truncated", ErrInvalidWKB)
+	}
+	values := make([]float64, dimension.size())
+	for i := range values {
+		values[i] = math.Float64frombits(p.order.Uint64(p.data[p.offset+8*i:]))
+	}
+	p.offset += size
+	c := Coordinate{X: values[0], Y: values[1]}
+	switch dimension {
+	case XYZ:
+		c.Z = values[2]
+	case XYM:
+		c.M = values[2]
+	case XYZM:
+		c.Z, c.M = values[2], values[3]
+	}
+	return c, nil
+}
+
+func (p *wkbParser) coordinates(dimension Dimension) ([]Coordinate, error) {
+	n, err := p.count(dimension.size() * 8)
+	if err != nil {
+		return nil, err
+	}
+	coordinates := make([]Coordinate, n)
+	for i := range coordinates {
+		if coordinates[i], err = p.coordinate(dimension); err != nil {
+			return nil, err
+		}
+	}
+	return coordinates, nil
+}
+
+func (p *wkbParser) geometry(depth int) (Geometry, error) {
+	if depth > maxWKBDepth {
+		return Geometry{}, fmt.Errorf("%w: nested too deeply", ErrInvalidWKB)
+	}
+	if p.offset >= len(p.data) {
+		return Geometry{}, fmt.Errorf("%w: truncated", ErrInvalidWKB)
+	}
+	switch p.data[p.offset] {
+	case 0:
+		p.order = binary.BigEndian
+	case 1:
+		p.order = binary.LittleEndian
+	default:
+		return Geometry{}, fmt.Errorf("%w: invalid byte order %d", ErrInvalidWKB, p.data[p.offset])
+	}
+	p.offset++
+	code, err := p.uint32()
+	if err != nil {
+		return Geometry{}, err
+	}
+	geometry, err := geometryOfCode(code)
+	if err != nil {
+		return Geometry{}, err
+	}
+
+	switch geometry.Type {
+	case Point:
+		c, err := p.coordinate(geometry.Dimension)
+		if err != nil {
+			return Geometry{}, err
+		}
+		// Empty points have NaN coordinates.
+		if !math.IsNaN(c.X) || !math.IsNaN(c.Y) {
+			geometry.Coordinates = []Coordinate{c}
+		}
+	case LineString:
+		geometry.Coordinates, err = p.coordinates(geometry.Dimension)
+	case Polygon:
+		var n int
+		if n, err = p.count(4); err != nil {
+			return Geometry{}, err
+		}
+		geometry.Rings = make([][]Coordinate, n)
+		for i := range geometry.Rings {
+			if geometry.Rings[i], err = p.coordinates(geometry.Dimension); err != nil {
+				return Geometry{}, err
+			}
+		}
+	default:
+		var n int
+		// Every member has at least a byte order and a type.
+		if n, err = p.count(5); err != nil {
+			return Geometry{}, err
+		}
+		geometry.Geometries = make([]Geometry, n)
+		for i := range geometry.Geometries {
+			member, err := p.geometry(depth + 1)
+			if err != nil {
+				return Geometry{}, err
+			}
+			if err := checkMember(geometry, member); err != nil {
+				return Geometry{}, err
+			}
+			geometry.Geometries[i] = member
+		}
+	}
+	return geometry, err
+}
+
+// geometryOfCode returns an empty geometry of the type and dimension of an
+// ISO or extended WKB code.
+func geometryOfCode(code uint32) (Geometry, error) {
+	const extendedZ, extendedM, extendedSRID = 0x80000000, 0x40000000, 0x20000000
+	if code&extendedSRID != 0 {
+		return Geometry{}, fmt.Errorf("%w: unsupported SRID", ErrInvalidWKB)
+	}
+	hasZ, hasM := code&extendedZ != 0, code&extendedM != 0
+	code &^= extendedZ | extendedM
+	if code/1000 > 3 {
+		return Geometry{}, fmt.Errorf("%w: unknown geometry type %d", ErrInvalidWKB, code)
+	}
+	dimension := Dimension(code / 1000)
+	if hasZ || hasM {
+		if dimension != XY {
+			return Geometry{}, fmt.Errorf("%w: unknown geometry type %d", ErrInvalidWKB, code)
+		}
+		switch {
+		case hasZ && hasM:
+			dimension = XYZM
+		case hasZ:
+			dimension = XYZ
+		default:
+			dimension = XYM
+		}
+	}
+	typ := GeometryType(code % 1000)
+	if typ < Point || typ > GeometryCollection {
+		return Geometry{}, fmt.Errorf("%w: unknown geometry type %d", ErrInvalidWKB, code)
+	}
+	return Geometry{Type: typ, Dimension: dimension}, nil
+}
+
+// checkMember checks the type and dimension of a member of a multi geometry
+// or collection.
+func checkMember(parent, member Geometry) error {
+	if member.Dimension != parent.Dimension {
+		return fmt.Errorf("%w: %v member of a %v geometry", ErrInvalidWKB, member.Dimension, parent.Dimension)
+	}
+	expected := map[GeometryType]GeometryType{MultiPoint: Point, MultiLineString: LineString, MultiPolygon: Polygon}[parent.Type]
+	if expected != 0 && member.Type != expected {
+		return fmt.Errorf("%w: %v in a %v", ErrInvalidWKB, member.Type, parent.Type)
+	}
+	return nil
+}
+
+// WKB encodes the geometry as little-endian ISO WKB.
+func (g Geometry) WKB() []byte {
+	return g.appendWKB(nil)
+}
+
+func (g Geometry) appendWKB(b []byte) []byte {
+	b = append(b, 1)
+	b = binary.LittleEndian.AppendUint32(b, uint32(g.Code()))
+	switch g.Type {
+	case Point:
+		if len(g.Coordinates) == 0 {
+			nan := math.NaN()
+			return g.appendCoordinate(b, Coordinate{nan, nan, nan, nan})
+		}
+		return g.appendCoordinate(b, g.Coordinates[0])
+	case LineString:
+		return g.appendCoordinates(b, g.Coordinates)
+	case Polygon:
+		b = binary.LittleEndian.AppendUint32(b, uint32(len(g.Rings)))
+		for _, ring := range g.Rings {
+			b = g.appendCoordinates(b, ring)
+		}
+		return b
+	}
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(g.Geometries)))
+	for _, member := range g.Geometries {
+		b = member.appendWKB(b)
+	}
+	return b
+}
+
+func (g Geometry) appendCoordinates(b []byte, coordinates []Coordinate) []byte {
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(coordinates)))
+	for _, c := range coordinates {
+		b = g.appendCoordinate(b, c)
+	}
+	return b
+}
+
+func (g Geometry) appendCoordinate(b []byte, c Coordinate) []byte {
+	values := []float64{c.X, c.Y}
+	if g.Dimension.HasZ() {
+		values = append(values, c.Z)
+	}
+	if g.Dimension.HasM() {
+		values = append(values, c.M)
+	}
+	for _, v := range values {
+		b = binary.LittleEndian.AppendUint64(b, math.Float64bits(v))
+	}
+	return b
+}
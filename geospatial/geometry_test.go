@@ -0,0 +1,205 @@
+package geospatial
+
+import (
+	"encoding/hex"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestWKBRoundTrip(t *testing.T) {
+	ring := []Coordinate{{0, 0, 0, 0}, {4, 0, 0, 0}, {4, 4, 0, 0}, {0, 0, 0, 0}}
+	testcases := map[string]Geometry{
+		"point":       {Type: Point, Coordinates: []Coordinate{{X: 1.5, Y: -2}}},
+		"empty point": {Type: Point},
+		"point z":     {Type: Point, Dimension: XYZ, Coordinates: []Coordinate{{X: 1, Y: 2, Z: 3}}},
+		"point m":     {Type: Point, Dimension: XYM, Coordinates: []Coordinate{{X: 1, Y: 2, M: 4}}},
+		"point zm":    {Type: Point, Dimension: XYZM, Coordinates: []Coordinate{{1, 2, 3, 4}}},
+		"linestring":  {Type: LineString, Coordinates: []Coordinate{{X: 0, Y: 0}, {X: 1, Y: 1}}},
+		"polygon":     {Type: Polygon, Rings: [][]Coordinate{ring}},
+		"multipoint": {Type: MultiPoint, Geometries: []Geometry{
+			{Type: Point, Coordinates: []Coordinate{{X: 1, Y: 2}}},
+			{Type: Point, Coordinates: []Coordinate{{X: 3, Y: 4}}},
+		}},
+		"collection": {Type: GeometryCollection, Dimension: XYZ, Geometries: []Geometry{
+			{Type: Point, Dimension: XYZ, Coordinates: []Coordinate{{X: 1, Y: 2, Z: 3}}},
+			{Type: GeometryCollection, Dimension: XYZ, Geometries: []Geometry{}},
+		}},
+	}
+
+	for name, geometry := range testcases {
+		t.Run(name, func(t *testing.T) {
+			parsed, err := ParseWKB(geometry.WKB())
+			if err != nil {
+				t.Fatalf("unable to parse WKB: %v", err)
+			}
+			if !reflect.DeepEqual(parsed, geometry) {
+				t.Errorf("expected %+v, got %+v", geometry, parsed)
+			}
+		})
+	}
+}
+
+func TestParseWKB(t *testing.T) {
+	testcases := map[string]struct {
+		wkb      string
+		expected Geometry
+	}{
+		"big endian point": {
+			wkb:      "00000000013ff00000000000004000000000000000",
+			expected: Geometry{Type: Point, Coordinates: []Coordinate{{X: 1, Y: 2}}},
+		},
+		"extended z point": {
+			wkb:      "0101000080000000000000f03f00000000000000400000000000000840",
+			expected: Geometry{Type: Point, Dimension: XYZ, Coordinates: []Coordinate{{X: 1, Y: 2, Z: 3}}},
+		},
+		"iso m linestring": {
+			wkb:      "01d207000001000000000000000000f03f00000000000000400000000000001040",
+			expected: Geometry{Type: LineString, Dimension: XYM, Coordinates: []Coordinate{{X: 1, Y: 2, M: 4}}},
+		},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			wkb, _ := hex.DecodeString(test.wkb)
+			geometry, err := ParseWKB(wkb)
+			if err != nil {
+				t.Fatalf("unable to parse WKB: %v", err)
+			}
+			if !reflect.DeepEqual(geometry, test.expected) {
+				t.Errorf("expected %+v, got %+v", test.expected, geometry)
+			}
+		})
+	}
+}
+
+func TestParseWKBInvalid(t *testing.T) {
+	point := Geometry{Type: Point, Coordinates: []Coordinate{{X: 1, Y: 2}}}.WKB()
+	testcases := map[string]string{
+		"empty":           "",
+		"byte order":      "02" + hex.EncodeToString(point[1:]),
+		"unknown type":    "0108000000",
+		"unknown dim":     "01a10f0000",
+		"srid":            "0101000020e6100000000000000000f03f0000000000000040",
+		"truncated":       hex.EncodeToString(point[:len(point)-1]),
+		"trailing":        hex.EncodeToString(point) + "00",
+		"huge count":      "0102000000ffffffff",
+		"wrong member":    "01040000000100000001020000000000000000",
+		"mixed dimension": "010400000001000000010101000000000000000000f03f00000000000000400000000000000840",
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			wkb, _ := hex.DecodeString(test)
+			if _, err := ParseWKB(wkb); !errors.Is(err, ErrInvalidWKB) {
+				t.Errorf("expected ErrInvalidWKB, got %v", err)
+			}
+		})
+	}
+}
+
+func TestParseWKBDepth(t *testing.T) {
+	geometry := Geometry{Type: GeometryCollection}
+	for range maxWKBDepth + 1 {
+		geometry = Geometry{Type: GeometryCollection, Geometries: []Geometry{geometry}}
+	}
+	if _, err := ParseWKB(geometry.WKB()); !errors.Is(err, ErrInvalidWKB) {
+		t.Errorf("expected ErrInvalidWKB, got %v", err)
+	}
+}
+
+func TestBounds(t *testing.T) {
+	nan := math.NaN()
+	testcases := map[string]struct {
+		geometry Geometry
+		expected BoundingBox
+		ok       bool
+	}{
+		"empty point": {geometry: Geometry{Type: Point}},
+		"linestring": {
+			geometry: Geometry{Type: LineString, Coordinates: []Coordinate{{X: 3, Y: -1}, {X: -2, Y: 5}}},
+			expected: BoundingBox{Xmin: -2, Xmax: 3, Ymin: -1, Ymax: 5},
+			ok:       true,
+		},
+		"nan ignored": {
+			geometry: Geometry{Type: LineString, Coordinates: []Coordinate{{X: 1, Y: 1}, {X: nan, Y: nan}}},
+			expected: BoundingBox{Xmin: 1, Xmax: 1, Ymin: 1, Ymax: 1},
+			ok:       true,
+		},
+		"nested zm": {
+			geometry: Geometry{Type: GeometryCollection, Dimension: XYZM, Geometries: []Geometry{
+				{Type: Point, Dimension: XYZM, Coordinates: []Coordinate{{1, 2, 3, 4}}},
+				{Type: Polygon, Dimension: XYZM, Rings: [][]Coordinate{{{-1, 0, 9, -4}}}},
+			}},
+			expected: BoundingBox{Xmin: -1, Xmax: 1, Ymin: 0, Ymax: 2, Zmin: 3, Zmax: 9, Mmin: -4, Mmax: 4, HasZ: true, HasM: true},
+			ok:       true,
+		},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			box, ok := test.geometry.Bounds()
+			if ok != test.ok || box != test.expected {
+				t.Errorf("expected %+v %v, got %+v %v", test.expected, test.ok, box, ok)
+			}
+		})
+	}
+}
+
+func TestIntersects(t *testing.T) {
+	box := BoundingBox{Xmin: 0, Xmax: 10, Ymin: 0, Ymax: 10}
+	testcases := map[string]struct {
+		a, b     BoundingBox
+		expected bool
+	}{
+		"overlap":        {a: box, b: BoundingBox{Xmin: 5, Xmax: 15, Ymin: 5, Ymax: 15}, expected: true},
+		"touching":       {a: box, b: BoundingBox{Xmin: 10, Xmax: 20, Ymin: 0, Ymax: 10}, expected: true},
+		"disjoint x":     {a: box, b: BoundingBox{Xmin: 11, Xmax: 20, Ymin: 0, Ymax: 10}},
+		"disjoint y":     {a: box, b: BoundingBox{Xmin: 0, Xmax: 10, Ymin: -5, Ymax: -1}},
+		"wraps":          {a: BoundingBox{Xmin: 170, Xmax: -170, Ymin: 0, Ymax: 10}, b: BoundingBox{Xmin: -175, Xmax: -172, Ymin: 0, Ymax: 10}, expected: true},
+		"wraps disjoint": {a: BoundingBox{Xmin: 170, Xmax: -170, Ymin: 0, Ymax: 10}, b: box},
+		"both wrap":      {a: BoundingBox{Xmin: 170, Xmax: -170, Ymin: 0, Ymax: 10}, b: BoundingBox{Xmin: 175, Xmax: -175, Ymin: 0, Ymax: 10}, expected: true},
+		"disjoint z": {
+			a:        BoundingBox{Xmax: 10, Ymax: 10, Zmin: 0, Zmax: 1, HasZ: true},
+			b:        BoundingBox{Xmax: 10, Ymax: 10, Zmin: 2, Zmax: 3, HasZ: true},
+			expected: false,
+		},
+		"z on one side": {a: BoundingBox{Xmax: 10, Ymax: 10, Zmin: 5, Zmax: 6, HasZ: true}, b: box, expected: true},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			if got := test.a.Intersects(test.b); got != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, got)
+			}
+			if got := test.b.Intersects(test.a); got != test.expected {
+				t.Errorf("expected %v when swapped, got %v", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestAccumulator(t *testing.T) {
+	accumulator := NewAccumulator()
+	accumulator.Add(Geometry{Type: Point, Coordinates: []Coordinate{{X: 1, Y: 2}}}.WKB())
+	accumulator.Add(Geometry{Type: LineString, Dimension: XYZ, Coordinates: []Coordinate{{X: -1, Y: 5, Z: 7}}}.WKB())
+	accumulator.Add(Geometry{Type: Point}.WKB())
+
+	expected := &Statistics{
+		BoundingBox: &BoundingBox{Xmin: -1, Xmax: 1, Ymin: 2, Ymax: 5, Zmin: 7, Zmax: 7, HasZ: true},
+		Types:       []int32{1, 1002},
+	}
+	if statistics := accumulator.Statistics(); !reflect.DeepEqual(statistics, expected) {
+		t.Errorf("expected %+v, got %+v", expected, statistics)
+	}
+
+	accumulator.Add([]byte{1, 2, 3})
+	if statistics := accumulator.Statistics(); statistics != nil {
+		t.Errorf("expected no statistics after invalid WKB, got %+v", statistics)
+	}
+
+	if statistics := NewAccumulator().Statistics(); statistics.BoundingBox != nil || len(statistics.Types) != 0 {
+		t.Errorf("expected empty statistics, got %+v", statistics)
+	}
+}
@@ -2,4 +2,4 @@ module github.com/RichardNooooh/parquet-go
 
 go 1.25.5
 
-require github.com/apache/thrift v0.22.0 // indirect
+require github.com/apache/thrift v0.22.0
@@ -0,0 +1,352 @@
+// Package httpreader reads remote objects, such as presigned object store
+// URLs, through HTTP range requests.
+package httpreader
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrRangeNotSupported = errors.New("server does not support range requests")
+	// ErrObjectChanged is returned when the object no longer has the ETag it
+	// had when it was opened.
+	ErrObjectChanged = errors.New("object changed while reading")
+)
+
+type Option func(*config)
+
+type config struct {
+	client     *http.Client
+	retries    int
+	backoff    time.Duration
+	blockSize  int64
+	cacheBlock int
+}
+
+const (
+	defaultRetries    = 3
+	defaultBackoff    = 100 * time.Millisecond
+	defaultBlockSize  = 64 << 10
+	defaultCacheBlock = 64
+)
+
+// WithClient sends requests with the given client instead of
+// http.DefaultClient.
+func WithClient(client *http.Client) Option {
+	return func(c *config) { c.client = client }
+}
+
+// WithRetries retries a failed request up to retries times, waiting backoff
+// before the first retry and doubling the wait after each one. Requests are
+// retried on network errors, truncated bodies, 429 and 5xx responses.
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(c *config) { c.retries, c.backoff = retries, backoff }
+}
+
+// WithBlockCache caches up to blocks blocks of blockSize bytes, evicting the
+// least recently used one. Reads are rounded out to whole blocks, so that
+// small nearby reads such as page headers share a request. Reads larger than
+// the cache bypass it. Zero blocks disables the cache.
+func WithBlockCache(blockSize int64, blocks int) Option {
+	return func(c *config) { c.blockSize, c.cacheBlock = blockSize, blocks }
+}
+
+// ReaderAt is an io.ReaderAt over a remote object. It pins the object to the
+// ETag seen by Open, so that a read never mixes bytes of two versions. It is
+// safe for concurrent use.
+type ReaderAt struct {
+	// ctx is the context passed to Open, which bounds every request.
+	ctx    context.Context
+	url    string
+	config config
+	size   int64
+	etag   string
+
+	mutex  sync.Mutex
+	blocks map[int64]*list.Element
+	lru    *list.List // of *block, most recently used first
+}
+
+type block struct {
+	index int64
+	data  []byte
+}
+
+// Open finds the size and ETag of the object at objectURL with a one byte range
+// request, since presigned URLs are usually only signed for GET. Reads are
+// sent with ctx too, so they fail once it is canceled.
+func Open(ctx context.Context, objectURL string, opts ...Option) (*ReaderAt, error) {
+	r := &ReaderAt{
+		ctx: ctx,
+		url: objectURL,
+		config: config{
+			client:     http.DefaultClient,
+			retries:    defaultRetries,
+			backoff:    defaultBackoff,
+			blockSize:  defaultBlockSize,
+			cacheBlock: defaultCacheBlock,
+		},
+		blocks: make(map[int64]*list.Element),
+		lru:    list.New(),
+	}
+	for _, opt := range opts {
+		opt(&r.config)
+	}
+	if r.config.blockSize <= 0 {
+		r.config.cacheBlock = 0
+	}
+
+	err := r.retry(ctx, func() error {
+		response, err := r.get(ctx, "bytes=0-0")
+		if err != nil {
+			return err
+		}
+		defer response.Body.Close()
+		switch response.StatusCode {
+		case http.StatusPartialContent:
+			_, _, size, err := parseContentRange(response.Header.Get("Content-Range"))
+			if err != nil {
+				return err
+			}
+			r.size, r.etag = size, response.Header.Get("ETag")
+		case http.StatusRequestedRangeNotSatisfiable:
+			// An empty object has no byte to return.
+			r.size, r.etag = 0, response.Header.Get("ETag")
+		case http.StatusOK:
+			return ErrRangeNotSupported
+		default:
+			return statusError(response)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to open %s: %w", redact(objectURL), err)
+	}
+	return r, nil
+}
+
+// Size returns the size of the object, for use with parquet.Open.
+func (r *ReaderAt) Size() int64 { return r.size }
+
+// ETag returns the ETag the reader is pinned to, or "" when the server sent
+// none.
+func (r *ReaderAt) ETag() string { return r.etag }
+
+func (r *ReaderAt) ReadAt(p []byte, offset int64) (int, error) {
+	if offset < 0 {
+		return 0, fmt.Errorf("negative offset %d", offset)
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if offset >= r.size {
+		return 0, io.EOF
+	}
+	length := min(int64(len(p)), r.size-offset)
+
+	var err error
+	if r.config.cacheBlock == 0 {
+		err = r.fetch(p[:length], offset)
+	} else if firstBlock, lastBlock := offset/r.config.blockSize, (offset+length-1)/r.config.blockSize; lastBlock-firstBlock >= int64(r.config.cacheBlock) {
+		err = r.fetch(p[:length], offset)
+	} else {
+		err = r.readBlocks(p[:length], offset, firstBlock, lastBlock)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if length < int64(len(p)) {
+		return int(length), io.EOF
+	}
+	return int(length), nil
+}
+
+// readBlocks copies [offset, offset+len(p)) out of the cached blocks, fetching
+// each run of missing blocks with a single request.
+func (r *ReaderAt) readBlocks(p []byte, offset, firstBlock, lastBlock int64) error {
+	blockSize := r.config.blockSize
+	blocks := make([][]byte, lastBlock-firstBlock+1)
+	r.mutex.Lock()
+	for i := range blocks {
+		if element, ok := r.blocks[firstBlock+int64(i)]; ok {
+			r.lru.MoveToFront(element)
+			blocks[i] = element.Value.(*block).data
+		}
+	}
+	r.mutex.Unlock()
+
+	for i := 0; i < len(blocks); {
+		if blocks[i] != nil {
+			i++
+			continue
+		}
+		end := i
+		for end < len(blocks) && blocks[end] == nil {
+			end++
+		}
+		start := (firstBlock + int64(i)) * blockSize
+		data := make([]byte, min(int64(end-i)*blockSize, r.size-start))
+		if err := r.fetch(data, start); err != nil {
+			return err
+		}
+		for ; i < end; i++ {
+			blocks[i] = data[:min(blockSize, int64(len(data)))]
+			data = data[len(blocks[i]):]
+			r.cache(firstBlock+int64(i), blocks[i])
+		}
+	}
+
+	for i, data := range blocks {
+		blockStart := (firstBlock + int64(i)) * blockSize
+		from := max(offset-blockStart, 0)
+		copied := copy(p, data[from:])
+		p = p[copied:]
+	}
+	return nil
+}
+
+func (r *ReaderAt) cache(index int64, data []byte) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if element, ok := r.blocks[index]; ok {
+		r.lru.MoveToFront(element)
+		return
+	}
+	r.blocks[index] = r.lru.PushFront(&block{index, data})
+	for r.lru.Len() > r.config.cacheBlock {
+		oldest := r.lru.Back()
+		r.lru.Remove(oldest)
+		delete(r.blocks, oldest.Value.(*block).index)
+	}
+}
+
+// fetch fills p with the bytes of the object starting at offset.
+func (r *ReaderAt) fetch(p []byte, offset int64) error {
+	end := offset + int64(len(p)) - 1
+	err := r.retry(r.ctx, func() error {
+		response, err := r.get(r.ctx, fmt.Sprintf("bytes=%d-%d", offset, end))
+		if err != nil {
+			return err
+		}
+		defer response.Body.Close()
+		if response.StatusCode == http.StatusPreconditionFailed {
+			return ErrObjectChanged
+		}
+		if response.StatusCode != http.StatusPartialContent {
+			return statusError(response)
+		}
+		if r.etag != "" && response.Header.Get("ETag") != r.etag {
+			return ErrObjectChanged
+		}
+		start, last, size, err := parseContentRange(response.Header.Get("Content-Range"))
+		if err != nil {
+			return err
+		}
+		if size != r.size {
+			return ErrObjectChanged
+		}
+		if start != offset || last != end {
+			return fmt.Errorf("server returned bytes %d-%d, requested %d-%d", start, last, offset, end)
+		}
+		if _, err := io.ReadFull(response.Body, p); err != nil {
+			return retryable{fmt.Errorf("unable to read response body: %w", err)}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to read bytes %d-%d of %s: %w", offset, end, redact(r.url), err)
+	}
+	return nil
+}
+
+func (r *ReaderAt) get(ctx context.Context, byteRange string) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, r.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Range", byteRange)
+	if r.etag != "" && !strings.HasPrefix(r.etag, "W/") {
+		// If-Match only compares strong ETags, so weak ones are only checked
+		// against the response.
+		request.Header.Set("If-Match", r.etag)
+	}
+	response, err := r.config.client.Do(request)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = redact(urlErr.URL)
+		}
+		return nil, retryable{err}
+	}
+	return response, nil
+}
+
+// retry calls fn until it succeeds, fails with an error that is not worth
+// retrying, or runs out of retries.
+func (r *ReaderAt) retry(ctx context.Context, fn func() error) error {
+	backoff := r.config.backoff
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		var retryableErr retryable
+		if err == nil || !errors.As(err, &retryableErr) {
+			return err
+		}
+		if attempt >= r.config.retries {
+			return fmt.Errorf("giving up after %d attempts: %w", attempt+1, retryableErr.err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// retryable marks errors of a request that may succeed when sent again.
+type retryable struct{ err error }
+
+func (e retryable) Error() string { return e.err.Error() }
+func (e retryable) Unwrap() error { return e.err }
+
+func statusError(response *http.Response) error {
+	err := fmt.Errorf("unexpected status %s", response.Status)
+	if response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= 500 {
+		return retryable{err}
+	}
+	return err
+}
+
+// parseContentRange parses a "bytes start-end/size" Content-Range header.
+func parseContentRange(header string) (start, end, size int64, err error) {
+	spec, ok := strings.CutPrefix(header, "bytes ")
+	byteRange, sizeString, found := strings.Cut(spec, "/")
+	startString, endString, hasDash := strings.Cut(byteRange, "-")
+	if !ok || !found || !hasDash {
+		return 0, 0, 0, fmt.Errorf("invalid Content-Range %q", header)
+	}
+	start, startErr := strconv.ParseInt(startString, 10, 64)
+	end, endErr := strconv.ParseInt(endString, 10, 64)
+	size, sizeErr := strconv.ParseInt(sizeString, 10, 64)
+	if startErr != nil || endErr != nil || sizeErr != nil || start > end || end >= size {
+		return 0, 0, 0, fmt.Errorf("invalid Content-Range %q", header)
+	}
+	return start, end, size, nil
+}
+
+// redact drops the query of a URL from error messages, since presigned URLs
+// carry their credentials there.
+func redact(objectURL string) string {
+	base, _, _ := strings.Cut(objectURL, "?")
+	return base
+}
@@ -0,0 +1,291 @@
+package httpreader
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/RichardNooooh/parquet-go/parquet"
+)
+
+// objectServer serves data with range support and an ETag, failing the
+// first failures requests with a 503.
+type objectServer struct {
+	mutex    sync.Mutex
+	data     []byte
+	etag     string
+	failures int
+	requests int
+	noRange  bool
+}
+
+func (s *objectServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	s.requests++
+	data, etag, fail := s.data, s.etag, s.failures > 0
+	if fail {
+		s.failures--
+	}
+	s.mutex.Unlock()
+
+	if fail {
+		http.Error(w, "try again", http.StatusServiceUnavailable)
+		return
+	}
+	if s.noRange {
+		w.Write(data)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+}
+
+func (s *objectServer) replace(data []byte, etag string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.data, s.etag = data, etag
+}
+
+func (s *objectServer) numRequests() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.requests
+}
+
+func readAllRows(t *testing.T, r *parquet.ParquetReader) []parquet.Row {
+	t.Helper()
+	rows, err := r.ReadAll(context.Background())
+	if err != nil {
+		t.Fatalf("unable to read rows: %v", err)
+	}
+	return rows
+}
+
+func TestReaderAtWithParquet(t *testing.T) {
+	for _, name := range []string{
+		filepath.Join("apache_examples", "alltypes_plain.parquet"),
+		filepath.Join("apache_examples", "nested_maps.snappy.parquet"),
+		filepath.Join("timestored_examples", "iris.parquet"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("..", "testdata", name))
+			if err != nil {
+				t.Fatalf("unable to read file: %v", err)
+			}
+			local, err := parquet.Open(bytes.NewReader(data), int64(len(data)))
+			if err != nil {
+				t.Fatalf("unable to open local file: %v", err)
+			}
+
+			server := httptest.NewServer(&objectServer{data: data, etag: `"v1"`})
+			defer server.Close()
+			remote, err := Open(context.Background(), server.URL+"/file.parquet?signature=secret", WithBlockCache(1024, 16))
+			if err != nil {
+				t.Fatalf("unable to open remote file: %v", err)
+			}
+			if remote.Size() != int64(len(data)) {
+				t.Fatalf("expected size %d, got %d", len(data), remote.Size())
+			}
+			reader, err := parquet.Open(remote, remote.Size(), parquet.WithFooterReadSize(64<<10))
+			if err != nil {
+				t.Fatalf("unable to open parquet over http: %v", err)
+			}
+
+			if expected, got := readAllRows(t, local), readAllRows(t, reader); !reflect.DeepEqual(expected, got) {
+				t.Errorf("rows read over http differ from the local file")
+			}
+		})
+	}
+}
+
+func TestReaderAtReadAt(t *testing.T) {
+	data := make([]byte, 10000)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+
+	testcases := map[string]struct {
+		opts   []Option
+		offset int64
+		length int
+	}{
+		"within a block":    {offset: 10, length: 20},
+		"across blocks":     {opts: []Option{WithBlockCache(100, 8)}, offset: 150, length: 300},
+		"larger than cache": {opts: []Option{WithBlockCache(100, 2)}, offset: 150, length: 300},
+		"without cache":     {opts: []Option{WithBlockCache(0, 0)}, offset: 9000, length: 1000},
+		"last block":        {opts: []Option{WithBlockCache(3000, 8)}, offset: 9990, length: 10},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(&objectServer{data: data, etag: `"v1"`})
+			defer server.Close()
+			reader, err := Open(context.Background(), server.URL, test.opts...)
+			if err != nil {
+				t.Fatalf("unable to open: %v", err)
+			}
+
+			for range 2 {
+				p := make([]byte, test.length)
+				if n, err := reader.ReadAt(p, test.offset); err != nil || n != test.length {
+					t.Fatalf("expected %d bytes, got %d: %v", test.length, n, err)
+				}
+				if !bytes.Equal(p, data[test.offset:test.offset+int64(test.length)]) {
+					t.Errorf("wrong bytes at offset %d", test.offset)
+				}
+			}
+		})
+	}
+}
+
+func TestReaderAtCache(t *testing.T) {
+	data := make([]byte, 1000)
+	server := &objectServer{data: data, etag: `"v1"`}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	reader, err := Open(context.Background(), httpServer.URL, WithBlockCache(100, 2))
+	if err != nil {
+		t.Fatalf("unable to open: %v", err)
+	}
+
+	testcases := []struct {
+		offset, length int64
+		requests       int
+	}{
+		{offset: 10, length: 10, requests: 1},
+		{offset: 50, length: 10, requests: 0},  // same block
+		{offset: 90, length: 20, requests: 1},  // second block only
+		{offset: 250, length: 10, requests: 1}, // evicts the first block
+		{offset: 150, length: 10, requests: 0},
+		{offset: 0, length: 10, requests: 1},
+	}
+	for _, test := range testcases {
+		before := server.numRequests()
+		if _, err := reader.ReadAt(make([]byte, test.length), test.offset); err != nil {
+			t.Fatalf("unable to read at %d: %v", test.offset, err)
+		}
+		if requests := server.numRequests() - before; requests != test.requests {
+			t.Errorf("read at %d: expected %d requests, got %d", test.offset, test.requests, requests)
+		}
+	}
+}
+
+func TestReaderAtEOF(t *testing.T) {
+	server := httptest.NewServer(&objectServer{data: []byte("0123456789"), etag: `"v1"`})
+	defer server.Close()
+	reader, err := Open(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("unable to open: %v", err)
+	}
+
+	p := make([]byte, 4)
+	if n, err := reader.ReadAt(p, 8); n != 2 || err != io.EOF || string(p[:n]) != "89" {
+		t.Errorf("expected 2 bytes and io.EOF, got %d: %v", n, err)
+	}
+	if n, err := reader.ReadAt(p, 10); n != 0 || err != io.EOF {
+		t.Errorf("expected io.EOF, got %d: %v", n, err)
+	}
+}
+
+func TestReaderAtEmptyRead(t *testing.T) {
+	server := &objectServer{data: []byte("0123456789"), etag: `"v1"`}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	reader, err := Open(context.Background(), httpServer.URL, WithBlockCache(0, 0))
+	if err != nil {
+		t.Fatalf("unable to open: %v", err)
+	}
+
+	before := server.numRequests()
+	for _, offset := range []int64{0, 5, 10, 20} {
+		if n, err := reader.ReadAt(nil, offset); n != 0 || err != nil {
+			t.Errorf("expected an empty read at %d, got %d: %v", offset, n, err)
+		}
+	}
+	if requests := server.numRequests() - before; requests != 0 {
+		t.Errorf("expected no requests, got %d", requests)
+	}
+}
+
+func TestReaderAtRetries(t *testing.T) {
+	data := []byte("0123456789")
+	server := &objectServer{data: data, etag: `"v1"`, failures: 2}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	reader, err := Open(context.Background(), httpServer.URL, WithRetries(2, time.Millisecond))
+	if err != nil {
+		t.Fatalf("unable to open after retries: %v", err)
+	}
+	server.mutex.Lock()
+	server.failures = 3
+	server.mutex.Unlock()
+	_, err = reader.ReadAt(make([]byte, 4), 0)
+	if err == nil {
+		t.Fatalf("expected error after running out of retries, got nil error")
+	}
+	if requests := server.numRequests(); requests != 6 {
+		t.Errorf("expected 6 requests, got %d", requests)
+	}
+}
+
+func TestReaderAtErrors(t *testing.T) {
+	data := []byte("0123456789")
+
+	t.Run("object changed", func(t *testing.T) {
+		server := &objectServer{data: data, etag: `"v1"`}
+		httpServer := httptest.NewServer(server)
+		defer httpServer.Close()
+		reader, err := Open(context.Background(), httpServer.URL)
+		if err != nil {
+			t.Fatalf("unable to open: %v", err)
+		}
+		server.replace([]byte("abcdefghij"), `"v2"`)
+		if _, err := reader.ReadAt(make([]byte, 4), 0); !errors.Is(err, ErrObjectChanged) {
+			t.Errorf("expected ErrObjectChanged, got %v", err)
+		}
+	})
+
+	t.Run("range not supported", func(t *testing.T) {
+		httpServer := httptest.NewServer(&objectServer{data: data, noRange: true})
+		defer httpServer.Close()
+		if _, err := Open(context.Background(), httpServer.URL); !errors.Is(err, ErrRangeNotSupported) {
+			t.Errorf("expected ErrRangeNotSupported, got %v", err)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		httpServer := httptest.NewServer(&objectServer{data: data, etag: `"v1"`})
+		defer httpServer.Close()
+		ctx, cancel := context.WithCancel(context.Background())
+		reader, err := Open(ctx, httpServer.URL)
+		if err != nil {
+			t.Fatalf("unable to open: %v", err)
+		}
+		cancel()
+		if _, err := reader.ReadAt(make([]byte, 4), 0); !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		httpServer := httptest.NewServer(http.NotFoundHandler())
+		defer httpServer.Close()
+		_, err := Open(context.Background(), httpServer.URL+"?signature=secret")
+		if err == nil {
+			t.Fatalf("expected error, got nil error")
+		}
+		if bytes.Contains([]byte(err.Error()), []byte("secret")) {
+			t.Errorf("expected the query to be redacted, got %v", err)
+		}
+	})
+}
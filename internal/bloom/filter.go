@@ -0,0 +1,106 @@
+package bloom
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/RichardNooooh/parquet-go/internal/encoder"
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+// BlockSize is the size in bytes of a split-block bloom filter block: eight
+// 32-bit words.
+const BlockSize = 32
+
+var ErrInvalidFilter = errors.New("invalid bloom filter")
+
+var salt = [8]uint32{
+	0x47b6137b, 0x44974d91, 0x8824ad5b, 0xa2b7289d,
+	0x705495c7, 0x2df1424b, 0x9efc4947, 0x5c6bfb31,
+}
+
+// Filter is a split-block bloom filter as specified by Parquet. Its bitset
+// is stored in the little-endian layout written to files.
+type Filter struct {
+	bitset []byte
+}
+
+// New returns an empty filter of numBytes bytes, rounded up to whole blocks.
+func New(numBytes int) *Filter {
+	numBlocks := max((numBytes+BlockSize-1)/BlockSize, 1)
+	return &Filter{bitset: make([]byte, numBlocks*BlockSize)}
+}
+
+// FromBytes wraps a bitset read from a file without copying it.
+func FromBytes(bitset []byte) (*Filter, error) {
+	if len(bitset) == 0 || len(bitset)%BlockSize != 0 {
+		return nil, fmt.Errorf("%w: bitset of %d bytes is not a whole number of blocks", ErrInvalidFilter, len(bitset))
+	}
+	return &Filter{bitset: bitset}, nil
+}
+
+func (f *Filter) Bytes() []byte { return f.bitset }
+
+// Insert adds a value hash to the filter.
+func (f *Filter) Insert(hash uint64) {
+	block := f.block(hash)
+	key := uint32(hash)
+	for i := range salt {
+		word := block[i*4 : i*4+4]
+		binary.LittleEndian.PutUint32(word, binary.LittleEndian.Uint32(word)|1<<((key*salt[i])>>27))
+	}
+}
+
+// Check reports whether a value hash might have been inserted. False
+// positives are possible, false negatives are not.
+func (f *Filter) Check(hash uint64) bool {
+	block := f.block(hash)
+	key := uint32(hash)
+	for i := range salt {
+		mask := uint32(1) << ((key * salt[i]) >> 27)
+		if binary.LittleEndian.Uint32(block[i*4:])&mask == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// block selects the block of a hash from its upper 32 bits.
+func (f *Filter) block(hash uint64) []byte {
+	numBlocks := uint64(len(f.bitset) / BlockSize)
+	index := ((hash >> 32) * numBlocks) >> 32
+	return f.bitset[index*BlockSize : (index+1)*BlockSize]
+}
+
+// Hash hashes a physical value the way Parquet bloom filters expect: XXH64
+// of its plain encoding, without a length prefix for byte arrays.
+func Hash(typ schema.Type, value any) (uint64, error) {
+	data, err := encoder.PlainValue(typ, value)
+	if err != nil {
+		return 0, err
+	}
+	return Sum64(data), nil
+}
+
+// Bounds on the size of filters chosen by OptimalNumBytes.
+const (
+	MinNumBytes = BlockSize
+	MaxNumBytes = 128 << 20
+)
+
+// OptimalNumBytes returns the bitset size that keeps the false positive
+// probability of a filter holding ndv distinct values at or below fpp,
+// rounded up to a power of two.
+func OptimalNumBytes(ndv int64, fpp float64) int {
+	if ndv <= 0 || fpp <= 0 || fpp >= 1 {
+		return MinNumBytes
+	}
+	numBits := -8 * float64(ndv) / math.Log(1-math.Pow(fpp, 1.0/8))
+	numBytes := MinNumBytes
+	for numBytes < MaxNumBytes && float64(numBytes)*8 < numBits {
+		numBytes <<= 1
+	}
+	return numBytes
+}
@@ -0,0 +1,86 @@
+package bloom
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+func TestSum64(t *testing.T) {
+	testcases := map[string]struct {
+		input    string
+		expected uint64
+	}{
+		"empty": {input: "", expected: 0xef46db3751d8e999},
+		"a":     {input: "a", expected: 0xd24ec4f1a98c6e5b},
+		"abc":   {input: "abc", expected: 0x44bc2cf5ad770999},
+		"long":  {input: "Nobody inspects the spammish repetition", expected: 0xfbcea83c8a378bf1},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			if got := Sum64([]byte(test.input)); got != test.expected {
+				t.Errorf("expected %#x, got %#x", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestFilterNoFalseNegatives(t *testing.T) {
+	filter := New(1024)
+	for i := range 500 {
+		hash, err := Hash(schema.ByteArray, []byte(fmt.Sprint("value-", i)))
+		if err != nil {
+			t.Fatalf("unable to hash: %v", err)
+		}
+		filter.Insert(hash)
+	}
+
+	falsePositives := 0
+	for i := range 1000 {
+		hash, _ := Hash(schema.ByteArray, []byte(fmt.Sprint("value-", i)))
+		contained := filter.Check(hash)
+		if i < 500 && !contained {
+			t.Fatalf("value %d was inserted but not found", i)
+		}
+		if i >= 500 && contained {
+			falsePositives++
+		}
+	}
+	if falsePositives > 100 {
+		t.Errorf("expected few false positives, got %d of 500", falsePositives)
+	}
+}
+
+func TestFromBytes(t *testing.T) {
+	if _, err := FromBytes(make([]byte, 33)); err == nil {
+		t.Errorf("expected an error for a partial block")
+	}
+	if _, err := FromBytes(nil); err == nil {
+		t.Errorf("expected an error for an empty bitset")
+	}
+}
+
+func TestOptimalNumBytes(t *testing.T) {
+	testcases := map[string]struct {
+		ndv      int64
+		fpp      float64
+		expected int
+	}{
+		"no values":   {ndv: 0, fpp: 0.01, expected: MinNumBytes},
+		"small":       {ndv: 10, fpp: 0.01, expected: 32},
+		"one million": {ndv: 1_000_000, fpp: 0.01, expected: 2 << 20},
+		"strict":      {ndv: 1_000_000, fpp: 0.0001, expected: 4 << 20},
+		"capped":      {ndv: 1 << 40, fpp: 0.01, expected: MaxNumBytes},
+		"invalid fpp": {ndv: 1000, fpp: 1, expected: MinNumBytes},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			if got := OptimalNumBytes(test.ndv, test.fpp); got != test.expected {
+				t.Errorf("expected %d bytes, got %d", test.expected, got)
+			}
+		})
+	}
+}
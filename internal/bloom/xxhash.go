@@ -0,0 +1,74 @@
+package bloom
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// The primes are variables so that their sums may wrap around.
+var (
+	prime1 uint64 = 11400714785074694791
+	prime2 uint64 = 14029467366897019727
+	prime3 uint64 = 1609587929392839161
+	prime4 uint64 = 9650029242287828579
+	prime5 uint64 = 2870177450012600261
+)
+
+// Sum64 returns the XXH64 hash of data with a seed of zero, as used by
+// Parquet bloom filters.
+func Sum64(data []byte) uint64 {
+	length := uint64(len(data))
+	var h uint64
+	if len(data) >= 32 {
+		v1 := prime1 + prime2
+		v2 := prime2
+		v3 := uint64(0)
+		v4 := -prime1
+		for ; len(data) >= 32; data = data[32:] {
+			v1 = round(v1, binary.LittleEndian.Uint64(data[0:8]))
+			v2 = round(v2, binary.LittleEndian.Uint64(data[8:16]))
+			v3 = round(v3, binary.LittleEndian.Uint64(data[16:24]))
+			v4 = round(v4, binary.LittleEndian.Uint64(data[24:32]))
+		}
+		h = bits.RotateLeft64(v1, 1) + bits.RotateLeft64(v2, 7) + bits.RotateLeft64(v3, 12) + bits.RotateLeft64(v4, 18)
+		h = mergeRound(h, v1)
+		h = mergeRound(h, v2)
+		h = mergeRound(h, v3)
+		h = mergeRound(h, v4)
+	} else {
+		h = prime5
+	}
+
+	h += length
+	for ; len(data) >= 8; data = data[8:] {
+		h ^= round(0, binary.LittleEndian.Uint64(data))
+		h = bits.RotateLeft64(h, 27)*prime1 + prime4
+	}
+	if len(data) >= 4 {
+		h ^= uint64(binary.LittleEndian.Uint32(data)) * prime1
+		h = bits.RotateLeft64(h, 23)*prime2 + prime3
+		data = data[4:]
+	}
+	for _, b := range data {
+		h ^= uint64(b) * prime5
+		h = bits.RotateLeft64(h, 11) * prime1
+	}
+
+	h ^= h >> 33
+	h *= prime2
+	h ^= h >> 29
+	h *= prime3
+	h ^= h >> 32
+	return h
+}
+
+func round(acc, input uint64) uint64 {
+	acc += input * prime2
+	acc = bits.RotateLeft64(acc, 31)
+	return acc * prime1
+}
+
+func mergeRound(acc, value uint64) uint64 {
+	acc ^= round(0, value)
+	return acc*prime1 + prime4
+}
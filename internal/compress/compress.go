@@ -0,0 +1,91 @@
+package compress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+
+	format "github.com/RichardNooooh/parquet-go/internal/format/gen-go/parquet"
+)
+
+var (
+	ErrUnsupportedCodec = errors.New("unsupported compression codec")
+	ErrInvalidSize      = errors.New("invalid uncompressed page size")
+)
+
+// Decompress decompresses a page body. The uncompressed size comes from the
+// page header and is used to size the output and validate it. It is
+// untrusted, so it must not exceed maxSize, the uncompressed size of the
+// column chunk holding the page.
+func Decompress(codec format.CompressionCodec, src []byte, uncompressedSize, maxSize int) ([]byte, error) {
+	if uncompressedSize < 0 || uncompressedSize > maxSize {
+		return nil, fmt.Errorf("%w: %v page of %d bytes in a column chunk of %d bytes", ErrInvalidSize, codec, uncompressedSize, maxSize)
+	}
+	var dst []byte
+	var err error
+	switch codec {
+	case format.CompressionCodec_UNCOMPRESSED:
+		dst = src
+	case format.CompressionCodec_SNAPPY:
+		dst, err = decodeSnappy(src, uncompressedSize)
+	case format.CompressionCodec_GZIP:
+		dst, err = decodeGzip(src, uncompressedSize)
+	case format.CompressionCodec_LZ4_RAW:
+		dst, err = decodeLZ4Raw(src, uncompressedSize)
+	default:
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedCodec, codec)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to decompress %v page: %w", codec, err)
+	}
+	if len(dst) != uncompressedSize {
+		return nil, fmt.Errorf("%v page decompressed to %d bytes, expected %d", codec, len(dst), uncompressedSize)
+	}
+	return dst, nil
+}
+
+// Compress compresses a page body. The output only depends on the input, so
+// pages can be compressed concurrently without changing the file.
+func Compress(codec format.CompressionCodec, src []byte) ([]byte, error) {
+	switch codec {
+	case format.CompressionCodec_UNCOMPRESSED:
+		return src, nil
+	case format.CompressionCodec_SNAPPY:
+		return encodeSnappy(src), nil
+	case format.CompressionCodec_GZIP:
+		return encodeGzip(src)
+	case format.CompressionCodec_LZ4_RAW:
+		return encodeLZ4Raw(src), nil
+	}
+	return nil, fmt.Errorf("%w: %v", ErrUnsupportedCodec, codec)
+}
+
+func encodeGzip(src []byte) ([]byte, error) {
+	var dst bytes.Buffer
+	writer := gzip.NewWriter(&dst)
+	if _, err := writer.Write(src); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return dst.Bytes(), nil
+}
+
+func decodeGzip(src []byte, uncompressedSize int) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	// Reading one byte past the expected size is enough to detect longer
+	// output without inflating all of it.
+	dst := bytes.NewBuffer(make([]byte, 0, uncompressedSize))
+	if _, err := io.Copy(dst, io.LimitReader(reader, int64(uncompressedSize)+1)); err != nil {
+		return nil, err
+	}
+	return dst.Bytes(), nil
+}
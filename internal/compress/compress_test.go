@@ -0,0 +1,91 @@
+package compress
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	format "github.com/RichardNooooh/parquet-go/internal/format/gen-go/parquet"
+)
+
+func TestCompressRoundTrip(t *testing.T) {
+	random := make([]byte, 100_000)
+	rand.New(rand.NewSource(1)).Read(random)
+	inputs := map[string][]byte{
+		"empty":      {},
+		"short":      []byte("abc"),
+		"repetitive": bytes.Repeat([]byte("parquet "), 20_000),
+		"long run":   make([]byte, 70_000),
+		"random":     random,
+		"mixed":      append(bytes.Repeat([]byte{1, 2, 3}, 300), random[:5000]...),
+	}
+	codecs := []format.CompressionCodec{
+		format.CompressionCodec_UNCOMPRESSED,
+		format.CompressionCodec_SNAPPY,
+		format.CompressionCodec_GZIP,
+		format.CompressionCodec_LZ4_RAW,
+	}
+
+	for name, input := range inputs {
+		for _, codec := range codecs {
+			t.Run(name+"/"+codec.String(), func(t *testing.T) {
+				compressed, err := Compress(codec, input)
+				if err != nil {
+					t.Fatalf("unable to compress: %v", err)
+				}
+				output, err := Decompress(codec, compressed, len(input), len(input))
+				if err != nil {
+					t.Fatalf("unable to decompress: %v", err)
+				}
+				if !bytes.Equal(output, input) {
+					t.Errorf("round trip changed the data")
+				}
+				if name == "repetitive" && codec != format.CompressionCodec_UNCOMPRESSED && len(compressed)*10 > len(input) {
+					t.Errorf("expected repetitive data to compress well, got %d bytes", len(compressed))
+				}
+			})
+		}
+	}
+}
+
+func TestCompressUnsupportedCodec(t *testing.T) {
+	if _, err := Compress(format.CompressionCodec_BROTLI, []byte("abc")); err == nil {
+		t.Errorf("expected an error for an unsupported codec")
+	}
+}
+
+func TestDecompressInvalidSizes(t *testing.T) {
+	input := bytes.Repeat([]byte("parquet "), 100)
+	compressed := map[format.CompressionCodec][]byte{}
+	for _, codec := range []format.CompressionCodec{format.CompressionCodec_SNAPPY, format.CompressionCodec_GZIP, format.CompressionCodec_LZ4_RAW} {
+		data, err := Compress(codec, input)
+		if err != nil {
+			t.Fatalf("unable to compress: %v", err)
+		}
+		compressed[codec] = data
+	}
+
+	testcases := map[string]struct {
+		codec                     format.CompressionCodec
+		src                       []byte
+		uncompressedSize, maxSize int
+	}{
+		"negative lz4":          {codec: format.CompressionCodec_LZ4_RAW, src: compressed[format.CompressionCodec_LZ4_RAW], uncompressedSize: -5, maxSize: 1000},
+		"negative gzip":         {codec: format.CompressionCodec_GZIP, src: compressed[format.CompressionCodec_GZIP], uncompressedSize: -5, maxSize: 1000},
+		"negative snappy":       {codec: format.CompressionCodec_SNAPPY, src: compressed[format.CompressionCodec_SNAPPY], uncompressedSize: -5, maxSize: 1000},
+		"above chunk size":      {codec: format.CompressionCodec_GZIP, src: compressed[format.CompressionCodec_GZIP], uncompressedSize: 1 << 30, maxSize: 1000},
+		"lz4 too short":         {codec: format.CompressionCodec_LZ4_RAW, src: compressed[format.CompressionCodec_LZ4_RAW], uncompressedSize: 100, maxSize: 1000},
+		"gzip too short":        {codec: format.CompressionCodec_GZIP, src: compressed[format.CompressionCodec_GZIP], uncompressedSize: 100, maxSize: 1000},
+		"snappy length differs": {codec: format.CompressionCodec_SNAPPY, src: compressed[format.CompressionCodec_SNAPPY], uncompressedSize: 100, maxSize: 1000},
+		// A varint length of 4GiB, followed by nothing.
+		"snappy huge length": {codec: format.CompressionCodec_SNAPPY, src: []byte{0x80, 0x80, 0x80, 0x80, 0x10}, uncompressedSize: 800, maxSize: 1000},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Decompress(test.codec, test.src, test.uncompressedSize, test.maxSize); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
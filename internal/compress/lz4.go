@@ -0,0 +1,110 @@
+package compress
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var errCorruptLZ4 = errors.New("corrupt lz4 block")
+
+// decodeLZ4Raw decodes an LZ4 block without framing, as used by the LZ4_RAW
+// codec.
+func decodeLZ4Raw(src []byte, uncompressedSize int) ([]byte, error) {
+	dst := make([]byte, 0, uncompressedSize)
+	for len(src) > 0 {
+		token := src[0]
+		src = src[1:]
+
+		literals, rest, ok := lz4Length(int(token>>4), src)
+		if !ok || literals > len(rest) || len(dst)+literals > uncompressedSize {
+			return nil, errCorruptLZ4
+		}
+		dst = append(dst, rest[:literals]...)
+		src = rest[literals:]
+		if len(src) == 0 {
+			break
+		}
+
+		if len(src) < 2 {
+			return nil, errCorruptLZ4
+		}
+		offset := int(binary.LittleEndian.Uint16(src))
+		src = src[2:]
+		match, rest, ok := lz4Length(int(token&0x0F), src)
+		if !ok || offset == 0 || offset > len(dst) || len(dst)+match+4 > uncompressedSize {
+			return nil, errCorruptLZ4
+		}
+		src = rest
+		start := len(dst) - offset
+		for i := range match + 4 {
+			dst = append(dst, dst[start+i])
+		}
+	}
+	return dst, nil
+}
+
+// lz4Length reads the optional 255-byte continuation of a 4-bit length.
+func lz4Length(length int, src []byte) (int, []byte, bool) {
+	if length != 0x0F {
+		return length, src, true
+	}
+	for {
+		if len(src) == 0 {
+			return 0, nil, false
+		}
+		b := src[0]
+		src = src[1:]
+		length += int(b)
+		if b != 0xFF {
+			return length, src, true
+		}
+	}
+}
+
+// encodeLZ4Raw encodes an LZ4 block with a greedy single-probe match finder.
+// As the format requires, the last five bytes are literals and no match
+// starts within the last twelve bytes.
+func encodeLZ4Raw(src []byte) []byte {
+	dst := make([]byte, 0, len(src)+len(src)/255+16)
+	var table [1 << hashTableBits]int32
+	anchor := 0
+	for i := 0; i <= len(src)-12; {
+		key := hash4(src[i:])
+		candidate := int(table[key]) - 1
+		table[key] = int32(i + 1)
+		if candidate < 0 || i-candidate > 0xFFFF || binary.LittleEndian.Uint32(src[candidate:]) != binary.LittleEndian.Uint32(src[i:]) {
+			i++
+			continue
+		}
+
+		length := 4
+		for i+length < len(src)-5 && src[candidate+length] == src[i+length] {
+			length++
+		}
+		literals := src[anchor:i]
+		dst = append(dst, byte(min(len(literals), 0x0F))<<4|byte(min(length-4, 0x0F)))
+		dst = appendLZ4Length(dst, len(literals))
+		dst = append(dst, literals...)
+		dst = binary.LittleEndian.AppendUint16(dst, uint16(i-candidate))
+		dst = appendLZ4Length(dst, length-4)
+		i += length
+		anchor = i
+	}
+
+	literals := src[anchor:]
+	dst = append(dst, byte(min(len(literals), 0x0F))<<4)
+	dst = appendLZ4Length(dst, len(literals))
+	return append(dst, literals...)
+}
+
+// appendLZ4Length appends the continuation bytes of a length whose first 15
+// are stored in a token nibble.
+func appendLZ4Length(dst []byte, length int) []byte {
+	if length < 0x0F {
+		return dst
+	}
+	for length -= 0x0F; length >= 0xFF; length -= 0xFF {
+		dst = append(dst, 0xFF)
+	}
+	return append(dst, byte(length))
+}
@@ -0,0 +1,139 @@
+package compress
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var errCorruptSnappy = errors.New("corrupt snappy block")
+
+// decodeSnappy decodes a raw snappy block: a varint uncompressed length
+// followed by literal and copy elements. The length must match the expected
+// size before any memory is reserved for the output.
+func decodeSnappy(src []byte, uncompressedSize int) ([]byte, error) {
+	length, n := binary.Uvarint(src)
+	if n <= 0 || length != uint64(uncompressedSize) {
+		return nil, errCorruptSnappy
+	}
+	dst := make([]byte, 0, length)
+	src = src[n:]
+
+	for len(src) > 0 {
+		tag := src[0]
+		var offset, size int
+		switch tag & 0x03 {
+		case 0x00:
+			size = int(tag >> 2)
+			src = src[1:]
+			if size >= 60 {
+				extra := size - 59
+				if len(src) < extra {
+					return nil, errCorruptSnappy
+				}
+				size = 0
+				for i := extra - 1; i >= 0; i-- {
+					size = size<<8 | int(src[i])
+				}
+				src = src[extra:]
+			}
+			size++
+			if size > len(src) || len(dst)+size > int(length) {
+				return nil, errCorruptSnappy
+			}
+			dst = append(dst, src[:size]...)
+			src = src[size:]
+			continue
+		case 0x01:
+			if len(src) < 2 {
+				return nil, errCorruptSnappy
+			}
+			size = 4 + int(tag>>2)&0x07
+			offset = int(tag>>5)<<8 | int(src[1])
+			src = src[2:]
+		case 0x02:
+			if len(src) < 3 {
+				return nil, errCorruptSnappy
+			}
+			size = 1 + int(tag>>2)
+			offset = int(binary.LittleEndian.Uint16(src[1:]))
+			src = src[3:]
+		case 0x03:
+			if len(src) < 5 {
+				return nil, errCorruptSnappy
+			}
+			size = 1 + int(tag>>2)
+			offset = int(binary.LittleEndian.Uint32(src[1:]))
+			src = src[5:]
+		}
+
+		if offset <= 0 || offset > len(dst) || len(dst)+size > int(length) {
+			return nil, errCorruptSnappy
+		}
+		start := len(dst) - offset
+		for i := range size {
+			dst = append(dst, dst[start+i])
+		}
+	}
+
+	if uint64(len(dst)) != length {
+		return nil, errCorruptSnappy
+	}
+	return dst, nil
+}
+
+const hashTableBits = 14
+
+// hash4 hashes the four bytes at the start of b for match finding.
+func hash4(b []byte) uint32 {
+	return (binary.LittleEndian.Uint32(b) * 0x1e35a7bd) >> (32 - hashTableBits)
+}
+
+// encodeSnappy encodes a raw snappy block with a greedy single-probe match
+// finder. Copies reach back at most 64KiB, as in the reference encoder.
+func encodeSnappy(src []byte) []byte {
+	dst := binary.AppendUvarint(make([]byte, 0, len(src)+len(src)/6+8), uint64(len(src)))
+	var table [1 << hashTableBits]int32
+	literalStart := 0
+	for i := 0; i+4 <= len(src); {
+		key := hash4(src[i:])
+		candidate := int(table[key]) - 1
+		table[key] = int32(i + 1)
+		if candidate < 0 || i-candidate > 0xFFFF || binary.LittleEndian.Uint32(src[candidate:]) != binary.LittleEndian.Uint32(src[i:]) {
+			i++
+			continue
+		}
+
+		length := 4
+		for i+length < len(src) && src[candidate+length] == src[i+length] {
+			length++
+		}
+		dst = appendSnappyLiteral(dst, src[literalStart:i])
+		for offset, remaining := i-candidate, length; remaining > 0; {
+			n := min(remaining, 64)
+			dst = append(dst, byte(n-1)<<2|0x02, byte(offset), byte(offset>>8))
+			remaining -= n
+		}
+		i += length
+		literalStart = i
+	}
+	return appendSnappyLiteral(dst, src[literalStart:])
+}
+
+func appendSnappyLiteral(dst, literal []byte) []byte {
+	n := len(literal) - 1
+	switch {
+	case n < 0:
+		return dst
+	case n < 60:
+		dst = append(dst, byte(n)<<2)
+	case n < 1<<8:
+		dst = append(dst, 60<<2, byte(n))
+	case n < 1<<16:
+		dst = append(dst, 61<<2, byte(n), byte(n>>8))
+	case n < 1<<24:
+		dst = append(dst, 62<<2, byte(n), byte(n>>8), byte(n>>16))
+	default:
+		dst = append(dst, 63<<2, byte(n), byte(n>>8), byte(n>>16), byte(n>>24))
+	}
+	return append(dst, literal...)
+}
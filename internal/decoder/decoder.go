@@ -1,6 +1,47 @@
 package decoder
 
 import (
-// "log"
-// "io"
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/RichardNooooh/parquet-go/schema"
 )
+
+// DecodePlainValue decodes a single value stored the way statistics store
+// it: the PLAIN encoding without the length prefix of BYTE_ARRAY values.
+func DecodePlainValue(typ schema.Type, typeLength int32, data []byte) (any, error) {
+	switch typ {
+	case schema.Boolean:
+		if len(data) == 1 {
+			return data[0] != 0, nil
+		}
+	case schema.Int32:
+		if len(data) == 4 {
+			return int32(binary.LittleEndian.Uint32(data)), nil
+		}
+	case schema.Int64:
+		if len(data) == 8 {
+			return int64(binary.LittleEndian.Uint64(data)), nil
+		}
+	case schema.Int96:
+		if len(data) == 12 {
+			return [12]byte(data), nil
+		}
+	case schema.Float:
+		if len(data) == 4 {
+			return math.Float32frombits(binary.LittleEndian.Uint32(data)), nil
+		}
+	case schema.Double:
+		if len(data) == 8 {
+			return math.Float64frombits(binary.LittleEndian.Uint64(data)), nil
+		}
+	case schema.ByteArray:
+		return append([]byte{}, data...), nil
+	case schema.FixedLenByteArray:
+		if len(data) == int(typeLength) {
+			return append([]byte{}, data...), nil
+		}
+	}
+	return nil, fmt.Errorf("cannot decode %d bytes as %v", len(data), typ)
+}
@@ -0,0 +1,97 @@
+package decoder
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+// DecodePlain decodes numValues PLAIN-encoded values of the given physical
+// type. BYTE_ARRAY and FIXED_LEN_BYTE_ARRAY values alias data.
+func DecodePlain(typ schema.Type, typeLength int32, data []byte, numValues int) ([]any, error) {
+	if numValues < 0 {
+		return nil, fmt.Errorf("%w: negative value count %d", ErrCorruptData, numValues)
+	}
+	// Check the count against the data before allocating for it.
+	switch typ {
+	case schema.Boolean:
+		if numValues > 8*len(data) {
+			return nil, errTruncated(typ)
+		}
+	case schema.ByteArray:
+		// Each value has at least its 4-byte length.
+		if numValues > len(data)/4 {
+			return nil, errTruncated(typ)
+		}
+	default:
+		size := plainSize(typ, typeLength)
+		if size <= 0 {
+			return nil, fmt.Errorf("%w: invalid size for %v", ErrCorruptData, typ)
+		}
+		if numValues > len(data)/size {
+			return nil, errTruncated(typ)
+		}
+	}
+
+	values := make([]any, numValues)
+	switch typ {
+	case schema.Boolean:
+		for i := range values {
+			values[i] = data[i/8]&(1<<(i%8)) != 0
+		}
+		return values, nil
+	case schema.ByteArray:
+		for i := range values {
+			if len(data) < 4 {
+				return nil, errTruncated(typ)
+			}
+			length := int(binary.LittleEndian.Uint32(data))
+			if length < 0 || len(data)-4 < length {
+				return nil, errTruncated(typ)
+			}
+			values[i] = data[4 : 4+length : 4+length]
+			data = data[4+length:]
+		}
+		return values, nil
+	}
+
+	size := plainSize(typ, typeLength)
+	for i := range values {
+		v := data[i*size : (i+1)*size : (i+1)*size]
+		switch typ {
+		case schema.Int32:
+			values[i] = int32(binary.LittleEndian.Uint32(v))
+		case schema.Int64:
+			values[i] = int64(binary.LittleEndian.Uint64(v))
+		case schema.Int96:
+			values[i] = [12]byte(v)
+		case schema.Float:
+			values[i] = math.Float32frombits(binary.LittleEndian.Uint32(v))
+		case schema.Double:
+			values[i] = math.Float64frombits(binary.LittleEndian.Uint64(v))
+		case schema.FixedLenByteArray:
+			values[i] = v
+		}
+	}
+	return values, nil
+}
+
+func plainSize(typ schema.Type, typeLength int32) int {
+	switch typ {
+	case schema.Int32, schema.Float:
+		return 4
+	case schema.Int64, schema.Double:
+		return 8
+	case schema.Int96:
+		return 12
+	case schema.FixedLenByteArray:
+		return int(typeLength)
+	}
+	return 0
+}
+
+func errTruncated(typ schema.Type) error {
+	return fmt.Errorf("%w: truncated PLAIN %v values", ErrCorruptData, typ)
+}
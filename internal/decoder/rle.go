@@ -0,0 +1,109 @@
+package decoder
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+var ErrCorruptData = errors.New("corrupt encoded data")
+
+// DecodeRLE decodes numValues values stored with the RLE/bit-packing hybrid
+// encoding, without a length prefix.
+func DecodeRLE(data []byte, bitWidth int, numValues int) ([]int32, error) {
+	if bitWidth < 0 || bitWidth > 32 {
+		return nil, fmt.Errorf("%w: invalid bit width %d", ErrCorruptData, bitWidth)
+	}
+	if numValues < 0 {
+		return nil, fmt.Errorf("%w: negative value count %d", ErrCorruptData, numValues)
+	}
+
+	// Runs can repeat a value any number of times, so the count is only
+	// trusted as far as the data decoded so far goes.
+	values := make([]int32, 0, min(numValues, 8*len(data)))
+	for len(values) < numValues {
+		header, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, fmt.Errorf("%w: truncated RLE run header", ErrCorruptData)
+		}
+		data = data[n:]
+
+		if header&1 == 0 {
+			count := int(header >> 1)
+			width := (bitWidth + 7) / 8
+			if len(data) < width {
+				return nil, fmt.Errorf("%w: truncated RLE run", ErrCorruptData)
+			}
+			var value int32
+			for b := range width {
+				value |= int32(data[b]) << (8 * b)
+			}
+			data = data[width:]
+			for range min(count, numValues-len(values)) {
+				values = append(values, value)
+			}
+			continue
+		}
+
+		// Groups past the values still needed are never decoded, so bound
+		// the count by them before it can overflow.
+		groups := min(header>>1, uint64(numValues-len(values)+7)/8)
+		count := int(groups) * 8
+		size := count * bitWidth / 8
+		if len(data) < size {
+			// The last bit-packed run may be cut short by the writer when it
+			// holds fewer than eight real values.
+			size = len(data)
+			count = size * 8 / max(bitWidth, 1)
+		}
+		values = unpackLSB(values, data[:size], bitWidth, min(count, numValues-len(values)))
+		data = data[size:]
+		if count == 0 {
+			return nil, fmt.Errorf("%w: empty bit-packed run", ErrCorruptData)
+		}
+	}
+	return values, nil
+}
+
+// unpackLSB unpacks count values packed from the least significant bit.
+func unpackLSB(values []int32, data []byte, bitWidth int, count int) []int32 {
+	bit := 0
+	for range count {
+		var value int32
+		for b := range bitWidth {
+			if data[(bit+b)/8]&(1<<((bit+b)%8)) != 0 {
+				value |= 1 << b
+			}
+		}
+		values = append(values, value)
+		bit += bitWidth
+	}
+	return values
+}
+
+// DecodeBitPacked decodes the deprecated BIT_PACKED level encoding, which
+// packs values from the most significant bit.
+func DecodeBitPacked(data []byte, bitWidth int, numValues int) ([]int32, int, error) {
+	if numValues < 0 || bitWidth > 0 && numValues > 8*len(data)/bitWidth {
+		return nil, 0, fmt.Errorf("%w: truncated bit-packed levels", ErrCorruptData)
+	}
+	size := (numValues*bitWidth + 7) / 8
+	if len(data) < size {
+		return nil, 0, fmt.Errorf("%w: truncated bit-packed levels", ErrCorruptData)
+	}
+
+	values := make([]int32, numValues)
+	bit := 0
+	for i := range values {
+		var value int32
+		for range bitWidth {
+			value <<= 1
+			if data[bit/8]&(0x80>>(bit%8)) != 0 {
+				value |= 1
+			}
+			bit++
+		}
+		values[i] = value
+	}
+	return values, size, nil
+}
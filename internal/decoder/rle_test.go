@@ -0,0 +1,66 @@
+package decoder
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/RichardNooooh/parquet-go/internal/encoder"
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+func TestDecodeRLERoundTrip(t *testing.T) {
+	testcases := map[string]struct {
+		values   []int32
+		bitWidth int
+	}{
+		"single run":     {values: slices.Repeat([]int32{3}, 20), bitWidth: 2},
+		"bit packed":     {values: []int32{0, 1, 2, 3, 4, 5, 6, 7, 0, 1, 2}, bitWidth: 3},
+		"mixed":          {values: append(slices.Repeat([]int32{1}, 12), 0, 1, 0, 1, 1, 0), bitWidth: 1},
+		"zero bit width": {values: make([]int32, 5), bitWidth: 0},
+		"wide values":    {values: []int32{1 << 20, 0, 1<<20 + 1}, bitWidth: 21},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			data := encoder.EncodeRLE(test.values, test.bitWidth)
+			values, err := DecodeRLE(data, test.bitWidth, len(test.values))
+			if err != nil {
+				t.Fatalf("unable to decode: %v", err)
+			}
+			if !slices.Equal(values, test.values) {
+				t.Errorf("expected %v, got %v", test.values, values)
+			}
+		})
+	}
+}
+
+func TestDecodeRLETruncated(t *testing.T) {
+	data := encoder.EncodeRLE([]int32{0, 1, 2, 3, 4, 5, 6, 7}, 3)
+	if _, err := DecodeRLE(data[:len(data)-1], 3, 8); err == nil {
+		t.Errorf("expected an error for truncated data")
+	}
+}
+
+func TestDecodeCorrupt(t *testing.T) {
+	overflow := []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01, 0xff}
+
+	testcases := map[string]func() error{
+		"negative RLE count": func() error { _, err := DecodeRLE(nil, 1, -1); return err },
+		"overflowing bit-packed run": func() error {
+			_, err := DecodeRLE(overflow, 1, 16)
+			return err
+		},
+		"negative bit-packed count": func() error { _, _, err := DecodeBitPacked(nil, 1, -1); return err },
+		"negative plain count":      func() error { _, err := DecodePlain(schema.Int32, 0, nil, -1); return err },
+		"plain count beyond data":   func() error { _, err := DecodePlain(schema.ByteArray, 0, make([]byte, 7), 2); return err },
+	}
+
+	for name, decode := range testcases {
+		t.Run(name, func(t *testing.T) {
+			if err := decode(); !errors.Is(err, ErrCorruptData) {
+				t.Errorf("expected %v, got %v", ErrCorruptData, err)
+			}
+		})
+	}
+}
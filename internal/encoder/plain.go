@@ -0,0 +1,91 @@
+package encoder
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+// EncodePlain encodes values of the given physical type with the PLAIN
+// encoding. Values must already hold the Go type matching the physical type:
+// bool, int32, int64, [12]byte, float32, float64 or []byte.
+func EncodePlain(typ schema.Type, values []any) ([]byte, error) {
+	if typ == schema.Boolean {
+		return encodePlainBooleans(values)
+	}
+
+	var buffer []byte
+	for _, value := range values {
+		if typ == schema.ByteArray {
+			v, ok := value.([]byte)
+			if !ok {
+				return nil, fmt.Errorf("expected []byte for %v, got %T", typ, value)
+			}
+			buffer = binary.LittleEndian.AppendUint32(buffer, uint32(len(v)))
+			buffer = append(buffer, v...)
+			continue
+		}
+
+		encoded, err := PlainValue(typ, value)
+		if err != nil {
+			return nil, err
+		}
+		buffer = append(buffer, encoded...)
+	}
+	return buffer, nil
+}
+
+// PlainValue encodes a single value the way it appears in statistics: the
+// PLAIN encoding without the length prefix of BYTE_ARRAY values.
+func PlainValue(typ schema.Type, value any) ([]byte, error) {
+	switch v := value.(type) {
+	case bool:
+		if typ == schema.Boolean {
+			if v {
+				return []byte{1}, nil
+			}
+			return []byte{0}, nil
+		}
+	case int32:
+		if typ == schema.Int32 {
+			return binary.LittleEndian.AppendUint32(nil, uint32(v)), nil
+		}
+	case int64:
+		if typ == schema.Int64 {
+			return binary.LittleEndian.AppendUint64(nil, uint64(v)), nil
+		}
+	case [12]byte:
+		if typ == schema.Int96 {
+			return v[:], nil
+		}
+	case float32:
+		if typ == schema.Float {
+			return binary.LittleEndian.AppendUint32(nil, math.Float32bits(v)), nil
+		}
+	case float64:
+		if typ == schema.Double {
+			return binary.LittleEndian.AppendUint64(nil, math.Float64bits(v)), nil
+		}
+	case []byte:
+		if typ == schema.ByteArray || typ == schema.FixedLenByteArray {
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot encode %T as %v", value, typ)
+}
+
+func encodePlainBooleans(values []any) ([]byte, error) {
+	buffer := make([]byte, (len(values)+7)/8)
+	for i, value := range values {
+		v, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expected bool for %v, got %T", schema.Boolean, value)
+		}
+		if v {
+			buffer[i/8] |= 1 << (i % 8)
+		}
+	}
+	return buffer, nil
+}
@@ -0,0 +1,68 @@
+package encoder
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// BitWidth returns the number of bits needed to store values up to maxValue.
+func BitWidth(maxValue int) int {
+	return bits.Len(uint(maxValue))
+}
+
+// EncodeRLE encodes values with the RLE/bit-packing hybrid encoding. Runs of at
+// least eight repeated values are run-length encoded, everything else is
+// bit-packed in groups of eight.
+func EncodeRLE(values []int32, bitWidth int) []byte {
+	var buffer []byte
+	for i := 0; i < len(values); {
+		if run := runLength(values, i); run >= 8 {
+			buffer = appendRLERun(buffer, values[i], run, bitWidth)
+			i += run
+			continue
+		}
+
+		start := i
+		for i < len(values) {
+			if (i-start)%8 == 0 && runLength(values, i) >= 8 {
+				break
+			}
+			i++
+		}
+		buffer = appendBitPackedRun(buffer, values[start:i], bitWidth)
+	}
+	return buffer
+}
+
+func runLength(values []int32, i int) int {
+	j := i + 1
+	for j < len(values) && values[j] == values[i] {
+		j++
+	}
+	return j - i
+}
+
+func appendRLERun(buffer []byte, value int32, count int, bitWidth int) []byte {
+	buffer = binary.AppendUvarint(buffer, uint64(count)<<1)
+	for b := 0; b < (bitWidth+7)/8; b++ {
+		buffer = append(buffer, byte(value>>(8*b)))
+	}
+	return buffer
+}
+
+func appendBitPackedRun(buffer []byte, values []int32, bitWidth int) []byte {
+	groups := (len(values) + 7) / 8
+	buffer = binary.AppendUvarint(buffer, uint64(groups)<<1|1)
+
+	packed := make([]byte, groups*bitWidth)
+	bit := 0
+	for _, value := range values {
+		for b := 0; b < bitWidth; b++ {
+			if value&(1<<b) != 0 {
+				packed[bit/8] |= 1 << (bit % 8)
+			}
+			bit++
+		}
+	}
+	return append(buffer, packed...)
+}
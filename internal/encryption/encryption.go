@@ -0,0 +1,272 @@
+// Package encryption implements the ciphers and additional authenticated data
+// of Parquet modular encryption.
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+const (
+	NonceLength     = 12
+	TagLength       = 16
+	SignatureLength = NonceLength + TagLength
+	lengthSize      = 4
+)
+
+var (
+	ErrDecryption = errors.New("unable to decrypt module")
+	// ErrSignature is returned when a plaintext footer does not match its
+	// signature.
+	ErrSignature = errors.New("footer signature mismatch")
+)
+
+// Algorithm is the cipher of the pages of a file. Every other module is
+// encrypted with AES GCM.
+type Algorithm int
+
+const (
+	AesGcmV1 Algorithm = iota
+	// AesGcmCtrV1 encrypts pages with AES CTR, which skips authenticating
+	// the bulk of the data.
+	AesGcmCtrV1
+)
+
+// ModuleType identifies the kind of module in its additional authenticated
+// data, so that a module cannot be swapped for another.
+type ModuleType byte
+
+const (
+	Footer ModuleType = iota
+	ColumnMetaData
+	DataPage
+	DictionaryPage
+	DataPageHeader
+	DictionaryPageHeader
+	ColumnIndex
+	OffsetIndex
+	BloomFilterHeader
+	BloomFilterBitset
+)
+
+// CheckKey checks that key is an AES-128, AES-192 or AES-256 key.
+func CheckKey(key []byte) error {
+	_, err := aes.NewCipher(key)
+	return err
+}
+
+// ModuleAAD returns the additional authenticated data of a module: the file
+// AAD followed by the module type and, except for the footer, the row group
+// and column ordinals. Data pages and their headers add the page ordinal.
+func ModuleAAD(fileAAD []byte, module ModuleType, rowGroup, column, page int) ([]byte, error) {
+	aad := append(fileAAD[:len(fileAAD):len(fileAAD)], byte(module))
+	if module == Footer {
+		return aad, nil
+	}
+	ordinals := []int{rowGroup, column}
+	if module == DataPage || module == DataPageHeader {
+		ordinals = append(ordinals, page)
+	}
+	for _, ordinal := range ordinals {
+		if ordinal < 0 || ordinal > math.MaxInt16 {
+			return nil, fmt.Errorf("module ordinal %d out of range [0, %d]", ordinal, math.MaxInt16)
+		}
+		aad = binary.LittleEndian.AppendUint16(aad, uint16(ordinal))
+	}
+	return aad, nil
+}
+
+// EncryptGCM encrypts plaintext into a module of its length, the nonce, the
+// ciphertext and the GCM tag.
+func EncryptGCM(key, nonce, plaintext, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key, nonce)
+	if err != nil {
+		return nil, err
+	}
+	module := binary.LittleEndian.AppendUint32(nil, uint32(NonceLength+len(plaintext)+TagLength))
+	module = append(module, nonce...)
+	return gcm.Seal(module, nonce, plaintext, aad), nil
+}
+
+// DecryptGCM decrypts the GCM module at the start of data, returning the
+// plaintext and the size of the module.
+func DecryptGCM(key, data, aad []byte) ([]byte, int, error) {
+	body, n, err := splitModule(data, NonceLength+TagLength)
+	if err != nil {
+		return nil, 0, err
+	}
+	gcm, err := newGCM(key, body[:NonceLength])
+	if err != nil {
+		return nil, 0, err
+	}
+	plaintext, err := gcm.Open(nil, body[:NonceLength], body[NonceLength:], aad)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", ErrDecryption, err)
+	}
+	return plaintext, n, nil
+}
+
+// EncryptCTR encrypts plaintext into a module of its length, the nonce and
+// the ciphertext.
+func EncryptCTR(key, nonce, plaintext []byte) ([]byte, error) {
+	stream, err := newCTR(key, nonce)
+	if err != nil {
+		return nil, err
+	}
+	module := binary.LittleEndian.AppendUint32(nil, uint32(NonceLength+len(plaintext)))
+	module = append(module, nonce...)
+	ciphertext := make([]byte, len(plaintext))
+	stream.XORKeyStream(ciphertext, plaintext)
+	return append(module, ciphertext...), nil
+}
+
+// DecryptCTR decrypts the CTR module at the start of data, returning the
+// plaintext and the size of the module.
+func DecryptCTR(key, data []byte) ([]byte, int, error) {
+	body, n, err := splitModule(data, NonceLength)
+	if err != nil {
+		return nil, 0, err
+	}
+	stream, err := newCTR(key, body[:NonceLength])
+	if err != nil {
+		return nil, 0, err
+	}
+	plaintext := make([]byte, len(body)-NonceLength)
+	stream.XORKeyStream(plaintext, body[NonceLength:])
+	return plaintext, n, nil
+}
+
+// Sign returns the signature of a plaintext footer: the nonce and the GCM tag
+// of the encrypted footer.
+func Sign(key, nonce, footer, aad []byte) ([]byte, error) {
+	module, err := EncryptGCM(key, nonce, footer, aad)
+	if err != nil {
+		return nil, err
+	}
+	return append(nonce[:NonceLength:NonceLength], module[len(module)-TagLength:]...), nil
+}
+
+// Verify checks the signature of a plaintext footer.
+func Verify(key, footer, signature, aad []byte) error {
+	if len(signature) != SignatureLength {
+		return fmt.Errorf("%w: signature of %d bytes", ErrSignature, len(signature))
+	}
+	expected, err := Sign(key, signature[:NonceLength], footer, aad)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(expected, signature) != 1 {
+		return ErrSignature
+	}
+	return nil
+}
+
+// splitModule returns the body of the module at the start of data, after its
+// length, and the size of the whole module.
+func splitModule(data []byte, minLength int) ([]byte, int, error) {
+	if len(data) < lengthSize {
+		return nil, 0, fmt.Errorf("%w: truncated module length", ErrDecryption)
+	}
+	length := int64(binary.LittleEndian.Uint32(data))
+	if length < int64(minLength) || length > int64(len(data)-lengthSize) {
+		return nil, 0, fmt.Errorf("%w: module of %d bytes in %d bytes", ErrDecryption, length, len(data)-lengthSize)
+	}
+	n := lengthSize + int(length)
+	return data[lengthSize:n], n, nil
+}
+
+func newGCM(key, nonce []byte) (cipher.AEAD, error) {
+	if len(nonce) != NonceLength {
+		return nil, fmt.Errorf("nonce of %d bytes, expected %d", len(nonce), NonceLength)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// newCTR starts the 4 byte counter that follows the nonce at 1.
+func newCTR(key, nonce []byte) (cipher.Stream, error) {
+	if len(nonce) != NonceLength {
+		return nil, fmt.Errorf("nonce of %d bytes, expected %d", len(nonce), NonceLength)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	iv := make([]byte, aes.BlockSize)
+	copy(iv, nonce)
+	iv[aes.BlockSize-1] = 1
+	return cipher.NewCTR(block, iv), nil
+}
+
+// Decryptor decrypts the modules of one column chunk. The footer modules of
+// a file only use its algorithm, key and file AAD.
+type Decryptor struct {
+	Algorithm Algorithm
+	Key       []byte
+	FileAAD   []byte
+	RowGroup  int
+	Column    int
+}
+
+// Decrypt decrypts the module at the start of data, returning the plaintext
+// and the size of the module. page is the ordinal of a data page within its
+// column chunk, and is ignored for other modules.
+func (d *Decryptor) Decrypt(module ModuleType, page int, data []byte) ([]byte, int, error) {
+	if d.Algorithm == AesGcmCtrV1 && (module == DataPage || module == DictionaryPage) {
+		return DecryptCTR(d.Key, data)
+	}
+	aad, err := ModuleAAD(d.FileAAD, module, d.RowGroup, d.Column, page)
+	if err != nil {
+		return nil, 0, err
+	}
+	return DecryptGCM(d.Key, data, aad)
+}
+
+// Encryptor encrypts the modules of one column chunk with random nonces. The
+// footer modules of a file only use its algorithm, key and file AAD.
+type Encryptor struct {
+	Algorithm Algorithm
+	Key       []byte
+	FileAAD   []byte
+	RowGroup  int
+	Column    int
+}
+
+// Encrypt encrypts plaintext into a module. page is the ordinal of a data
+// page within its column chunk, and is ignored for other modules.
+func (e *Encryptor) Encrypt(module ModuleType, page int, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, NonceLength)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	if e.Algorithm == AesGcmCtrV1 && (module == DataPage || module == DictionaryPage) {
+		return EncryptCTR(e.Key, nonce, plaintext)
+	}
+	aad, err := ModuleAAD(e.FileAAD, module, e.RowGroup, e.Column, page)
+	if err != nil {
+		return nil, err
+	}
+	return EncryptGCM(e.Key, nonce, plaintext, aad)
+}
+
+// Sign signs a plaintext footer with a random nonce.
+func (e *Encryptor) Sign(footer []byte) ([]byte, error) {
+	nonce := make([]byte, NonceLength)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	aad, err := ModuleAAD(e.FileAAD, Footer, 0, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	return Sign(e.Key, nonce, footer, aad)
+}
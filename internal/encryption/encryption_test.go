@@ -0,0 +1,160 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto/aes"
+	"errors"
+	"testing"
+)
+
+var (
+	testKey   = []byte("0123456789012345")
+	testNonce = []byte("nonce-012345")
+)
+
+func TestModuleAAD(t *testing.T) {
+	fileAAD := []byte("file")
+	testcases := map[string]struct {
+		module                 ModuleType
+		rowGroup, column, page int
+		expected               []byte
+	}{
+		"footer":          {module: Footer, rowGroup: 1, column: 2, page: 3, expected: []byte("file\x00")},
+		"column metadata": {module: ColumnMetaData, rowGroup: 1, column: 2, page: 3, expected: []byte("file\x01\x01\x00\x02\x00")},
+		"data page":       {module: DataPage, rowGroup: 1, column: 2, page: 258, expected: []byte("file\x02\x01\x00\x02\x00\x02\x01")},
+		"page header":     {module: DataPageHeader, rowGroup: 0, column: 0, page: 0, expected: []byte("file\x04\x00\x00\x00\x00\x00\x00")},
+		"dictionary page": {module: DictionaryPage, rowGroup: 3, column: 4, page: 5, expected: []byte("file\x03\x03\x00\x04\x00")},
+		"offset index":    {module: OffsetIndex, rowGroup: 0, column: 1, expected: []byte("file\x07\x00\x00\x01\x00")},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			aad, err := ModuleAAD(fileAAD, test.module, test.rowGroup, test.column, test.page)
+			if err != nil {
+				t.Fatalf("unable to build AAD: %v", err)
+			}
+			if !bytes.Equal(aad, test.expected) {
+				t.Errorf("expected %q, got %q", test.expected, aad)
+			}
+		})
+	}
+
+	if _, err := ModuleAAD(fileAAD, DataPage, 0, 0, 1<<15); err == nil {
+		t.Errorf("expected error for a page ordinal above 32767, got nil error")
+	}
+}
+
+func TestModuleRoundTrip(t *testing.T) {
+	plaintext := []byte("some page bytes")
+	aad := []byte("aad")
+
+	gcm, err := EncryptGCM(testKey, testNonce, plaintext, aad)
+	if err != nil {
+		t.Fatalf("unable to encrypt: %v", err)
+	}
+	if len(gcm) != 4+NonceLength+len(plaintext)+TagLength {
+		t.Errorf("expected a GCM module of %d bytes, got %d", 4+NonceLength+len(plaintext)+TagLength, len(gcm))
+	}
+	decrypted, n, err := DecryptGCM(testKey, append(gcm, "next module"...), aad)
+	if err != nil || n != len(gcm) || !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("expected %q in %d bytes, got %q in %d bytes: %v", plaintext, len(gcm), decrypted, n, err)
+	}
+
+	ctr, err := EncryptCTR(testKey, testNonce, plaintext)
+	if err != nil {
+		t.Fatalf("unable to encrypt: %v", err)
+	}
+	if len(ctr) != 4+NonceLength+len(plaintext) {
+		t.Errorf("expected a CTR module of %d bytes, got %d", 4+NonceLength+len(plaintext), len(ctr))
+	}
+	decrypted, n, err = DecryptCTR(testKey, ctr)
+	if err != nil || n != len(ctr) || !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("expected %q in %d bytes, got %q in %d bytes: %v", plaintext, len(ctr), decrypted, n, err)
+	}
+
+	// The counter of the first block is 1.
+	block, err := aes.NewCipher(testKey)
+	if err != nil {
+		t.Fatalf("unable to create cipher: %v", err)
+	}
+	keystream := make([]byte, aes.BlockSize)
+	block.Encrypt(keystream, append(bytes.Clone(testNonce), 0, 0, 0, 1))
+	for i, b := range ctr[4+NonceLength:] {
+		if b != plaintext[i]^keystream[i] {
+			t.Fatalf("expected the CTR counter to start at 1")
+		}
+	}
+}
+
+func TestDecryptGCMErrors(t *testing.T) {
+	aad := []byte("aad")
+	module, err := EncryptGCM(testKey, testNonce, []byte("secret"), aad)
+	if err != nil {
+		t.Fatalf("unable to encrypt: %v", err)
+	}
+	tampered := bytes.Clone(module)
+	tampered[len(tampered)-1] ^= 1
+
+	testcases := map[string]struct {
+		key, module, aad []byte
+	}{
+		"wrong key":    {key: []byte("5432109876543210"), module: module, aad: aad},
+		"wrong aad":    {key: testKey, module: module, aad: []byte("other")},
+		"tampered":     {key: testKey, module: tampered, aad: aad},
+		"truncated":    {key: testKey, module: module[:len(module)-1], aad: aad},
+		"no length":    {key: testKey, module: module[:3], aad: aad},
+		"short module": {key: testKey, module: []byte("\x04\x00\x00\x00abcd"), aad: aad},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := DecryptGCM(test.key, test.module, test.aad); !errors.Is(err, ErrDecryption) {
+				t.Errorf("expected ErrDecryption, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSignature(t *testing.T) {
+	footer := []byte("footer bytes")
+	aad := []byte("file\x00")
+	signature, err := Sign(testKey, testNonce, footer, aad)
+	if err != nil {
+		t.Fatalf("unable to sign: %v", err)
+	}
+	if len(signature) != SignatureLength {
+		t.Fatalf("expected a signature of %d bytes, got %d", SignatureLength, len(signature))
+	}
+	if err := Verify(testKey, footer, signature, aad); err != nil {
+		t.Errorf("expected the signature to verify, got %v", err)
+	}
+	if err := Verify(testKey, []byte("footer bytez"), signature, aad); !errors.Is(err, ErrSignature) {
+		t.Errorf("expected ErrSignature for a modified footer, got %v", err)
+	}
+	if err := Verify(testKey, footer, signature[:20], aad); !errors.Is(err, ErrSignature) {
+		t.Errorf("expected ErrSignature for a truncated signature, got %v", err)
+	}
+}
+
+func TestEncryptorRoundTrip(t *testing.T) {
+	plaintext := []byte("some page bytes")
+	for _, algorithm := range []Algorithm{AesGcmV1, AesGcmCtrV1} {
+		encryptor := &Encryptor{Algorithm: algorithm, Key: testKey, FileAAD: []byte("file"), RowGroup: 1, Column: 2}
+		decryptor := &Decryptor{Algorithm: algorithm, Key: testKey, FileAAD: []byte("file"), RowGroup: 1, Column: 2}
+		for _, module := range []ModuleType{DataPage, DataPageHeader, ColumnIndex} {
+			data, err := encryptor.Encrypt(module, 3, plaintext)
+			if err != nil {
+				t.Fatalf("unable to encrypt: %v", err)
+			}
+			decrypted, _, err := decryptor.Decrypt(module, 3, data)
+			if err != nil || !bytes.Equal(decrypted, plaintext) {
+				t.Errorf("module %d: expected %q, got %q: %v", module, plaintext, decrypted, err)
+			}
+			if module == DataPageHeader {
+				if _, _, err := decryptor.Decrypt(module, 4, data); !errors.Is(err, ErrDecryption) {
+					t.Errorf("expected ErrDecryption for another page ordinal, got %v", err)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package file
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/RichardNooooh/parquet-go/internal/encryption"
+	format "github.com/RichardNooooh/parquet-go/internal/format/gen-go/parquet"
+	"github.com/RichardNooooh/parquet-go/internal/thriftio"
+)
+
+// maxBloomFilterHeaderSize bounds the read of a bloom filter header when the
+// column chunk does not record the filter length. Headers take a handful of
+// bytes.
+const maxBloomFilterHeaderSize = 64
+
+// GetBloomFilter reads the bloom filter header and bitset of a column chunk,
+// or returns nil when the chunk has none. decryptor is nil unless the column
+// is encrypted.
+func GetBloomFilter(ctx context.Context, file *FileReader, metadata *format.ColumnMetaData, decryptor *encryption.Decryptor) (*format.BloomFilterHeader, []byte, error) {
+	if !metadata.IsSetBloomFilterOffset() {
+		return nil, nil, nil
+	}
+	if decryptor != nil {
+		return getEncryptedBloomFilter(ctx, file, metadata.GetBloomFilterOffset(), decryptor)
+	}
+
+	offset := metadata.GetBloomFilterOffset()
+	length := int64(metadata.GetBloomFilterLength())
+	if !metadata.IsSetBloomFilterLength() {
+		length = min(maxBloomFilterHeaderSize, file.Size-offset)
+	}
+	data, err := readRange(file, offset, length)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read bloom filter: %w", err)
+	}
+
+	header, n, err := decodeBloomFilterHeader(ctx, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	numBytes := int64(header.GetNumBytes())
+	if metadata.IsSetBloomFilterLength() {
+		if numBytes != int64(len(data)-n) {
+			return nil, nil, fmt.Errorf("bloom filter of %d bytes does not fill its %d byte range", numBytes, length)
+		}
+		return header, data[n:], nil
+	}
+	bitset, err := readRange(file, offset+int64(n), numBytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read bloom filter bitset: %w", err)
+	}
+	return header, bitset, nil
+}
+
+// getEncryptedBloomFilter reads the bloom filter header and bitset modules of
+// an encrypted column chunk.
+func getEncryptedBloomFilter(ctx context.Context, file *FileReader, offset int64, decryptor *encryption.Decryptor) (*format.BloomFilterHeader, []byte, error) {
+	module, err := readModule(file, offset)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read bloom filter: %w", err)
+	}
+	data, _, err := decryptor.Decrypt(encryption.BloomFilterHeader, 0, module)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to decrypt bloom filter header: %w", err)
+	}
+	header, _, err := decodeBloomFilterHeader(ctx, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if module, err = readModule(file, offset+int64(len(module))); err != nil {
+		return nil, nil, fmt.Errorf("unable to read bloom filter bitset: %w", err)
+	}
+	bitset, _, err := decryptor.Decrypt(encryption.BloomFilterBitset, 0, module)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to decrypt bloom filter bitset: %w", err)
+	}
+	if int64(len(bitset)) != int64(header.GetNumBytes()) {
+		return nil, nil, fmt.Errorf("bloom filter of %d bytes has a %d byte bitset", header.GetNumBytes(), len(bitset))
+	}
+	return header, bitset, nil
+}
+
+func decodeBloomFilterHeader(ctx context.Context, data []byte) (*format.BloomFilterHeader, int, error) {
+	header := format.NewBloomFilterHeader()
+	n, err := thriftio.Decode(ctx, data, header)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to read bloom filter header: %w", err)
+	}
+	if !header.GetAlgorithm().IsSetBLOCK() || !header.GetHash().IsSetXXHASH() || !header.GetCompression().IsSetUNCOMPRESSED() {
+		return nil, 0, fmt.Errorf("unsupported bloom filter %v", header)
+	}
+	return header, n, nil
+}
@@ -0,0 +1,62 @@
+package file
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/RichardNooooh/parquet-go/internal/encryption"
+	format "github.com/RichardNooooh/parquet-go/internal/format/gen-go/parquet"
+	"github.com/RichardNooooh/parquet-go/internal/thriftio"
+)
+
+// DecryptPages splits a buffer of consecutive encrypted pages that starts at
+// offset in the file, decrypting every page header and page. dictionary
+// reports whether the buffer starts with the dictionary page of its column
+// chunk, and page is the ordinal of the first data page in the buffer.
+func DecryptPages(ctx context.Context, data []byte, offset int64, decryptor *encryption.Decryptor, dictionary bool, page int) ([]*Page, error) {
+	var pages []*Page
+	for position := 0; position < len(data); {
+		headerModule, pageModule := encryption.DataPageHeader, encryption.DataPage
+		if dictionary && position == 0 {
+			headerModule, pageModule = encryption.DictionaryPageHeader, encryption.DictionaryPage
+		}
+		pageOffset := offset + int64(position)
+
+		plaintext, n, err := decryptor.Decrypt(headerModule, page, data[position:])
+		if err != nil {
+			return nil, fmt.Errorf("unable to decrypt page header at offset %d: %w", pageOffset, err)
+		}
+		header := format.NewPageHeader()
+		if _, err := thriftio.Decode(ctx, plaintext, header); err != nil {
+			return nil, fmt.Errorf("unable to read page header at offset %d: %w", pageOffset, err)
+		}
+		// The compressed size of an encrypted page is the size of its module.
+		size := int(header.GetCompressedPageSize())
+		if size < 0 || size > len(data)-position-n {
+			return nil, fmt.Errorf("page at offset %d overruns its column chunk (%d bytes)", pageOffset, size)
+		}
+		body, _, err := decryptor.Decrypt(pageModule, page, data[position+n:position+n+size])
+		if err != nil {
+			return nil, fmt.Errorf("unable to decrypt page at offset %d: %w", pageOffset, err)
+		}
+
+		pages = append(pages, &Page{Header: header, Data: body, Offset: pageOffset})
+		if header.GetType() != format.PageType_DICTIONARY_PAGE {
+			page++
+		}
+		position += n + size
+	}
+	return pages, nil
+}
+
+// readModule reads the encrypted module at offset, whose size is only known
+// from its length prefix.
+func readModule(file *FileReader, offset int64) ([]byte, error) {
+	prefix, err := readRange(file, offset, wordLength)
+	if err != nil {
+		return nil, err
+	}
+	length := int64(binary.LittleEndian.Uint32(prefix))
+	return readRange(file, offset, wordLength+length)
+}
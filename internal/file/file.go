@@ -1,6 +1,7 @@
 package file
 
 import (
+	"bytes"
 	"context"
 	"encoding/binary"
 	"errors"
@@ -20,32 +21,117 @@ func NewReader(reader io.ReaderAt, size int64) *FileReader {
 	return &FileReader{reader, size}
 }
 
+// Mapped is implemented by readers that hold the whole file in memory, such
+// as memory-mapped files. Ranges of them are returned without copying.
+type Mapped interface {
+	Bytes() []byte
+}
+
 var parquetMagic = []byte("PAR1")
+
+// encryptedMagic starts and ends files whose footer is encrypted.
+var encryptedMagic = []byte("PARE")
+
 var ErrNotParquet = errors.New("not a Parquet file")
 
+// ErrEncryptedFooter is returned when decoding the footer of a file whose
+// footer is encrypted without decrypting it first.
+var ErrEncryptedFooter = errors.New("footer is encrypted")
+
 const wordLength = 4
 
+// Footer is the serialized footer of a file: the FileMetaData, or for a file
+// with an encrypted footer the FileCryptoMetaData followed by the encrypted
+// FileMetaData.
+type Footer struct {
+	Data      []byte
+	Encrypted bool
+}
+
 func GetFileMetadata(ctx context.Context, file *FileReader) (*format.FileMetaData, error) {
-	if err := checkParquet(file); err != nil {
+	footer, err := ReadFooter(ctx, file, 0)
+	if err != nil {
 		return nil, err
 	}
-	fileMetadataSize, err := getFileMetadataSize(file)
+	return decodeFooter(ctx, footer)
+}
+
+// GetFileMetadataFromTail is GetFileMetadata reading the footer with a single
+// read of the last tailSize bytes of the file, as described by ReadFooter.
+func GetFileMetadataFromTail(ctx context.Context, file *FileReader, tailSize int64) (*format.FileMetaData, error) {
+	footer, err := ReadFooter(ctx, file, max(tailSize, 2*wordLength))
 	if err != nil {
 		return nil, err
 	}
+	return decodeFooter(ctx, footer)
+}
 
-	compactMetadataBuffer := make([]byte, fileMetadataSize)
-	count, err := file.Reader.ReadAt(compactMetadataBuffer, file.Size-2*wordLength-int64(fileMetadataSize))
-	if err != nil {
-		return nil, fmt.Errorf("unable to read footer metadata: %w", err)
+func decodeFooter(ctx context.Context, footer *Footer) (*format.FileMetaData, error) {
+	if footer.Encrypted {
+		return nil, ErrEncryptedFooter
 	}
-	if int64(count) < fileMetadataSize {
-		return nil, fmt.Errorf("unable to read all footer metadata")
+	return thriftio.DecodeFileMetadata(ctx, footer.Data, int64(len(footer.Data)))
+}
+
+// ReadFooter reads the footer of a file. With a tailSize of zero, the magic
+// numbers, the footer length and the footer are read separately. Otherwise
+// the last tailSize bytes of the file are read in a single call, and the file
+// is only read again when the footer does not fit in them, saving reads
+// against high-latency storage. The header magic is then checked only when
+// the tail covers the whole file.
+func ReadFooter(ctx context.Context, file *FileReader, tailSize int64) (*Footer, error) {
+	if tailSize <= 0 {
+		encrypted, err := checkParquet(file)
+		if err != nil {
+			return nil, err
+		}
+		fileMetadataSize, err := getFileMetadataSize(file)
+		if err != nil {
+			return nil, err
+		}
+		footer := &Footer{Data: make([]byte, fileMetadataSize), Encrypted: encrypted}
+		count, err := file.Reader.ReadAt(footer.Data, file.Size-2*wordLength-fileMetadataSize)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read footer metadata: %w", err)
+		}
+		if int64(count) < fileMetadataSize {
+			return nil, fmt.Errorf("unable to read all footer metadata")
+		}
+		return footer, nil
+	}
+
+	if file.Size < 3*wordLength {
+		return nil, fmt.Errorf("%w: file is too small! minimum size: %d, actual size: %d", ErrNotParquet, 3*wordLength, file.Size)
+	}
+	tailSize = min(max(tailSize, 2*wordLength), file.Size)
+	tail := make([]byte, tailSize)
+	if _, err := file.Reader.ReadAt(tail, file.Size-tailSize); err != nil {
+		return nil, fmt.Errorf("could not read enough bytes at end of file: %w", err)
 	}
 
-	fileMetadata, err := thriftio.DecodeFileMetadata(ctx, compactMetadataBuffer, fileMetadataSize)
+	footerMagic := tail[tailSize-wordLength:]
+	encrypted := bytes.Equal(footerMagic, encryptedMagic)
+	if !encrypted && !bytes.Equal(footerMagic, parquetMagic) {
+		return nil, fmt.Errorf("%w: footer magic mismatch: got %q", ErrNotParquet, footerMagic)
+	}
+	if tailSize == file.Size && !bytes.Equal(tail[:wordLength], footerMagic) {
+		return nil, fmt.Errorf("%w: header magic mismatch: got %q", ErrNotParquet, tail[:wordLength])
+	}
+	fileMetadataSize, err := decodeFileMetadataSize(tail[tailSize-2*wordLength:tailSize-wordLength], file.Size)
+	if err != nil {
+		return nil, err
+	}
 
-	return fileMetadata, nil
+	footer := &Footer{Encrypted: encrypted}
+	if footerSize := fileMetadataSize + 2*wordLength; footerSize <= tailSize {
+		footer.Data = tail[tailSize-footerSize : tailSize-2*wordLength]
+	} else {
+		footer.Data = make([]byte, fileMetadataSize)
+		if _, err := file.Reader.ReadAt(footer.Data, file.Size-footerSize); err != nil {
+			return nil, fmt.Errorf("unable to read footer metadata: %w", err)
+		}
+	}
+	return footer, nil
 }
 
 func GetPageLocations(fileMetadata *format.FileMetaData) ([]int64, error) {
@@ -63,8 +149,12 @@ func getFileMetadataSize(file *FileReader) (int64, error) {
 		return 0, fmt.Errorf("%w: could not read enough bytes for file metadata size", ErrNotParquet)
 	}
 
-	fileMetadataSize := int64(binary.LittleEndian.Uint32(fileMetadataLenBuffer[:]))
-	if fileMetadataSize > file.Size-3*wordLength {
+	return decodeFileMetadataSize(fileMetadataLenBuffer[:], file.Size)
+}
+
+func decodeFileMetadataSize(buffer []byte, fileSize int64) (int64, error) {
+	fileMetadataSize := int64(binary.LittleEndian.Uint32(buffer))
+	if fileMetadataSize > fileSize-3*wordLength {
 		return 0, fmt.Errorf("%w: file metadata too large (%d bytes)", ErrNotParquet, fileMetadataSize)
 	} else if fileMetadataSize == 0 {
 		return 0, fmt.Errorf("%w: file metadata is of size 0", ErrNotParquet)
@@ -2,10 +2,12 @@ package file
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"testing"
 )
@@ -111,3 +113,63 @@ func generateValidFakeParquet(size uint32, littleEndianSize string) []byte {
 
 	return buffer
 }
+
+func TestGetFileMetadataFromTail(t *testing.T) {
+	testcaseDir := filepath.Join(getTestcaseDirectory(), "timestored_examples")
+	testcases := *getExpectedFileMetadataSizes(filepath.Join(testcaseDir, "fileMetadataSizes.json"))
+	for name, footerSize := range testcases {
+		data, err := os.ReadFile(filepath.Join(testcaseDir, name+".parquet"))
+		if err != nil {
+			t.Fatalf("unable to read %v: %v", name, err)
+		}
+		expected, err := GetFileMetadata(context.Background(), NewReader(bytes.NewReader(data), int64(len(data))))
+		if err != nil {
+			t.Fatalf("unable to read footer of %v: %v", name, err)
+		}
+
+		for _, tailSize := range []int64{0, 64, 64 << 10, int64(len(data)) + 1} {
+			t.Run(fmt.Sprintf("%s/%d", name, tailSize), func(t *testing.T) {
+				reader := &countingReaderAt{reader: bytes.NewReader(data)}
+				fileMetadata, err := GetFileMetadataFromTail(context.Background(), NewReader(reader, int64(len(data))), tailSize)
+				if err != nil {
+					t.Fatalf("unable to read footer: %v", err)
+				}
+				if !reflect.DeepEqual(fileMetadata, expected) {
+					t.Errorf("footer differs from GetFileMetadata")
+				}
+
+				expectedCalls := 1
+				if footerSize+2*wordLength > min(max(tailSize, 2*wordLength), int64(len(data))) {
+					expectedCalls = 2
+				}
+				if reader.calls != expectedCalls {
+					t.Errorf("expected %d reads, got %d", expectedCalls, reader.calls)
+				}
+			})
+		}
+	}
+}
+
+func TestGetFileMetadataFromTailErrors(t *testing.T) {
+	testcases := map[string]struct {
+		data     []byte
+		tailSize int64
+	}{
+		"tooSmall":          {data: []byte("PAR1PAR1"), tailSize: 64},
+		"footerMagic":       {data: []byte("PAR1\x01\x00\x00\x00\x00PAR2"), tailSize: 64},
+		"headerMagic":       {data: []byte("PAR2\x01\x00\x00\x00\x00PAR1"), tailSize: 64},
+		"emptyFooter":       {data: generateValidFakeParquet(16, "\x00\x00\x00\x00"), tailSize: 64},
+		"footerTooLarge":    {data: generateValidFakeParquet(16, "\x11\x00\x00\x00"), tailSize: 8},
+		"corruptFooter":     {data: generateValidFakeParquet(16, "\x10\x00\x00\x00"), tailSize: 64},
+		"corruptLongFooter": {data: generateValidFakeParquet(1024, "\x00\x04\x00\x00"), tailSize: 64},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			reader := NewReader(bytes.NewReader(test.data), int64(len(test.data)))
+			if _, err := GetFileMetadataFromTail(context.Background(), reader, test.tailSize); err == nil {
+				t.Errorf("expected error, got nil error")
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package file
+
+import (
+	"context"
+	"fmt"
+
+	format "github.com/RichardNooooh/parquet-go/internal/format/gen-go/parquet"
+	"github.com/RichardNooooh/parquet-go/internal/thriftio"
+)
+
+// Page is a page header together with its still compressed body.
+type Page struct {
+	Header *format.PageHeader
+	Data   []byte
+	Offset int64
+}
+
+// ColumnChunkRange returns the byte range of a column chunk, starting at its
+// dictionary page when it has one.
+func ColumnChunkRange(metadata *format.ColumnMetaData) (int64, int64) {
+	start := metadata.GetDataPageOffset()
+	// Some writers set dictionary_page_offset to 0 when there is no
+	// dictionary, so only trust it when it precedes the data pages.
+	if metadata.IsSetDictionaryPageOffset() {
+		if offset := metadata.GetDictionaryPageOffset(); offset > 0 && offset < start {
+			start = offset
+		}
+	}
+	return start, metadata.GetTotalCompressedSize()
+}
+
+// GetPages reads a whole column chunk and splits it into pages.
+func GetPages(ctx context.Context, reader RangeReader, metadata *format.ColumnMetaData) ([]*Page, error) {
+	offset, length := ColumnChunkRange(metadata)
+	return ReadPages(ctx, reader, offset, length)
+}
+
+// ReadPages reads the consecutive pages stored in the given byte range.
+func ReadPages(ctx context.Context, reader RangeReader, offset, length int64) ([]*Page, error) {
+	data, err := reader.ReadRange(ctx, offset, length)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read pages: %w", err)
+	}
+	return DecodePages(ctx, data, offset)
+}
+
+// DecodePages splits a buffer of consecutive pages that starts at offset in
+// the file.
+func DecodePages(ctx context.Context, data []byte, offset int64) ([]*Page, error) {
+	var pages []*Page
+	for position := 0; position < len(data); {
+		page, n, err := decodePage(ctx, data[position:], offset+int64(position))
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, page)
+		position += n
+	}
+	return pages, nil
+}
+
+func decodePage(ctx context.Context, data []byte, offset int64) (*Page, int, error) {
+	header := format.NewPageHeader()
+	n, err := thriftio.Decode(ctx, data, header)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to read page header at offset %d: %w", offset, err)
+	}
+	size := int(header.GetCompressedPageSize())
+	if size < 0 || size > len(data)-n {
+		return nil, 0, fmt.Errorf("page at offset %d overruns its column chunk (%d bytes)", offset, size)
+	}
+	return &Page{Header: header, Data: data[n : n+size], Offset: offset}, n + size, nil
+}
@@ -0,0 +1,73 @@
+package file
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/RichardNooooh/parquet-go/internal/encryption"
+	format "github.com/RichardNooooh/parquet-go/internal/format/gen-go/parquet"
+	"github.com/RichardNooooh/parquet-go/internal/thriftio"
+	thrift "github.com/apache/thrift/lib/go/thrift"
+)
+
+// GetColumnIndex reads the ColumnIndex of a column chunk, or returns nil when
+// the chunk has none. decryptor is nil unless the column is encrypted.
+func GetColumnIndex(ctx context.Context, file *FileReader, chunk *format.ColumnChunk, decryptor *encryption.Decryptor) (*format.ColumnIndex, error) {
+	if !chunk.IsSetColumnIndexOffset() || !chunk.IsSetColumnIndexLength() {
+		return nil, nil
+	}
+
+	columnIndex := format.NewColumnIndex()
+	err := readStruct(ctx, file, chunk.GetColumnIndexOffset(), int64(chunk.GetColumnIndexLength()), columnIndex, decryptor, encryption.ColumnIndex)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read column index: %w", err)
+	}
+	return columnIndex, nil
+}
+
+// GetOffsetIndex reads the OffsetIndex of a column chunk, or returns nil when
+// the chunk has none. decryptor is nil unless the column is encrypted.
+func GetOffsetIndex(ctx context.Context, file *FileReader, chunk *format.ColumnChunk, decryptor *encryption.Decryptor) (*format.OffsetIndex, error) {
+	if !chunk.IsSetOffsetIndexOffset() || !chunk.IsSetOffsetIndexLength() {
+		return nil, nil
+	}
+
+	offsetIndex := format.NewOffsetIndex()
+	err := readStruct(ctx, file, chunk.GetOffsetIndexOffset(), int64(chunk.GetOffsetIndexLength()), offsetIndex, decryptor, encryption.OffsetIndex)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read offset index: %w", err)
+	}
+	return offsetIndex, nil
+}
+
+func readStruct(ctx context.Context, file *FileReader, offset, length int64, value thrift.TStruct, decryptor *encryption.Decryptor, module encryption.ModuleType) error {
+	buffer, err := readRange(file, offset, length)
+	if err != nil {
+		return err
+	}
+	if decryptor != nil {
+		if buffer, _, err = decryptor.Decrypt(module, 0, buffer); err != nil {
+			return err
+		}
+	}
+	_, err = thriftio.Decode(ctx, buffer, value)
+	return err
+}
+
+// readRange reads length bytes at offset, checking the range against the
+// file size first.
+func readRange(file *FileReader, offset, length int64) ([]byte, error) {
+	if offset < 0 || length < 0 || offset+length > file.Size {
+		return nil, fmt.Errorf("range [%d, %d) is outside of the file (%d bytes)", offset, offset+length, file.Size)
+	}
+	if mapped, ok := file.Reader.(Mapped); ok && int64(len(mapped.Bytes())) >= file.Size {
+		return mapped.Bytes()[offset : offset+length : offset+length], nil
+	}
+
+	buffer := make([]byte, length)
+	count, err := file.Reader.ReadAt(buffer, offset)
+	if int64(count) < length {
+		return nil, fmt.Errorf("short read of %d bytes at offset %d: %w", length, offset, err)
+	}
+	return buffer, nil
+}
@@ -0,0 +1,87 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	format "github.com/RichardNooooh/parquet-go/internal/format/gen-go/parquet"
+)
+
+func TestPageIndexes(t *testing.T) {
+	testfilename := filepath.Join(getTestcaseDirectory(), "apache_examples", "alltypes_tiny_pages_plain.parquet")
+	f, err := os.Open(testfilename)
+	if err != nil {
+		t.Fatalf("%v: unable to open file %v", err, testfilename)
+	}
+	defer f.Close()
+	fileStat, err := f.Stat()
+	if err != nil {
+		t.Fatalf("%v: unable to get filestat of %v", err, testfilename)
+	}
+
+	ctx := context.Background()
+	reader := NewReader(f, fileStat.Size())
+	fileMetadata, err := GetFileMetadata(ctx, reader)
+	if err != nil {
+		t.Fatalf("%v: unable to read footer of %v", err, testfilename)
+	}
+
+	testcases := map[string]struct {
+		column         int
+		pages          int
+		hasColumnIndex bool
+	}{
+		"id":           {column: 0, pages: 325, hasColumnIndex: true},
+		"bool_col":     {column: 1, pages: 82, hasColumnIndex: true},
+		"string_col":   {column: 9, pages: 352, hasColumnIndex: true},
+		"timestampCol": {column: 10, pages: 974, hasColumnIndex: false},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			chunk := fileMetadata.RowGroups[0].Columns[test.column]
+			offsetIndex, err := GetOffsetIndex(ctx, reader, chunk, nil)
+			if err != nil {
+				t.Fatalf("unable to read offset index: %v", err)
+			}
+			locations := offsetIndex.GetPageLocations()
+			if len(locations) != test.pages {
+				t.Fatalf("expected %d pages, got %d", test.pages, len(locations))
+			}
+			if locations[0].Offset != chunk.MetaData.DataPageOffset || locations[0].FirstRowIndex != 0 {
+				t.Errorf("expected first page at %d for row 0, got %v", chunk.MetaData.DataPageOffset, locations[0])
+			}
+			for i := 1; i < len(locations); i++ {
+				previous := locations[i-1]
+				if locations[i].Offset != previous.Offset+int64(previous.CompressedPageSize) || locations[i].FirstRowIndex <= previous.FirstRowIndex {
+					t.Fatalf("page %d does not follow page %d: %v, %v", i, i-1, locations[i], previous)
+				}
+			}
+
+			columnIndex, err := GetColumnIndex(ctx, reader, chunk, nil)
+			if err != nil {
+				t.Fatalf("unable to read column index: %v", err)
+			}
+			if (columnIndex != nil) != test.hasColumnIndex {
+				t.Fatalf("expected column index %t, got %v", test.hasColumnIndex, columnIndex)
+			}
+			if columnIndex != nil && len(columnIndex.GetNullPages()) != test.pages {
+				t.Errorf("expected %d pages in column index, got %d", test.pages, len(columnIndex.GetNullPages()))
+			}
+		})
+	}
+}
+
+func TestPageIndexOutOfRange(t *testing.T) {
+	data := generateValidFakeParquet(64, "\x40\x00\x00\x00")
+	reader := NewReader(bytes.NewReader(data), int64(len(data)))
+	offset, length := int64(60), int32(100)
+	chunk := &format.ColumnChunk{OffsetIndexOffset: &offset, OffsetIndexLength: &length}
+
+	if _, err := GetOffsetIndex(context.Background(), reader, chunk, nil); err == nil {
+		t.Errorf("expected error, got nil error")
+	}
+}
@@ -0,0 +1,168 @@
+package file
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// Range is a byte range of a file.
+type Range struct {
+	Offset int64
+	Length int64
+}
+
+func (r Range) End() int64 { return r.Offset + r.Length }
+
+// RangeReader reads byte ranges of a file.
+type RangeReader interface {
+	ReadRange(ctx context.Context, offset, length int64) ([]byte, error)
+}
+
+// ReadRange reads length bytes at offset directly from the file.
+func (f *FileReader) ReadRange(ctx context.Context, offset, length int64) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return readRange(f, offset, length)
+}
+
+// CoalesceRanges sorts ranges and merges those separated by at most maxGap
+// bytes, as long as the merged range stays within maxSize bytes. Overlapping
+// ranges are always merged.
+func CoalesceRanges(ranges []Range, maxGap, maxSize int64) []Range {
+	sorted := slices.Clone(ranges)
+	slices.SortFunc(sorted, func(a, b Range) int { return cmp.Compare(a.Offset, b.Offset) })
+
+	var merged []Range
+	for _, r := range sorted {
+		if r.Length <= 0 {
+			continue
+		}
+		if n := len(merged); n > 0 {
+			last := &merged[n-1]
+			end := max(last.End(), r.End())
+			if r.Offset < last.End() || r.Offset-last.End() <= maxGap && end-last.Offset <= maxSize {
+				last.Length = end - last.Offset
+				continue
+			}
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// Prefetcher serves the reads of a plan from its coalesced ranges. It reads
+// the ranges in order ahead of their use, keeping at most window reads ahead
+// of the furthest range requested so far, and drops each range once all of
+// its planned reads are served. Other reads go to the file directly.
+type Prefetcher struct {
+	// ctx bounds the reads of the ranges, which are shared by all callers.
+	ctx    context.Context
+	file   *FileReader
+	ranges []Range
+	window int
+
+	mutex   sync.Mutex
+	fetches []*fetch
+}
+
+type fetch struct {
+	done chan struct{}
+	data []byte
+	err  error
+	// pending counts the planned reads within the range not served yet.
+	pending int
+}
+
+// NewPrefetcher coalesces the reads of plan with CoalesceRanges and starts
+// reading the first window ranges. The reads of the ranges stop when ctx is
+// done.
+func NewPrefetcher(ctx context.Context, file *FileReader, plan []Range, maxGap, maxSize int64, window int) *Prefetcher {
+	ranges := CoalesceRanges(plan, maxGap, maxSize)
+	p := &Prefetcher{
+		ctx:     ctx,
+		file:    file,
+		ranges:  ranges,
+		fetches: make([]*fetch, len(ranges)),
+		window:  max(window, 1),
+	}
+	for i := range p.fetches {
+		p.fetches[i] = &fetch{}
+	}
+	for _, r := range plan {
+		if i := p.lookup(r); i >= 0 && r.Length > 0 {
+			p.fetches[i].pending++
+		}
+	}
+	p.startUpTo(p.window)
+	return p
+}
+
+// lookup returns the index of the range that holds r, or -1.
+func (p *Prefetcher) lookup(r Range) int {
+	i := sort.Search(len(p.ranges), func(i int) bool { return p.ranges[i].End() > r.Offset })
+	if i == len(p.ranges) || p.ranges[i].Offset > r.Offset || p.ranges[i].End() < r.End() {
+		return -1
+	}
+	return i
+}
+
+// startUpTo starts the reads of all ranges before end that are not started.
+// Callers other than NewPrefetcher must hold the mutex.
+func (p *Prefetcher) startUpTo(end int) {
+	for i := range min(end, len(p.ranges)) {
+		f := p.fetches[i]
+		if f.done != nil {
+			continue
+		}
+		f.done = make(chan struct{})
+		r := p.ranges[i]
+		go func() {
+			defer close(f.done)
+			if f.err = p.ctx.Err(); f.err == nil {
+				f.data, f.err = readRange(p.file, r.Offset, r.Length)
+			}
+		}()
+	}
+}
+
+// ReadRange reads a range through the plan, waiting for the planned range
+// that holds it until ctx is done. Giving up does not stop the read of the
+// range for other callers. It is safe for concurrent use.
+func (p *Prefetcher) ReadRange(ctx context.Context, offset, length int64) ([]byte, error) {
+	i := p.lookup(Range{Offset: offset, Length: length})
+	if i < 0 {
+		return p.file.ReadRange(ctx, offset, length)
+	}
+
+	p.mutex.Lock()
+	p.startUpTo(i + p.window)
+	f := p.fetches[i]
+	p.mutex.Unlock()
+
+	select {
+	case <-f.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	p.mutex.Lock()
+	if f.pending <= 0 {
+		// A read beyond the plan, after the range was dropped.
+		p.mutex.Unlock()
+		return p.file.ReadRange(ctx, offset, length)
+	}
+	start := offset - p.ranges[i].Offset
+	data := f.data[start : start+length]
+	if f.pending--; f.pending == 0 {
+		f.data = nil
+	}
+	p.mutex.Unlock()
+	return data, nil
+}
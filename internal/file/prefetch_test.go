@@ -0,0 +1,146 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestCoalesceRanges(t *testing.T) {
+	ranges := []Range{{100, 10}, {0, 10}, {10, 5}, {30, 10}, {112, 100}, {50, 0}}
+
+	testcases := map[string]struct {
+		maxGap, maxSize int64
+		expected        []Range
+		ranges          []Range
+	}{
+		"touching only":  {maxGap: 0, maxSize: 1 << 20, expected: []Range{{0, 15}, {30, 10}, {100, 10}, {112, 100}}},
+		"small gaps":     {maxGap: 20, maxSize: 1 << 20, expected: []Range{{0, 40}, {100, 112}}},
+		"size limit":     {maxGap: 20, maxSize: 50, expected: []Range{{0, 40}, {100, 10}, {112, 100}}},
+		"everything":     {maxGap: 100, maxSize: 1 << 20, expected: []Range{{0, 212}}},
+		"limit too tiny": {maxGap: 100, maxSize: 1, expected: []Range{{0, 10}, {10, 5}, {30, 10}, {100, 10}, {112, 100}}},
+		"overlapping":    {maxGap: 0, maxSize: 1, expected: []Range{{0, 15}}, ranges: []Range{{0, 10}, {5, 10}}},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			input := ranges
+			if test.ranges != nil {
+				input = test.ranges
+			}
+			if got := CoalesceRanges(input, test.maxGap, test.maxSize); !slices.Equal(got, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
+
+type countingReaderAt struct {
+	reader io.ReaderAt
+	mutex  sync.Mutex
+	calls  int
+}
+
+func (r *countingReaderAt) ReadAt(p []byte, offset int64) (int, error) {
+	r.mutex.Lock()
+	r.calls++
+	r.mutex.Unlock()
+	return r.reader.ReadAt(p, offset)
+}
+
+func TestPrefetcher(t *testing.T) {
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	reader := &countingReaderAt{reader: bytes.NewReader(data)}
+	plan := []Range{{0, 5}, {10, 20}, {200, 100}, {500, 100}, {600, 50}, {700, 100}}
+	prefetcher := NewPrefetcher(context.Background(), NewReader(reader, int64(len(data))), plan, 10, 1<<20, 2)
+
+	testcases := []Range{{10, 20}, {200, 100}, {600, 50}, {0, 5}, {900, 10}, {500, 100}, {700, 100}}
+	for _, r := range testcases {
+		got, err := prefetcher.ReadRange(context.Background(), r.Offset, r.Length)
+		if err != nil {
+			t.Fatalf("unable to read %v: %v", r, err)
+		}
+		if !bytes.Equal(got, data[r.Offset:r.End()]) {
+			t.Errorf("wrong bytes for %v", r)
+		}
+	}
+	// One read per coalesced range, and one for the range outside of the
+	// plan.
+	if reader.calls != 5 {
+		t.Errorf("expected 5 reads, got %d", reader.calls)
+	}
+	for i, f := range prefetcher.fetches {
+		if f.data != nil {
+			t.Errorf("expected range %v to be dropped once read", prefetcher.ranges[i])
+		}
+	}
+
+	// Reading a planned range again goes to the file.
+	if got, err := prefetcher.ReadRange(context.Background(), 0, 5); err != nil || !bytes.Equal(got, data[:5]) {
+		t.Errorf("expected to read a dropped range again, got %v", err)
+	}
+	if _, err := prefetcher.ReadRange(context.Background(), 950, 100); err == nil {
+		t.Errorf("expected an error for a range past the end of the file")
+	}
+}
+
+// blockingReaderAt blocks reads until release is closed.
+type blockingReaderAt struct {
+	release chan struct{}
+}
+
+func (r *blockingReaderAt) ReadAt(p []byte, offset int64) (int, error) {
+	<-r.release
+	return len(p), nil
+}
+
+func TestPrefetcherContext(t *testing.T) {
+	reader := &blockingReaderAt{release: make(chan struct{})}
+	defer close(reader.release)
+	file := NewReader(reader, 1000)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	prefetcher := NewPrefetcher(ctx, file, []Range{{0, 100}}, 0, 1<<20, 1)
+	done := make(chan error)
+	go func() {
+		_, err := prefetcher.ReadRange(ctx, 0, 100)
+		done <- err
+	}()
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	prefetcher = NewPrefetcher(ctx, file, []Range{{0, 100}}, 0, 1<<20, 1)
+	if _, err := prefetcher.ReadRange(ctx, 0, 100); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a canceled prefetch to fail with context.Canceled, got %v", err)
+	}
+	if _, err := file.ReadRange(ctx, 0, 100); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a direct read to fail with context.Canceled, got %v", err)
+	}
+}
+
+func TestPrefetcherCanceledCaller(t *testing.T) {
+	reader := &blockingReaderAt{release: make(chan struct{})}
+	file := NewReader(reader, 1000)
+	prefetcher := NewPrefetcher(context.Background(), file, []Range{{0, 100}, {200, 100}, {200, 100}}, 0, 1<<20, 1)
+
+	// The first caller starts the read of the second range and gives up,
+	// which must not fail the read for the second caller.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := prefetcher.ReadRange(ctx, 200, 100); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	close(reader.release)
+	if _, err := prefetcher.ReadRange(context.Background(), 200, 100); err != nil {
+		t.Errorf("unable to read after another caller canceled: %v", err)
+	}
+}
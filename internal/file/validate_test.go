@@ -29,12 +29,15 @@ func TestMagicNumbers(t *testing.T) {
 		"invalidSmall1":     {data: []byte("PAR1\x00PAR1"), valid: false},
 		"invalidSmall2":     {data: []byte("PAR1\x00\x00PAR1"), valid: false},
 		"invalidSmall3":     {data: []byte("PAR1\x00\x00\x00PAR1"), valid: false},
+		"validEncrypted":    {data: []byte("PARE\x00\x00\x00\x00PARE"), valid: true},
+		"invalidMixed0":     {data: []byte("PARE\x00\x00\x00\x00PAR1"), valid: false},
+		"invalidMixed1":     {data: []byte("PAR1\x00\x00\x00\x00PARE"), valid: false},
 	}
 
 	for name, test := range testcases {
 		t.Run(name, func(t *testing.T) {
 			reader := NewReader(bytes.NewReader(test.data), int64(len(test.data)))
-			err := checkParquet(reader)
+			_, err := checkParquet(reader)
 
 			if test.valid && err != nil {
 				t.Errorf("expected valid result, got error: %v", err)
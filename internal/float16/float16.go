@@ -0,0 +1,95 @@
+// Package float16 converts IEEE 754 half precision floats, stored as the two
+// little-endian bytes of a FLOAT16 value.
+package float16
+
+import "math"
+
+// ToFloat32 converts the bits of a half precision float to a float32, which
+// represents every half precision value exactly.
+func ToFloat32(bits uint16) float32 {
+	sign := uint32(bits>>15) << 31
+	exponent := uint32(bits>>10) & 0x1f
+	mantissa := uint32(bits) & 0x3ff
+	switch exponent {
+	case 0:
+		if mantissa == 0 {
+			return math.Float32frombits(sign)
+		}
+		// Subnormal: mantissa × 2^-24.
+		value := float32(mantissa) / (1 << 24)
+		if sign != 0 {
+			value = -value
+		}
+		return value
+	case 0x1f:
+		return math.Float32frombits(sign | 0xff<<23 | mantissa<<13)
+	}
+	return math.Float32frombits(sign | (exponent+127-15)<<23 | mantissa<<13)
+}
+
+// FromFloat32 returns the bits of the half precision float nearest to f,
+// rounding ties to even. Values too large become infinities, and NaNs stay
+// NaNs.
+func FromFloat32(f float32) uint16 {
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exponent := int(bits>>23) & 0xff
+	mantissa := bits & 0x7fffff
+	if exponent == 0xff {
+		if mantissa != 0 {
+			return sign | 0x7e00 | uint16(mantissa>>13)
+		}
+		return sign | 0x7c00
+	}
+
+	exponent += 15 - 127
+	switch {
+	case exponent >= 0x1f:
+		return sign | 0x7c00
+	case exponent < -10:
+		return sign
+	case exponent <= 0:
+		// Subnormal: shift the mantissa, with its implicit bit, to a
+		// multiple of 2^-24.
+		return sign | uint16(roundShift(mantissa|0x800000, uint(14-exponent)))
+	}
+	// Rounding up may carry into the exponent, up to infinity.
+	return sign | uint16(roundShift(uint32(exponent)<<23|mantissa, 13))
+}
+
+// FromFloat64 is FromFloat32 for a float64, which is rounded once: rounding
+// to a float32 first may land on a tie that rounds the other way.
+func FromFloat64(f float64) uint16 {
+	bits := math.Float64bits(f)
+	sign := uint16(bits>>48) & 0x8000
+	exponent := int(bits>>52) & 0x7ff
+	mantissa := bits & (1<<52 - 1)
+	if exponent == 0x7ff {
+		if mantissa != 0 {
+			return sign | 0x7e00 | uint16(mantissa>>42)
+		}
+		return sign | 0x7c00
+	}
+
+	exponent += 15 - 1023
+	switch {
+	case exponent >= 0x1f:
+		return sign | 0x7c00
+	case exponent < -10:
+		return sign
+	case exponent <= 0:
+		return sign | uint16(roundShift(mantissa|1<<52, uint(43-exponent)))
+	}
+	return sign | uint16(roundShift(uint64(exponent)<<52|mantissa, 42))
+}
+
+// roundShift shifts v right, rounding to nearest with ties to even.
+func roundShift[T uint32 | uint64](v T, shift uint) T {
+	rounded := v >> shift
+	remainder := v & (1<<shift - 1)
+	half := T(1) << (shift - 1)
+	if remainder > half || remainder == half && rounded&1 == 1 {
+		rounded++
+	}
+	return rounded
+}
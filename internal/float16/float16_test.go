@@ -0,0 +1,94 @@
+package float16
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFromFloat32(t *testing.T) {
+	testcases := map[string]struct {
+		value float32
+		bits  uint16
+	}{
+		"one":                 {value: 1, bits: 0x3c00},
+		"negative":            {value: -2.5, bits: 0xc100},
+		"zero":                {value: 0, bits: 0x0000},
+		"negative zero":       {value: float32(math.Copysign(0, -1)), bits: 0x8000},
+		"max":                 {value: 65504, bits: 0x7bff},
+		"overflow":            {value: 65520, bits: 0x7c00},
+		"below overflow":      {value: 65519, bits: 0x7bff},
+		"infinity":            {value: float32(math.Inf(-1)), bits: 0xfc00},
+		"smallest normal":     {value: 6.1035156e-05, bits: 0x0400},
+		"smallest subnormal":  {value: 5.9604645e-08, bits: 0x0001},
+		"rounds to zero":      {value: 2.9802322e-08, bits: 0x0000},
+		"rounds to subnormal": {value: 2.9802326e-08, bits: 0x0001},
+		"tie to even":         {value: 1 + 1.0/2048, bits: 0x3c00},
+		"tie to odd up":       {value: 1 + 3.0/2048, bits: 0x3c02},
+		"above tie":           {value: 1 + 1.0/2048 + 1.0/65536, bits: 0x3c01},
+		"float32 subnormal":   {value: math.SmallestNonzeroFloat32, bits: 0x0000},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			if bits := FromFloat32(test.value); bits != test.bits {
+				t.Errorf("expected %#04x, got %#04x", test.bits, bits)
+			}
+		})
+	}
+}
+
+func TestFromFloat64(t *testing.T) {
+	testcases := map[string]struct {
+		value float64
+		bits  uint16
+	}{
+		"one":                {value: 1, bits: 0x3c00},
+		"negative zero":      {value: math.Copysign(0, -1), bits: 0x8000},
+		"max":                {value: 65504, bits: 0x7bff},
+		"overflow":           {value: 65520, bits: 0x7c00},
+		"infinity":           {value: math.Inf(1), bits: 0x7c00},
+		"smallest subnormal": {value: math.Ldexp(1, -24), bits: 0x0001},
+		"tie to zero":        {value: math.Ldexp(1, -25), bits: 0x0000},
+		"tie to even":        {value: 1 + 1.0/2048, bits: 0x3c00},
+		"float64 subnormal":  {value: math.SmallestNonzeroFloat64, bits: 0x0000},
+		// Rounded to a float32 first, the value becomes a tie and rounds down.
+		"double rounding":           {value: 1 + 1.0/2048 + math.Ldexp(1, -40), bits: 0x3c01},
+		"double rounding subnormal": {value: math.Ldexp(1, -25) + math.Ldexp(1, -60), bits: 0x0001},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			if bits := FromFloat64(test.value); bits != test.bits {
+				t.Errorf("expected %#04x, got %#04x", test.bits, bits)
+			}
+		})
+	}
+}
+
+func TestFromFloat32NaN(t *testing.T) {
+	bits := FromFloat32(float32(math.NaN()))
+	if bits&0x7c00 != 0x7c00 || bits&0x3ff == 0 {
+		t.Errorf("expected a NaN, got %#04x", bits)
+	}
+	if value := ToFloat32(bits); !math.IsNaN(float64(value)) {
+		t.Errorf("expected NaN, got %v", value)
+	}
+	if bits := FromFloat64(math.NaN()); bits&0x7c00 != 0x7c00 || bits&0x3ff == 0 {
+		t.Errorf("expected a NaN from a float64, got %#04x", bits)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for bits := range uint32(1 << 16) {
+		value := ToFloat32(uint16(bits))
+		if math.IsNaN(float64(value)) {
+			continue
+		}
+		if got := FromFloat32(value); got != uint16(bits) {
+			t.Fatalf("expected %#04x, got %#04x", bits, got)
+		}
+		if got := FromFloat64(float64(value)); got != uint16(bits) {
+			t.Fatalf("expected %#04x from a float64, got %#04x", bits, got)
+		}
+	}
+}
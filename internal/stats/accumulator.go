@@ -0,0 +1,229 @@
+package stats
+
+import (
+	"math"
+
+	"github.com/RichardNooooh/parquet-go/internal/encoder"
+	format "github.com/RichardNooooh/parquet-go/internal/format/gen-go/parquet"
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+type Options struct {
+	// DistinctCount enables the exact distinct_count statistic, which keeps
+	// every distinct value of the page or column chunk in memory.
+	DistinctCount bool
+	// TruncateLength caps the length of BYTE_ARRAY min and max values. Zero
+	// disables truncation.
+	TruncateLength int
+}
+
+// Accumulator computes the statistics of a page or column chunk from the
+// values written to it.
+type Accumulator struct {
+	leaf      *schema.SchemaElement
+	typ       schema.Type
+	float16   bool
+	order     SortOrder
+	compare   Comparator
+	options   Options
+	min       any
+	max       any
+	nullCount int64
+	distinct  map[any]struct{}
+}
+
+func NewAccumulator(leaf *schema.SchemaElement, options Options) *Accumulator {
+	order := SortOrderOf(leaf)
+	a := &Accumulator{
+		leaf:    leaf,
+		typ:     leaf.Type,
+		float16: IsFloat16(leaf),
+		order:   order,
+		compare: ComparatorOf(leaf),
+		options: options,
+	}
+	a.Reset()
+	return a
+}
+
+func (a *Accumulator) Reset() {
+	a.min, a.max = nil, nil
+	a.nullCount = 0
+	if a.options.DistinctCount {
+		a.distinct = make(map[any]struct{})
+	}
+}
+
+func (a *Accumulator) AddNulls(count int64) { a.nullCount += count }
+
+// Add records a non-null physical value.
+func (a *Accumulator) Add(value any) {
+	if a.distinct != nil {
+		key := value
+		if a.float16 {
+			key = float16Value(value)
+		}
+		a.distinct[distinctKey(key)] = struct{}{}
+	}
+	if a.compare == nil || IsNaN(a.leaf, value) {
+		return
+	}
+	if a.min == nil || a.compare(value, a.min) < 0 {
+		a.min = value
+	}
+	if a.max == nil || a.compare(value, a.max) > 0 {
+		a.max = value
+	}
+}
+
+// Merge folds the statistics of another accumulator of the same column into a.
+func (a *Accumulator) Merge(other *Accumulator) {
+	a.nullCount += other.nullCount
+	if a.distinct != nil {
+		for key := range other.distinct {
+			a.distinct[key] = struct{}{}
+		}
+	}
+	if other.min != nil && (a.min == nil || a.compare(other.min, a.min) < 0) {
+		a.min = other.min
+	}
+	if other.max != nil && (a.max == nil || a.compare(other.max, a.max) > 0) {
+		a.max = other.max
+	}
+}
+
+func (a *Accumulator) NullCount() int64 { return a.nullCount }
+
+// MinMax returns the current bounds, or ok=false when no orderable value has
+// been added.
+func (a *Accumulator) MinMax() (minValue, maxValue any, ok bool) {
+	return a.min, a.max, a.min != nil
+}
+
+func (a *Accumulator) Statistics() *format.Statistics {
+	statistics := format.NewStatistics()
+	nullCount := a.nullCount
+	statistics.NullCount = &nullCount
+	if a.distinct != nil {
+		distinctCount := int64(len(a.distinct))
+		statistics.DistinctCount = &distinctCount
+	}
+	if a.min == nil {
+		return statistics
+	}
+
+	minValue, minExact := a.encodeBound(a.min, false)
+	maxValue, maxExact := a.encodeBound(a.max, true)
+	statistics.MinValue = minValue
+	statistics.MaxValue = maxValue
+	statistics.IsMinValueExact = &minExact
+	statistics.IsMaxValueExact = &maxExact
+	if a.order == Signed && !a.float16 {
+		// Readers that predate min_value and max_value only understand the
+		// deprecated fields, which were always written with signed ordering.
+		// FLOAT16 came later, so they would compare its bytes.
+		statistics.Min = minValue
+		statistics.Max = maxValue
+	}
+	return statistics
+}
+
+func (a *Accumulator) encodeBound(value any, isMax bool) ([]byte, bool) {
+	// Zeros are written as -0.0 for min and +0.0 for max so that readers
+	// comparing against either sign of zero never prune incorrectly.
+	switch v := value.(type) {
+	case float32:
+		if v == 0 {
+			value = float32(math.Copysign(0, boundSign(isMax)))
+		}
+	case float64:
+		if v == 0 {
+			value = math.Copysign(0, boundSign(isMax))
+		}
+	case []byte:
+		if a.float16 && float16Value(v) == 0 {
+			value = []byte{0x00, 0x80}
+			if isMax {
+				value = []byte{0x00, 0x00}
+			}
+		}
+	}
+
+	encoded, err := encoder.PlainValue(a.typ, value)
+	if err != nil {
+		return nil, false
+	}
+	encoded = append([]byte(nil), encoded...)
+	if a.typ != schema.ByteArray || a.order != Unsigned || a.options.TruncateLength <= 0 {
+		return encoded, true
+	}
+	if isMax {
+		return truncateMax(encoded, a.options.TruncateLength)
+	}
+	return truncateMin(encoded, a.options.TruncateLength)
+}
+
+func boundSign(isMax bool) float64 {
+	if isMax {
+		return 1
+	}
+	return -1
+}
+
+// truncateMin shortens a lower bound to a prefix, which still sorts before
+// the original value.
+func truncateMin(value []byte, length int) ([]byte, bool) {
+	if len(value) <= length {
+		return value, true
+	}
+	return value[:length], false
+}
+
+// truncateMax shortens an upper bound by incrementing the last byte of the
+// prefix that can be incremented, so the result still sorts after the
+// original value. Values made of 0xFF bytes cannot be shortened.
+func truncateMax(value []byte, length int) ([]byte, bool) {
+	if len(value) <= length {
+		return value, true
+	}
+	prefix := append([]byte(nil), value[:length]...)
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] != 0xFF {
+			prefix[i]++
+			return prefix[:i+1], false
+		}
+	}
+	return value, true
+}
+
+func isNaN(value any) bool {
+	switch v := value.(type) {
+	case float32:
+		return v != v
+	case float64:
+		return v != v
+	}
+	return false
+}
+
+func distinctKey(value any) any {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case float32:
+		if v == 0 {
+			v = 0
+		} else if v != v {
+			return math.Float32bits(float32(math.NaN()))
+		}
+		return math.Float32bits(v)
+	case float64:
+		if v == 0 {
+			v = 0
+		} else if v != v {
+			return math.Float64bits(math.NaN())
+		}
+		return math.Float64bits(v)
+	}
+	return value
+}
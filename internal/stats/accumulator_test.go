@@ -0,0 +1,175 @@
+package stats
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+func TestAccumulatorMinMax(t *testing.T) {
+	uint32Leaf := schema.NewLeaf("u", schema.Int32, schema.Optional)
+	uint32Leaf.LogicalType = &schema.LogicalType{Kind: schema.LogicalInteger, BitWidth: 32, IsSigned: false}
+	decimalLeaf := schema.NewLeaf("d", schema.FixedLenByteArray, schema.Optional)
+	decimalLeaf.TypeLength = 2
+	decimalLeaf.ConvertedType = schema.Decimal
+	float16Leaf := schema.NewLeaf("h", schema.FixedLenByteArray, schema.Optional)
+	float16Leaf.TypeLength = 2
+	float16Leaf.LogicalType = &schema.LogicalType{Kind: schema.LogicalFloat16}
+
+	testcases := map[string]struct {
+		leaf     *schema.SchemaElement
+		values   []any
+		min, max []byte
+	}{
+		"signedInt32": {
+			leaf:   schema.NewLeaf("i", schema.Int32, schema.Optional),
+			values: []any{int32(3), int32(-7), int32(12)},
+			min:    le32(uint32(0xFFFFFFF9)), max: le32(12),
+		},
+		"unsignedInt32": {
+			leaf:   uint32Leaf,
+			values: []any{int32(3), int32(-7), int32(12)},
+			min:    le32(3), max: le32(uint32(0xFFFFFFF9)),
+		},
+		"byteArray": {
+			leaf:   schema.NewLeaf("s", schema.ByteArray, schema.Optional),
+			values: []any{[]byte("b"), []byte("\xffa"), []byte("ab")},
+			min:    []byte("ab"), max: []byte("\xffa"),
+		},
+		"signedDecimal": {
+			leaf:   decimalLeaf,
+			values: []any{[]byte{0x00, 0x05}, []byte{0xFF, 0x00}, []byte{0x7F, 0x00}},
+			min:    []byte{0xFF, 0x00}, max: []byte{0x7F, 0x00},
+		},
+		"boolean": {
+			leaf:   schema.NewLeaf("b", schema.Boolean, schema.Optional),
+			values: []any{true, false, true},
+			min:    []byte{0}, max: []byte{1},
+		},
+		"doubleIgnoresNaN": {
+			leaf:   schema.NewLeaf("f", schema.Double, schema.Optional),
+			values: []any{math.NaN(), 2.5, -1.0, math.NaN()},
+			min:    le64(math.Float64bits(-1)), max: le64(math.Float64bits(2.5)),
+		},
+		"doubleZeroSigns": {
+			leaf:   schema.NewLeaf("f", schema.Double, schema.Optional),
+			values: []any{0.0, math.Copysign(0, -1)},
+			min:    le64(math.Float64bits(math.Copysign(0, -1))), max: le64(math.Float64bits(0)),
+		},
+		"floatZeroSigns": {
+			leaf:   schema.NewLeaf("f", schema.Float, schema.Optional),
+			values: []any{float32(math.Copysign(0, -1)), float32(-3)},
+			min:    le32(math.Float32bits(-3)), max: le32(math.Float32bits(0)),
+		},
+		"float16IgnoresNaN": {
+			leaf:   float16Leaf,
+			values: []any{[]byte{0x00, 0x7e}, []byte{0x00, 0x3c}, []byte{0x00, 0xc0}, []byte{0x00, 0x3e}},
+			min:    []byte{0x00, 0xc0}, max: []byte{0x00, 0x3e},
+		},
+		"float16ZeroSigns": {
+			leaf:   float16Leaf,
+			values: []any{[]byte{0x00, 0x00}, []byte{0x00, 0x80}},
+			min:    []byte{0x00, 0x80}, max: []byte{0x00, 0x00},
+		},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			accumulator := NewAccumulator(test.leaf, Options{})
+			for _, value := range test.values {
+				accumulator.Add(value)
+			}
+			statistics := accumulator.Statistics()
+			if !bytes.Equal(statistics.MinValue, test.min) {
+				t.Errorf("expected min %x, got %x", test.min, statistics.MinValue)
+			}
+			if !bytes.Equal(statistics.MaxValue, test.max) {
+				t.Errorf("expected max %x, got %x", test.max, statistics.MaxValue)
+			}
+		})
+	}
+}
+
+func TestAccumulatorWithoutOrder(t *testing.T) {
+	testcases := map[string]struct {
+		leaf   *schema.SchemaElement
+		values []any
+	}{
+		"int96":  {leaf: schema.NewLeaf("t", schema.Int96, schema.Optional), values: []any{[12]byte{1}}},
+		"allNaN": {leaf: schema.NewLeaf("f", schema.Float, schema.Optional), values: []any{float32(math.NaN())}},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			accumulator := NewAccumulator(test.leaf, Options{})
+			for _, value := range test.values {
+				accumulator.Add(value)
+			}
+			accumulator.AddNulls(2)
+			statistics := accumulator.Statistics()
+			if statistics.IsSetMinValue() || statistics.IsSetMaxValue() {
+				t.Errorf("expected no min/max, got %x/%x", statistics.MinValue, statistics.MaxValue)
+			}
+			if statistics.GetNullCount() != 2 {
+				t.Errorf("expected null count 2, got %d", statistics.GetNullCount())
+			}
+		})
+	}
+}
+
+func TestAccumulatorTruncation(t *testing.T) {
+	testcases := map[string]struct {
+		values             []any
+		min, max           []byte
+		minExact, maxExact bool
+	}{
+		"short":      {values: []any{[]byte("abc")}, min: []byte("abc"), max: []byte("abc"), minExact: true, maxExact: true},
+		"long":       {values: []any{[]byte("abcdef"), []byte("abzzzz")}, min: []byte("abcd"), max: []byte("abz{"), minExact: false, maxExact: false},
+		"carry":      {values: []any{[]byte("ab\xff\xffzz")}, min: []byte("ab\xff\xff"), max: []byte("ac"), minExact: false, maxExact: false},
+		"allMaxByte": {values: []any{[]byte("\xff\xff\xff\xff\xff")}, min: []byte("\xff\xff\xff\xff"), max: []byte("\xff\xff\xff\xff\xff"), minExact: false, maxExact: true},
+	}
+
+	leaf := schema.NewLeaf("s", schema.ByteArray, schema.Required)
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			accumulator := NewAccumulator(leaf, Options{TruncateLength: 4})
+			for _, value := range test.values {
+				accumulator.Add(value)
+			}
+			statistics := accumulator.Statistics()
+			if !bytes.Equal(statistics.MinValue, test.min) || statistics.GetIsMinValueExact() != test.minExact {
+				t.Errorf("expected min %q (exact %t), got %q (exact %t)", test.min, test.minExact, statistics.MinValue, statistics.GetIsMinValueExact())
+			}
+			if !bytes.Equal(statistics.MaxValue, test.max) || statistics.GetIsMaxValueExact() != test.maxExact {
+				t.Errorf("expected max %q (exact %t), got %q (exact %t)", test.max, test.maxExact, statistics.MaxValue, statistics.GetIsMaxValueExact())
+			}
+			if statistics.IsSetMin() || statistics.IsSetMax() {
+				t.Errorf("deprecated min/max must not be written for unsigned byte arrays")
+			}
+		})
+	}
+}
+
+func TestAccumulatorDistinctCount(t *testing.T) {
+	leaf := schema.NewLeaf("f", schema.Double, schema.Optional)
+	accumulator := NewAccumulator(leaf, Options{DistinctCount: true})
+	for _, value := range []any{1.0, 1.0, 0.0, math.Copysign(0, -1), math.NaN(), math.NaN(), 2.0} {
+		accumulator.Add(value)
+	}
+
+	other := NewAccumulator(leaf, Options{DistinctCount: true})
+	other.Add(3.0)
+	other.Add(1.0)
+	accumulator.Merge(other)
+
+	if count := accumulator.Statistics().GetDistinctCount(); count != 5 {
+		t.Errorf("expected 5 distinct values, got %d", count)
+	}
+}
+
+func le32(v uint32) []byte { return binary.LittleEndian.AppendUint32(nil, v) }
+
+func le64(v uint64) []byte { return binary.LittleEndian.AppendUint64(nil, v) }
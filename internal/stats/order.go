@@ -0,0 +1,184 @@
+package stats
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
+
+	"github.com/RichardNooooh/parquet-go/internal/float16"
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+// SortOrder is the ordering that min and max statistics of a column follow,
+// as defined by the TypeDefinedOrder column order.
+type SortOrder int
+
+const (
+	Signed SortOrder = iota
+	Unsigned
+	Unknown
+)
+
+func (o SortOrder) String() string {
+	switch o {
+	case Signed:
+		return "SIGNED"
+	case Unsigned:
+		return "UNSIGNED"
+	}
+	return "UNKNOWN"
+}
+
+// SortOrderOf returns the type-defined sort order of a leaf column based on
+// its physical and logical types.
+func SortOrderOf(leaf *schema.SchemaElement) SortOrder {
+	switch leaf.Type {
+	case schema.Boolean, schema.Float, schema.Double:
+		return Signed
+	case schema.Int32, schema.Int64:
+		if isUnsigned(leaf) {
+			return Unsigned
+		}
+		return Signed
+	case schema.ByteArray, schema.FixedLenByteArray:
+		if isDecimal(leaf) || IsFloat16(leaf) {
+			return Signed
+		}
+		if leaf.ConvertedType == schema.Interval || isGeospatial(leaf) {
+			return Unknown
+		}
+		return Unsigned
+	}
+	return Unknown
+}
+
+func isUnsigned(leaf *schema.SchemaElement) bool {
+	if leaf.LogicalType != nil && leaf.LogicalType.Kind == schema.LogicalInteger {
+		return !leaf.LogicalType.IsSigned
+	}
+	switch leaf.ConvertedType {
+	case schema.Uint8, schema.Uint16, schema.Uint32, schema.Uint64:
+		return true
+	}
+	return false
+}
+
+// isGeospatial reports whether a leaf holds GEOMETRY or GEOGRAPHY values,
+// whose order is undefined.
+func isGeospatial(leaf *schema.SchemaElement) bool {
+	return leaf.LogicalType != nil && (leaf.LogicalType.Kind == schema.LogicalGeometry || leaf.LogicalType.Kind == schema.LogicalGeography)
+}
+
+// IsFloat16 reports whether a leaf holds FLOAT16 values, which are ordered
+// as the numbers they encode.
+func IsFloat16(leaf *schema.SchemaElement) bool {
+	return leaf.Type == schema.FixedLenByteArray && leaf.TypeLength == 2 &&
+		leaf.LogicalType != nil && leaf.LogicalType.Kind == schema.LogicalFloat16
+}
+
+func isDecimal(leaf *schema.SchemaElement) bool {
+	if leaf.LogicalType != nil {
+		return leaf.LogicalType.Kind == schema.LogicalDecimal
+	}
+	return leaf.ConvertedType == schema.Decimal
+}
+
+// Comparator orders two non-null physical values of the same column.
+type Comparator func(a, b any) int
+
+// ComparatorOf returns the comparator for values of a leaf under its
+// type-defined sort order, or nil if the order is unknown.
+func ComparatorOf(leaf *schema.SchemaElement) Comparator {
+	if IsFloat16(leaf) {
+		return func(a, b any) int { return cmp.Compare(float16Value(a), float16Value(b)) }
+	}
+	return ComparatorFor(leaf.Type, SortOrderOf(leaf))
+}
+
+// IsNaN reports whether a physical value of a leaf is a NaN, which has no
+// place in the order of its column.
+func IsNaN(leaf *schema.SchemaElement, value any) bool {
+	if IsFloat16(leaf) {
+		f := float16Value(value)
+		return f != f
+	}
+	return isNaN(value)
+}
+
+func float16Value(value any) float32 {
+	return float16.ToFloat32(binary.LittleEndian.Uint16(value.([]byte)))
+}
+
+// ComparatorFor returns the comparator for values of the given physical type
+// under the given sort order, or nil if the order is unknown.
+func ComparatorFor(typ schema.Type, order SortOrder) Comparator {
+	if order == Unknown {
+		return nil
+	}
+
+	switch typ {
+	case schema.Boolean:
+		return func(a, b any) int {
+			x, y := a.(bool), b.(bool)
+			switch {
+			case x == y:
+				return 0
+			case !x:
+				return -1
+			}
+			return 1
+		}
+	case schema.Int32:
+		if order == Unsigned {
+			return func(a, b any) int { return cmp.Compare(uint32(a.(int32)), uint32(b.(int32))) }
+		}
+		return func(a, b any) int { return cmp.Compare(a.(int32), b.(int32)) }
+	case schema.Int64:
+		if order == Unsigned {
+			return func(a, b any) int { return cmp.Compare(uint64(a.(int64)), uint64(b.(int64))) }
+		}
+		return func(a, b any) int { return cmp.Compare(a.(int64), b.(int64)) }
+	case schema.Float:
+		return func(a, b any) int { return cmp.Compare(a.(float32), b.(float32)) }
+	case schema.Double:
+		return func(a, b any) int { return cmp.Compare(a.(float64), b.(float64)) }
+	case schema.ByteArray, schema.FixedLenByteArray:
+		if order == Signed {
+			return func(a, b any) int { return compareSignedBytes(a.([]byte), b.([]byte)) }
+		}
+		return func(a, b any) int { return bytes.Compare(a.([]byte), b.([]byte)) }
+	}
+	return nil
+}
+
+// compareSignedBytes compares big-endian two's complement integers of
+// possibly different lengths, as used by DECIMAL byte arrays.
+func compareSignedBytes(a, b []byte) int {
+	aNegative := len(a) > 0 && a[0]&0x80 != 0
+	bNegative := len(b) > 0 && b[0]&0x80 != 0
+	if aNegative != bNegative {
+		if aNegative {
+			return -1
+		}
+		return 1
+	}
+
+	var pad byte
+	if aNegative {
+		pad = 0xFF
+	}
+	n := max(len(a), len(b))
+	for i := range n {
+		x, y := pad, pad
+		if j := i - (n - len(a)); j >= 0 {
+			x = a[j]
+		}
+		if j := i - (n - len(b)); j >= 0 {
+			y = b[j]
+		}
+		if x != y {
+			return cmp.Compare(x, y)
+		}
+	}
+	return 0
+}
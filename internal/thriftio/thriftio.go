@@ -24,3 +24,32 @@ func DecodeFileMetadata(ctx context.Context, buffer []byte, size int64) (*format
 
 	return fileMetadata, nil
 }
+
+// Decode reads a single compact-protocol struct from the start of buffer and
+// returns the number of bytes it occupied.
+func Decode(ctx context.Context, buffer []byte, value thrift.TStruct) (int, error) {
+	thriftBuffer := thrift.NewTMemoryBufferLen(len(buffer))
+	if _, err := thriftBuffer.Write(buffer); err != nil {
+		return 0, fmt.Errorf("failed to transfer buffer to thrift buffer: %w", err)
+	}
+
+	protocol := thrift.NewTCompactProtocolConf(thriftBuffer, &thrift.TConfiguration{})
+	if err := value.Read(ctx, protocol); err != nil {
+		return 0, fmt.Errorf("failed to decode thrift struct: %w", err)
+	}
+
+	return len(buffer) - thriftBuffer.Len(), nil
+}
+
+func Encode(ctx context.Context, value thrift.TStruct) ([]byte, error) {
+	thriftBuffer := thrift.NewTMemoryBuffer()
+	protocol := thrift.NewTCompactProtocolConf(thriftBuffer, &thrift.TConfiguration{})
+	if err := value.Write(ctx, protocol); err != nil {
+		return nil, fmt.Errorf("failed to encode thrift struct: %w", err)
+	}
+	if err := protocol.Flush(ctx); err != nil {
+		return nil, fmt.Errorf("failed to flush thrift struct: %w", err)
+	}
+
+	return thriftBuffer.Bytes(), nil
+}
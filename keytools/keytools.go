@@ -0,0 +1,309 @@
+// Package keytools manages the keys of encrypted Parquet files with a key
+// management service. Every file gets random data keys, which are wrapped
+// with master keys held by the KMS and stored as key metadata in the file or
+// in a KeyMaterialStore next to it.
+package keytools
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/RichardNooooh/parquet-go/parquet"
+)
+
+type Option func(*config)
+
+type config struct {
+	doubleWrapping bool
+	dataKeyLength  int
+	kmsInstanceID  string
+	kmsInstanceURL string
+}
+
+const (
+	defaultDataKeyLength = 16
+	defaultKmsInstance   = "DEFAULT"
+	kekLength            = 16
+	kekIDLength          = 16
+)
+
+// WithDoubleWrapping wraps data keys locally with key encryption keys, which
+// are wrapped by the KMS once per master key instead of once per data key.
+// It is enabled by default.
+func WithDoubleWrapping(enabled bool) Option {
+	return func(c *config) { c.doubleWrapping = enabled }
+}
+
+// WithDataKeyLength sets the size of the data keys, 16, 24 or 32 bytes.
+func WithDataKeyLength(bytes int) Option {
+	return func(c *config) { c.dataKeyLength = bytes }
+}
+
+// WithKmsInstance records the KMS instance in the key material of footer
+// keys.
+func WithKmsInstance(id, url string) Option {
+	return func(c *config) { c.kmsInstanceID, c.kmsInstanceURL = id, url }
+}
+
+// KeyTools creates and recovers the data keys of encrypted files. It is safe
+// for concurrent use.
+type KeyTools struct {
+	client KmsClient
+	config *config
+
+	mutex sync.Mutex
+	// keks holds the key encryption key used to wrap new data keys under
+	// each master key, and unwrappedKEKs the key encryption keys unwrapped
+	// for reading by their base64 identifier.
+	keks          map[string]*kek
+	unwrappedKEKs map[string][]byte
+}
+
+type kek struct {
+	id, key []byte
+	wrapped string
+}
+
+func New(client KmsClient, opts ...Option) *KeyTools {
+	config := &config{
+		doubleWrapping: true,
+		dataKeyLength:  defaultDataKeyLength,
+		kmsInstanceID:  defaultKmsInstance,
+		kmsInstanceURL: defaultKmsInstance,
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+	return &KeyTools{
+		client:        client,
+		config:        config,
+		keks:          make(map[string]*kek),
+		unwrappedKEKs: make(map[string][]byte),
+	}
+}
+
+// WriterOptions draws random data keys for the footer and for each column of
+// columnMasterKeys, a map from column path to master key identifier, and
+// returns the writer options that encrypt a file with them. Every column is
+// encrypted with the footer key when columnMasterKeys is empty. The key
+// material is stored in the file when store is nil.
+func (k *KeyTools) WriterOptions(footerMasterKey string, columnMasterKeys map[string]string, store KeyMaterialStore) ([]parquet.ParquetWriterOption, error) {
+	footerKey, footerMetadata, err := k.newDataKey(footerMasterKey, true, "footerKey", store)
+	if err != nil {
+		return nil, fmt.Errorf("footer key: %w", err)
+	}
+	opts := []parquet.ParquetWriterOption{parquet.WithEncryption(footerKey, footerMetadata)}
+
+	columns := make([]string, 0, len(columnMasterKeys))
+	for column := range columnMasterKeys {
+		columns = append(columns, column)
+	}
+	slices.Sort(columns)
+	for i, column := range columns {
+		key, metadata, err := k.newDataKey(columnMasterKeys[column], false, "columnKey"+strconv.Itoa(i), store)
+		if err != nil {
+			return nil, fmt.Errorf("key of column %q: %w", column, err)
+		}
+		opts = append(opts, parquet.WithColumnEncryption(column, key, metadata))
+	}
+	return opts, nil
+}
+
+// KeyRetriever returns a retriever that recovers the data keys of a file
+// from its key metadata. store holds the key material of files written with
+// one, and may be nil otherwise.
+func (k *KeyTools) KeyRetriever(store KeyMaterialStore) parquet.KeyRetriever {
+	return func(metadata []byte) ([]byte, error) {
+		material, err := readKeyMaterial(metadata, store)
+		if err != nil {
+			return nil, err
+		}
+		return k.unwrap(material)
+	}
+}
+
+// RotateMasterKeys re-wraps the data keys of a file whose key material is in
+// store with the current versions of their master keys, leaving the file
+// unchanged. Keys are rewritten one by one, so the KMS must keep the old
+// versions until rotation succeeds.
+func (k *KeyTools) RotateMasterKeys(store KeyMaterialStore) error {
+	k.mutex.Lock()
+	k.keks = make(map[string]*kek)
+	k.mutex.Unlock()
+
+	keyIDs, err := store.Keys()
+	if err != nil {
+		return err
+	}
+	for _, keyID := range keyIDs {
+		data, err := store.Get(keyID)
+		if err != nil {
+			return err
+		}
+		material, err := parseKeyMaterial([]byte(data))
+		if err != nil {
+			return fmt.Errorf("key %q: %w", keyID, err)
+		}
+		key, err := k.unwrap(material)
+		if err != nil {
+			return fmt.Errorf("key %q: %w", keyID, err)
+		}
+		rotated, err := k.wrap(key, material.MasterKeyID, material.IsFooterKey)
+		if err != nil {
+			return fmt.Errorf("key %q: %w", keyID, err)
+		}
+		rotated.KmsInstanceID, rotated.KmsInstanceURL = material.KmsInstanceID, material.KmsInstanceURL
+		data, err = marshalString(rotated)
+		if err != nil {
+			return err
+		}
+		if err := store.Put(keyID, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newDataKey draws a data key and returns it with its key metadata, storing
+// its material under keyID when store is not nil.
+func (k *KeyTools) newDataKey(masterKeyID string, footer bool, keyID string, store KeyMaterialStore) ([]byte, []byte, error) {
+	key := make([]byte, k.config.dataKeyLength)
+	if _, err := rand.Read(key); err != nil {
+		return nil, nil, err
+	}
+	material, err := k.wrap(key, masterKeyID, footer)
+	if err != nil {
+		return nil, nil, err
+	}
+	if store == nil {
+		material.InternalStorage = true
+		metadata, err := json.Marshal(material)
+		return key, metadata, err
+	}
+
+	data, err := marshalString(material)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := store.Put(keyID, data); err != nil {
+		return nil, nil, err
+	}
+	metadata, err := json.Marshal(keyMetadata{Type: keyMaterialType, KeyReference: keyID})
+	return key, metadata, err
+}
+
+// wrap wraps a data key with a master key, directly or through the key
+// encryption key of the master key.
+func (k *KeyTools) wrap(key []byte, masterKeyID string, footer bool) (*keyMaterial, error) {
+	material := &keyMaterial{
+		Type:           keyMaterialType,
+		IsFooterKey:    footer,
+		MasterKeyID:    masterKeyID,
+		DoubleWrapping: k.config.doubleWrapping,
+	}
+	if footer {
+		material.KmsInstanceID, material.KmsInstanceURL = k.config.kmsInstanceID, k.config.kmsInstanceURL
+	}
+	if !k.config.doubleWrapping {
+		wrapped, err := k.client.WrapKey(key, masterKeyID)
+		if err != nil {
+			return nil, err
+		}
+		material.WrappedDEK = wrapped
+		return material, nil
+	}
+
+	kek, err := k.kek(masterKeyID)
+	if err != nil {
+		return nil, err
+	}
+	if material.WrappedDEK, err = encryptKey(key, kek.key, kek.id); err != nil {
+		return nil, err
+	}
+	material.KEKID = base64.StdEncoding.EncodeToString(kek.id)
+	material.WrappedKEK = kek.wrapped
+	return material, nil
+}
+
+// kek returns the key encryption key of a master key, creating and wrapping
+// it on first use.
+func (k *KeyTools) kek(masterKeyID string) (*kek, error) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	if cached, ok := k.keks[masterKeyID]; ok {
+		return cached, nil
+	}
+	created := &kek{id: make([]byte, kekIDLength), key: make([]byte, kekLength)}
+	if _, err := rand.Read(created.id); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(created.key); err != nil {
+		return nil, err
+	}
+	wrapped, err := k.client.WrapKey(created.key, masterKeyID)
+	if err != nil {
+		return nil, err
+	}
+	created.wrapped = wrapped
+	k.keks[masterKeyID] = created
+	k.unwrappedKEKs[base64.StdEncoding.EncodeToString(created.id)] = created.key
+	return created, nil
+}
+
+// unwrap recovers the data key of its key material.
+func (k *KeyTools) unwrap(material *keyMaterial) ([]byte, error) {
+	if !material.DoubleWrapping {
+		return k.client.UnwrapKey(material.WrappedDEK, material.MasterKeyID)
+	}
+	kekID, err := base64.StdEncoding.DecodeString(material.KEKID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key encryption key identifier: %w", err)
+	}
+
+	k.mutex.Lock()
+	key, ok := k.unwrappedKEKs[material.KEKID]
+	k.mutex.Unlock()
+	if !ok {
+		if key, err = k.client.UnwrapKey(material.WrappedKEK, material.MasterKeyID); err != nil {
+			return nil, err
+		}
+		k.mutex.Lock()
+		k.unwrappedKEKs[material.KEKID] = key
+		k.mutex.Unlock()
+	}
+	return decryptKey(material.WrappedDEK, key, kekID)
+}
+
+// readKeyMaterial reads the key material in key metadata, or the material
+// it references in store.
+func readKeyMaterial(metadata []byte, store KeyMaterialStore) (*keyMaterial, error) {
+	var header keyMetadata
+	if err := json.Unmarshal(metadata, &header); err != nil {
+		return nil, fmt.Errorf("invalid key metadata: %w", err)
+	}
+	if header.Type != keyMaterialType {
+		return nil, fmt.Errorf("unsupported key material type %q", header.Type)
+	}
+	if header.InternalStorage {
+		return parseKeyMaterial(metadata)
+	}
+	if store == nil {
+		return nil, errors.New("key material is stored outside of the file and needs a KeyMaterialStore")
+	}
+	data, err := store.Get(header.KeyReference)
+	if err != nil {
+		return nil, err
+	}
+	return parseKeyMaterial([]byte(data))
+}
+
+func marshalString(value any) (string, error) {
+	data, err := json.Marshal(value)
+	return string(data), err
+}
@@ -0,0 +1,254 @@
+package keytools
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/RichardNooooh/parquet-go/parquet"
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+var testMasterKeys = map[string][]byte{
+	"footer-master": []byte("0123456789012345"),
+	"pii-master":    []byte("1234567890123450"),
+}
+
+// countingKms counts the calls made to a KMS.
+type countingKms struct {
+	KmsClient
+	mutex          sync.Mutex
+	wraps, unwraps int
+}
+
+func (k *countingKms) WrapKey(key []byte, masterKeyID string) (string, error) {
+	k.mutex.Lock()
+	k.wraps++
+	k.mutex.Unlock()
+	return k.KmsClient.WrapKey(key, masterKeyID)
+}
+
+func (k *countingKms) UnwrapKey(wrappedKey string, masterKeyID string) ([]byte, error) {
+	k.mutex.Lock()
+	k.unwraps++
+	k.mutex.Unlock()
+	return k.KmsClient.UnwrapKey(wrappedKey, masterKeyID)
+}
+
+func testSchema() *schema.SchemaElement {
+	return schema.NewSchema(
+		schema.NewLeaf("id", schema.Int64, schema.Required),
+		schema.NewLeaf("email", schema.ByteArray, schema.Required),
+		schema.NewLeaf("phone", schema.ByteArray, schema.Optional),
+	)
+}
+
+var testRows = []parquet.Row{
+	{"id": int64(1), "email": []byte("a@example.com"), "phone": []byte("555-0100")},
+	{"id": int64(2), "email": []byte("b@example.com"), "phone": nil},
+}
+
+func writeFile(t *testing.T, opts []parquet.ParquetWriterOption) []byte {
+	t.Helper()
+	var buffer bytes.Buffer
+	writer, err := parquet.NewWriter(&buffer, testSchema(), opts...)
+	if err != nil {
+		t.Fatalf("unable to create writer: %v", err)
+	}
+	for _, row := range testRows {
+		if err := writer.Write(row); err != nil {
+			t.Fatalf("unable to write row: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("unable to close writer: %v", err)
+	}
+	return buffer.Bytes()
+}
+
+func readFile(data []byte, retriever parquet.KeyRetriever) ([]parquet.Row, error) {
+	reader, err := parquet.Open(bytes.NewReader(data), int64(len(data)), parquet.WithKeyRetriever(retriever))
+	if err != nil {
+		return nil, err
+	}
+	var rows []parquet.Row
+	for i := range reader.RowGroups() {
+		rowGroup, err := reader.ReadRowGroup(i)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, rowGroup...)
+	}
+	return rows, nil
+}
+
+func TestKeyToolsRoundTrip(t *testing.T) {
+	columnMasterKeys := map[string]string{"email": "pii-master", "phone": "pii-master"}
+	testcases := map[string]struct {
+		opts     []Option
+		external bool
+	}{
+		"double wrapping":   {},
+		"single wrapping":   {opts: []Option{WithDoubleWrapping(false)}},
+		"external material": {external: true},
+		"256 bit keys":      {opts: []Option{WithDataKeyLength(32), WithKmsInstance("kms-1", "https://kms.example.com")}},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			kms := NewInMemoryKms(testMasterKeys)
+			var store KeyMaterialStore
+			if test.external {
+				store = NewMemoryKeyMaterialStore()
+			}
+			opts, err := New(kms, test.opts...).WriterOptions("footer-master", columnMasterKeys, store)
+			if err != nil {
+				t.Fatalf("unable to create keys: %v", err)
+			}
+			data := writeFile(t, opts)
+
+			// A new instance shares no cached keys with the writer.
+			rows, err := readFile(data, New(kms, test.opts...).KeyRetriever(store))
+			if err != nil {
+				t.Fatalf("unable to read file: %v", err)
+			}
+			if !reflect.DeepEqual(rows, testRows) {
+				t.Errorf("expected %v, got %v", testRows, rows)
+			}
+		})
+	}
+}
+
+func TestKeyToolsDoubleWrappingReusesKEKs(t *testing.T) {
+	kms := &countingKms{KmsClient: NewInMemoryKms(testMasterKeys)}
+	tools := New(kms)
+	columnMasterKeys := map[string]string{"email": "pii-master", "phone": "pii-master"}
+	var files [][]byte
+	for range 3 {
+		opts, err := tools.WriterOptions("footer-master", columnMasterKeys, nil)
+		if err != nil {
+			t.Fatalf("unable to create keys: %v", err)
+		}
+		files = append(files, writeFile(t, opts))
+	}
+	if kms.wraps != 2 {
+		t.Errorf("expected one KMS call per master key, got %d", kms.wraps)
+	}
+
+	retriever := New(kms).KeyRetriever(nil)
+	for _, data := range files {
+		if _, err := readFile(data, retriever); err != nil {
+			t.Fatalf("unable to read file: %v", err)
+		}
+	}
+	if kms.unwraps != 2 {
+		t.Errorf("expected one KMS call per key encryption key, got %d", kms.unwraps)
+	}
+}
+
+func TestKeyToolsKeyMetadata(t *testing.T) {
+	tools := New(NewInMemoryKms(testMasterKeys), WithKmsInstance("kms-1", "https://kms.example.com"))
+	footerKey, metadata, err := tools.newDataKey("footer-master", true, "footerKey", nil)
+	if err != nil {
+		t.Fatalf("unable to create key: %v", err)
+	}
+	var material map[string]any
+	if err := json.Unmarshal(metadata, &material); err != nil {
+		t.Fatalf("expected JSON key metadata, got %q: %v", metadata, err)
+	}
+	for field, expected := range map[string]any{
+		"keyMaterialType": "PKMT1",
+		"internalStorage": true,
+		"isFooterKey":     true,
+		"kmsInstanceID":   "kms-1",
+		"kmsInstanceURL":  "https://kms.example.com",
+		"masterKeyID":     "footer-master",
+		"doubleWrapping":  true,
+	} {
+		if material[field] != expected {
+			t.Errorf("expected %s to be %v, got %v", field, expected, material[field])
+		}
+	}
+	for _, field := range []string{"wrappedDEK", "keyEncryptionKeyID", "wrappedKEK"} {
+		if material[field] == nil {
+			t.Errorf("expected %s in the key material", field)
+		}
+	}
+	if bytes.Contains(metadata, footerKey) {
+		t.Errorf("expected the key to be wrapped")
+	}
+
+	store := NewMemoryKeyMaterialStore()
+	_, metadata, err = tools.newDataKey("pii-master", false, "columnKey0", store)
+	if err != nil {
+		t.Fatalf("unable to create key: %v", err)
+	}
+	expected := `{"keyMaterialType":"PKMT1","internalStorage":false,"keyReference":"columnKey0"}`
+	if string(metadata) != expected {
+		t.Errorf("expected %s, got %s", expected, metadata)
+	}
+}
+
+func TestKeyToolsRotateMasterKeys(t *testing.T) {
+	kms := NewInMemoryKms(testMasterKeys)
+	store := NewMemoryKeyMaterialStore()
+	tools := New(kms)
+	opts, err := tools.WriterOptions("footer-master", map[string]string{"email": "pii-master"}, store)
+	if err != nil {
+		t.Fatalf("unable to create keys: %v", err)
+	}
+	data := writeFile(t, opts)
+
+	kms.RotateMasterKey("footer-master", []byte("5432109876543210"))
+	kms.RotateMasterKey("pii-master", []byte("4321098765432105"))
+	if err := tools.RotateMasterKeys(store); err != nil {
+		t.Fatalf("unable to rotate master keys: %v", err)
+	}
+	kms.RemoveOldVersions("footer-master")
+	kms.RemoveOldVersions("pii-master")
+
+	rows, err := readFile(data, New(kms).KeyRetriever(store))
+	if err != nil {
+		t.Fatalf("unable to read file after rotation: %v", err)
+	}
+	if !reflect.DeepEqual(rows, testRows) {
+		t.Errorf("expected %v, got %v", testRows, rows)
+	}
+
+	// New files use the rotated master keys.
+	opts, err = tools.WriterOptions("footer-master", nil, nil)
+	if err != nil {
+		t.Fatalf("unable to create keys: %v", err)
+	}
+	if _, err := readFile(writeFile(t, opts), New(kms).KeyRetriever(nil)); err != nil {
+		t.Errorf("unable to read a file written after rotation: %v", err)
+	}
+}
+
+func TestKeyToolsErrors(t *testing.T) {
+	kms := NewInMemoryKms(testMasterKeys)
+	if _, err := New(kms).WriterOptions("missing-master", nil, nil); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("expected ErrKeyNotFound for an unknown master key, got %v", err)
+	}
+
+	store := NewMemoryKeyMaterialStore()
+	opts, err := New(kms).WriterOptions("footer-master", nil, store)
+	if err != nil {
+		t.Fatalf("unable to create keys: %v", err)
+	}
+	data := writeFile(t, opts)
+	if _, err := readFile(data, New(kms).KeyRetriever(nil)); !errors.Is(err, parquet.ErrMissingKey) {
+		t.Errorf("expected ErrMissingKey without the key material store, got %v", err)
+	}
+	if _, err := readFile(data, New(kms).KeyRetriever(NewMemoryKeyMaterialStore())); !errors.Is(err, ErrMaterialNotFound) {
+		t.Errorf("expected ErrMaterialNotFound with another store, got %v", err)
+	}
+
+	other := NewInMemoryKms(map[string][]byte{"footer-master": []byte("5432109876543210")})
+	if _, err := readFile(data, New(other).KeyRetriever(store)); err == nil {
+		t.Errorf("expected error unwrapping with another master key, got nil error")
+	}
+}
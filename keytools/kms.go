@@ -0,0 +1,178 @@
+package keytools
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// ErrKeyNotFound is returned by a KMS that does not hold a master key.
+var ErrKeyNotFound = errors.New("master key not found")
+
+// KmsClient wraps and unwraps data keys with master keys held by a key
+// management service. Implementations must be safe for concurrent use.
+type KmsClient interface {
+	// WrapKey encrypts key with the current version of a master key.
+	WrapKey(key []byte, masterKeyID string) (string, error)
+	// UnwrapKey decrypts a key wrapped with any version of a master key.
+	UnwrapKey(wrappedKey string, masterKeyID string) ([]byte, error)
+}
+
+// localWrapping is the wrapped form of a key encrypted by a KMS that holds
+// its master keys in memory.
+type localWrapping struct {
+	Type          string `json:"localWrappingType"`
+	MasterVersion string `json:"masterKeyVersion"`
+	EncryptedKey  string `json:"encryptedKey"`
+}
+
+const localWrappingType = "LKW1"
+
+// InMemoryKms is a KmsClient that wraps keys locally with master keys held in
+// memory. It is meant for tests: master keys never leave a real KMS.
+type InMemoryKms struct {
+	mutex      sync.RWMutex
+	masterKeys map[string]*masterKey
+}
+
+// masterKey holds the versions of a master key.
+type masterKey struct {
+	current  int
+	versions map[int][]byte
+}
+
+// NewInMemoryKms returns a KMS holding the given master keys by identifier.
+func NewInMemoryKms(masterKeys map[string][]byte) *InMemoryKms {
+	kms := &InMemoryKms{masterKeys: make(map[string]*masterKey)}
+	for id, key := range masterKeys {
+		kms.masterKeys[id] = &masterKey{versions: map[int][]byte{0: key}}
+	}
+	return kms
+}
+
+// RotateMasterKey makes key the current version of a master key. Keys
+// wrapped with earlier versions can still be unwrapped.
+func (k *InMemoryKms) RotateMasterKey(masterKeyID string, key []byte) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	master, ok := k.masterKeys[masterKeyID]
+	if !ok {
+		k.masterKeys[masterKeyID] = &masterKey{versions: map[int][]byte{0: key}}
+		return
+	}
+	master.current++
+	master.versions[master.current] = key
+}
+
+// RemoveOldVersions drops every version of a master key but the current
+// one, after which keys wrapped with them can no longer be unwrapped.
+func (k *InMemoryKms) RemoveOldVersions(masterKeyID string) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	if master, ok := k.masterKeys[masterKeyID]; ok {
+		master.versions = map[int][]byte{master.current: master.versions[master.current]}
+	}
+}
+
+// version returns a version of a master key, or its current version when
+// version is negative.
+func (k *InMemoryKms) version(masterKeyID string, version int) ([]byte, int, error) {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+	master, ok := k.masterKeys[masterKeyID]
+	if !ok {
+		return nil, 0, fmt.Errorf("%w: %q", ErrKeyNotFound, masterKeyID)
+	}
+	if version < 0 {
+		version = master.current
+	}
+	key, ok := master.versions[version]
+	if !ok {
+		return nil, 0, fmt.Errorf("%w: %q version %d", ErrKeyNotFound, masterKeyID, version)
+	}
+	return key, version, nil
+}
+
+func (k *InMemoryKms) WrapKey(key []byte, masterKeyID string) (string, error) {
+	master, version, err := k.version(masterKeyID, -1)
+	if err != nil {
+		return "", err
+	}
+	encrypted, err := encryptKey(key, master, []byte(masterKeyID))
+	if err != nil {
+		return "", err
+	}
+	wrapped, err := json.Marshal(localWrapping{
+		Type:          localWrappingType,
+		MasterVersion: strconv.Itoa(version),
+		EncryptedKey:  encrypted,
+	})
+	return string(wrapped), err
+}
+
+func (k *InMemoryKms) UnwrapKey(wrappedKey string, masterKeyID string) ([]byte, error) {
+	var wrapping localWrapping
+	if err := json.Unmarshal([]byte(wrappedKey), &wrapping); err != nil {
+		return nil, fmt.Errorf("invalid wrapped key: %w", err)
+	}
+	if wrapping.Type != localWrappingType {
+		return nil, fmt.Errorf("unsupported local wrapping type %q", wrapping.Type)
+	}
+	version, err := strconv.Atoi(wrapping.MasterVersion)
+	if err != nil || version < 0 {
+		return nil, fmt.Errorf("invalid master key version %q", wrapping.MasterVersion)
+	}
+	master, _, err := k.version(masterKeyID, version)
+	if err != nil {
+		return nil, err
+	}
+	return decryptKey(wrapping.EncryptedKey, master, []byte(masterKeyID))
+}
+
+// encryptKey encrypts a key with AES GCM into the base64 encoding of the
+// nonce, the ciphertext and the tag.
+func encryptKey(key, wrappingKey, aad []byte) (string, error) {
+	gcm, err := newGCM(wrappingKey)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, key, aad)), nil
+}
+
+// decryptKey decrypts a key encrypted by encryptKey.
+func decryptKey(encrypted string, wrappingKey, aad []byte) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encrypted key: %w", err)
+	}
+	gcm, err := newGCM(wrappingKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize()+gcm.Overhead() {
+		return nil, errors.New("truncated encrypted key")
+	}
+	key, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], aad)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt key: %w", err)
+	}
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
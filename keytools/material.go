@@ -0,0 +1,100 @@
+package keytools
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+const keyMaterialType = "PKMT1"
+
+// keyMaterial is the JSON description of a wrapped data key. It is stored
+// in the key metadata of the file, or in a KeyMaterialStore next to it.
+type keyMaterial struct {
+	Type            string `json:"keyMaterialType"`
+	InternalStorage bool   `json:"internalStorage,omitempty"`
+	IsFooterKey     bool   `json:"isFooterKey"`
+	KmsInstanceID   string `json:"kmsInstanceID,omitempty"`
+	KmsInstanceURL  string `json:"kmsInstanceURL,omitempty"`
+	MasterKeyID     string `json:"masterKeyID"`
+	WrappedDEK      string `json:"wrappedDEK"`
+	DoubleWrapping  bool   `json:"doubleWrapping"`
+	// KEKID and WrappedKEK describe the key encryption key of a double
+	// wrapped data key. KEKID is base64 encoded.
+	KEKID      string `json:"keyEncryptionKeyID,omitempty"`
+	WrappedKEK string `json:"wrappedKEK,omitempty"`
+}
+
+// keyMetadata is the key metadata of a data key whose material is kept in a
+// KeyMaterialStore.
+type keyMetadata struct {
+	Type            string `json:"keyMaterialType"`
+	InternalStorage bool   `json:"internalStorage"`
+	KeyReference    string `json:"keyReference"`
+}
+
+func parseKeyMaterial(data []byte) (*keyMaterial, error) {
+	material := &keyMaterial{}
+	if err := json.Unmarshal(data, material); err != nil {
+		return nil, fmt.Errorf("invalid key material: %w", err)
+	}
+	if material.Type != keyMaterialType {
+		return nil, fmt.Errorf("unsupported key material type %q", material.Type)
+	}
+	return material, nil
+}
+
+// ErrMaterialNotFound is returned by a KeyMaterialStore without the material
+// of a key.
+var ErrMaterialNotFound = errors.New("key material not found")
+
+// KeyMaterialStore keeps the key material of the keys of one file outside of
+// the file, so that master keys can be rotated without rewriting it.
+type KeyMaterialStore interface {
+	// Get returns the key material of a key of the file.
+	Get(keyID string) (string, error)
+	// Put stores the key material of a key of the file.
+	Put(keyID, material string) error
+	// Keys returns the identifiers of every stored key.
+	Keys() ([]string, error)
+}
+
+// MemoryKeyMaterialStore is a KeyMaterialStore held in memory.
+type MemoryKeyMaterialStore struct {
+	mutex     sync.RWMutex
+	materials map[string]string
+}
+
+func NewMemoryKeyMaterialStore() *MemoryKeyMaterialStore {
+	return &MemoryKeyMaterialStore{materials: make(map[string]string)}
+}
+
+func (s *MemoryKeyMaterialStore) Get(keyID string) (string, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	material, ok := s.materials[keyID]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrMaterialNotFound, keyID)
+	}
+	return material, nil
+}
+
+func (s *MemoryKeyMaterialStore) Put(keyID, material string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.materials[keyID] = material
+	return nil
+}
+
+func (s *MemoryKeyMaterialStore) Keys() ([]string, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	keys := make([]string, 0, len(s.materials))
+	for keyID := range s.materials {
+		keys = append(keys, keyID)
+	}
+	slices.Sort(keys)
+	return keys, nil
+}
@@ -0,0 +1,123 @@
+package metadata
+
+import (
+	"github.com/RichardNooooh/parquet-go/internal/decoder"
+	format "github.com/RichardNooooh/parquet-go/internal/format/gen-go/parquet"
+	"github.com/RichardNooooh/parquet-go/internal/stats"
+)
+
+type BoundaryOrder int32
+
+const (
+	Unordered BoundaryOrder = iota
+	Ascending
+	Descending
+)
+
+func (o BoundaryOrder) String() string {
+	switch o {
+	case Ascending:
+		return "ASCENDING"
+	case Descending:
+		return "DESCENDING"
+	}
+	return "UNORDERED"
+}
+
+// ColumnIndex holds the per-page statistics of a column chunk. Min and Max
+// are decoded like Stats and are nil for pages that only contain nulls.
+type ColumnIndex struct {
+	NullPages     []bool
+	Min           []any
+	Max           []any
+	BoundaryOrder BoundaryOrder
+	NullCounts    []int64
+	HasNullCounts bool
+	// Trusted reports whether Min and Max follow the column's type-defined
+	// sort order and can be used to skip pages.
+	Trusted bool
+	// RepetitionLevelHistograms and DefinitionLevelHistograms hold the level
+	// histograms of each page, like those of SizeStatistics. They are nil
+	// when unknown.
+	RepetitionLevelHistograms [][]int64
+	DefinitionLevelHistograms [][]int64
+}
+
+func (c *ColumnIndex) NumPages() int { return len(c.NullPages) }
+
+type PageLocation struct {
+	Offset             int64
+	CompressedPageSize int32
+	FirstRowIndex      int64
+}
+
+// OffsetIndex locates the data pages of a column chunk.
+type OffsetIndex struct {
+	PageLocations []PageLocation
+	// UnencodedByteArrayDataBytes holds the length of the BYTE_ARRAY values
+	// of each page, like that of SizeStatistics. It is nil when unknown.
+	UnencodedByteArrayDataBytes []int64
+}
+
+func (o *OffsetIndex) NumPages() int { return len(o.PageLocations) }
+
+// LastRowIndex returns the index of the last row in page i, relative to the
+// row group, given the number of rows in the row group.
+func (o *OffsetIndex) LastRowIndex(i int, numRows int64) int64 {
+	if i+1 < len(o.PageLocations) {
+		return o.PageLocations[i+1].FirstRowIndex - 1
+	}
+	return numRows - 1
+}
+
+// DecodeColumnIndex decodes the page statistics of the given column.
+func (m *FileMeta) DecodeColumnIndex(columnIndex int, src *format.ColumnIndex) *ColumnIndex {
+	column := m.columns[columnIndex]
+	leaf := column.Leaf
+	numPages := len(src.GetNullPages())
+	index := &ColumnIndex{
+		NullPages:     src.GetNullPages(),
+		Min:           make([]any, numPages),
+		Max:           make([]any, numPages),
+		BoundaryOrder: BoundaryOrder(src.GetBoundaryOrder()),
+		NullCounts:    src.GetNullCounts(),
+		HasNullCounts: src.IsSetNullCounts(),
+		Trusted:       m.sortOrder(columnIndex) != stats.Unknown,
+
+		RepetitionLevelHistograms: pageLevelHistograms(src.GetRepetitionLevelHistograms(), column.MaxRepetitionLevel, numPages),
+		DefinitionLevelHistograms: pageLevelHistograms(src.GetDefinitionLevelHistograms(), column.MaxDefinitionLevel, numPages),
+	}
+	if len(src.GetMinValues()) != numPages || len(src.GetMaxValues()) != numPages {
+		index.Trusted = false
+		return index
+	}
+
+	for i := range numPages {
+		if index.NullPages[i] {
+			continue
+		}
+		minValue, minErr := decoder.DecodePlainValue(leaf.Type, leaf.TypeLength, src.MinValues[i])
+		maxValue, maxErr := decoder.DecodePlainValue(leaf.Type, leaf.TypeLength, src.MaxValues[i])
+		if minErr != nil || maxErr != nil || stats.IsNaN(leaf, minValue) || stats.IsNaN(leaf, maxValue) {
+			index.Trusted = false
+			continue
+		}
+		index.Min[i], index.Max[i] = minValue, maxValue
+	}
+	return index
+}
+
+func NewOffsetIndex(src *format.OffsetIndex) *OffsetIndex {
+	index := &OffsetIndex{PageLocations: make([]PageLocation, len(src.GetPageLocations()))}
+	for i, location := range src.GetPageLocations() {
+		index.PageLocations[i] = PageLocation{
+			Offset:             location.GetOffset(),
+			CompressedPageSize: location.GetCompressedPageSize(),
+			FirstRowIndex:      location.GetFirstRowIndex(),
+		}
+	}
+	if sizes := src.GetUnencodedByteArrayDataBytes(); len(sizes) == len(index.PageLocations) {
+		index.UnencodedByteArrayDataBytes = sizes
+	}
+	return index
+}
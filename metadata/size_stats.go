@@ -0,0 +1,62 @@
+package metadata
+
+import (
+	format "github.com/RichardNooooh/parquet-go/internal/format/gen-go/parquet"
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+// SizeStatistics describes the size of the data of a column chunk once
+// decoded, so that the memory needed to read it can be estimated up front.
+type SizeStatistics struct {
+	// UnencodedByteArrayDataBytes is the total length of the BYTE_ARRAY
+	// values, without their length prefixes. It is only set when
+	// HasUnencodedByteArrayDataBytes.
+	UnencodedByteArrayDataBytes    int64
+	HasUnencodedByteArrayDataBytes bool
+	// RepetitionLevelHistogram and DefinitionLevelHistogram count the
+	// values at each level. They are nil when unknown, and for levels whose
+	// maximum is zero.
+	RepetitionLevelHistogram []int64
+	DefinitionLevelHistogram []int64
+}
+
+// NumNonNullValues returns the number of non-null values, counted by the
+// definition level histogram, or false when it is unknown. Columns without
+// definition levels have no histogram, and only non-null values.
+func (s *SizeStatistics) NumNonNullValues(column *schema.Column) (int64, bool) {
+	if column.MaxDefinitionLevel == 0 || s.DefinitionLevelHistogram == nil {
+		return 0, false
+	}
+	return s.DefinitionLevelHistogram[column.MaxDefinitionLevel], true
+}
+
+func decodeSizeStatistics(column *schema.Column, src *format.SizeStatistics) *SizeStatistics {
+	return &SizeStatistics{
+		UnencodedByteArrayDataBytes:    src.GetUnencodedByteArrayDataBytes(),
+		HasUnencodedByteArrayDataBytes: src.IsSetUnencodedByteArrayDataBytes() && column.Leaf.Type == schema.ByteArray,
+		RepetitionLevelHistogram:       levelHistogram(src.GetRepetitionLevelHistogram(), column.MaxRepetitionLevel),
+		DefinitionLevelHistogram:       levelHistogram(src.GetDefinitionLevelHistogram(), column.MaxDefinitionLevel),
+	}
+}
+
+// levelHistogram returns a histogram when it has one entry per level.
+func levelHistogram(histogram []int64, maxLevel int16) []int64 {
+	if maxLevel == 0 || len(histogram) != int(maxLevel)+1 {
+		return nil
+	}
+	return histogram
+}
+
+// pageLevelHistograms splits the concatenated histograms of numPages pages,
+// returning nil when they are missing or malformed.
+func pageLevelHistograms(histograms []int64, maxLevel int16, numPages int) [][]int64 {
+	size := int(maxLevel) + 1
+	if maxLevel == 0 || len(histograms) != numPages*size {
+		return nil
+	}
+	pages := make([][]int64, numPages)
+	for i := range pages {
+		pages[i] = histograms[i*size : (i+1)*size : (i+1)*size]
+	}
+	return pages
+}
@@ -0,0 +1,29 @@
+package metadata
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPageLevelHistograms(t *testing.T) {
+	testcases := map[string]struct {
+		histograms []int64
+		maxLevel   int16
+		numPages   int
+		expected   [][]int64
+	}{
+		"split":         {histograms: []int64{1, 2, 3, 4, 5, 6}, maxLevel: 2, numPages: 2, expected: [][]int64{{1, 2, 3}, {4, 5, 6}}},
+		"missing":       {maxLevel: 1, numPages: 2},
+		"wrong length":  {histograms: []int64{1, 2, 3}, maxLevel: 1, numPages: 2},
+		"no levels":     {histograms: []int64{1, 2}, maxLevel: 0, numPages: 2},
+		"level too big": {histograms: []int64{1, 2, 3, 4}, maxLevel: 3, numPages: 2},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			if got := pageLevelHistograms(test.histograms, test.maxLevel, test.numPages); !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package metadata
+
+import (
+	"bytes"
+
+	"github.com/RichardNooooh/parquet-go/internal/decoder"
+	format "github.com/RichardNooooh/parquet-go/internal/format/gen-go/parquet"
+	"github.com/RichardNooooh/parquet-go/internal/stats"
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+// Stats holds the statistics of a page or column chunk decoded into the Go
+// types of the column's physical type: bool, int32, int64, [12]byte, float32,
+// float64 or []byte. parquet.LogicalStats converts Min and Max to the Go types
+// of the column's logical type.
+type Stats struct {
+	Min        any
+	Max        any
+	HasMinMax  bool
+	IsMinExact bool
+	IsMaxExact bool
+	// Trusted reports whether Min and Max follow the column's type-defined
+	// sort order and can be used to skip data. It is false for statistics
+	// written with a mismatching order or by writers with known bugs.
+	Trusted bool
+	// Deprecated reports whether Min and Max come from the deprecated min and
+	// max fields rather than min_value and max_value.
+	Deprecated bool
+
+	NullCount        int64
+	HasNullCount     bool
+	DistinctCount    int64
+	HasDistinctCount bool
+}
+
+// sortOrder returns the order that trusted min_value and max_value
+// statistics and column indexes of the column follow. Their meaning is
+// undefined without column_orders, unless the writer is known to use the
+// type-defined order.
+func (m *FileMeta) sortOrder(columnIndex int) stats.SortOrder {
+	columnOrders := m.fileMetadata.GetColumnOrders()
+	if len(columnOrders) == 0 && !typeDefinedOrderWriters[m.writer.Application] {
+		return stats.Unknown
+	}
+	if columnIndex < len(columnOrders) && !columnOrders[columnIndex].IsSetTYPE_ORDER() {
+		// A column order added to the format after this reader was written.
+		return stats.Unknown
+	}
+	return stats.SortOrderOf(m.columns[columnIndex].Leaf)
+}
+
+// typeDefinedOrderWriters lists the applications whose min_value and
+// max_value follow the type-defined order even in files written without
+// column_orders.
+var typeDefinedOrderWriters = map[string]bool{
+	"parquet-mr":        true,
+	"parquet-cpp":       true,
+	"parquet-cpp-arrow": true,
+	"parquet-rs":        true,
+	"DuckDB":            true,
+}
+
+// DecodeStats decodes statistics of the given column, deciding whether the
+// min and max can be trusted from the column order and the writer version.
+func (m *FileMeta) DecodeStats(columnIndex int, statistics *format.Statistics) *Stats {
+	leaf := m.columns[columnIndex].Leaf
+	s := &Stats{
+		NullCount:        statistics.GetNullCount(),
+		HasNullCount:     statistics.IsSetNullCount(),
+		DistinctCount:    statistics.GetDistinctCount(),
+		HasDistinctCount: statistics.IsSetDistinctCount(),
+	}
+
+	order := m.sortOrder(columnIndex)
+	minBytes, maxBytes := statistics.MinValue, statistics.MaxValue
+	if statistics.IsSetMinValue() && statistics.IsSetMaxValue() {
+		s.IsMinExact = !statistics.IsSetIsMinValueExact() || statistics.GetIsMinValueExact()
+		s.IsMaxExact = !statistics.IsSetIsMaxValueExact() || statistics.GetIsMaxValueExact()
+		s.Trusted = order != stats.Unknown
+	} else if statistics.IsSetMin() && statistics.IsSetMax() {
+		minBytes, maxBytes = statistics.Min, statistics.Max
+		s.Deprecated = true
+		s.IsMinExact, s.IsMaxExact = true, true
+		// The deprecated fields predate column_orders, so their trust only
+		// depends on the type-defined order and the writer.
+		s.Trusted = m.trustDeprecatedMinMax(leaf, stats.SortOrderOf(leaf), minBytes, maxBytes)
+	} else {
+		return s
+	}
+
+	minValue, minErr := decoder.DecodePlainValue(leaf.Type, leaf.TypeLength, minBytes)
+	maxValue, maxErr := decoder.DecodePlainValue(leaf.Type, leaf.TypeLength, maxBytes)
+	if minErr != nil || maxErr != nil {
+		s.Trusted = false
+		return s
+	}
+	s.Min, s.Max, s.HasMinMax = minValue, maxValue, true
+	if stats.IsNaN(leaf, minValue) || stats.IsNaN(leaf, maxValue) {
+		// Older writers let NaN leak into float statistics.
+		s.Trusted = false
+	}
+	return s
+}
+
+// trustDeprecatedMinMax follows parquet-mr's rules for the deprecated min and
+// max fields. They were always computed with signed comparisons, so they only
+// match the type-defined order of signed numeric columns, or any column whose
+// min equals its max. BYTE_ARRAY statistics from parquet-mr before 1.8.0 are
+// corrupt (PARQUET-251), as are those of files from the same era that were
+// written without created_by (PARQUET-297).
+func (m *FileMeta) trustDeprecatedMinMax(leaf *schema.SchemaElement, order stats.SortOrder, minBytes, maxBytes []byte) bool {
+	isBinary := leaf.Type == schema.ByteArray || leaf.Type == schema.FixedLenByteArray
+	if order == stats.Unknown {
+		return false
+	}
+	if isBinary && (m.writer.Before("parquet-mr", 1, 8, 0) || m.writer.Application == "") {
+		return false
+	}
+	if bytes.Equal(minBytes, maxBytes) {
+		return true
+	}
+	return order == stats.Signed && !isBinary
+}
@@ -0,0 +1,180 @@
+package metadata
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/RichardNooooh/parquet-go/internal/file"
+	format "github.com/RichardNooooh/parquet-go/internal/format/gen-go/parquet"
+)
+
+func TestDecodeStatsFromFiles(t *testing.T) {
+	testcases := map[string]struct {
+		filename string
+		column   int
+		trusted  bool
+		min, max any
+	}{
+		"parquetMRSignedInt":        {filename: "timestored_examples/userdata.parquet", column: 1, trusted: true, min: int32(1), max: int32(1000)},
+		"parquetMRDeprecatedBinary": {filename: "timestored_examples/userdata.parquet", column: 3, trusted: false, min: []byte("Adams"), max: []byte("Young")},
+		"parquetMRInt96":            {filename: "timestored_examples/userdata.parquet", column: 0, trusted: false},
+		"deprecatedBinaryMinIsMax":  {filename: "apache_examples/nested_maps.snappy.parquet", column: 3, trusted: true, min: int32(1), max: int32(1)},
+		"minValueDouble":            {filename: "timestored_examples/iris.parquet", column: 0, trusted: true, min: 4.3, max: 7.9},
+		"minValueString":            {filename: "timestored_examples/iris.parquet", column: 4, trusted: true, min: []byte("Setosa"), max: []byte("Virginica")},
+		"typeDefinedOrderBinary":    {filename: "timestored_examples/table.parquet", column: 1, trusted: true, min: []byte("bar"), max: []byte("foo")},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			meta := openTestFileMeta(t, test.filename)
+			stats := meta.RowGroup(0).Column(test.column).Statistics()
+			if stats == nil {
+				t.Fatalf("expected statistics, got nil")
+			}
+			if stats.Trusted != test.trusted {
+				t.Errorf("expected trusted %t, got %t", test.trusted, stats.Trusted)
+			}
+			if test.min != nil && (!stats.HasMinMax || !equalValues(stats.Min, test.min) || !equalValues(stats.Max, test.max)) {
+				t.Errorf("expected [%v, %v], got [%v, %v]", test.min, test.max, stats.Min, stats.Max)
+			}
+		})
+	}
+}
+
+func TestDecodeStatsTrust(t *testing.T) {
+	int32Bytes := func(v int32) []byte { return binary.LittleEndian.AppendUint32(nil, uint32(v)) }
+	unsigned := format.ConvertedType_UINT_32
+
+	testcases := map[string]struct {
+		createdBy     string
+		convertedType *format.ConvertedType
+		columnOrders  []*format.ColumnOrder
+		statistics    *format.Statistics
+		trusted       bool
+	}{
+		"minValue": {
+			createdBy:  "parquet-mr version 1.12.3",
+			statistics: &format.Statistics{MinValue: int32Bytes(-1), MaxValue: int32Bytes(5)},
+			trusted:    true,
+		},
+		"unknownColumnOrder": {
+			createdBy:    "parquet-mr version 1.12.3",
+			columnOrders: []*format.ColumnOrder{{}},
+			statistics:   &format.Statistics{MinValue: int32Bytes(-1), MaxValue: int32Bytes(5)},
+			trusted:      false,
+		},
+		"noColumnOrders": {
+			createdBy:  "unknown-writer version 1.0.0",
+			statistics: &format.Statistics{MinValue: int32Bytes(-1), MaxValue: int32Bytes(5)},
+			trusted:    false,
+		},
+		"noColumnOrdersNoCreatedBy": {
+			statistics: &format.Statistics{MinValue: int32Bytes(-1), MaxValue: int32Bytes(5)},
+			trusted:    false,
+		},
+		"noColumnOrdersKnownWriter": {
+			createdBy:  "DuckDB",
+			statistics: &format.Statistics{MinValue: int32Bytes(-1), MaxValue: int32Bytes(5)},
+			trusted:    true,
+		},
+		"columnOrdersUnknownWriter": {
+			createdBy:    "unknown-writer version 1.0.0",
+			columnOrders: []*format.ColumnOrder{{TYPE_ORDER: format.NewTypeDefinedOrder()}},
+			statistics:   &format.Statistics{MinValue: int32Bytes(-1), MaxValue: int32Bytes(5)},
+			trusted:      true,
+		},
+		"deprecatedUnknownWriter": {
+			createdBy:  "unknown-writer version 1.0.0",
+			statistics: &format.Statistics{Min: int32Bytes(-1), Max: int32Bytes(5)},
+			trusted:    true,
+		},
+		"deprecatedSigned": {
+			createdBy:  "parquet-mr version 1.6.0",
+			statistics: &format.Statistics{Min: int32Bytes(-1), Max: int32Bytes(5)},
+			trusted:    true,
+		},
+		"deprecatedUnsigned": {
+			createdBy:     "parquet-mr version 1.9.0",
+			convertedType: &unsigned,
+			statistics:    &format.Statistics{Min: int32Bytes(-1), Max: int32Bytes(5)},
+			trusted:       false,
+		},
+		"deprecatedUnsignedMinIsMax": {
+			createdBy:     "parquet-mr version 1.9.0",
+			convertedType: &unsigned,
+			statistics:    &format.Statistics{Min: int32Bytes(7), Max: int32Bytes(7)},
+			trusted:       true,
+		},
+		"malformed": {
+			createdBy:  "parquet-mr version 1.12.3",
+			statistics: &format.Statistics{MinValue: []byte{1}, MaxValue: int32Bytes(5)},
+			trusted:    false,
+		},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			typ := format.Type_INT32
+			repetition := format.FieldRepetitionType_REQUIRED
+			numChildren := int32(1)
+			fileMetadata := &format.FileMetaData{
+				Schema: []*format.SchemaElement{
+					{Name: "schema", NumChildren: &numChildren},
+					{Name: "x", Type: &typ, RepetitionType: &repetition, ConvertedType: test.convertedType},
+				},
+				CreatedBy:    &test.createdBy,
+				ColumnOrders: test.columnOrders,
+			}
+			meta, err := NewFileMeta(fileMetadata)
+			if err != nil {
+				t.Fatalf("unable to build file metadata: %v", err)
+			}
+			if stats := meta.DecodeStats(0, test.statistics); stats.Trusted != test.trusted {
+				t.Errorf("expected trusted %t, got %t", test.trusted, stats.Trusted)
+			}
+		})
+	}
+}
+
+func openTestFileMeta(t *testing.T, name string) *FileMeta {
+	t.Helper()
+	f, err := os.Open(filepath.Join(getTestcaseDirectory(), name))
+	if err != nil {
+		t.Fatalf("unable to open %v: %v", name, err)
+	}
+	t.Cleanup(func() { f.Close() })
+	fileStat, err := f.Stat()
+	if err != nil {
+		t.Fatalf("unable to get filestat of %v: %v", name, err)
+	}
+
+	fileMetadata, err := file.GetFileMetadata(context.Background(), file.NewReader(f, fileStat.Size()))
+	if err != nil {
+		t.Fatalf("unable to read footer of %v: %v", name, err)
+	}
+	meta, err := NewFileMeta(fileMetadata)
+	if err != nil {
+		t.Fatalf("unable to build file metadata of %v: %v", name, err)
+	}
+	return meta
+}
+
+func getTestcaseDirectory() string {
+	_, thisFile, _, ok := runtime.Caller(0)
+	if !ok {
+		panic("runtime.Caller failed")
+	}
+	return filepath.Clean(filepath.Join(filepath.Dir(thisFile), "..", "testdata"))
+}
+
+func equalValues(a, b any) bool {
+	if x, ok := a.([]byte); ok {
+		y, ok := b.([]byte)
+		return ok && string(x) == string(y)
+	}
+	return a == b
+}
@@ -1,6 +1,158 @@
 package metadata
 
-type FileMeta struct{}
-type RowGroupMeta struct{}
-type ColumnChunkMeta struct{}
-type Stats struct{}
+import (
+	"fmt"
+
+	"github.com/RichardNooooh/parquet-go/geospatial"
+	format "github.com/RichardNooooh/parquet-go/internal/format/gen-go/parquet"
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+// FileMeta wraps the decoded file footer together with the schema tree built
+// from it.
+type FileMeta struct {
+	fileMetadata *format.FileMetaData
+	schema       *schema.SchemaElement
+	columns      []*schema.Column
+	writer       WriterVersion
+}
+
+type RowGroupMeta struct {
+	file     *FileMeta
+	rowGroup *format.RowGroup
+	index    int
+}
+
+type ColumnChunkMeta struct {
+	file   *FileMeta
+	column *schema.Column
+	chunk  *format.ColumnChunk
+}
+
+func NewFileMeta(fileMetadata *format.FileMetaData) (*FileMeta, error) {
+	root, err := schema.FromFormat(fileMetadata.GetSchema())
+	if err != nil {
+		return nil, err
+	}
+	columns := root.Columns()
+	for i, rowGroup := range fileMetadata.GetRowGroups() {
+		if len(rowGroup.GetColumns()) != len(columns) {
+			return nil, fmt.Errorf("%w: row group %d has %d columns, schema has %d",
+				schema.ErrInvalidSchema, i, len(rowGroup.GetColumns()), len(columns))
+		}
+	}
+
+	return &FileMeta{
+		fileMetadata: fileMetadata,
+		schema:       root,
+		columns:      columns,
+		writer:       ParseCreatedBy(fileMetadata.GetCreatedBy()),
+	}, nil
+}
+
+func (m *FileMeta) Version() int32 { return m.fileMetadata.GetVersion() }
+
+func (m *FileMeta) NumRows() int64 { return m.fileMetadata.GetNumRows() }
+
+func (m *FileMeta) CreatedBy() string { return m.fileMetadata.GetCreatedBy() }
+
+func (m *FileMeta) WriterVersion() WriterVersion { return m.writer }
+
+func (m *FileMeta) Schema() *schema.SchemaElement { return m.schema }
+
+func (m *FileMeta) Columns() []*schema.Column { return m.columns }
+
+func (m *FileMeta) KeyValueMetadata() map[string]string {
+	keyValues := make(map[string]string)
+	for _, keyValue := range m.fileMetadata.GetKeyValueMetadata() {
+		keyValues[keyValue.GetKey()] = keyValue.GetValue()
+	}
+	return keyValues
+}
+
+func (m *FileMeta) NumRowGroups() int { return len(m.fileMetadata.GetRowGroups()) }
+
+func (m *FileMeta) RowGroup(i int) *RowGroupMeta {
+	return &RowGroupMeta{file: m, rowGroup: m.fileMetadata.GetRowGroups()[i], index: i}
+}
+
+// Format returns the underlying thrift structure of the footer.
+func (m *FileMeta) Format() *format.FileMetaData { return m.fileMetadata }
+
+func (g *RowGroupMeta) Index() int { return g.index }
+
+func (g *RowGroupMeta) NumRows() int64 { return g.rowGroup.GetNumRows() }
+
+func (g *RowGroupMeta) TotalByteSize() int64 { return g.rowGroup.GetTotalByteSize() }
+
+func (g *RowGroupMeta) NumColumns() int { return len(g.rowGroup.GetColumns()) }
+
+func (g *RowGroupMeta) Column(i int) *ColumnChunkMeta {
+	return &ColumnChunkMeta{file: g.file, column: g.file.columns[i], chunk: g.rowGroup.GetColumns()[i]}
+}
+
+func (g *RowGroupMeta) Format() *format.RowGroup { return g.rowGroup }
+
+func (c *ColumnChunkMeta) Column() *schema.Column { return c.column }
+
+// columnMetadata returns the metadata of the chunk, which is empty for a
+// column encrypted with a key the reader does not have.
+func (c *ColumnChunkMeta) columnMetadata() *format.ColumnMetaData {
+	if columnMetadata := c.chunk.GetMetaData(); columnMetadata != nil {
+		return columnMetadata
+	}
+	return format.NewColumnMetaData()
+}
+
+func (c *ColumnChunkMeta) NumValues() int64 { return c.columnMetadata().GetNumValues() }
+
+func (c *ColumnChunkMeta) TotalCompressedSize() int64 {
+	return c.columnMetadata().GetTotalCompressedSize()
+}
+
+func (c *ColumnChunkMeta) TotalUncompressedSize() int64 {
+	return c.columnMetadata().GetTotalUncompressedSize()
+}
+
+// Statistics decodes the column chunk statistics, or returns nil when the
+// chunk has none.
+func (c *ColumnChunkMeta) Statistics() *Stats {
+	statistics := c.columnMetadata().GetStatistics()
+	if statistics == nil {
+		return nil
+	}
+	return c.file.DecodeStats(c.column.Index, statistics)
+}
+
+// SizeStatistics decodes the decoded sizes of the values and levels of the
+// chunk, or returns nil when it has none.
+func (c *ColumnChunkMeta) SizeStatistics() *SizeStatistics {
+	statistics := c.columnMetadata().GetSizeStatistics()
+	if statistics == nil {
+		return nil
+	}
+	return decodeSizeStatistics(c.column, statistics)
+}
+
+// GeospatialStatistics decodes the bounding box and geometry types of a
+// GEOMETRY or GEOGRAPHY column chunk, or returns nil when it has none.
+func (c *ColumnChunkMeta) GeospatialStatistics() *geospatial.Statistics {
+	statistics := c.columnMetadata().GetGeospatialStatistics()
+	if statistics == nil {
+		return nil
+	}
+	decoded := &geospatial.Statistics{Types: statistics.GetGeospatialTypes()}
+	if bbox := statistics.GetBbox(); bbox != nil {
+		box := &geospatial.BoundingBox{Xmin: bbox.Xmin, Xmax: bbox.Xmax, Ymin: bbox.Ymin, Ymax: bbox.Ymax}
+		if bbox.IsSetZmin() && bbox.IsSetZmax() {
+			box.Zmin, box.Zmax, box.HasZ = bbox.GetZmin(), bbox.GetZmax(), true
+		}
+		if bbox.IsSetMmin() && bbox.IsSetMmax() {
+			box.Mmin, box.Mmax, box.HasM = bbox.GetMmin(), bbox.GetMmax(), true
+		}
+		decoded.BoundingBox = box
+	}
+	return decoded
+}
+
+func (c *ColumnChunkMeta) Format() *format.ColumnChunk { return c.chunk }
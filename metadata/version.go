@@ -0,0 +1,57 @@
+package metadata
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// WriterVersion is the application and version parsed from the created_by
+// field of the footer, e.g. "parquet-mr version 1.8.1 (build 4aba4dae)".
+type WriterVersion struct {
+	Application string
+	Major       int
+	Minor       int
+	Patch       int
+	PreRelease  string
+	Build       string
+}
+
+var createdByPattern = regexp.MustCompile(`^(.+?)\s+version\s+(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:-([0-9A-Za-z.-]+))?(?:\s+\(build\s+([^)]*)\))?`)
+
+// ParseCreatedBy parses a created_by string. Strings that do not follow the
+// "<application> version <version>" convention keep the whole string as the
+// application name and a zero version.
+func ParseCreatedBy(createdBy string) WriterVersion {
+	match := createdByPattern.FindStringSubmatch(createdBy)
+	if match == nil {
+		return WriterVersion{Application: createdBy}
+	}
+	major, _ := strconv.Atoi(match[2])
+	minor, _ := strconv.Atoi(match[3])
+	patch, _ := strconv.Atoi(match[4])
+	return WriterVersion{
+		Application: match[1],
+		Major:       major,
+		Minor:       minor,
+		Patch:       patch,
+		PreRelease:  match[5],
+		Build:       match[6],
+	}
+}
+
+// Before reports whether v was written by application at a version older
+// than major.minor.patch. Pre-release versions sort before their release.
+func (v WriterVersion) Before(application string, major, minor, patch int) bool {
+	if v.Application != application {
+		return false
+	}
+	switch {
+	case v.Major != major:
+		return v.Major < major
+	case v.Minor != minor:
+		return v.Minor < minor
+	case v.Patch != patch:
+		return v.Patch < patch
+	}
+	return v.PreRelease != ""
+}
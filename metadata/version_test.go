@@ -0,0 +1,47 @@
+package metadata
+
+import "testing"
+
+func TestParseCreatedBy(t *testing.T) {
+	testcases := map[string]struct {
+		createdBy string
+		expected  WriterVersion
+	}{
+		"parquetMR": {createdBy: "parquet-mr version 1.8.1 (build 4aba4dae7bb0d4edbcf7923ae1339f28fd3f7fcf)", expected: WriterVersion{Application: "parquet-mr", Major: 1, Minor: 8, Patch: 1, Build: "4aba4dae7bb0d4edbcf7923ae1339f28fd3f7fcf"}},
+		"snapshot":  {createdBy: "parquet-mr version 1.12.0-SNAPSHOT (build 6901a2040848c6b37fa61f4b0a76246445f396db)", expected: WriterVersion{Application: "parquet-mr", Major: 1, Minor: 12, PreRelease: "SNAPSHOT", Build: "6901a2040848c6b37fa61f4b0a76246445f396db"}},
+		"arrow":     {createdBy: "parquet-cpp-arrow version 10.0.1", expected: WriterVersion{Application: "parquet-cpp-arrow", Major: 10, Patch: 1}},
+		"impala":    {createdBy: "impala version 1.3.0-INTERNAL (build 8a48ddb1eff84592b3fc06bc6f51ec120e1fffc9)", expected: WriterVersion{Application: "impala", Major: 1, Minor: 3, PreRelease: "INTERNAL", Build: "8a48ddb1eff84592b3fc06bc6f51ec120e1fffc9"}},
+		"noVersion": {createdBy: "DuckDB", expected: WriterVersion{Application: "DuckDB"}},
+		"empty":     {createdBy: "", expected: WriterVersion{}},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			if version := ParseCreatedBy(test.createdBy); version != test.expected {
+				t.Errorf("expected %+v, got %+v", test.expected, version)
+			}
+		})
+	}
+}
+
+func TestWriterVersionBefore(t *testing.T) {
+	testcases := map[string]struct {
+		createdBy string
+		before    bool
+	}{
+		"older":        {createdBy: "parquet-mr version 1.7.9", before: true},
+		"same":         {createdBy: "parquet-mr version 1.8.0", before: false},
+		"newer":        {createdBy: "parquet-mr version 1.10.0", before: false},
+		"preRelease":   {createdBy: "parquet-mr version 1.8.0-SNAPSHOT", before: true},
+		"otherWriter":  {createdBy: "parquet-cpp version 1.0.0", before: false},
+		"missingPatch": {createdBy: "parquet-mr version 1.6", before: true},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			if before := ParseCreatedBy(test.createdBy).Before("parquet-mr", 1, 8, 0); before != test.before {
+				t.Errorf("expected %t, got %t", test.before, before)
+			}
+		})
+	}
+}
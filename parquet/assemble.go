@@ -0,0 +1,206 @@
+package parquet
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/RichardNooooh/parquet-go/internal/decoder"
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+// columnCursor walks the levels of one column while rows are assembled,
+// remembering the current element of every repeated node on its path.
+type columnCursor struct {
+	column *schema.Column
+	values *columnValues
+	// nodeDefinition and nodeRepetition hold the levels at which each node on
+	// the column path is defined and repeated.
+	nodeDefinition []int32
+	nodeRepetition []int32
+	elements       []int
+	// row is the index within the row group of the row at level.
+	row   int64
+	level int
+	value int
+}
+
+func newColumnCursor(column *schema.Column, values *columnValues) *columnCursor {
+	cursor := &columnCursor{
+		column:         column,
+		values:         values,
+		nodeDefinition: make([]int32, len(column.Nodes)),
+		nodeRepetition: make([]int32, len(column.Nodes)),
+		elements:       make([]int, len(column.Nodes)),
+	}
+	var definition, repetition int32
+	for i, node := range column.Nodes {
+		if node.Repetition != schema.Required {
+			definition++
+		}
+		if node.Repetition == schema.Repeated {
+			repetition++
+		}
+		cursor.nodeDefinition[i], cursor.nodeRepetition[i] = definition, repetition
+	}
+	return cursor
+}
+
+// assembleRows rebuilds the rows in ranges from the decoded values of the
+// given columns, which must all belong to the same row group of numRows rows.
+func assembleRows(columns []*schema.Column, values []*columnValues, ranges rowRanges, numRows int64) ([]Row, error) {
+	cursors := make([]*columnCursor, len(columns))
+	for i, column := range columns {
+		cursors[i] = newColumnCursor(column, values[i])
+	}
+
+	rows := make([]Row, 0, ranges.numRows())
+	for _, rowRange := range ranges {
+		for i := rowRange.start; i < rowRange.end; i++ {
+			row := make(Row)
+			for _, cursor := range cursors {
+				if err := cursor.seek(i); err != nil {
+					return nil, fmt.Errorf("column %q, row %d: %w", cursor.column.PathString(), i, err)
+				}
+				if err := cursor.readRow(row); err != nil {
+					return nil, fmt.Errorf("column %q, row %d: %w", cursor.column.PathString(), i, err)
+				}
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	if len(ranges) == 1 && ranges[0] == (rowRange{0, numRows}) {
+		for _, cursor := range cursors {
+			if cursor.level != len(cursor.values.definitionLevels) {
+				return nil, fmt.Errorf("column %q: %w: values left after %d rows",
+					cursor.column.PathString(), decoder.ErrCorruptData, numRows)
+			}
+		}
+	}
+	return rows, nil
+}
+
+// seek moves the cursor to the given row, jumping to the page that holds it
+// when the row is not reachable from the current position.
+func (c *columnCursor) seek(row int64) error {
+	pages := c.values.pages
+	i := sort.Search(len(pages), func(i int) bool { return pages[i].row > row }) - 1
+	if i < 0 {
+		return fmt.Errorf("%w: no page holds the row", decoder.ErrCorruptData)
+	}
+	if c.row < pages[i].row || c.row > row {
+		c.row, c.level, c.value = pages[i].row, pages[i].level, pages[i].value
+	}
+	for c.row < row {
+		if err := c.readRow(nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readRow inserts the values of the next row of the column into row, or
+// skips them when row is nil.
+func (c *columnCursor) readRow(row Row) error {
+	definitionLevels := c.values.definitionLevels
+	if c.level >= len(definitionLevels) {
+		return fmt.Errorf("%w: column ended early", decoder.ErrCorruptData)
+	}
+
+	maxDefinition := int32(c.column.MaxDefinitionLevel)
+	for start := c.level; c.level < len(definitionLevels); c.level++ {
+		var repetition int32
+		if c.values.repetitionLevels != nil {
+			repetition = c.values.repetitionLevels[c.level]
+		}
+		if repetition == 0 && c.level != start {
+			break
+		}
+
+		definition := definitionLevels[c.level]
+		var value any
+		if definition == maxDefinition {
+			if c.value >= len(c.values.values) {
+				return fmt.Errorf("%w: fewer values than definition levels", decoder.ErrCorruptData)
+			}
+			value = c.values.values[c.value]
+			c.value++
+		}
+		if row == nil {
+			continue
+		}
+		if err := c.insert(row, repetition, definition, value); err != nil {
+			return err
+		}
+	}
+	c.row++
+	return nil
+}
+
+// insert places a single value, or the null or empty list recorded by its
+// levels, into the nested maps and slices of row.
+func (c *columnCursor) insert(row Row, repetition, definition int32, value any) error {
+	container := map[string]any(row)
+	for i, node := range c.column.Nodes {
+		if node.Repetition != schema.Repeated {
+			if definition < c.nodeDefinition[i] {
+				if _, ok := container[node.Name]; !ok {
+					container[node.Name] = nil
+				}
+				return nil
+			}
+			if node.IsLeaf() {
+				container[node.Name] = value
+				return nil
+			}
+			child, ok := container[node.Name].(map[string]any)
+			if !ok {
+				child = make(map[string]any)
+				container[node.Name] = child
+			}
+			container = child
+			continue
+		}
+
+		list, _ := container[node.Name].([]any)
+		if definition < c.nodeDefinition[i] {
+			if list == nil {
+				container[node.Name] = []any{}
+			}
+			return nil
+		}
+
+		// A repetition level above this node's continues the current element,
+		// one equal to it starts a new element and one below it starts a new
+		// list.
+		element := 0
+		switch {
+		case repetition > c.nodeRepetition[i]:
+			element = c.elements[i]
+		case repetition == c.nodeRepetition[i]:
+			element = c.elements[i] + 1
+		}
+		c.elements[i] = element
+
+		switch {
+		case element == len(list):
+			if node.IsLeaf() {
+				list = append(list, value)
+			} else {
+				list = append(list, make(map[string]any))
+			}
+			container[node.Name] = list
+		case element > len(list):
+			return fmt.Errorf("%w: repetition level skips an element", decoder.ErrCorruptData)
+		}
+		if node.IsLeaf() {
+			return nil
+		}
+		child, ok := list[element].(map[string]any)
+		if !ok {
+			return fmt.Errorf("%w: repeated group element is not a group", decoder.ErrCorruptData)
+		}
+		container = child
+	}
+	return nil
+}
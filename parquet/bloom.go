@@ -0,0 +1,80 @@
+package parquet
+
+import (
+	"context"
+
+	"github.com/RichardNooooh/parquet-go/internal/bloom"
+	"github.com/RichardNooooh/parquet-go/internal/file"
+	"github.com/RichardNooooh/parquet-go/internal/stats"
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+// BloomFilter tests whether values might be stored in a column chunk.
+type BloomFilter struct {
+	column *schema.Column
+	filter *bloom.Filter
+}
+
+// MightContain reports whether value might be stored in the column chunk. A
+// false result is certain. Values that cannot be converted to the column's
+// physical type are reported as possibly contained.
+func (f *BloomFilter) MightContain(value any) bool {
+	physical, err := toPhysical(f.column.Leaf, value)
+	if err != nil {
+		return true
+	}
+	hash, ok := bloomHash(f.column.Leaf, physical)
+	return !ok || f.filter.Check(hash)
+}
+
+// BloomFilter loads the bloom filter of a column chunk. It returns nil when
+// the chunk was written without one.
+func (r *ParquetReader) BloomFilter(rowGroup, column int) (*BloomFilter, error) {
+	chunk, err := r.columnChunk(rowGroup, column)
+	if err != nil {
+		return nil, err
+	}
+	decryptor, err := r.decryption.decryptor(rowGroup, column)
+	if err != nil {
+		return nil, err
+	}
+	columnMetadata := chunk.Format().GetMetaData()
+	if columnMetadata == nil {
+		return nil, nil
+	}
+	_, bitset, err := file.GetBloomFilter(context.Background(), r.file, columnMetadata, decryptor)
+	if err != nil || bitset == nil {
+		return nil, err
+	}
+	filter, err := bloom.FromBytes(bitset)
+	if err != nil {
+		return nil, err
+	}
+	return &BloomFilter{column: chunk.Column(), filter: filter}, nil
+}
+
+// bloomHash hashes a physical value for a bloom filter lookup. Booleans are
+// never hashed, and neither are floating point zeros or NaN, whose equal
+// values have different encodings.
+func bloomHash(leaf *schema.SchemaElement, value any) (uint64, bool) {
+	if stats.IsFloat16(leaf) {
+		// Zeros have all bits but the sign cleared.
+		if b := value.([]byte); b[0] == 0 && b[1]&0x7f == 0 || stats.IsNaN(leaf, value) {
+			return 0, false
+		}
+	}
+	switch v := value.(type) {
+	case bool:
+		return 0, false
+	case float32:
+		if v == 0 || v != v {
+			return 0, false
+		}
+	case float64:
+		if v == 0 || v != v {
+			return 0, false
+		}
+	}
+	hash, err := bloom.Hash(leaf.Type, value)
+	return hash, err == nil
+}
@@ -0,0 +1,110 @@
+package parquet
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"testing"
+
+	"github.com/RichardNooooh/parquet-go/internal/bloom"
+	format "github.com/RichardNooooh/parquet-go/internal/format/gen-go/parquet"
+	"github.com/RichardNooooh/parquet-go/internal/thriftio"
+)
+
+// addBloomFilters rewrites the footer of a file to add bloom filters of the
+// "name" column, built from the rows of each row group.
+func addBloomFilters(t *testing.T, data []byte, rows []Row, rowGroupRows int) []byte {
+	t.Helper()
+	fileMetadata := readTestFooter(t, data)
+	footerLength := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	output := bytes.Clone(data[:len(data)-8-footerLength])
+
+	for i, rowGroup := range fileMetadata.RowGroups {
+		filter := bloom.New(256)
+		for _, row := range rows[i*rowGroupRows : (i+1)*rowGroupRows] {
+			if name, ok := row["name"].(string); ok {
+				hash, _ := bloom.Hash(testSchema().Child("name").Type, []byte(name))
+				filter.Insert(hash)
+			}
+		}
+		header := &format.BloomFilterHeader{
+			NumBytes:    int32(len(filter.Bytes())),
+			Algorithm:   &format.BloomFilterAlgorithm{BLOCK: format.NewSplitBlockAlgorithm()},
+			Hash:        &format.BloomFilterHash{XXHASH: format.NewXxHash()},
+			Compression: &format.BloomFilterCompression{UNCOMPRESSED: format.NewUncompressed()},
+		}
+		encoded, err := thriftio.Encode(context.Background(), header)
+		if err != nil {
+			t.Fatalf("unable to encode bloom filter header: %v", err)
+		}
+		offset, length := int64(len(output)), int32(len(encoded)+len(filter.Bytes()))
+		output = append(append(output, encoded...), filter.Bytes()...)
+		rowGroup.Columns[1].MetaData.BloomFilterOffset = &offset
+		rowGroup.Columns[1].MetaData.BloomFilterLength = &length
+	}
+
+	footer, err := thriftio.Encode(context.Background(), fileMetadata)
+	if err != nil {
+		t.Fatalf("unable to encode footer: %v", err)
+	}
+	output = append(output, footer...)
+	output = binary.LittleEndian.AppendUint32(output, uint32(len(footer)))
+	return append(output, parquetMagic...)
+}
+
+func TestBloomFilterMightContain(t *testing.T) {
+	rows := testRows(100)
+	data := addBloomFilters(t, writeTestFile(t, rows, WithRowGroupSize(10)), rows, 10)
+	reader, err := Open(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("unable to open file: %v", err)
+	}
+
+	for i := range reader.GetMeta().NumRowGroups() {
+		filter, err := reader.BloomFilter(i, 1)
+		if err != nil || filter == nil {
+			t.Fatalf("expected a bloom filter for row group %d, got %v", i, err)
+		}
+		for _, row := range rows[i*10 : (i+1)*10] {
+			if name, ok := row["name"].(string); ok && !filter.MightContain(name) {
+				t.Errorf("row group %d: expected %q to be contained", i, name)
+			}
+		}
+	}
+	if filter, err := reader.BloomFilter(0, 0); err != nil || filter != nil {
+		t.Errorf("expected no bloom filter for column id, got %v, %v", filter, err)
+	}
+}
+
+func TestBloomFilterPrunesRowGroups(t *testing.T) {
+	rows := testRows(100)
+	withoutBloom := writeTestFile(t, rows, WithRowGroupSize(10))
+	withBloom := addBloomFilters(t, withoutBloom, rows, 10)
+
+	testcases := map[string]Predicate{
+		"eq": Eq("name", "c"),
+		"in": In("name", "c", "q"),
+	}
+
+	for name, filter := range testcases {
+		t.Run(name, func(t *testing.T) {
+			statsReader, err := Open(bytes.NewReader(withoutBloom), int64(len(withoutBloom)), WithFilter(filter))
+			if err != nil {
+				t.Fatalf("unable to open file: %v", err)
+			}
+			bloomReader, err := Open(bytes.NewReader(withBloom), int64(len(withBloom)), WithFilter(filter))
+			if err != nil {
+				t.Fatalf("unable to open file: %v", err)
+			}
+
+			if bloomReader.PrunedRowGroups() <= statsReader.PrunedRowGroups() {
+				t.Errorf("expected bloom filters to prune more than %d row groups, got %d",
+					statsReader.PrunedRowGroups(), bloomReader.PrunedRowGroups())
+			}
+			expected, got := readAllRows(t, statsReader), readAllRows(t, bloomReader)
+			if len(expected) == 0 || len(got) != len(expected) {
+				t.Errorf("expected %d rows, got %d", len(expected), len(got))
+			}
+		})
+	}
+}
@@ -0,0 +1,370 @@
+package parquet
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/RichardNooooh/parquet-go/internal/compress"
+	"github.com/RichardNooooh/parquet-go/internal/decoder"
+	"github.com/RichardNooooh/parquet-go/internal/encoder"
+	"github.com/RichardNooooh/parquet-go/internal/encryption"
+	"github.com/RichardNooooh/parquet-go/internal/file"
+	format "github.com/RichardNooooh/parquet-go/internal/format/gen-go/parquet"
+	"github.com/RichardNooooh/parquet-go/metadata"
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+var ErrUnsupportedEncoding = errors.New("unsupported encoding")
+
+// columnValues holds the decoded levels and non-null values of a column
+// chunk. Repetition levels are nil for columns that are not repeated.
+type columnValues struct {
+	repetitionLevels []int32
+	definitionLevels []int32
+	values           []any
+	// data holds the BYTE_ARRAY values when their total size is known up
+	// front, and is nil otherwise.
+	data []byte
+	// pages records where each run of consecutively read rows starts, so
+	// that rows can be located when only some pages were read.
+	pages []pageStart
+}
+
+type pageStart struct {
+	row   int64
+	level int
+	value int
+}
+
+// pageDecoder decodes the pages of one column chunk in order, keeping the
+// dictionary page for the data pages that follow it.
+type pageDecoder struct {
+	column *schema.Column
+	codec  format.CompressionCodec
+	// chunkSize is the uncompressed size of the column chunk, which bounds
+	// the uncompressed size of each of its pages.
+	chunkSize int
+	// numValues is the number of levels in the column chunk, which bounds
+	// the levels of each of its pages.
+	numValues  int64
+	dictionary []any
+}
+
+// chunkRead is a byte range of a column chunk to read and decode. Reads that
+// start with a data page record the row it starts with. Encrypted pages also
+// need to know whether the read starts with the dictionary page, and the
+// ordinal of its first data page. Reads of chunks whose pages are not all
+// read are partial.
+type chunkRead struct {
+	file.Range
+	dataPages  bool
+	firstRow   int64
+	dictionary bool
+	page       int
+	partial    bool
+}
+
+// planColumnChunk lists the reads needed for a column chunk. When ranges is
+// not nil and the chunk has an offset index, only the pages overlapping
+// ranges are read.
+func (r *ParquetReader) planColumnChunk(indexes *pageIndexes, column int, ranges rowRanges) ([]chunkRead, error) {
+	chunk := r.meta.RowGroup(indexes.rowGroup).Column(column)
+	if _, err := r.decryption.decryptor(indexes.rowGroup, column); err != nil {
+		return nil, err
+	}
+	columnMetadata := chunk.Format().GetMetaData()
+	if columnMetadata == nil {
+		return nil, fmt.Errorf("column %q has no metadata", chunk.Column().PathString())
+	}
+
+	var offsetIndex *metadata.OffsetIndex
+	if ranges != nil {
+		if offsetIndex = indexes.offsetIndex(column); indexes.err != nil {
+			return nil, indexes.err
+		}
+	}
+	start, length := file.ColumnChunkRange(columnMetadata)
+	if offsetIndex == nil || offsetIndex.NumPages() == 0 {
+		return []chunkRead{{
+			Range:      file.Range{Offset: start, Length: length},
+			dataPages:  true,
+			dictionary: start < columnMetadata.GetDataPageOffset(),
+		}}, nil
+	}
+
+	// Pages before the first data page, such as the dictionary, are always
+	// needed.
+	var reads []chunkRead
+	var numPages int
+	locations := offsetIndex.PageLocations
+	if start < locations[0].Offset {
+		reads = append(reads, chunkRead{Range: file.Range{Offset: start, Length: locations[0].Offset - start}, dictionary: true})
+	}
+	for i, location := range locations {
+		if ranges.overlaps(location.FirstRowIndex, offsetIndex.LastRowIndex(i, indexes.numRows)+1) {
+			reads = append(reads, chunkRead{
+				Range:     file.Range{Offset: location.Offset, Length: int64(location.CompressedPageSize)},
+				dataPages: true,
+				firstRow:  location.FirstRowIndex,
+				page:      i,
+			})
+			numPages++
+		}
+	}
+	if numPages < len(locations) {
+		for i := range reads {
+			reads[i].partial = true
+		}
+	}
+	return reads, nil
+}
+
+// readColumnChunk decodes the pages of a column chunk listed in reads.
+func (r *ParquetReader) readColumnChunk(ctx context.Context, source file.RangeReader, rowGroup, column int, reads []chunkRead) (*columnValues, error) {
+	chunk := r.meta.RowGroup(rowGroup).Column(column)
+	decryptor, err := r.decryption.decryptor(rowGroup, column)
+	if err != nil {
+		return nil, err
+	}
+	pageDecoder := &pageDecoder{
+		column:    chunk.Column(),
+		codec:     chunk.Format().GetMetaData().GetCodec(),
+		chunkSize: int(min(chunk.TotalUncompressedSize(), math.MaxInt32)),
+		numValues: chunk.NumValues(),
+	}
+	values := &columnValues{}
+	if r.config.preallocate && !slices.ContainsFunc(reads, func(read chunkRead) bool { return read.partial }) {
+		preallocateColumnValues(chunk, values)
+	}
+	for _, read := range reads {
+		var pages []*file.Page
+		if decryptor == nil {
+			pages, err = file.ReadPages(ctx, source, read.Offset, read.Length)
+		} else {
+			pages, err = readEncryptedPages(ctx, source, read, decryptor)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if read.dataPages {
+			values.pages = append(values.pages, pageStart{
+				row:   read.firstRow,
+				level: len(values.definitionLevels),
+				value: len(values.values),
+			})
+		}
+		for _, page := range pages {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			if err := pageDecoder.decode(page, values); err != nil {
+				return nil, fmt.Errorf("column %q, page at offset %d: %w", chunk.Column().PathString(), page.Offset, err)
+			}
+		}
+	}
+	return values, nil
+}
+
+func readEncryptedPages(ctx context.Context, source file.RangeReader, read chunkRead, decryptor *encryption.Decryptor) ([]*file.Page, error) {
+	data, err := source.ReadRange(ctx, read.Offset, read.Length)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read pages: %w", err)
+	}
+	return file.DecryptPages(ctx, data, read.Offset, decryptor, read.dictionary, read.page)
+}
+
+func (d *pageDecoder) decode(page *file.Page, out *columnValues) error {
+	header := page.Header
+	switch header.GetType() {
+	case format.PageType_DICTIONARY_PAGE:
+		return d.decodeDictionaryPage(page)
+	case format.PageType_DATA_PAGE:
+		return d.decodeDataPage(page, out)
+	case format.PageType_DATA_PAGE_V2:
+		return d.decodeDataPageV2(page, out)
+	}
+	return nil
+}
+
+func (d *pageDecoder) decodeDictionaryPage(page *file.Page) error {
+	dictionaryHeader := page.Header.GetDictionaryPageHeader()
+	if dictionaryHeader == nil {
+		return fmt.Errorf("%w: dictionary page without header", decoder.ErrCorruptData)
+	}
+	body, err := compress.Decompress(d.codec, page.Data, int(page.Header.GetUncompressedPageSize()), d.chunkSize)
+	if err != nil {
+		return err
+	}
+	leaf := d.column.Leaf
+	d.dictionary, err = decoder.DecodePlain(leaf.Type, leaf.TypeLength, body, int(dictionaryHeader.GetNumValues()))
+	return err
+}
+
+func (d *pageDecoder) decodeDataPage(page *file.Page, out *columnValues) error {
+	dataHeader := page.Header.GetDataPageHeader()
+	if dataHeader == nil {
+		return fmt.Errorf("%w: data page without header", decoder.ErrCorruptData)
+	}
+	body, err := compress.Decompress(d.codec, page.Data, int(page.Header.GetUncompressedPageSize()), d.chunkSize)
+	if err != nil {
+		return err
+	}
+
+	numValues, err := d.pageValues(dataHeader.GetNumValues())
+	if err != nil {
+		return err
+	}
+	repetitionLevels, n, err := decodeLevels(dataHeader.GetRepetitionLevelEncoding(), body, d.column.MaxRepetitionLevel, numValues)
+	if err != nil {
+		return fmt.Errorf("repetition levels: %w", err)
+	}
+	body = body[n:]
+	definitionLevels, n, err := decodeLevels(dataHeader.GetDefinitionLevelEncoding(), body, d.column.MaxDefinitionLevel, numValues)
+	if err != nil {
+		return fmt.Errorf("definition levels: %w", err)
+	}
+	body = body[n:]
+
+	return d.appendPage(out, repetitionLevels, definitionLevels, dataHeader.GetEncoding(), body)
+}
+
+func (d *pageDecoder) decodeDataPageV2(page *file.Page, out *columnValues) error {
+	dataHeader := page.Header.GetDataPageHeaderV2()
+	if dataHeader == nil {
+		return fmt.Errorf("%w: data page without header", decoder.ErrCorruptData)
+	}
+	repetitionLength := int(dataHeader.GetRepetitionLevelsByteLength())
+	definitionLength := int(dataHeader.GetDefinitionLevelsByteLength())
+	if repetitionLength < 0 || definitionLength < 0 || repetitionLength+definitionLength > len(page.Data) {
+		return fmt.Errorf("%w: level lengths exceed the page", decoder.ErrCorruptData)
+	}
+
+	numValues, err := d.pageValues(dataHeader.GetNumValues())
+	if err != nil {
+		return err
+	}
+
+	// Levels of v2 pages are never compressed and have no length prefix.
+	levels := page.Data[:repetitionLength+definitionLength]
+	repetitionLevels, err := decodeLevelsV2(levels[:repetitionLength], d.column.MaxRepetitionLevel, numValues)
+	if err != nil {
+		return fmt.Errorf("repetition levels: %w", err)
+	}
+	definitionLevels, err := decodeLevelsV2(levels[repetitionLength:], d.column.MaxDefinitionLevel, numValues)
+	if err != nil {
+		return fmt.Errorf("definition levels: %w", err)
+	}
+
+	body := page.Data[len(levels):]
+	if dataHeader.IsCompressed {
+		uncompressedSize := int(page.Header.GetUncompressedPageSize()) - len(levels)
+		if body, err = compress.Decompress(d.codec, body, uncompressedSize, d.chunkSize); err != nil {
+			return err
+		}
+	}
+	return d.appendPage(out, repetitionLevels, definitionLevels, dataHeader.GetEncoding(), body)
+}
+
+// pageValues checks the number of levels of a data page against its column
+// chunk before anything is allocated for them.
+func (d *pageDecoder) pageValues(numValues int32) (int, error) {
+	if numValues < 0 || int64(numValues) > d.numValues {
+		return 0, fmt.Errorf("%w: page has %d values, column chunk has %d", decoder.ErrCorruptData, numValues, d.numValues)
+	}
+	return int(numValues), nil
+}
+
+func (d *pageDecoder) appendPage(out *columnValues, repetitionLevels, definitionLevels []int32, encoding format.Encoding, body []byte) error {
+	maxDef := int32(d.column.MaxDefinitionLevel)
+	numNonNull := 0
+	for _, def := range definitionLevels {
+		if def == maxDef {
+			numNonNull++
+		}
+	}
+
+	values, err := d.decodeValues(encoding, body, numNonNull)
+	if err != nil {
+		return err
+	}
+	if out.data != nil {
+		for i, value := range values {
+			start := len(out.data)
+			out.data = append(out.data, value.([]byte)...)
+			values[i] = out.data[start:len(out.data):len(out.data)]
+		}
+	}
+	if d.column.MaxRepetitionLevel > 0 {
+		out.repetitionLevels = append(out.repetitionLevels, repetitionLevels...)
+	}
+	out.definitionLevels = append(out.definitionLevels, definitionLevels...)
+	out.values = append(out.values, values...)
+	return nil
+}
+
+func (d *pageDecoder) decodeValues(encoding format.Encoding, body []byte, numValues int) ([]any, error) {
+	leaf := d.column.Leaf
+	switch encoding {
+	case format.Encoding_PLAIN:
+		return decoder.DecodePlain(leaf.Type, leaf.TypeLength, body, numValues)
+	case format.Encoding_PLAIN_DICTIONARY, format.Encoding_RLE_DICTIONARY:
+		if d.dictionary == nil {
+			return nil, fmt.Errorf("%w: dictionary encoded page without a dictionary", decoder.ErrCorruptData)
+		}
+		if numValues == 0 {
+			return nil, nil
+		}
+		if len(body) == 0 {
+			return nil, fmt.Errorf("%w: missing dictionary index bit width", decoder.ErrCorruptData)
+		}
+		indices, err := decoder.DecodeRLE(body[1:], int(body[0]), numValues)
+		if err != nil {
+			return nil, err
+		}
+		values := make([]any, numValues)
+		for i, index := range indices {
+			if index < 0 || int(index) >= len(d.dictionary) {
+				return nil, fmt.Errorf("%w: dictionary index %d out of range", decoder.ErrCorruptData, index)
+			}
+			values[i] = d.dictionary[index]
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("%w: %v", ErrUnsupportedEncoding, encoding)
+}
+
+// decodeLevels decodes the levels at the start of a v1 data page and returns
+// how many bytes they took.
+func decodeLevels(encoding format.Encoding, body []byte, maxLevel int16, numValues int) ([]int32, int, error) {
+	if maxLevel == 0 {
+		return make([]int32, numValues), 0, nil
+	}
+
+	bitWidth := encoder.BitWidth(int(maxLevel))
+	switch encoding {
+	case format.Encoding_RLE:
+		if len(body) < 4 {
+			return nil, 0, fmt.Errorf("%w: missing level length", decoder.ErrCorruptData)
+		}
+		length := int(binary.LittleEndian.Uint32(body))
+		if length < 0 || length > len(body)-4 {
+			return nil, 0, fmt.Errorf("%w: level length %d exceeds the page", decoder.ErrCorruptData, length)
+		}
+		levels, err := decoder.DecodeRLE(body[4:4+length], bitWidth, numValues)
+		return levels, 4 + length, err
+	case format.Encoding_BIT_PACKED:
+		return decoder.DecodeBitPacked(body, bitWidth, numValues)
+	}
+	return nil, 0, fmt.Errorf("%w: %v levels", ErrUnsupportedEncoding, encoding)
+}
+
+func decodeLevelsV2(data []byte, maxLevel int16, numValues int) ([]int32, error) {
+	if maxLevel == 0 {
+		return make([]int32, numValues), nil
+	}
+	return decoder.DecodeRLE(data, encoder.BitWidth(int(maxLevel)), numValues)
+}
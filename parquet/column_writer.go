@@ -0,0 +1,253 @@
+package parquet
+
+import (
+	"context"
+	"encoding/binary"
+
+	"github.com/RichardNooooh/parquet-go/internal/bloom"
+	"github.com/RichardNooooh/parquet-go/internal/compress"
+	"github.com/RichardNooooh/parquet-go/internal/encoder"
+	"github.com/RichardNooooh/parquet-go/internal/encryption"
+	format "github.com/RichardNooooh/parquet-go/internal/format/gen-go/parquet"
+	"github.com/RichardNooooh/parquet-go/internal/stats"
+	"github.com/RichardNooooh/parquet-go/internal/thriftio"
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+// encodedChunk is a column chunk serialized into memory. Offsets in its
+// metadata are relative to the start of data until the chunk is placed in
+// the file.
+type encodedChunk struct {
+	data        []byte
+	metadata    *format.ColumnMetaData
+	columnIndex *format.ColumnIndex
+	offsetIndex *format.OffsetIndex
+	bloomFilter *bloom.Filter
+}
+
+// page is the range of a column buffer that goes into one data page.
+type page struct {
+	levelStart, levelEnd int
+	valueStart, valueEnd int
+}
+
+// encodeColumnChunk encodes the data pages of a column chunk, encrypting
+// them and their headers when encryptor is not nil.
+func (w *ParquetWriter) encodeColumnChunk(ctx context.Context, buffer *columnBuffer, encryptor *encryption.Encryptor) (*encodedChunk, error) {
+	column := buffer.column
+	statsOptions := stats.Options{
+		DistinctCount:  w.config.distinctCount,
+		TruncateLength: w.config.truncateLength,
+	}
+	chunkStats := stats.NewAccumulator(column.Leaf, statsOptions)
+	pageStats := stats.NewAccumulator(column.Leaf, statsOptions)
+
+	var pageIndex *pageIndexBuilder
+	if w.config.pageIndex {
+		pageIndex = newPageIndexBuilder(column.Leaf)
+	}
+
+	chunk := &encodedChunk{}
+	sizeStats := newSizeStatistics(column)
+	var uncompressedSize int64
+	var firstRowIndex int64
+	for i, p := range w.splitPages(buffer) {
+		pageStats.Reset()
+		for _, value := range buffer.values[p.valueStart:p.valueEnd] {
+			pageStats.Add(value)
+			chunkStats.Add(value)
+		}
+		nulls := int64((p.levelEnd - p.levelStart) - (p.valueEnd - p.valueStart))
+		pageStats.AddNulls(nulls)
+		chunkStats.AddNulls(nulls)
+		pageSizeStats := pageSizeStatistics(buffer, p)
+		addSizeStatistics(sizeStats, pageSizeStats)
+
+		body, err := encodeDataPage(buffer, p)
+		if err != nil {
+			return nil, err
+		}
+
+		compressed, err := compress.Compress(w.config.codec, body)
+		if err != nil {
+			return nil, err
+		}
+		if encryptor != nil {
+			if compressed, err = encryptor.Encrypt(encryption.DataPage, i, compressed); err != nil {
+				return nil, err
+			}
+		}
+
+		numValues := p.levelEnd - p.levelStart
+		statistics := pageStats.Statistics()
+		header := format.NewPageHeader()
+		header.Type = format.PageType_DATA_PAGE
+		header.UncompressedPageSize = int32(len(body))
+		header.CompressedPageSize = int32(len(compressed))
+		header.DataPageHeader = &format.DataPageHeader{
+			NumValues:               int32(numValues),
+			Encoding:                format.Encoding_PLAIN,
+			DefinitionLevelEncoding: format.Encoding_RLE,
+			RepetitionLevelEncoding: format.Encoding_RLE,
+			Statistics:              statistics,
+		}
+		headerBytes, err := thriftio.Encode(ctx, header)
+		if err != nil {
+			return nil, err
+		}
+		if encryptor != nil {
+			if headerBytes, err = encryptor.Encrypt(encryption.DataPageHeader, i, headerBytes); err != nil {
+				return nil, err
+			}
+		}
+
+		if pageIndex != nil {
+			location := &format.PageLocation{
+				Offset:             int64(len(chunk.data)),
+				CompressedPageSize: int32(len(headerBytes) + len(compressed)),
+				FirstRowIndex:      firstRowIndex,
+			}
+			pageIndex.addPage(pageStats, statistics, pageSizeStats, location, numValues)
+		}
+		firstRowIndex += countRows(buffer.repetitionLevels[p.levelStart:p.levelEnd])
+
+		chunk.data = append(chunk.data, headerBytes...)
+		chunk.data = append(chunk.data, compressed...)
+		uncompressedSize += int64(len(headerBytes) + len(body))
+	}
+
+	encodings := []format.Encoding{format.Encoding_PLAIN}
+	if column.MaxDefinitionLevel > 0 || column.MaxRepetitionLevel > 0 {
+		encodings = append(encodings, format.Encoding_RLE)
+	}
+	chunk.metadata = &format.ColumnMetaData{
+		Type:                  format.Type(column.Leaf.Type),
+		Encodings:             encodings,
+		PathInSchema:          column.Path,
+		Codec:                 w.config.codec,
+		NumValues:             int64(len(buffer.definitionLevels)),
+		TotalUncompressedSize: uncompressedSize,
+		TotalCompressedSize:   int64(len(chunk.data)),
+		DataPageOffset:        0,
+		Statistics:            chunkStats.Statistics(),
+		SizeStatistics:        sizeStats,
+	}
+	if isGeospatial(column.Leaf) {
+		chunk.metadata.GeospatialStatistics = geospatialStatistics(column.Leaf, buffer.values)
+	}
+	if pageIndex != nil {
+		chunk.columnIndex, chunk.offsetIndex = pageIndex.build()
+	}
+	if config, ok := w.config.bloomFilters[column.PathString()]; ok {
+		chunk.bloomFilter = buildBloomFilter(column.Leaf.Type, buffer.values, config)
+	}
+	return chunk, nil
+}
+
+// buildBloomFilter inserts the non-null values of a column chunk into a
+// filter sized for the configured or the observed number of distinct values.
+func buildBloomFilter(typ schema.Type, values []any, config bloomFilterConfig) *bloom.Filter {
+	hashes := make(map[uint64]struct{})
+	for _, value := range values {
+		if hash, err := bloom.Hash(typ, value); err == nil {
+			hashes[hash] = struct{}{}
+		}
+	}
+	ndv := config.ndv
+	if ndv <= 0 {
+		ndv = int64(len(hashes))
+	}
+	filter := bloom.New(bloom.OptimalNumBytes(ndv, config.fpp))
+	for hash := range hashes {
+		filter.Insert(hash)
+	}
+	return filter
+}
+
+// countRows counts the rows started in a run of repetition levels.
+func countRows(repetitionLevels []int32) int64 {
+	var rows int64
+	for _, rep := range repetitionLevels {
+		if rep == 0 {
+			rows++
+		}
+	}
+	return rows
+}
+
+// splitPages cuts the buffer into pages of roughly the configured size. Pages
+// only end where a new row starts so that no row spans two pages, and hold
+// at least one row.
+func (w *ParquetWriter) splitPages(buffer *columnBuffer) []page {
+	var pages []page
+	current := page{}
+	size := 0
+	value := 0
+	maxDef := int32(buffer.column.MaxDefinitionLevel)
+	for level := range buffer.definitionLevels {
+		if size >= w.config.pageSize && level > current.levelStart && buffer.repetitionLevels[level] == 0 {
+			current.levelEnd, current.valueEnd = level, value
+			pages = append(pages, current)
+			current = page{levelStart: level, valueStart: value}
+			size = 0
+		}
+		size++
+		if buffer.definitionLevels[level] == maxDef {
+			size += estimatedSize(buffer.values[value])
+			value++
+		}
+	}
+	current.levelEnd, current.valueEnd = len(buffer.definitionLevels), value
+	if current.levelEnd > current.levelStart || len(pages) == 0 {
+		pages = append(pages, current)
+	}
+	return pages
+}
+
+func estimatedSize(value any) int {
+	switch v := value.(type) {
+	case bool:
+		return 1
+	case int32, float32:
+		return 4
+	case int64, float64:
+		return 8
+	case [12]byte:
+		return 12
+	case []byte:
+		return 4 + len(v)
+	}
+	return 0
+}
+
+// encodeDataPage encodes the body of a v1 data page: repetition levels,
+// definition levels and PLAIN values.
+func encodeDataPage(buffer *columnBuffer, p page) ([]byte, error) {
+	var body []byte
+	column := buffer.column
+	if column.MaxRepetitionLevel > 0 {
+		body = appendLevels(body, buffer.repetitionLevels[p.levelStart:p.levelEnd], int(column.MaxRepetitionLevel))
+	}
+	if column.MaxDefinitionLevel > 0 {
+		body = appendLevels(body, buffer.definitionLevels[p.levelStart:p.levelEnd], int(column.MaxDefinitionLevel))
+	}
+	values, err := encoder.EncodePlain(column.Leaf.Type, buffer.values[p.valueStart:p.valueEnd])
+	if err != nil {
+		return nil, err
+	}
+	return append(body, values...), nil
+}
+
+func appendLevels(body []byte, levels []int32, maxLevel int) []byte {
+	encoded := encoder.EncodeRLE(levels, encoder.BitWidth(maxLevel))
+	body = binary.LittleEndian.AppendUint32(body, uint32(len(encoded)))
+	return append(body, encoded...)
+}
+
+func typeDefinedColumnOrders(columns []*schema.Column) []*format.ColumnOrder {
+	orders := make([]*format.ColumnOrder, len(columns))
+	for i := range orders {
+		orders[i] = &format.ColumnOrder{TYPE_ORDER: format.NewTypeDefinedOrder()}
+	}
+	return orders
+}
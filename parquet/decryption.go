@@ -0,0 +1,246 @@
+package parquet
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/RichardNooooh/parquet-go/internal/encryption"
+	"github.com/RichardNooooh/parquet-go/internal/file"
+	format "github.com/RichardNooooh/parquet-go/internal/format/gen-go/parquet"
+	"github.com/RichardNooooh/parquet-go/internal/thriftio"
+)
+
+var (
+	// ErrMissingKey is returned when reading an encrypted footer or column
+	// without its key.
+	ErrMissingKey = errors.New("missing decryption key")
+	// ErrSignature is returned when a plaintext footer does not match its
+	// signature, which means that the footer was tampered with.
+	ErrSignature = encryption.ErrSignature
+)
+
+// KeyRetriever returns the key identified by key metadata stored in an
+// encrypted file.
+type KeyRetriever func(keyMetadata []byte) ([]byte, error)
+
+// FileEncryption describes how a file is encrypted.
+type FileEncryption struct {
+	Algorithm EncryptionAlgorithm
+	// EncryptedFooter reports whether the footer is encrypted, rather than
+	// stored in plaintext and signed.
+	EncryptedFooter bool
+	// FooterKeyMetadata is the key metadata of the footer key, if any.
+	FooterKeyMetadata []byte
+}
+
+// Encryption describes how the file is encrypted, or returns nil when it is
+// not.
+func (r *ParquetReader) Encryption() *FileEncryption {
+	if r.decryption == nil {
+		return nil
+	}
+	algorithm := AesGcm
+	if r.decryption.algorithm == encryption.AesGcmCtrV1 {
+		algorithm = AesGcmCtr
+	}
+	return &FileEncryption{
+		Algorithm:         algorithm,
+		EncryptedFooter:   r.decryption.encryptedFooter,
+		FooterKeyMetadata: r.decryption.footerKeyMetadata,
+	}
+}
+
+// fileDecryption decrypts the modules of an encrypted file.
+type fileDecryption struct {
+	algorithm         encryption.Algorithm
+	fileAAD           []byte
+	encryptedFooter   bool
+	footerKeyMetadata []byte
+	// decryptors holds the decryptor of each encrypted column chunk by row
+	// group, and errors the reason why a chunk cannot be decrypted.
+	decryptors [][]*encryption.Decryptor
+	errors     [][]error
+}
+
+// readFooter reads and decodes the footer of a file, decrypting it when it
+// is encrypted and verifying its signature when it is signed. The returned
+// decryption is nil for files that are not encrypted.
+func readFooter(ctx context.Context, reader *file.FileReader, config *readerConfig) (*format.FileMetaData, *fileDecryption, error) {
+	footer, err := file.ReadFooter(ctx, reader, config.footerRead)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if footer.Encrypted {
+		cryptoMetadata := format.NewFileCryptoMetaData()
+		n, err := thriftio.Decode(ctx, footer.Data, cryptoMetadata)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to read file crypto metadata: %w", err)
+		}
+		decryption, err := newFileDecryption(cryptoMetadata.GetEncryptionAlgorithm(), config)
+		if err != nil {
+			return nil, nil, err
+		}
+		decryption.encryptedFooter, decryption.footerKeyMetadata = true, cryptoMetadata.GetKeyMetadata()
+		footerKey, err := config.key(config.footerKey, cryptoMetadata.GetKeyMetadata())
+		if err != nil {
+			return nil, nil, fmt.Errorf("footer: %w", err)
+		}
+		footerDecryptor := &encryption.Decryptor{Algorithm: decryption.algorithm, Key: footerKey, FileAAD: decryption.fileAAD}
+		data, _, err := footerDecryptor.Decrypt(encryption.Footer, 0, footer.Data[n:])
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to decrypt footer: %w", err)
+		}
+		fileMetadata, err := thriftio.DecodeFileMetadata(ctx, data, int64(len(data)))
+		if err != nil {
+			return nil, nil, err
+		}
+		return fileMetadata, decryption, decryption.decryptColumnMetadata(ctx, fileMetadata, footerKey, config)
+	}
+
+	fileMetadata := format.NewFileMetaData()
+	n, err := thriftio.Decode(ctx, footer.Data, fileMetadata)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode thrift metadata: %w", err)
+	}
+	if !fileMetadata.IsSetEncryptionAlgorithm() {
+		return fileMetadata, nil, nil
+	}
+
+	// A plaintext footer of an encrypted file is followed by its signature,
+	// which can only be checked with the footer key.
+	decryption, err := newFileDecryption(fileMetadata.GetEncryptionAlgorithm(), config)
+	if err != nil {
+		return nil, nil, err
+	}
+	decryption.footerKeyMetadata = fileMetadata.GetFooterSigningKeyMetadata()
+	footerKey, err := config.key(config.footerKey, fileMetadata.GetFooterSigningKeyMetadata())
+	if errors.Is(err, ErrMissingKey) && config.footerKey == nil && config.keyRetriever == nil {
+		return fileMetadata, decryption, decryption.decryptColumnMetadata(ctx, fileMetadata, nil, config)
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("footer: %w", err)
+	}
+	aad, err := encryption.ModuleAAD(decryption.fileAAD, encryption.Footer, 0, 0, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := encryption.Verify(footerKey, footer.Data[:n], footer.Data[n:], aad); err != nil {
+		return nil, nil, err
+	}
+	return fileMetadata, decryption, decryption.decryptColumnMetadata(ctx, fileMetadata, footerKey, config)
+}
+
+// newFileDecryption reads the cipher and the AAD of a file. The AAD prefix
+// stored in the file, if any, must match the one given to the reader.
+func newFileDecryption(algorithm *format.EncryptionAlgorithm, config *readerConfig) (*fileDecryption, error) {
+	var prefix, fileUnique []byte
+	var supplyPrefix bool
+	decryption := &fileDecryption{}
+	switch {
+	case algorithm.IsSetAES_GCM_V1():
+		gcm := algorithm.GetAES_GCM_V1()
+		prefix, fileUnique, supplyPrefix = gcm.GetAadPrefix(), gcm.GetAadFileUnique(), gcm.GetSupplyAadPrefix()
+		decryption.algorithm = encryption.AesGcmV1
+	case algorithm.IsSetAES_GCM_CTR_V1():
+		ctr := algorithm.GetAES_GCM_CTR_V1()
+		prefix, fileUnique, supplyPrefix = ctr.GetAadPrefix(), ctr.GetAadFileUnique(), ctr.GetSupplyAadPrefix()
+		decryption.algorithm = encryption.AesGcmCtrV1
+	default:
+		return nil, fmt.Errorf("unsupported encryption algorithm %v", algorithm)
+	}
+
+	switch {
+	case config.aadPrefix != nil:
+		if prefix != nil && !bytes.Equal(prefix, config.aadPrefix) {
+			return nil, errors.New("AAD prefix does not match the one stored in the file")
+		}
+		prefix = config.aadPrefix
+	case supplyPrefix:
+		return nil, errors.New("file was written without its AAD prefix, which must be supplied with WithAADPrefix")
+	}
+	decryption.fileAAD = append(prefix[:len(prefix):len(prefix)], fileUnique...)
+	return decryption, nil
+}
+
+// decryptColumnMetadata finds the key of every encrypted column chunk, and
+// replaces the metadata of the chunks that carry it encrypted with its
+// decrypted version. Chunks without an available key keep the plaintext
+// metadata of the file, if any, and fail when read.
+func (d *fileDecryption) decryptColumnMetadata(ctx context.Context, fileMetadata *format.FileMetaData, footerKey []byte, config *readerConfig) error {
+	columnKeys := make(map[string][]byte)
+	d.decryptors = make([][]*encryption.Decryptor, len(fileMetadata.GetRowGroups()))
+	d.errors = make([][]error, len(fileMetadata.GetRowGroups()))
+	for i, rowGroup := range fileMetadata.GetRowGroups() {
+		d.decryptors[i] = make([]*encryption.Decryptor, len(rowGroup.GetColumns()))
+		d.errors[i] = make([]error, len(rowGroup.GetColumns()))
+		for j, chunk := range rowGroup.GetColumns() {
+			cryptoMetadata := chunk.GetCryptoMetadata()
+			if cryptoMetadata == nil {
+				continue
+			}
+
+			key := footerKey
+			var err error
+			if columnKey := cryptoMetadata.GetENCRYPTION_WITH_COLUMN_KEY(); columnKey != nil {
+				path := strings.Join(columnKey.GetPathInSchema(), ".")
+				keyMetadata := columnKey.GetKeyMetadata()
+				cacheKey := path + "\x00" + string(keyMetadata)
+				if key = columnKeys[cacheKey]; key == nil {
+					if key, err = config.key(config.columnKeys[path], keyMetadata); err == nil {
+						columnKeys[cacheKey] = key
+					}
+				}
+				if err != nil {
+					d.errors[i][j] = fmt.Errorf("column %q: %w", path, err)
+					continue
+				}
+			} else if key == nil {
+				d.errors[i][j] = fmt.Errorf("column %d: footer key: %w", j, ErrMissingKey)
+				continue
+			}
+
+			decryptor := &encryption.Decryptor{Algorithm: d.algorithm, Key: key, FileAAD: d.fileAAD, RowGroup: i, Column: j}
+			d.decryptors[i][j] = decryptor
+			if chunk.IsSetEncryptedColumnMetadata() {
+				data, _, err := decryptor.Decrypt(encryption.ColumnMetaData, 0, chunk.GetEncryptedColumnMetadata())
+				if err != nil {
+					return fmt.Errorf("row group %d, column %d: unable to decrypt column metadata: %w", i, j, err)
+				}
+				columnMetadata := format.NewColumnMetaData()
+				if _, err := thriftio.Decode(ctx, data, columnMetadata); err != nil {
+					return fmt.Errorf("row group %d, column %d: unable to read column metadata: %w", i, j, err)
+				}
+				chunk.MetaData = columnMetadata
+			}
+		}
+	}
+	return nil
+}
+
+// decryptor returns the decryptor of a column chunk, or nil when the chunk is
+// not encrypted.
+func (d *fileDecryption) decryptor(rowGroup, column int) (*encryption.Decryptor, error) {
+	if d == nil {
+		return nil, nil
+	}
+	return d.decryptors[rowGroup][column], d.errors[rowGroup][column]
+}
+
+// key returns the key given to the reader, or retrieves it from its key
+// metadata.
+func (c *readerConfig) key(key, keyMetadata []byte) ([]byte, error) {
+	if key != nil {
+		return key, nil
+	}
+	if c.keyRetriever == nil {
+		return nil, ErrMissingKey
+	}
+	key, err := c.keyRetriever(keyMetadata)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMissingKey, err)
+	}
+	return key, nil
+}
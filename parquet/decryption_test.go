@@ -0,0 +1,522 @@
+package parquet
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/RichardNooooh/parquet-go/internal/encryption"
+	"github.com/RichardNooooh/parquet-go/internal/file"
+	format "github.com/RichardNooooh/parquet-go/internal/format/gen-go/parquet"
+	"github.com/RichardNooooh/parquet-go/internal/thriftio"
+	"github.com/RichardNooooh/parquet-go/metadata"
+	thrift "github.com/apache/thrift/lib/go/thrift"
+)
+
+var (
+	testFooterKey = []byte("0123456789012345")
+	testNameKey   = []byte("1234567890123450")
+	testTagsKey   = []byte("1234567890123451")
+)
+
+// testEncryption describes how encryptTestFile encrypts a file. Columns
+// missing from columns stay in plaintext, and columns with a nil key are
+// encrypted with the footer key.
+type testEncryption struct {
+	ctr               bool
+	plaintextFooter   bool
+	footerKeyMetadata []byte
+	columns           map[string]testColumnKey
+	aadPrefix         []byte
+	storeAADPrefix    bool
+}
+
+type testColumnKey struct {
+	key, metadata []byte
+}
+
+// encryptTestFile rewrites a file without page indexes or bloom filters with
+// its pages, column metadata and footer encrypted with testFooterKey and the
+// given column keys.
+func encryptTestFile(t *testing.T, data []byte, settings testEncryption) []byte {
+	t.Helper()
+	ctx := context.Background()
+	reader := file.NewReader(bytes.NewReader(data), int64(len(data)))
+	fileMetadata := readTestFooter(t, data)
+	meta, err := metadata.NewFileMeta(fileMetadata)
+	if err != nil {
+		t.Fatalf("unable to read footer: %v", err)
+	}
+
+	nonce := bytes.Repeat([]byte{7}, encryption.NonceLength)
+	fileUnique := []byte("unique")
+	fileAAD := append(bytes.Clone(settings.aadPrefix), fileUnique...)
+	encrypt := func(key []byte, module encryption.ModuleType, rowGroup, column, page int, plaintext []byte) []byte {
+		if settings.ctr && (module == encryption.DataPage || module == encryption.DictionaryPage) {
+			ciphertext, err := encryption.EncryptCTR(key, nonce, plaintext)
+			if err != nil {
+				t.Fatalf("unable to encrypt: %v", err)
+			}
+			return ciphertext
+		}
+		aad, err := encryption.ModuleAAD(fileAAD, module, rowGroup, column, page)
+		if err != nil {
+			t.Fatalf("unable to build AAD: %v", err)
+		}
+		ciphertext, err := encryption.EncryptGCM(key, nonce, plaintext, aad)
+		if err != nil {
+			t.Fatalf("unable to encrypt: %v", err)
+		}
+		return ciphertext
+	}
+	var out bytes.Buffer
+	if settings.plaintextFooter {
+		out.WriteString("PAR1")
+	} else {
+		out.WriteString("PARE")
+	}
+	for i, rowGroup := range fileMetadata.RowGroups {
+		for j, chunk := range rowGroup.Columns {
+			columnMetadata := chunk.MetaData
+			offset, length := file.ColumnChunkRange(columnMetadata)
+			start := int64(out.Len())
+			if j == 0 {
+				rowGroup.FileOffset = &start
+			}
+			chunk.FileOffset = start
+			setting, encrypted := settings.columns[meta.Columns()[j].PathString()]
+			if !encrypted {
+				out.Write(data[offset : offset+length])
+				columnMetadata.DataPageOffset += start - offset
+				if columnMetadata.DictionaryPageOffset != nil {
+					dictionaryOffset := *columnMetadata.DictionaryPageOffset + start - offset
+					columnMetadata.DictionaryPageOffset = &dictionaryOffset
+				}
+				continue
+			}
+
+			key := setting.key
+			if key == nil {
+				key = testFooterKey
+			}
+			pages, err := file.GetPages(ctx, reader, columnMetadata)
+			if err != nil {
+				t.Fatalf("unable to read pages: %v", err)
+			}
+			page := 0
+			for _, p := range pages {
+				headerModule, pageModule := encryption.DataPageHeader, encryption.DataPage
+				pageOffset := int64(out.Len())
+				if p.Header.GetType() == format.PageType_DICTIONARY_PAGE {
+					headerModule, pageModule = encryption.DictionaryPageHeader, encryption.DictionaryPage
+					columnMetadata.DictionaryPageOffset = &pageOffset
+				} else if page == 0 {
+					columnMetadata.DataPageOffset = pageOffset
+				}
+				body := encrypt(key, pageModule, i, j, page, p.Data)
+				header := *p.Header
+				header.CompressedPageSize = int32(len(body))
+				out.Write(encrypt(key, headerModule, i, j, page, encodeTestStruct(t, &header)))
+				out.Write(body)
+				if pageModule == encryption.DataPage {
+					page++
+				}
+			}
+			columnMetadata.TotalCompressedSize = int64(out.Len()) - start
+
+			chunk.CryptoMetadata = &format.ColumnCryptoMetaData{ENCRYPTION_WITH_FOOTER_KEY: &format.EncryptionWithFooterKey{}}
+			if setting.key != nil {
+				chunk.CryptoMetadata = &format.ColumnCryptoMetaData{ENCRYPTION_WITH_COLUMN_KEY: &format.EncryptionWithColumnKey{
+					PathInSchema: meta.Columns()[j].Path,
+					KeyMetadata:  setting.metadata,
+				}}
+			}
+			if setting.key != nil || settings.plaintextFooter {
+				chunk.EncryptedColumnMetadata = encrypt(key, encryption.ColumnMetaData, i, j, 0, encodeTestStruct(t, columnMetadata))
+				chunk.MetaData = nil
+				if settings.plaintextFooter {
+					stripped := *columnMetadata
+					stripped.Statistics = nil
+					chunk.MetaData = &stripped
+				}
+			}
+		}
+	}
+
+	aesGcm := &format.AesGcmV1{AadFileUnique: fileUnique}
+	if settings.storeAADPrefix {
+		aesGcm.AadPrefix = settings.aadPrefix
+	} else if settings.aadPrefix != nil {
+		supply := true
+		aesGcm.SupplyAadPrefix = &supply
+	}
+	algorithm := &format.EncryptionAlgorithm{AES_GCM_V1: aesGcm}
+	if settings.ctr {
+		algorithm = &format.EncryptionAlgorithm{AES_GCM_CTR_V1: &format.AesGcmCtrV1{
+			AadPrefix:       aesGcm.AadPrefix,
+			AadFileUnique:   aesGcm.AadFileUnique,
+			SupplyAadPrefix: aesGcm.SupplyAadPrefix,
+		}}
+	}
+
+	footerStart := out.Len()
+	if settings.plaintextFooter {
+		fileMetadata.EncryptionAlgorithm = algorithm
+		fileMetadata.FooterSigningKeyMetadata = settings.footerKeyMetadata
+		footer := encodeTestStruct(t, fileMetadata)
+		signature, err := encryption.Sign(testFooterKey, nonce, footer, append(bytes.Clone(fileAAD), byte(encryption.Footer)))
+		if err != nil {
+			t.Fatalf("unable to sign footer: %v", err)
+		}
+		out.Write(footer)
+		out.Write(signature)
+	} else {
+		out.Write(encodeTestStruct(t, &format.FileCryptoMetaData{EncryptionAlgorithm: algorithm, KeyMetadata: settings.footerKeyMetadata}))
+		out.Write(encrypt(testFooterKey, encryption.Footer, 0, 0, 0, encodeTestStruct(t, fileMetadata)))
+	}
+	out.Write(binary.LittleEndian.AppendUint32(nil, uint32(out.Len()-footerStart)))
+	out.WriteString(string(out.Bytes()[:4]))
+	return out.Bytes()
+}
+
+func encodeTestStruct(t *testing.T, value thrift.TStruct) []byte {
+	t.Helper()
+	data, err := thriftio.Encode(context.Background(), value)
+	if err != nil {
+		t.Fatalf("unable to encode %T: %v", value, err)
+	}
+	return data
+}
+
+func TestReaderDecryption(t *testing.T) {
+	rows := testRows(300)
+	data := writeTestFile(t, rows, WithRowGroupSize(100), WithPageSize(256), WithPageIndex(false))
+	expected := readAllRows(t, openTestFile(t, data))
+	keys := map[string][]byte{"footer": testFooterKey, "name": testNameKey, "tags": testTagsKey}
+	retriever := func(keyMetadata []byte) ([]byte, error) {
+		if key, ok := keys[string(keyMetadata)]; ok {
+			return key, nil
+		}
+		return nil, errors.New("unknown key")
+	}
+
+	testcases := map[string]struct {
+		settings testEncryption
+		opts     []ParquetReaderOption
+	}{
+		"encrypted footer": {
+			settings: testEncryption{columns: map[string]testColumnKey{"id": {}, "name": {}, "score": {}, "tags.key": {}}},
+			opts:     []ParquetReaderOption{WithFooterKey(testFooterKey)},
+		},
+		"column keys": {
+			settings: testEncryption{columns: map[string]testColumnKey{"id": {}, "name": {key: testNameKey}, "tags.key": {key: testTagsKey}}},
+			opts:     []ParquetReaderOption{WithFooterKey(testFooterKey), WithColumnKey("name", testNameKey), WithColumnKey("tags.key", testTagsKey)},
+		},
+		"ctr": {
+			settings: testEncryption{ctr: true, columns: map[string]testColumnKey{"id": {}, "name": {key: testNameKey}}},
+			opts:     []ParquetReaderOption{WithFooterKey(testFooterKey), WithColumnKey("name", testNameKey)},
+		},
+		"plaintext footer": {
+			settings: testEncryption{plaintextFooter: true, columns: map[string]testColumnKey{"id": {}, "name": {key: testNameKey}}},
+			opts:     []ParquetReaderOption{WithFooterKey(testFooterKey), WithColumnKey("name", testNameKey)},
+		},
+		"key retriever": {
+			settings: testEncryption{
+				footerKeyMetadata: []byte("footer"),
+				columns:           map[string]testColumnKey{"score": {}, "name": {key: testNameKey, metadata: []byte("name")}, "tags.key": {key: testTagsKey, metadata: []byte("tags")}},
+			},
+			opts: []ParquetReaderOption{WithKeyRetriever(retriever)},
+		},
+		"supplied aad prefix": {
+			settings: testEncryption{aadPrefix: []byte("table/part-0.parquet"), columns: map[string]testColumnKey{"name": {key: testNameKey}}},
+			opts:     []ParquetReaderOption{WithFooterKey(testFooterKey), WithColumnKey("name", testNameKey), WithAADPrefix([]byte("table/part-0.parquet"))},
+		},
+		"stored aad prefix": {
+			settings: testEncryption{plaintextFooter: true, aadPrefix: []byte("table/part-0.parquet"), storeAADPrefix: true, columns: map[string]testColumnKey{"name": {}}},
+			opts:     []ParquetReaderOption{WithFooterKey(testFooterKey)},
+		},
+		"footer read size": {
+			settings: testEncryption{columns: map[string]testColumnKey{"name": {}}},
+			opts:     []ParquetReaderOption{WithFooterKey(testFooterKey), WithFooterReadSize(64 << 10)},
+		},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			encrypted := encryptTestFile(t, data, test.settings)
+			reader, err := Open(bytes.NewReader(encrypted), int64(len(encrypted)), test.opts...)
+			if err != nil {
+				t.Fatalf("unable to open file: %v", err)
+			}
+			if got := readAllRows(t, reader); !reflect.DeepEqual(got, expected) {
+				t.Errorf("decrypted rows differ from the plaintext file")
+			}
+		})
+	}
+}
+
+func TestReaderDecryptionErrors(t *testing.T) {
+	data := writeTestFile(t, testRows(100), WithPageIndex(false))
+	columnKeys := map[string]testColumnKey{"id": {}, "name": {key: testNameKey}}
+
+	testcases := map[string]struct {
+		settings testEncryption
+		tamper   func(data []byte)
+		opts     []ParquetReaderOption
+		expected error
+	}{
+		"missing footer key": {
+			settings: testEncryption{columns: columnKeys},
+			expected: ErrMissingKey,
+		},
+		"wrong footer key": {
+			settings: testEncryption{columns: columnKeys},
+			opts:     []ParquetReaderOption{WithFooterKey(testNameKey)},
+		},
+		"failing key retriever": {
+			settings: testEncryption{columns: columnKeys},
+			opts:     []ParquetReaderOption{WithKeyRetriever(func([]byte) ([]byte, error) { return nil, errors.New("no access") })},
+			expected: ErrMissingKey,
+		},
+		"tampered signature": {
+			settings: testEncryption{plaintextFooter: true, columns: columnKeys},
+			tamper:   func(data []byte) { data[len(data)-9] ^= 1 },
+			opts:     []ParquetReaderOption{WithFooterKey(testFooterKey)},
+			expected: ErrSignature,
+		},
+		"missing aad prefix": {
+			settings: testEncryption{aadPrefix: []byte("prefix"), columns: columnKeys},
+			opts:     []ParquetReaderOption{WithFooterKey(testFooterKey)},
+		},
+		"wrong aad prefix": {
+			settings: testEncryption{aadPrefix: []byte("prefix"), columns: columnKeys},
+			opts:     []ParquetReaderOption{WithFooterKey(testFooterKey), WithAADPrefix([]byte("other"))},
+		},
+		"mismatched stored aad prefix": {
+			settings: testEncryption{aadPrefix: []byte("prefix"), storeAADPrefix: true, columns: columnKeys},
+			opts:     []ParquetReaderOption{WithFooterKey(testFooterKey), WithAADPrefix([]byte("other"))},
+		},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			encrypted := encryptTestFile(t, data, test.settings)
+			if test.tamper != nil {
+				test.tamper(encrypted)
+			}
+			_, err := Open(bytes.NewReader(encrypted), int64(len(encrypted)), test.opts...)
+			if err == nil {
+				t.Fatalf("expected error, got nil error")
+			}
+			if test.expected != nil && !errors.Is(err, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, err)
+			}
+		})
+	}
+}
+
+func TestReaderDecryptionWithoutColumnKey(t *testing.T) {
+	data := writeTestFile(t, testRows(100), WithPageIndex(false))
+	expected := readAllRows(t, openTestFile(t, data, WithColumns("id", "score")))
+
+	testcases := map[string]struct {
+		settings testEncryption
+		opts     []ParquetReaderOption
+	}{
+		"encrypted footer": {
+			settings: testEncryption{columns: map[string]testColumnKey{"id": {}, "name": {key: testNameKey}}},
+			opts:     []ParquetReaderOption{WithFooterKey(testFooterKey)},
+		},
+		"plaintext footer without keys": {
+			settings: testEncryption{plaintextFooter: true, columns: map[string]testColumnKey{"name": {key: testNameKey}, "tags.key": {}}},
+		},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			encrypted := encryptTestFile(t, data, test.settings)
+			reader, err := Open(bytes.NewReader(encrypted), int64(len(encrypted)), test.opts...)
+			if err != nil {
+				t.Fatalf("unable to open file: %v", err)
+			}
+			if _, err := reader.ReadAll(context.Background()); !errors.Is(err, ErrMissingKey) {
+				t.Errorf("expected ErrMissingKey reading every column, got %v", err)
+			}
+
+			reader, err = Open(bytes.NewReader(encrypted), int64(len(encrypted)), append(test.opts, WithColumns("id", "score"))...)
+			if err != nil {
+				t.Fatalf("unable to open file: %v", err)
+			}
+			if got := readAllRows(t, reader); !reflect.DeepEqual(got, expected) {
+				t.Errorf("rows of the accessible columns differ from the plaintext file")
+			}
+		})
+	}
+}
+
+// referenceKeys holds the keys of the files in testdata/encryption by their
+// key metadata. They are the keys of the apache/parquet-testing files, which
+// encrypt double_field with kc1 and float_field with kc2.
+var referenceKeys = map[string][]byte{"kf": testFooterKey, "kc1": testNameKey, "kc2": testTagsKey}
+
+func referenceKeyRetriever(keyMetadata []byte) ([]byte, error) {
+	if key, ok := referenceKeys[string(keyMetadata)]; ok {
+		return key, nil
+	}
+	return nil, errors.New("unknown key")
+}
+
+// referenceRows returns the rows of the files in testdata/encryption: two
+// row groups of 50 rows with the values of the apache/parquet-testing files.
+func referenceRows() []Row {
+	var rows []Row
+	for range 2 {
+		for i := range 50 {
+			var int96 [12]byte
+			binary.LittleEndian.PutUint32(int96[:], uint32(i))
+			binary.LittleEndian.PutUint32(int96[4:], uint32(i+1))
+			binary.LittleEndian.PutUint32(int96[8:], uint32(i+2))
+			row := Row{
+				"boolean_field": i%2 == 0,
+				"int32_field":   int32(i),
+				"int64_field":   []any{int64(i*2) * 1e12, int64(i*2+1) * 1e12},
+				"int96_field":   int96,
+				"float_field":   float32(i) * 1.1,
+				"double_field":  float64(i) * 1.1111111,
+				"ba_field":      nil,
+				"flba_field":    bytes.Repeat([]byte{byte(i)}, 10),
+			}
+			if i%2 == 0 {
+				row["ba_field"] = fmt.Appendf(nil, "parquet%03d", i)
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+func readReferenceFile(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("..", "testdata", "encryption", name))
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+	return data
+}
+
+func TestReaderDecryptionReferenceFiles(t *testing.T) {
+	expected := referenceRows()
+	columnKeys := []ParquetReaderOption{WithFooterKey(testFooterKey), WithColumnKey("double_field", testNameKey), WithColumnKey("float_field", testTagsKey)}
+
+	testcases := map[string]struct {
+		file string
+		opts []ParquetReaderOption
+	}{
+		"uniform":                {file: "uniform_encryption.parquet.encrypted", opts: []ParquetReaderOption{WithKeyRetriever(referenceKeyRetriever)}},
+		"uniform footer key":     {file: "uniform_encryption.parquet.encrypted", opts: []ParquetReaderOption{WithFooterKey(testFooterKey)}},
+		"columns and footer":     {file: "encrypt_columns_and_footer.parquet.encrypted", opts: []ParquetReaderOption{WithKeyRetriever(referenceKeyRetriever)}},
+		"explicit keys":          {file: "encrypt_columns_and_footer.parquet.encrypted", opts: columnKeys},
+		"plaintext footer":       {file: "encrypt_columns_plaintext_footer.parquet.encrypted", opts: []ParquetReaderOption{WithKeyRetriever(referenceKeyRetriever)}},
+		"stored aad prefix":      {file: "encrypt_columns_and_footer_aad.parquet.encrypted", opts: []ParquetReaderOption{WithKeyRetriever(referenceKeyRetriever)}},
+		"verified aad prefix":    {file: "encrypt_columns_and_footer_aad.parquet.encrypted", opts: append(columnKeys, WithAADPrefix([]byte("tester")))},
+		"supplied aad prefix":    {file: "encrypt_columns_and_footer_disable_aad_storage.parquet.encrypted", opts: []ParquetReaderOption{WithKeyRetriever(referenceKeyRetriever), WithAADPrefix([]byte("tester"))}},
+		"ctr":                    {file: "encrypt_columns_and_footer_ctr.parquet.encrypted", opts: []ParquetReaderOption{WithKeyRetriever(referenceKeyRetriever)}},
+		"ctr with explicit keys": {file: "encrypt_columns_and_footer_ctr.parquet.encrypted", opts: columnKeys},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			reader := openTestFile(t, readReferenceFile(t, test.file), test.opts...)
+			if got := readAllRows(t, reader); !reflect.DeepEqual(got, expected) {
+				t.Errorf("expected the rows of the reference file, got %v", got)
+			}
+		})
+	}
+}
+
+func TestReaderEncryption(t *testing.T) {
+	testcases := map[string]struct {
+		file     string
+		expected *FileEncryption
+	}{
+		"encrypted footer": {file: "encrypt_columns_and_footer.parquet.encrypted", expected: &FileEncryption{Algorithm: AesGcm, EncryptedFooter: true, FooterKeyMetadata: []byte("kf")}},
+		"plaintext footer": {file: "encrypt_columns_plaintext_footer.parquet.encrypted", expected: &FileEncryption{Algorithm: AesGcm, FooterKeyMetadata: []byte("kf")}},
+		"ctr":              {file: "encrypt_columns_and_footer_ctr.parquet.encrypted", expected: &FileEncryption{Algorithm: AesGcmCtr, EncryptedFooter: true, FooterKeyMetadata: []byte("kf")}},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			reader := openTestFile(t, readReferenceFile(t, test.file), WithKeyRetriever(referenceKeyRetriever))
+			if got := reader.Encryption(); !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("expected %+v, got %+v", test.expected, got)
+			}
+		})
+	}
+
+	if got := openTestFile(t, writeTestFile(t, testRows(1))).Encryption(); got != nil {
+		t.Errorf("expected no encryption, got %+v", got)
+	}
+}
+
+func TestReaderDecryptionReferenceFileErrors(t *testing.T) {
+	testcases := map[string]struct {
+		file     string
+		opts     []ParquetReaderOption
+		expected error
+	}{
+		"missing footer key": {file: "encrypt_columns_and_footer.parquet.encrypted", expected: ErrMissingKey},
+		"missing aad prefix": {file: "encrypt_columns_and_footer_disable_aad_storage.parquet.encrypted", opts: []ParquetReaderOption{WithKeyRetriever(referenceKeyRetriever)}},
+		"wrong aad prefix":   {file: "encrypt_columns_and_footer_aad.parquet.encrypted", opts: []ParquetReaderOption{WithKeyRetriever(referenceKeyRetriever), WithAADPrefix([]byte("other"))}},
+		"wrong column key":   {file: "encrypt_columns_and_footer.parquet.encrypted", opts: []ParquetReaderOption{WithFooterKey(testFooterKey), WithColumnKey("double_field", testTagsKey), WithColumnKey("float_field", testTagsKey)}},
+		"tampered signature": {file: "encrypt_columns_plaintext_footer.parquet.encrypted", opts: []ParquetReaderOption{WithFooterKey(testNameKey)}, expected: ErrSignature},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			data := readReferenceFile(t, test.file)
+			reader, err := Open(bytes.NewReader(data), int64(len(data)), test.opts...)
+			if err == nil {
+				_, err = reader.ReadAll(context.Background())
+			}
+			if err == nil {
+				t.Fatalf("expected error, got nil error")
+			}
+			if test.expected != nil && !errors.Is(err, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, err)
+			}
+		})
+	}
+}
+
+func TestReaderReferencePlaintextFooterWithoutKeys(t *testing.T) {
+	data := readReferenceFile(t, "encrypt_columns_plaintext_footer.parquet.encrypted")
+	paths := []string{"boolean_field", "int32_field", "int64_field", "int96_field", "ba_field", "flba_field"}
+	expected := referenceRows()
+	for _, row := range expected {
+		delete(row, "float_field")
+		delete(row, "double_field")
+	}
+
+	reader := openTestFile(t, data, WithColumns(paths...))
+	if got := readAllRows(t, reader); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected the plaintext columns of the reference file, got %v", got)
+	}
+	if _, err := openTestFile(t, data).ReadAll(context.Background()); !errors.Is(err, ErrMissingKey) {
+		t.Errorf("expected ErrMissingKey reading the encrypted columns, got %v", err)
+	}
+}
+
+func openTestFile(t *testing.T, data []byte, opts ...ParquetReaderOption) *ParquetReader {
+	t.Helper()
+	reader, err := Open(bytes.NewReader(data), int64(len(data)), opts...)
+	if err != nil {
+		t.Fatalf("unable to open file: %v", err)
+	}
+	return reader
+}
@@ -0,0 +1,223 @@
+package parquet
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/RichardNooooh/parquet-go/internal/encryption"
+	format "github.com/RichardNooooh/parquet-go/internal/format/gen-go/parquet"
+	"github.com/RichardNooooh/parquet-go/internal/thriftio"
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+var encryptedMagic = []byte("PARE")
+
+// fileUniqueLength is the size of the random part of the file AAD.
+const fileUniqueLength = 8
+
+// fileEncryption encrypts the modules of a file being written.
+type fileEncryption struct {
+	algorithm       *format.EncryptionAlgorithm
+	footer          *encryption.Encryptor
+	keyMetadata     []byte
+	plaintextFooter bool
+	// columns holds the key and crypto metadata of each column, and is nil
+	// for plaintext columns.
+	columns []*columnEncryption
+}
+
+type columnEncryption struct {
+	key            []byte
+	cryptoMetadata *format.ColumnCryptoMetaData
+}
+
+// newFileEncryption checks the encryption options and draws the random part
+// of the file AAD. It returns nil when the file is not encrypted.
+func newFileEncryption(columns []*schema.Column, config *writerConfig) (*fileEncryption, error) {
+	if config.footerKey == nil {
+		if len(config.columnKeys) > 0 || config.plaintextFooter || config.aadPrefix != nil {
+			return nil, errors.New("encryption options need a footer key from WithEncryption")
+		}
+		return nil, nil
+	}
+	if err := encryption.CheckKey(config.footerKey); err != nil {
+		return nil, fmt.Errorf("footer key: %w", err)
+	}
+
+	fileUnique := make([]byte, fileUniqueLength)
+	if _, err := rand.Read(fileUnique); err != nil {
+		return nil, err
+	}
+	var prefix []byte
+	if config.storeAADPrefix {
+		prefix = config.aadPrefix
+	}
+	// supply_aad_prefix is optional, but Arrow readers expect it to be set.
+	supplyPrefix := new(bool)
+	*supplyPrefix = config.aadPrefix != nil && !config.storeAADPrefix
+	e := &fileEncryption{
+		footer: &encryption.Encryptor{
+			Key:     config.footerKey,
+			FileAAD: append(slices.Clone(config.aadPrefix), fileUnique...),
+		},
+		keyMetadata:     config.footerKeyMetadata,
+		plaintextFooter: config.plaintextFooter,
+		columns:         make([]*columnEncryption, len(columns)),
+	}
+	switch config.algorithm {
+	case AesGcm:
+		e.footer.Algorithm = encryption.AesGcmV1
+		e.algorithm = &format.EncryptionAlgorithm{AES_GCM_V1: &format.AesGcmV1{
+			AadPrefix: prefix, AadFileUnique: fileUnique, SupplyAadPrefix: supplyPrefix,
+		}}
+	case AesGcmCtr:
+		e.footer.Algorithm = encryption.AesGcmCtrV1
+		e.algorithm = &format.EncryptionAlgorithm{AES_GCM_CTR_V1: &format.AesGcmCtrV1{
+			AadPrefix: prefix, AadFileUnique: fileUnique, SupplyAadPrefix: supplyPrefix,
+		}}
+	default:
+		return nil, fmt.Errorf("unknown encryption algorithm %d", config.algorithm)
+	}
+
+	for path := range config.columnKeys {
+		if !slices.ContainsFunc(columns, func(column *schema.Column) bool { return column.PathString() == path }) {
+			return nil, fmt.Errorf("encrypted column %q not found", path)
+		}
+	}
+	for i, column := range columns {
+		columnKey, ok := config.columnKeys[column.PathString()]
+		switch {
+		case len(config.columnKeys) == 0 || ok && columnKey.key == nil:
+			e.columns[i] = &columnEncryption{
+				key:            config.footerKey,
+				cryptoMetadata: &format.ColumnCryptoMetaData{ENCRYPTION_WITH_FOOTER_KEY: format.NewEncryptionWithFooterKey()},
+			}
+		case ok:
+			if err := encryption.CheckKey(columnKey.key); err != nil {
+				return nil, fmt.Errorf("key of column %q: %w", column.PathString(), err)
+			}
+			e.columns[i] = &columnEncryption{
+				key: columnKey.key,
+				cryptoMetadata: &format.ColumnCryptoMetaData{ENCRYPTION_WITH_COLUMN_KEY: &format.EncryptionWithColumnKey{
+					PathInSchema: column.Path,
+					KeyMetadata:  columnKey.keyMetadata,
+				}},
+			}
+		}
+	}
+	return e, nil
+}
+
+// magic returns the magic of the file, which is only the encrypted magic
+// when the footer is encrypted.
+func (e *fileEncryption) magic() []byte {
+	if e == nil || e.plaintextFooter {
+		return parquetMagic
+	}
+	return encryptedMagic
+}
+
+// encryptor returns the encryptor of a column chunk, or nil when the column
+// is written in plaintext.
+func (e *fileEncryption) encryptor(rowGroup, column int) *encryption.Encryptor {
+	if e == nil || e.columns[column] == nil {
+		return nil
+	}
+	return &encryption.Encryptor{
+		Algorithm: e.footer.Algorithm,
+		Key:       e.columns[column].key,
+		FileAAD:   e.footer.FileAAD,
+		RowGroup:  rowGroup,
+		Column:    column,
+	}
+}
+
+// encryptColumnMetadata marks the encrypted column chunks with their crypto
+// metadata. Chunks of columns with their own key, and every encrypted chunk
+// under a plaintext footer, carry their metadata encrypted; a plaintext
+// footer keeps a redacted copy for readers without the key.
+func (e *fileEncryption) encryptColumnMetadata(ctx context.Context, rowGroups []*format.RowGroup) error {
+	if e == nil {
+		return nil
+	}
+	for i, rowGroup := range rowGroups {
+		for j, chunk := range rowGroup.Columns {
+			column := e.columns[j]
+			if column == nil {
+				continue
+			}
+			chunk.CryptoMetadata = column.cryptoMetadata
+			if column.cryptoMetadata.IsSetENCRYPTION_WITH_FOOTER_KEY() && !e.plaintextFooter {
+				continue
+			}
+			data, err := thriftio.Encode(ctx, chunk.MetaData)
+			if err != nil {
+				return err
+			}
+			if chunk.EncryptedColumnMetadata, err = e.encryptor(i, j).Encrypt(encryption.ColumnMetaData, 0, data); err != nil {
+				return fmt.Errorf("unable to encrypt column metadata: %w", err)
+			}
+			if e.plaintextFooter {
+				chunk.MetaData = redactColumnMetadata(chunk.MetaData)
+			} else {
+				chunk.MetaData = nil
+			}
+		}
+	}
+	return nil
+}
+
+// redactColumnMetadata returns the copy of the metadata of an encrypted
+// column chunk kept in a plaintext footer. It only holds the fields needed to
+// locate the chunk, so that statistics, including those added to the format
+// later, never leak.
+func redactColumnMetadata(meta *format.ColumnMetaData) *format.ColumnMetaData {
+	return &format.ColumnMetaData{
+		Type:                  meta.Type,
+		Encodings:             meta.Encodings,
+		PathInSchema:          meta.PathInSchema,
+		Codec:                 meta.Codec,
+		NumValues:             meta.NumValues,
+		TotalUncompressedSize: meta.TotalUncompressedSize,
+		TotalCompressedSize:   meta.TotalCompressedSize,
+		DataPageOffset:        meta.DataPageOffset,
+		IndexPageOffset:       meta.IndexPageOffset,
+		DictionaryPageOffset:  meta.DictionaryPageOffset,
+	}
+}
+
+// encodeFooter encodes the footer of an encrypted file without its length and
+// magic: the crypto metadata followed by the encrypted file metadata, or the
+// plaintext file metadata followed by its signature.
+func (e *fileEncryption) encodeFooter(ctx context.Context, fileMetadata *format.FileMetaData) ([]byte, error) {
+	if e.plaintextFooter {
+		fileMetadata.EncryptionAlgorithm = e.algorithm
+		fileMetadata.FooterSigningKeyMetadata = e.keyMetadata
+		footer, err := thriftio.Encode(ctx, fileMetadata)
+		if err != nil {
+			return nil, err
+		}
+		signature, err := e.footer.Sign(footer)
+		if err != nil {
+			return nil, fmt.Errorf("unable to sign footer: %w", err)
+		}
+		return append(footer, signature...), nil
+	}
+
+	footer, err := thriftio.Encode(ctx, &format.FileCryptoMetaData{EncryptionAlgorithm: e.algorithm, KeyMetadata: e.keyMetadata})
+	if err != nil {
+		return nil, err
+	}
+	data, err := thriftio.Encode(ctx, fileMetadata)
+	if err != nil {
+		return nil, err
+	}
+	module, err := e.footer.Encrypt(encryption.Footer, 0, data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encrypt footer: %w", err)
+	}
+	return append(footer, module...), nil
+}
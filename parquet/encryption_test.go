@@ -0,0 +1,327 @@
+package parquet
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/RichardNooooh/parquet-go/internal/file"
+	format "github.com/RichardNooooh/parquet-go/internal/format/gen-go/parquet"
+	"github.com/RichardNooooh/parquet-go/internal/thriftio"
+)
+
+func TestWriterEncryption(t *testing.T) {
+	rows := testRows(300)
+	writeOpts := []ParquetWriterOption{WithRowGroupSize(100), WithPageSize(256), WithBloomFilter("name", 0.01, 0)}
+	expected := readAllRows(t, openTestFile(t, writeTestFile(t, rows, writeOpts...)))
+	keys := map[string][]byte{"footer": testFooterKey, "name": testNameKey, "tags": testTagsKey}
+	retriever := func(keyMetadata []byte) ([]byte, error) {
+		if key, ok := keys[string(keyMetadata)]; ok {
+			return key, nil
+		}
+		return nil, errors.New("unknown key")
+	}
+
+	testcases := map[string]struct {
+		opts     []ParquetWriterOption
+		readOpts []ParquetReaderOption
+		magic    string
+	}{
+		"uniform": {
+			opts:     []ParquetWriterOption{WithEncryption(testFooterKey, nil)},
+			readOpts: []ParquetReaderOption{WithFooterKey(testFooterKey)},
+			magic:    "PARE",
+		},
+		"column keys": {
+			opts: []ParquetWriterOption{
+				WithEncryption(testFooterKey, []byte("footer")),
+				WithColumnEncryption("id", nil, nil),
+				WithColumnEncryption("name", testNameKey, []byte("name")),
+				WithColumnEncryption("tags.key", testTagsKey, []byte("tags")),
+			},
+			readOpts: []ParquetReaderOption{WithKeyRetriever(retriever)},
+			magic:    "PARE",
+		},
+		"ctr": {
+			opts:     []ParquetWriterOption{WithEncryption(testFooterKey, nil), WithEncryptionAlgorithm(AesGcmCtr), WithColumnEncryption("name", testNameKey, nil)},
+			readOpts: []ParquetReaderOption{WithFooterKey(testFooterKey), WithColumnKey("name", testNameKey)},
+			magic:    "PARE",
+		},
+		"plaintext footer": {
+			opts:     []ParquetWriterOption{WithEncryption(testFooterKey, nil), WithPlaintextFooter(true), WithColumnEncryption("name", testNameKey, nil), WithColumnEncryption("score", nil, nil)},
+			readOpts: []ParquetReaderOption{WithFooterKey(testFooterKey), WithColumnKey("name", testNameKey)},
+			magic:    "PAR1",
+		},
+		"supplied aad prefix": {
+			opts:     []ParquetWriterOption{WithEncryption(testFooterKey, nil), WithEncryptionAADPrefix([]byte("table/part-0.parquet"), false)},
+			readOpts: []ParquetReaderOption{WithFooterKey(testFooterKey), WithAADPrefix([]byte("table/part-0.parquet"))},
+			magic:    "PARE",
+		},
+		"stored aad prefix": {
+			opts:     []ParquetWriterOption{WithEncryption(testFooterKey, nil), WithPlaintextFooter(true), WithEncryptionAADPrefix([]byte("table/part-0.parquet"), true)},
+			readOpts: []ParquetReaderOption{WithFooterKey(testFooterKey)},
+			magic:    "PAR1",
+		},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			data := writeTestFile(t, rows, append(writeOpts, test.opts...)...)
+			if magic := string(data[:4]) + string(data[len(data)-4:]); magic != test.magic+test.magic {
+				t.Errorf("expected magic %s, got %s", test.magic, magic)
+			}
+			reader := openTestFile(t, data, test.readOpts...)
+			if got := readAllRows(t, reader); !reflect.DeepEqual(got, expected) {
+				t.Errorf("decrypted rows differ from the plaintext file")
+			}
+
+			for _, rowGroup := range reader.RowGroups() {
+				for column := range reader.GetMeta().Columns() {
+					if _, err := reader.ColumnIndex(rowGroup, column); err != nil {
+						t.Errorf("row group %d, column %d: unable to read column index: %v", rowGroup, column, err)
+					}
+					if _, err := reader.OffsetIndex(rowGroup, column); err != nil {
+						t.Errorf("row group %d, column %d: unable to read offset index: %v", rowGroup, column, err)
+					}
+				}
+				filter, err := reader.BloomFilter(rowGroup, 1)
+				if err != nil {
+					t.Fatalf("row group %d: unable to read bloom filter: %v", rowGroup, err)
+				}
+				if !filter.MightContain("b") {
+					t.Errorf("row group %d: expected the bloom filter to contain %q", rowGroup, "b")
+				}
+			}
+		})
+	}
+}
+
+func TestWriterEncryptionHidesData(t *testing.T) {
+	rows := []Row{{"id": int64(1), "name": "secret-name", "score": 1.5}}
+	data := writeTestFile(t, rows, WithEncryption(testFooterKey, nil))
+	for _, plaintext := range []string{"secret-name", "score", "tags"} {
+		if bytes.Contains(data, []byte(plaintext)) {
+			t.Errorf("expected %q to be encrypted", plaintext)
+		}
+	}
+
+	data = writeTestFile(t, rows, WithEncryption(testFooterKey, nil), WithPlaintextFooter(true), WithColumnEncryption("name", testNameKey, nil))
+	if bytes.Contains(data, []byte("secret-name")) {
+		t.Errorf("expected the values and statistics of an encrypted column to be encrypted")
+	}
+	if !bytes.Contains(data, []byte("score")) {
+		t.Errorf("expected the schema to be in the plaintext footer")
+	}
+
+	// Readers without keys read the plaintext columns.
+	reader := openTestFile(t, data, WithColumns("id", "score"))
+	expected := []Row{{"id": int64(1), "score": 1.5}}
+	if got := readAllRows(t, reader); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestPlaintextFooterRedactsColumnMetadata(t *testing.T) {
+	columns := testSchema().Columns()
+	e, err := newFileEncryption(columns, &writerConfig{footerKey: testFooterKey, plaintextFooter: true})
+	if err != nil {
+		t.Fatalf("unable to set up encryption: %v", err)
+	}
+	offset, length, unencoded := int64(4), int32(16), int64(8)
+	rowGroup := &format.RowGroup{}
+	for _, column := range columns {
+		rowGroup.Columns = append(rowGroup.Columns, &format.ColumnChunk{MetaData: &format.ColumnMetaData{
+			Type:                  format.Type_BYTE_ARRAY,
+			Encodings:             []format.Encoding{format.Encoding_PLAIN},
+			PathInSchema:          column.Path,
+			NumValues:             10,
+			TotalUncompressedSize: 100,
+			TotalCompressedSize:   100,
+			KeyValueMetadata:      []*format.KeyValue{{Key: "secret"}},
+			DataPageOffset:        offset,
+			DictionaryPageOffset:  &offset,
+			Statistics:            &format.Statistics{MinValue: []byte("secret"), MaxValue: []byte("secret")},
+			EncodingStats:         []*format.PageEncodingStats{{Count: 1}},
+			BloomFilterOffset:     &offset,
+			BloomFilterLength:     &length,
+			SizeStatistics:        &format.SizeStatistics{UnencodedByteArrayDataBytes: &unencoded},
+			GeospatialStatistics: &format.GeospatialStatistics{
+				Bbox:            &format.BoundingBox{Xmin: -1, Xmax: 1, Ymin: -1, Ymax: 1},
+				GeospatialTypes: []int32{1},
+			},
+		}})
+	}
+	if err := e.encryptColumnMetadata(context.Background(), []*format.RowGroup{rowGroup}); err != nil {
+		t.Fatalf("unable to encrypt column metadata: %v", err)
+	}
+
+	allowed := map[string]bool{
+		"Type": true, "Encodings": true, "PathInSchema": true, "Codec": true, "NumValues": true,
+		"TotalUncompressedSize": true, "TotalCompressedSize": true,
+		"DataPageOffset": true, "IndexPageOffset": true, "DictionaryPageOffset": true,
+	}
+	for i, chunk := range rowGroup.Columns {
+		if chunk.EncryptedColumnMetadata == nil || chunk.MetaData == nil {
+			t.Fatalf("column %d: expected encrypted and plaintext metadata", i)
+		}
+		value := reflect.ValueOf(chunk.MetaData).Elem()
+		for j := range value.NumField() {
+			name := value.Type().Field(j).Name
+			if !allowed[name] && !value.Field(j).IsZero() {
+				t.Errorf("column %d: expected %s to be removed from the plaintext footer, got %v", i, name, value.Field(j))
+			}
+		}
+		if chunk.MetaData.NumValues != 10 || chunk.MetaData.DataPageOffset != offset {
+			t.Errorf("column %d: expected the counts and offsets to be kept, got %v", i, chunk.MetaData)
+		}
+	}
+}
+
+// cryptoLayout summarizes how a file is encrypted, leaving out the random
+// parts and the ciphertexts.
+type cryptoLayout struct {
+	encryptedFooter   bool
+	ctr               bool
+	aadPrefix         string
+	supplyAADPrefix   bool
+	footerKeyMetadata string
+	// columns holds the key metadata of each column, "footer" for columns
+	// encrypted with the footer key and "" for plaintext ones.
+	columns []string
+}
+
+func readCryptoLayout(t *testing.T, data []byte, opts ...ParquetReaderOption) cryptoLayout {
+	t.Helper()
+	ctx := context.Background()
+	reader := file.NewReader(bytes.NewReader(data), int64(len(data)))
+	footer, err := file.ReadFooter(ctx, reader, 0)
+	if err != nil {
+		t.Fatalf("unable to read footer: %v", err)
+	}
+	fileMetadata, _, err := readFooter(ctx, reader, newReaderConfig(opts))
+	if err != nil {
+		t.Fatalf("unable to decrypt footer: %v", err)
+	}
+
+	layout := cryptoLayout{encryptedFooter: footer.Encrypted}
+	algorithm, keyMetadata := fileMetadata.EncryptionAlgorithm, fileMetadata.FooterSigningKeyMetadata
+	if footer.Encrypted {
+		cryptoMetadata := format.NewFileCryptoMetaData()
+		if _, err := thriftio.Decode(ctx, footer.Data, cryptoMetadata); err != nil {
+			t.Fatalf("unable to read file crypto metadata: %v", err)
+		}
+		algorithm, keyMetadata = cryptoMetadata.EncryptionAlgorithm, cryptoMetadata.KeyMetadata
+	}
+	layout.footerKeyMetadata = string(keyMetadata)
+	if aes := algorithm.AES_GCM_V1; aes != nil {
+		layout.aadPrefix, layout.supplyAADPrefix = string(aes.AadPrefix), aes.GetSupplyAadPrefix()
+		if !aes.IsSetSupplyAadPrefix() {
+			t.Errorf("expected supply_aad_prefix to be set")
+		}
+	} else {
+		aes := algorithm.AES_GCM_CTR_V1
+		layout.ctr, layout.aadPrefix, layout.supplyAADPrefix = true, string(aes.AadPrefix), aes.GetSupplyAadPrefix()
+		if !aes.IsSetSupplyAadPrefix() {
+			t.Errorf("expected supply_aad_prefix to be set")
+		}
+	}
+	for _, chunk := range fileMetadata.RowGroups[0].Columns {
+		switch crypto := chunk.CryptoMetadata; {
+		case crypto == nil:
+			layout.columns = append(layout.columns, "")
+		case crypto.IsSetENCRYPTION_WITH_FOOTER_KEY():
+			layout.columns = append(layout.columns, "footer")
+		default:
+			layout.columns = append(layout.columns, string(crypto.ENCRYPTION_WITH_COLUMN_KEY.KeyMetadata))
+		}
+	}
+	return layout
+}
+
+// TestWriterEncryptionReferenceFiles writes the rows of the files in
+// testdata/encryption with the same settings, and checks that they are
+// encrypted the same way and decrypt to the same rows.
+func TestWriterEncryptionReferenceFiles(t *testing.T) {
+	columnKeys := []ParquetWriterOption{
+		WithColumnEncryption("double_field", testNameKey, []byte("kc1")),
+		WithColumnEncryption("float_field", testTagsKey, []byte("kc2")),
+	}
+	testcases := map[string]struct {
+		file  string
+		write []ParquetWriterOption
+		read  []ParquetReaderOption
+	}{
+		"uniform":            {file: "uniform_encryption.parquet.encrypted"},
+		"columns and footer": {file: "encrypt_columns_and_footer.parquet.encrypted", write: columnKeys},
+		"plaintext footer":   {file: "encrypt_columns_plaintext_footer.parquet.encrypted", write: append(columnKeys, WithPlaintextFooter(true))},
+		"stored aad prefix":  {file: "encrypt_columns_and_footer_aad.parquet.encrypted", write: append(columnKeys, WithEncryptionAADPrefix([]byte("tester"), true))},
+		"supplied aad prefix": {
+			file:  "encrypt_columns_and_footer_disable_aad_storage.parquet.encrypted",
+			write: append(columnKeys, WithEncryptionAADPrefix([]byte("tester"), false)),
+			read:  []ParquetReaderOption{WithAADPrefix([]byte("tester"))},
+		},
+		"ctr": {file: "encrypt_columns_and_footer_ctr.parquet.encrypted", write: append(columnKeys, WithEncryptionAlgorithm(AesGcmCtr))},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			readOpts := append([]ParquetReaderOption{WithKeyRetriever(referenceKeyRetriever)}, test.read...)
+			reference := readReferenceFile(t, test.file)
+			root := openTestFile(t, reference, readOpts...).GetMeta().Schema()
+
+			var buffer bytes.Buffer
+			writeOpts := append([]ParquetWriterOption{WithEncryption(testFooterKey, []byte("kf")), WithRowGroupSize(50)}, test.write...)
+			writer, err := NewWriter(&buffer, root, writeOpts...)
+			if err != nil {
+				t.Fatalf("unable to create writer: %v", err)
+			}
+			for _, row := range referenceRows() {
+				if err := writer.Write(row); err != nil {
+					t.Fatalf("unable to write row: %v", err)
+				}
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatalf("unable to close writer: %v", err)
+			}
+
+			expected := readCryptoLayout(t, reference, readOpts...)
+			if got := readCryptoLayout(t, buffer.Bytes(), readOpts...); !reflect.DeepEqual(got, expected) {
+				t.Errorf("expected the encryption of the reference file %+v, got %+v", expected, got)
+			}
+			if got := readAllRows(t, openTestFile(t, buffer.Bytes(), readOpts...)); !reflect.DeepEqual(got, referenceRows()) {
+				t.Errorf("expected the rows of the reference file, got %v", got)
+			}
+		})
+	}
+}
+
+func TestWriterEncryptionIsRandomized(t *testing.T) {
+	rows := testRows(10)
+	first := writeTestFile(t, rows, WithEncryption(testFooterKey, nil))
+	second := writeTestFile(t, rows, WithEncryption(testFooterKey, nil))
+	if bytes.Equal(first, second) {
+		t.Errorf("expected files encrypted with random nonces and file AADs to differ")
+	}
+}
+
+func TestWriterRejectsInvalidEncryption(t *testing.T) {
+	testcases := map[string][]ParquetWriterOption{
+		"short footer key":          {WithEncryption([]byte("short"), nil)},
+		"short column key":          {WithEncryption(testFooterKey, nil), WithColumnEncryption("name", []byte("short"), nil)},
+		"unknown column":            {WithEncryption(testFooterKey, nil), WithColumnEncryption("missing", testNameKey, nil)},
+		"unknown algorithm":         {WithEncryption(testFooterKey, nil), WithEncryptionAlgorithm(EncryptionAlgorithm(7))},
+		"column key without footer": {WithColumnEncryption("name", testNameKey, nil)},
+		"plaintext footer only":     {WithPlaintextFooter(true)},
+		"aad prefix only":           {WithEncryptionAADPrefix([]byte("prefix"), true)},
+	}
+
+	for name, opts := range testcases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewWriter(&bytes.Buffer{}, testSchema(), opts...); err == nil {
+				t.Errorf("expected error, got nil error")
+			}
+		})
+	}
+}
@@ -0,0 +1,614 @@
+package parquet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/RichardNooooh/parquet-go/geospatial"
+	"github.com/RichardNooooh/parquet-go/internal/stats"
+	"github.com/RichardNooooh/parquet-go/metadata"
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+// Predicate is a filter expression over leaf columns, addressed by their
+// dotted path such as "a.b.c". Literals are given as Go values that the
+// writer accepts for the column.
+//
+// Comparisons never match null values. On repeated columns a comparison
+// matches a row when any of its values does, and IsNull matches rows without
+// any value.
+type Predicate interface {
+	String() string
+	negate() Predicate
+	bind(meta *metadata.FileMeta) (boundPredicate, error)
+}
+
+type compareOp int
+
+const (
+	opEq compareOp = iota
+	opNotEq
+	opLt
+	opLtEq
+	opGt
+	opGtEq
+)
+
+func (op compareOp) String() string {
+	return [...]string{"=", "!=", "<", "<=", ">", ">="}[op]
+}
+
+func (op compareOp) negate() compareOp {
+	return [...]compareOp{opNotEq, opEq, opGtEq, opGt, opLtEq, opLt}[op]
+}
+
+type comparison struct {
+	op     compareOp
+	column string
+	value  any
+}
+
+type setMembership struct {
+	column  string
+	values  []any
+	negated bool
+}
+
+type nullCheck struct {
+	column  string
+	negated bool
+}
+
+type conjunction struct {
+	children []Predicate
+	or       bool
+}
+
+type negation struct {
+	child Predicate
+}
+
+func Eq(column string, value any) Predicate    { return comparison{opEq, column, value} }
+func NotEq(column string, value any) Predicate { return comparison{opNotEq, column, value} }
+func Lt(column string, value any) Predicate    { return comparison{opLt, column, value} }
+func LtEq(column string, value any) Predicate  { return comparison{opLtEq, column, value} }
+func Gt(column string, value any) Predicate    { return comparison{opGt, column, value} }
+func GtEq(column string, value any) Predicate  { return comparison{opGtEq, column, value} }
+
+// In matches rows where the column equals any of the values.
+func In(column string, values ...any) Predicate { return setMembership{column: column, values: values} }
+
+func IsNull(column string) Predicate    { return nullCheck{column: column} }
+func IsNotNull(column string) Predicate { return nullCheck{column: column, negated: true} }
+
+func And(predicates ...Predicate) Predicate { return conjunction{children: predicates} }
+func Or(predicates ...Predicate) Predicate  { return conjunction{children: predicates, or: true} }
+
+// Not negates a predicate. Like in SQL, the negation of a comparison still
+// does not match null values. Over repeated columns, Not matches the rows
+// that the predicate does not match, such as those where no element equals
+// the value of Eq, including rows without elements.
+func Not(predicate Predicate) Predicate { return negation{predicate} }
+
+func (p comparison) String() string { return fmt.Sprintf("%s %v %v", p.column, p.op, p.value) }
+
+func (p comparison) negate() Predicate { return comparison{p.op.negate(), p.column, p.value} }
+
+func (p setMembership) String() string {
+	values := make([]string, len(p.values))
+	for i, value := range p.values {
+		values[i] = fmt.Sprint(value)
+	}
+	op := "IN"
+	if p.negated {
+		op = "NOT IN"
+	}
+	return fmt.Sprintf("%s %s (%s)", p.column, op, strings.Join(values, ", "))
+}
+
+func (p setMembership) negate() Predicate {
+	return setMembership{column: p.column, values: p.values, negated: !p.negated}
+}
+
+func (p nullCheck) String() string {
+	if p.negated {
+		return p.column + " IS NOT NULL"
+	}
+	return p.column + " IS NULL"
+}
+
+func (p nullCheck) negate() Predicate { return nullCheck{column: p.column, negated: !p.negated} }
+
+func (p conjunction) String() string {
+	children := make([]string, len(p.children))
+	for i, child := range p.children {
+		children[i] = "(" + child.String() + ")"
+	}
+	if p.or {
+		return strings.Join(children, " OR ")
+	}
+	return strings.Join(children, " AND ")
+}
+
+func (p conjunction) negate() Predicate {
+	children := make([]Predicate, len(p.children))
+	for i, child := range p.children {
+		children[i] = child.negate()
+	}
+	return conjunction{children: children, or: !p.or}
+}
+
+func (p negation) String() string { return "NOT (" + p.child.String() + ")" }
+
+func (p negation) negate() Predicate { return p.child }
+
+// valueBounds summarizes the values of one column in a row group or page.
+// Only trusted statistics set hasMinMax.
+type valueBounds struct {
+	min, max           any
+	hasMinMax          bool
+	minExact, maxExact bool
+	allNull            bool
+	noNulls            bool
+	// bbox bounds the geometries of a column chunk. It is nil when unknown.
+	bbox *geospatial.BoundingBox
+	// bloomFilter loads the bloom filter of a column chunk, returning nil
+	// when there is none. It is nil for pages.
+	bloomFilter func() *BloomFilter
+}
+
+// boundsLookup returns the bounds of a column, or nil when nothing is known
+// about it.
+type boundsLookup func(column int) *valueBounds
+
+// boundPredicate is a predicate resolved against the schema of a file.
+type boundPredicate interface {
+	// mightMatch reports false only when no value within the bounds can
+	// match.
+	mightMatch(bounds boundsLookup) bool
+	// selectRows returns the rows of a row group whose pages might match.
+	selectRows(pages *pageIndexes) rowRanges
+	matches(row Row) bool
+	// appendColumns appends the columns the predicate reads.
+	appendColumns(columns []*schema.Column) []*schema.Column
+}
+
+// statsBounds converts column chunk statistics into value bounds.
+func statsBounds(statistics *metadata.Stats, numValues int64) *valueBounds {
+	if statistics == nil {
+		return nil
+	}
+	return &valueBounds{
+		min:       statistics.Min,
+		max:       statistics.Max,
+		hasMinMax: statistics.HasMinMax && statistics.Trusted,
+		minExact:  statistics.IsMinExact,
+		maxExact:  statistics.IsMaxExact,
+		allNull:   statistics.HasNullCount && statistics.NullCount == numValues,
+		noNulls:   statistics.HasNullCount && statistics.NullCount == 0,
+	}
+}
+
+// rowGroupBounds looks up the column chunk statistics, geospatial bounding
+// boxes and bloom filters of a row group. Bloom filters that cannot be read
+// are ignored.
+func rowGroupBounds(reader *ParquetReader, rowGroup int) boundsLookup {
+	return func(column int) *valueBounds {
+		chunk := reader.meta.RowGroup(rowGroup).Column(column)
+		if chunk.Format().GetMetaData() == nil {
+			return nil
+		}
+		bounds := statsBounds(chunk.Statistics(), chunk.NumValues())
+		if bounds == nil {
+			bounds = &valueBounds{}
+		}
+		if statistics := chunk.GeospatialStatistics(); statistics != nil {
+			bounds.bbox = statistics.BoundingBox
+		}
+		var filter *BloomFilter
+		var loaded bool
+		bounds.bloomFilter = func() *BloomFilter {
+			if !loaded {
+				filter, _ = reader.BloomFilter(rowGroup, column)
+				loaded = true
+			}
+			return filter
+		}
+		return bounds
+	}
+}
+
+type filterColumn struct {
+	column  *schema.Column
+	compare stats.Comparator
+}
+
+func bindColumn(meta *metadata.FileMeta, path string) (*filterColumn, error) {
+	for _, column := range meta.Columns() {
+		if column.PathString() == path {
+			return &filterColumn{
+				column:  column,
+				compare: stats.ComparatorOf(column.Leaf),
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("filter column %q not found", path)
+}
+
+// isNaN reports whether a physical value of the column is a NaN.
+func (c *filterColumn) isNaN(value any) bool { return stats.IsNaN(c.column.Leaf, value) }
+
+// hasNaN reports whether the column can hold NaN, which statistics leave out.
+func (c *filterColumn) hasNaN() bool {
+	leaf := c.column.Leaf
+	return leaf.Type == schema.Float || leaf.Type == schema.Double || stats.IsFloat16(leaf)
+}
+
+// equal compares physical values of the column. Zeros of either sign are
+// equal, and NaN equals nothing.
+func (c *filterColumn) equal(a, b any) bool {
+	if stats.IsFloat16(c.column.Leaf) {
+		return !c.isNaN(a) && !c.isNaN(b) && c.compare(a, b) == 0
+	}
+	return equalValues(a, b)
+}
+
+func (c *filterColumn) literal(value any) (any, error) {
+	if value == nil {
+		return nil, fmt.Errorf("filter on %q compares with nil, use IsNull", c.column.PathString())
+	}
+	literal, err := toPhysical(c.column.Leaf, value)
+	if err != nil {
+		return nil, fmt.Errorf("filter on %q: %w", c.column.PathString(), err)
+	}
+	return literal, nil
+}
+
+// values collects the non-null values of the column in an assembled row.
+func (c *filterColumn) values(row Row) []any {
+	var values []any
+	var walk func(value any, depth int)
+	walk = func(value any, depth int) {
+		if value == nil {
+			return
+		}
+		if list, ok := value.([]any); ok && c.column.Nodes[depth-1].Repetition == schema.Repeated {
+			for _, element := range list {
+				walk(element, depth)
+			}
+			return
+		}
+		if depth == len(c.column.Nodes) {
+			values = append(values, value)
+			return
+		}
+		if group, ok := toGroup(value); ok {
+			walk(group[c.column.Nodes[depth].Name], depth+1)
+		}
+	}
+	walk(map[string]any(row)[c.column.Nodes[0].Name], 1)
+	return values
+}
+
+func (p comparison) bind(meta *metadata.FileMeta) (boundPredicate, error) {
+	column, err := bindColumn(meta, p.column)
+	if err != nil {
+		return nil, err
+	}
+	literal, err := column.literal(p.value)
+	if err != nil {
+		return nil, err
+	}
+	if column.compare == nil && p.op != opEq && p.op != opNotEq {
+		return nil, fmt.Errorf("filter on %q: column of type %v has no defined order", p.column, column.column.Leaf.Type)
+	}
+	return newBoundComparison(column, p.op, literal), nil
+}
+
+func (p setMembership) bind(meta *metadata.FileMeta) (boundPredicate, error) {
+	column, err := bindColumn(meta, p.column)
+	if err != nil {
+		return nil, err
+	}
+	op := opEq
+	if p.negated {
+		op = opNotEq
+	}
+	set := &boundSet{filterColumn: column, negated: p.negated}
+	for _, value := range p.values {
+		literal, err := column.literal(value)
+		if err != nil {
+			return nil, err
+		}
+		set.comparisons = append(set.comparisons, newBoundComparison(column, op, literal))
+	}
+	return set, nil
+}
+
+func (p nullCheck) bind(meta *metadata.FileMeta) (boundPredicate, error) {
+	column, err := bindColumn(meta, p.column)
+	if err != nil {
+		return nil, err
+	}
+	return &boundNullCheck{filterColumn: column, negated: p.negated}, nil
+}
+
+func (p conjunction) bind(meta *metadata.FileMeta) (boundPredicate, error) {
+	bound := &boundConjunction{or: p.or}
+	for _, child := range p.children {
+		if child == nil {
+			return nil, errors.New("nil predicate in AND or OR")
+		}
+		boundChild, err := child.bind(meta)
+		if err != nil {
+			return nil, err
+		}
+		bound.children = append(bound.children, boundChild)
+	}
+	return bound, nil
+}
+
+func (p negation) bind(meta *metadata.FileMeta) (boundPredicate, error) {
+	if p.child == nil {
+		return nil, errors.New("nil predicate in NOT")
+	}
+	child, err := p.child.bind(meta)
+	if err != nil {
+		return nil, err
+	}
+	// A comparison on a repeated column matches rows where any element
+	// matches, and its negation must match rows where none does, which
+	// negating each comparison does not give.
+	for _, column := range child.appendColumns(nil) {
+		if column.MaxRepetitionLevel > 0 {
+			return &boundNegation{child: child}, nil
+		}
+	}
+	return p.child.negate().bind(meta)
+}
+
+type boundComparison struct {
+	*filterColumn
+	op    compareOp
+	value any
+	// hash is the bloom filter hash of value, set for equality only.
+	hash   uint64
+	hashed bool
+}
+
+func newBoundComparison(column *filterColumn, op compareOp, value any) *boundComparison {
+	p := &boundComparison{filterColumn: column, op: op, value: value}
+	if op == opEq {
+		p.hash, p.hashed = bloomHash(column.column.Leaf, value)
+	}
+	return p
+}
+
+func (p *boundComparison) mightMatch(lookup boundsLookup) bool {
+	bounds := lookup(p.column.Index)
+	if bounds == nil {
+		return true
+	}
+	if bounds.allNull || !p.mightMatchRange(bounds) {
+		return false
+	}
+	if p.hashed && bounds.bloomFilter != nil {
+		if filter := bounds.bloomFilter(); filter != nil && !filter.filter.Check(p.hash) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *boundComparison) mightMatchRange(bounds *valueBounds) bool {
+	if !bounds.hasMinMax || p.compare == nil || p.isNaN(p.value) {
+		return true
+	}
+
+	// Truncated bounds are still a lower and an upper bound, so only the
+	// single-value test for != needs them to be exact. Bounds leave NaN out
+	// and do not count them, so any float column might hold a NaN != value.
+	switch p.op {
+	case opEq:
+		return p.compare(bounds.min, p.value) <= 0 && p.compare(bounds.max, p.value) >= 0
+	case opNotEq:
+		return p.hasNaN() || !bounds.minExact || !bounds.maxExact ||
+			p.compare(bounds.min, p.value) != 0 || p.compare(bounds.max, p.value) != 0
+	case opLt:
+		return p.compare(bounds.min, p.value) < 0
+	case opLtEq:
+		return p.compare(bounds.min, p.value) <= 0
+	case opGt:
+		return p.compare(bounds.max, p.value) > 0
+	case opGtEq:
+		return p.compare(bounds.max, p.value) >= 0
+	}
+	return true
+}
+
+func (p *boundComparison) selectRows(pages *pageIndexes) rowRanges {
+	return pages.selectPages(p.column.Index, p)
+}
+
+func (p *boundComparison) appendColumns(columns []*schema.Column) []*schema.Column {
+	return append(columns, p.column)
+}
+
+func (p *boundComparison) matches(row Row) bool {
+	for _, value := range p.values(row) {
+		if p.matchesValue(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *boundComparison) matchesValue(value any) bool {
+	switch p.op {
+	case opEq:
+		return p.equal(value, p.value)
+	case opNotEq:
+		return !p.equal(value, p.value)
+	}
+	if p.isNaN(value) || p.isNaN(p.value) {
+		return false
+	}
+	c := p.compare(value, p.value)
+	switch p.op {
+	case opLt:
+		return c < 0
+	case opLtEq:
+		return c <= 0
+	case opGt:
+		return c > 0
+	}
+	return c >= 0
+}
+
+// boundSet evaluates IN as a disjunction of equalities and NOT IN as a
+// conjunction of inequalities. An empty NOT IN matches every non-null value.
+type boundSet struct {
+	*filterColumn
+	comparisons []*boundComparison
+	negated     bool
+}
+
+func (p *boundSet) mightMatch(lookup boundsLookup) bool {
+	if len(p.comparisons) == 0 && p.negated {
+		bounds := lookup(p.column.Index)
+		return bounds == nil || !bounds.allNull
+	}
+	for _, comparison := range p.comparisons {
+		if comparison.mightMatch(lookup) != p.negated {
+			return !p.negated
+		}
+	}
+	return p.negated
+}
+
+func (p *boundSet) selectRows(pages *pageIndexes) rowRanges {
+	if len(p.comparisons) == 0 && !p.negated {
+		return rowRanges{}
+	}
+	return pages.selectPages(p.column.Index, p)
+}
+
+func (p *boundSet) appendColumns(columns []*schema.Column) []*schema.Column {
+	return append(columns, p.column)
+}
+
+func (p *boundSet) matches(row Row) bool {
+	for _, value := range p.values(row) {
+		matched := p.negated
+		for _, comparison := range p.comparisons {
+			if comparison.matchesValue(value) != p.negated {
+				matched = !p.negated
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+type boundNullCheck struct {
+	*filterColumn
+	negated bool
+}
+
+func (p *boundNullCheck) mightMatch(lookup boundsLookup) bool {
+	bounds := lookup(p.column.Index)
+	if bounds == nil {
+		return true
+	}
+	if p.negated {
+		return !bounds.allNull
+	}
+	return !bounds.noNulls
+}
+
+func (p *boundNullCheck) selectRows(pages *pageIndexes) rowRanges {
+	return pages.selectPages(p.column.Index, p)
+}
+
+func (p *boundNullCheck) appendColumns(columns []*schema.Column) []*schema.Column {
+	return append(columns, p.column)
+}
+
+func (p *boundNullCheck) matches(row Row) bool {
+	return (len(p.values(row)) == 0) != p.negated
+}
+
+type boundConjunction struct {
+	children []boundPredicate
+	or       bool
+}
+
+func (p *boundConjunction) mightMatch(lookup boundsLookup) bool {
+	for _, child := range p.children {
+		if child.mightMatch(lookup) == p.or {
+			return p.or
+		}
+	}
+	return !p.or
+}
+
+func (p *boundConjunction) selectRows(pages *pageIndexes) rowRanges {
+	ranges := rowRanges{}
+	if !p.or {
+		ranges = allRows(pages.numRows)
+	}
+	for _, child := range p.children {
+		if p.or {
+			ranges = ranges.union(child.selectRows(pages))
+		} else {
+			ranges = ranges.intersection(child.selectRows(pages))
+		}
+	}
+	return ranges
+}
+
+func (p *boundConjunction) appendColumns(columns []*schema.Column) []*schema.Column {
+	for _, child := range p.children {
+		columns = child.appendColumns(columns)
+	}
+	return columns
+}
+
+func (p *boundConjunction) matches(row Row) bool {
+	for _, child := range p.children {
+		if child.matches(row) == p.or {
+			return p.or
+		}
+	}
+	return !p.or
+}
+
+// boundNegation negates a predicate over repeated columns row by row. The
+// bounds of the child say nothing about the rows it does not match, so it
+// prunes nothing.
+type boundNegation struct {
+	child boundPredicate
+}
+
+func (p *boundNegation) mightMatch(boundsLookup) bool { return true }
+
+func (p *boundNegation) selectRows(pages *pageIndexes) rowRanges { return allRows(pages.numRows) }
+
+func (p *boundNegation) appendColumns(columns []*schema.Column) []*schema.Column {
+	return p.child.appendColumns(columns)
+}
+
+func (p *boundNegation) matches(row Row) bool { return !p.child.matches(row) }
+
+func equalValues(a, b any) bool {
+	if x, ok := a.([]byte); ok {
+		y, ok := b.([]byte)
+		return ok && bytes.Equal(x, y)
+	}
+	return a == b
+}
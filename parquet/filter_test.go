@@ -0,0 +1,168 @@
+package parquet
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+func TestFilterPrunesRowGroups(t *testing.T) {
+	data := writeTestFile(t, testRows(100), WithRowGroupSize(10))
+
+	testcases := map[string]struct {
+		filter Predicate
+		pruned int
+		rows   int
+	}{
+		"eq":                {filter: Eq("id", 15), pruned: 9, rows: 1},
+		"not eq":            {filter: NotEq("id", 15), pruned: 0, rows: 99},
+		"lt":                {filter: Lt("id", 20), pruned: 8, rows: 20},
+		"lt eq":             {filter: LtEq("id", 20), pruned: 7, rows: 21},
+		"gt":                {filter: Gt("id", 89), pruned: 9, rows: 10},
+		"gt eq":             {filter: GtEq("id", 95), pruned: 9, rows: 5},
+		"in":                {filter: In("id", 5, 55), pruned: 8, rows: 2},
+		"empty in":          {filter: In("id"), pruned: 10, rows: 0},
+		"not":               {filter: Not(Lt("id", 90)), pruned: 9, rows: 10},
+		"not in":            {filter: Not(In("id", 5, 55)), pruned: 0, rows: 98},
+		"empty not in":      {filter: Not(In("id")), pruned: 0, rows: 100},
+		"empty not in null": {filter: Not(In("name")), pruned: 0, rows: 66},
+		"and":               {filter: And(GtEq("id", 10), LtEq("id", 29)), pruned: 8, rows: 20},
+		"or":                {filter: Or(Eq("id", 3), Gt("score", 95.0)), pruned: 9, rows: 5},
+		"not and":           {filter: Not(And(GtEq("id", 10), Lt("id", 90))), pruned: 8, rows: 20},
+		"is null":           {filter: IsNull("name"), pruned: 0, rows: 34},
+		"is not null":       {filter: IsNotNull("score"), pruned: 0, rows: 100},
+		"out of range":      {filter: Gt("score", 1000.0), pruned: 10, rows: 0},
+		"repeated match":    {filter: Eq("tags.key", "x"), pruned: 0, rows: 50},
+		"repeated absent":   {filter: Eq("tags.key", "z"), pruned: 10, rows: 0},
+		"repeated not":      {filter: Not(Eq("tags.key", "x")), pruned: 0, rows: 50},
+		"repeated not all":  {filter: Not(Eq("tags.key", "z")), pruned: 0, rows: 100},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			reader, err := Open(bytes.NewReader(data), int64(len(data)), WithFilter(test.filter))
+			if err != nil {
+				t.Fatalf("unable to open file: %v", err)
+			}
+			defer reader.Close()
+
+			if reader.PrunedRowGroups() != test.pruned || len(reader.RowGroups()) != 10-test.pruned {
+				t.Errorf("expected %d pruned row groups, got %d (kept %v)", test.pruned, reader.PrunedRowGroups(), reader.RowGroups())
+			}
+			if rows := readAllRows(t, reader); len(rows) != test.rows {
+				t.Errorf("expected %d rows, got %d", test.rows, len(rows))
+			}
+		})
+	}
+}
+
+func TestFilterWithoutTrustedStatistics(t *testing.T) {
+	testcases := map[string]struct {
+		file   string
+		filter Predicate
+		rows   int
+	}{
+		"no statistics": {
+			file:   filepath.Join("apache_examples", "alltypes_plain.parquet"),
+			filter: Eq("id", 100),
+		},
+		"deprecated binary statistics": {
+			file:   filepath.Join("timestored_examples", "userdata.parquet"),
+			filter: Eq("first_name", "Amanda"),
+			rows:   7,
+		},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("..", "testdata", test.file))
+			if err != nil {
+				t.Fatalf("unable to open file: %v", err)
+			}
+			defer f.Close()
+			fileStat, err := f.Stat()
+			if err != nil {
+				t.Fatalf("unable to get filestat: %v", err)
+			}
+			reader, err := Open(f, fileStat.Size(), WithFilter(test.filter))
+			if err != nil {
+				t.Fatalf("unable to open file: %v", err)
+			}
+
+			if reader.PrunedRowGroups() != 0 {
+				t.Errorf("expected no pruned row groups, got %d", reader.PrunedRowGroups())
+			}
+			if rows := readAllRows(t, reader); len(rows) != test.rows {
+				t.Errorf("expected %d rows, got %d", test.rows, len(rows))
+			}
+		})
+	}
+}
+
+func TestFilterBindErrors(t *testing.T) {
+	data := writeTestFile(t, testRows(10))
+
+	testcases := map[string]Predicate{
+		"unknown column": Eq("missing", 1),
+		"group column":   IsNull("tags"),
+		"wrong type":     Lt("id", "ten"),
+		"nil literal":    Eq("name", nil),
+		"nested unknown": And(Eq("id", 1), Not(IsNull("tags.value"))),
+		"nil child":      Or(Eq("id", 1), nil),
+	}
+
+	for name, filter := range testcases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Open(bytes.NewReader(data), int64(len(data)), WithFilter(filter)); err == nil {
+				t.Errorf("expected an error for %v", filter)
+			}
+		})
+	}
+}
+
+func TestFilterNotEqWithNaN(t *testing.T) {
+	root := schema.NewSchema(schema.NewLeaf("x", schema.Double, schema.Required))
+	var buffer bytes.Buffer
+	writer, err := NewWriter(&buffer, root, WithRowGroupSize(2), WithPageSize(1), WithPageIndex(true))
+	if err != nil {
+		t.Fatalf("unable to create writer: %v", err)
+	}
+	// The statistics of both row groups and of all pages are [5, 5].
+	for _, x := range []float64{5, math.NaN(), 5, 5} {
+		if err := writer.Write(Row{"x": x}); err != nil {
+			t.Fatalf("unable to write row: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("unable to close writer: %v", err)
+	}
+	data := buffer.Bytes()
+
+	testcases := map[string]Predicate{
+		"not eq":     NotEq("x", 5.0),
+		"negated eq": Not(Eq("x", 5.0)),
+		"not in":     Not(In("x", 5.0, 6.0)),
+	}
+
+	for name, filter := range testcases {
+		t.Run(name, func(t *testing.T) {
+			reader, err := Open(bytes.NewReader(data), int64(len(data)), WithFilter(filter))
+			if err != nil {
+				t.Fatalf("unable to open file: %v", err)
+			}
+			defer reader.Close()
+
+			if reader.PrunedRowGroups() != 0 {
+				t.Errorf("expected no pruned row groups, got %d", reader.PrunedRowGroups())
+			}
+			rows := readAllRows(t, reader)
+			if len(rows) != 1 || !math.IsNaN(rows[0]["x"].(float64)) {
+				t.Errorf("expected the NaN row, got %v", rows)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package parquet
+
+import (
+	"fmt"
+
+	"github.com/RichardNooooh/parquet-go/geospatial"
+	format "github.com/RichardNooooh/parquet-go/internal/format/gen-go/parquet"
+	"github.com/RichardNooooh/parquet-go/metadata"
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+// isGeospatial reports whether a leaf holds GEOMETRY or GEOGRAPHY values.
+func isGeospatial(leaf *schema.SchemaElement) bool {
+	return leaf.LogicalType != nil && (leaf.LogicalType.Kind == schema.LogicalGeometry || leaf.LogicalType.Kind == schema.LogicalGeography)
+}
+
+// encodeGeometry encodes geometries written to GEOMETRY and GEOGRAPHY
+// columns as WKB.
+func encodeGeometry(value any) (any, error) {
+	switch v := value.(type) {
+	case geospatial.Geometry:
+		return v.WKB(), nil
+	case *geospatial.Geometry:
+		if v != nil {
+			return v.WKB(), nil
+		}
+	}
+	return value, nil
+}
+
+// geospatialStatistics computes the statistics of the WKB values of a
+// column chunk, or returns nil when a value is not valid WKB. GEOGRAPHY
+// chunks have no bounding box, since their edges are not straight lines
+// between the coordinates.
+func geospatialStatistics(leaf *schema.SchemaElement, values []any) *format.GeospatialStatistics {
+	accumulator := geospatial.NewAccumulator()
+	for _, value := range values {
+		accumulator.Add(value.([]byte))
+	}
+	statistics := accumulator.Statistics()
+	if statistics == nil {
+		return nil
+	}
+	encoded := &format.GeospatialStatistics{}
+	if len(statistics.Types) > 0 {
+		encoded.GeospatialTypes = statistics.Types
+	}
+	if box := statistics.BoundingBox; box != nil && leaf.LogicalType.Kind == schema.LogicalGeometry {
+		encoded.Bbox = &format.BoundingBox{Xmin: box.Xmin, Xmax: box.Xmax, Ymin: box.Ymin, Ymax: box.Ymax}
+		if box.HasZ {
+			encoded.Bbox.Zmin, encoded.Bbox.Zmax = &box.Zmin, &box.Zmax
+		}
+		if box.HasM {
+			encoded.Bbox.Mmin, encoded.Bbox.Mmax = &box.Mmin, &box.Mmax
+		}
+	}
+	return encoded
+}
+
+// intersection matches rows where the bounding box of a GEOMETRY or
+// GEOGRAPHY value intersects a box.
+type intersection struct {
+	column  string
+	box     geospatial.BoundingBox
+	negated bool
+}
+
+// Intersects matches rows where the bounding box of the column's geometry
+// intersects the box. Row groups are skipped using the bounding boxes of
+// their statistics. Values that are not valid WKB never match.
+func Intersects(column string, box geospatial.BoundingBox) Predicate {
+	return intersection{column: column, box: box}
+}
+
+func (p intersection) String() string {
+	op := "INTERSECTS"
+	if p.negated {
+		op = "NOT INTERSECTS"
+	}
+	return fmt.Sprintf("%s %s BOX(%v %v, %v %v)", p.column, op, p.box.Xmin, p.box.Ymin, p.box.Xmax, p.box.Ymax)
+}
+
+func (p intersection) negate() Predicate {
+	return intersection{column: p.column, box: p.box, negated: !p.negated}
+}
+
+func (p intersection) bind(meta *metadata.FileMeta) (boundPredicate, error) {
+	column, err := bindColumn(meta, p.column)
+	if err != nil {
+		return nil, err
+	}
+	if leaf := column.column.Leaf; leaf.Type != schema.ByteArray || !isGeospatial(leaf) {
+		return nil, fmt.Errorf("filter on %q: column is not a GEOMETRY or GEOGRAPHY column", p.column)
+	}
+	return &boundIntersection{filterColumn: column, box: p.box, negated: p.negated}, nil
+}
+
+type boundIntersection struct {
+	*filterColumn
+	box     geospatial.BoundingBox
+	negated bool
+}
+
+// mightMatch skips chunks without values, and chunks whose bounding box is
+// disjoint from the box. Negated, only chunks without values are skipped.
+func (p *boundIntersection) mightMatch(lookup boundsLookup) bool {
+	bounds := lookup(p.column.Index)
+	if bounds == nil {
+		return true
+	}
+	if bounds.allNull {
+		return false
+	}
+	return p.negated || bounds.bbox == nil || bounds.bbox.Intersects(p.box)
+}
+
+func (p *boundIntersection) selectRows(pages *pageIndexes) rowRanges {
+	return pages.selectPages(p.column.Index, p)
+}
+
+func (p *boundIntersection) appendColumns(columns []*schema.Column) []*schema.Column {
+	return append(columns, p.column)
+}
+
+func (p *boundIntersection) matches(row Row) bool {
+	for _, value := range p.values(row) {
+		wkb, ok := value.([]byte)
+		if !ok {
+			continue
+		}
+		geometry, err := geospatial.ParseWKB(wkb)
+		if err != nil {
+			continue
+		}
+		box, ok := geometry.Bounds()
+		if (ok && box.Intersects(p.box)) != p.negated {
+			return true
+		}
+	}
+	return false
+}
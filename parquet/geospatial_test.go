@@ -0,0 +1,154 @@
+package parquet
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/RichardNooooh/parquet-go/geospatial"
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+func geometrySchema(kind schema.LogicalKind) *schema.SchemaElement {
+	trace := schema.NewLeaf("trace", schema.ByteArray, schema.Optional)
+	trace.LogicalType = &schema.LogicalType{Kind: kind}
+	return schema.NewSchema(schema.NewLeaf("id", schema.Int64, schema.Required), trace)
+}
+
+// gpsTrace returns a line string of n points heading north-east from
+// (x, y).
+func gpsTrace(x, y float64, n int) geospatial.Geometry {
+	trace := geospatial.Geometry{Type: geospatial.LineString}
+	for i := range n {
+		trace.Coordinates = append(trace.Coordinates, geospatial.Coordinate{X: x + float64(i)*0.1, Y: y + float64(i)*0.1})
+	}
+	return trace
+}
+
+// writeTraces writes 40 rows in row groups of 10, where the traces of row
+// group g start at (10g, 10g) and every fifth trace is null.
+func writeTraces(t *testing.T, root *schema.SchemaElement) []byte {
+	t.Helper()
+	var buffer bytes.Buffer
+	writer, err := NewWriter(&buffer, root, WithRowGroupSize(10), WithPageIndex(true))
+	if err != nil {
+		t.Fatalf("unable to create writer: %v", err)
+	}
+	for i := range 40 {
+		row := Row{"id": int64(i)}
+		if i%5 != 4 {
+			origin := float64(i / 10 * 10)
+			row["trace"] = gpsTrace(origin+float64(i%10)*0.5, origin, 3)
+		}
+		if err := writer.Write(row); err != nil {
+			t.Fatalf("unable to write row: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("unable to close writer: %v", err)
+	}
+	return buffer.Bytes()
+}
+
+func TestWriterGeospatialStatistics(t *testing.T) {
+	data := writeTraces(t, geometrySchema(schema.LogicalGeometry))
+	reader, err := Open(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("unable to open file: %v", err)
+	}
+	defer reader.Close()
+
+	chunk := reader.GetMeta().RowGroup(1).Column(1)
+	expected := &geospatial.Statistics{
+		BoundingBox: &geospatial.BoundingBox{Xmin: 10, Xmax: 14.2, Ymin: 10, Ymax: 10.2},
+		Types:       []int32{2},
+	}
+	statistics := chunk.GeospatialStatistics()
+	if statistics == nil || statistics.BoundingBox == nil {
+		t.Fatalf("expected geospatial statistics, got %+v", statistics)
+	}
+	box := *statistics.BoundingBox
+	if box.Xmin != 10 || box.Xmax < 14.19 || box.Xmax > 14.21 || box.Ymin != 10 || box.Ymax < 10.19 || box.Ymax > 10.21 {
+		t.Errorf("expected bounding box %+v, got %+v", *expected.BoundingBox, box)
+	}
+	if !reflect.DeepEqual(statistics.Types, expected.Types) {
+		t.Errorf("expected types %v, got %v", expected.Types, statistics.Types)
+	}
+	if chunk.Statistics().HasMinMax {
+		t.Errorf("expected no min and max for geometries")
+	}
+}
+
+func TestWriterGeographyStatistics(t *testing.T) {
+	data := writeTraces(t, geometrySchema(schema.LogicalGeography))
+	reader, err := Open(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("unable to open file: %v", err)
+	}
+	defer reader.Close()
+
+	statistics := reader.GetMeta().RowGroup(0).Column(1).GeospatialStatistics()
+	if statistics == nil || statistics.BoundingBox != nil || !reflect.DeepEqual(statistics.Types, []int32{2}) {
+		t.Errorf("expected types without bounding box, got %+v", statistics)
+	}
+}
+
+func TestReaderGeometry(t *testing.T) {
+	data := writeTraces(t, geometrySchema(schema.LogicalGeometry))
+	reader, err := Open(bytes.NewReader(data), int64(len(data)), WithLogicalTypes(true))
+	if err != nil {
+		t.Fatalf("unable to open file: %v", err)
+	}
+	defer reader.Close()
+
+	rows := readAllRows(t, reader)
+	if expected := gpsTrace(0.5, 0, 3); !reflect.DeepEqual(rows[1]["trace"], expected) {
+		t.Errorf("expected %+v, got %+v", expected, rows[1]["trace"])
+	}
+	if rows[4]["trace"] != nil {
+		t.Errorf("expected null trace, got %+v", rows[4]["trace"])
+	}
+}
+
+func TestFilterIntersects(t *testing.T) {
+	data := writeTraces(t, geometrySchema(schema.LogicalGeometry))
+
+	testcases := map[string]struct {
+		filter Predicate
+		pruned int
+		rows   int
+	}{
+		"one row group":  {filter: Intersects("trace", geospatial.BoundingBox{Xmin: 20, Xmax: 21, Ymin: 19, Ymax: 21}), pruned: 3, rows: 3},
+		"two row groups": {filter: Intersects("trace", geospatial.BoundingBox{Xmin: 4, Xmax: 11, Ymin: 0, Ymax: 11}), pruned: 2, rows: 4},
+		"nowhere":        {filter: Intersects("trace", geospatial.BoundingBox{Xmin: -5, Xmax: -1, Ymin: -5, Ymax: -1}), pruned: 4, rows: 0},
+		"negated":        {filter: Not(Intersects("trace", geospatial.BoundingBox{Xmin: 20, Xmax: 21, Ymin: 19, Ymax: 21})), pruned: 0, rows: 29},
+		"and":            {filter: And(Intersects("trace", geospatial.BoundingBox{Xmin: 0, Xmax: 100, Ymin: 0, Ymax: 100}), Lt("id", int64(10))), pruned: 3, rows: 8},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			reader, err := Open(bytes.NewReader(data), int64(len(data)), WithFilter(test.filter))
+			if err != nil {
+				t.Fatalf("unable to open file: %v", err)
+			}
+			defer reader.Close()
+
+			if reader.PrunedRowGroups() != test.pruned {
+				t.Errorf("expected %d pruned row groups, got %d", test.pruned, reader.PrunedRowGroups())
+			}
+			if rows := readAllRows(t, reader); len(rows) != test.rows {
+				t.Errorf("expected %d rows, got %d", test.rows, len(rows))
+			}
+		})
+	}
+}
+
+func TestFilterIntersectsBindErrors(t *testing.T) {
+	data := writeTraces(t, geometrySchema(schema.LogicalGeometry))
+	for _, column := range []string{"id", "missing"} {
+		filter := Intersects(column, geospatial.BoundingBox{})
+		if _, err := Open(bytes.NewReader(data), int64(len(data)), WithFilter(filter)); err == nil {
+			t.Errorf("expected an error filtering on %q", column)
+		}
+	}
+}
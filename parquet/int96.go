@@ -0,0 +1,227 @@
+package parquet
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+// RebaseMode is how INT96 timestamps before the switch to the Gregorian
+// calendar on 1582-10-15 are read and written. Hive, Impala and Spark 2
+// stored them in the hybrid Julian calendar, while Go and Spark 3 use the
+// proleptic Gregorian calendar. Timestamps are rebased in UTC.
+type RebaseMode int
+
+const (
+	// RebaseCorrected reads and writes timestamps as they are.
+	RebaseCorrected RebaseMode = iota
+	// RebaseLegacy converts timestamps before the switch between the hybrid
+	// Julian calendar of the file and the proleptic Gregorian calendar.
+	RebaseLegacy
+	// RebaseException fails on timestamps before the switch, whose meaning
+	// depends on the writer.
+	RebaseException
+)
+
+// ErrAmbiguousTimestamp is returned in RebaseException mode for INT96
+// timestamps before the switch to the Gregorian calendar.
+var ErrAmbiguousTimestamp = errors.New("INT96 timestamp before 1582-10-15 is ambiguous")
+
+const (
+	// julianUnixEpoch is the Julian day number of 1970-01-01.
+	julianUnixEpoch = 2440588
+	// gregorianSwitch is the Julian day number of 1582-10-15, the first day
+	// of the Gregorian calendar.
+	gregorianSwitch = 2299161
+	secondsPerDay   = 24 * 60 * 60
+
+	// sparkVersionKey and sparkLegacyInt96Key are the key-value metadata
+	// Spark writes to tell whether its INT96 timestamps are legacy.
+	sparkVersionKey     = "org.apache.spark.version"
+	sparkLegacyInt96Key = "org.apache.spark.legacyINT96"
+)
+
+// int96ToTime decodes a legacy INT96 timestamp: nanoseconds within the day
+// followed by the Julian day number, both little-endian.
+func int96ToTime(value [12]byte, mode RebaseMode) (time.Time, error) {
+	nanos := time.Duration(binary.LittleEndian.Uint64(value[:8]))
+	day := int64(binary.LittleEndian.Uint32(value[8:]))
+	if day < gregorianSwitch {
+		switch mode {
+		case RebaseLegacy:
+			year, month, dayOfMonth := julianCalendarDate(day)
+			return time.Date(year, month, dayOfMonth, 0, 0, 0, 0, time.UTC).Add(nanos), nil
+		case RebaseException:
+			return time.Time{}, ErrAmbiguousTimestamp
+		}
+	}
+	return time.Unix((day-julianUnixEpoch)*secondsPerDay, 0).Add(nanos).UTC(), nil
+}
+
+// timeToInt96 encodes t as a legacy INT96 timestamp in UTC.
+func timeToInt96(t time.Time, mode RebaseMode) ([12]byte, error) {
+	t = t.UTC()
+	year, month, dayOfMonth := t.Date()
+	midnight := time.Date(year, month, dayOfMonth, 0, 0, 0, 0, time.UTC)
+	day := midnight.Unix()/secondsPerDay + julianUnixEpoch
+	if day < gregorianSwitch {
+		switch mode {
+		case RebaseLegacy:
+			day = julianCalendarDay(year, month, dayOfMonth)
+		case RebaseException:
+			return [12]byte{}, ErrAmbiguousTimestamp
+		}
+	}
+	if day < 0 || day > math.MaxUint32 {
+		return [12]byte{}, fmt.Errorf("timestamp %v out of the INT96 range", t)
+	}
+
+	var value [12]byte
+	binary.LittleEndian.PutUint64(value[:8], uint64(t.Sub(midnight)))
+	binary.LittleEndian.PutUint32(value[8:], uint32(day))
+	return value, nil
+}
+
+// julianCalendarDate returns the date of a Julian day number in the Julian
+// calendar.
+func julianCalendarDate(day int64) (int, time.Month, int) {
+	c := day + 32082
+	d := (4*c + 3) / 1461
+	e := c - 1461*d/4
+	m := (5*e + 2) / 153
+	return int(d - 4800 + m/10), time.Month(m + 3 - 12*(m/10)), int(e - (153*m+2)/5 + 1)
+}
+
+// julianCalendarDay returns the Julian day number of a date of the Julian
+// calendar.
+func julianCalendarDay(year int, month time.Month, day int) int64 {
+	a := (14 - int64(month)) / 12
+	y := int64(year) + 4800 - a
+	m := int64(month) + 12*a - 3
+	return int64(day) + (153*m+2)/5 + 365*y + y/4 - 32083
+}
+
+// int96RebaseMode returns the rebase mode of a file. Files written by Spark
+// record whether their timestamps are legacy, which takes precedence over
+// the configured mode.
+func int96RebaseMode(keyValues map[string]string, mode RebaseMode) RebaseMode {
+	if _, ok := keyValues[sparkLegacyInt96Key]; ok {
+		return RebaseLegacy
+	}
+	version, ok := keyValues[sparkVersionKey]
+	if !ok {
+		return mode
+	}
+	// Spark rebases INT96 timestamps since 3.1.
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) >= 2 {
+		major, majorErr := strconv.Atoi(parts[0])
+		minor, minorErr := strconv.Atoi(parts[1])
+		if majorErr == nil && minorErr == nil && (major < 3 || major == 3 && minor < 1) {
+			return RebaseLegacy
+		}
+	}
+	return RebaseCorrected
+}
+
+// newInt96Converter returns the converter of an INT96 column to time.Time,
+// or to int64 nanoseconds since the epoch when asTime is false.
+func newInt96Converter(mode RebaseMode, asTime bool) valueConverter {
+	return func(value any) (any, error) {
+		t, err := int96ToTime(value.([12]byte), mode)
+		if err != nil || asTime {
+			return t, err
+		}
+		nanos := t.UnixNano()
+		if !time.Unix(0, nanos).Equal(t) {
+			return nil, fmt.Errorf("timestamp %v out of the TIMESTAMP(NANOS) range", t)
+		}
+		return nanos, nil
+	}
+}
+
+// coerceInt96 returns a copy of the schema in which INT96 columns are
+// TIMESTAMP(NANOS) columns adjusted to UTC.
+func coerceInt96(element *schema.SchemaElement) *schema.SchemaElement {
+	coerced := *element
+	if element.IsLeaf() {
+		if element.Type == schema.Int96 {
+			coerced.Type = schema.Int64
+			coerced.LogicalType = &schema.LogicalType{Kind: schema.LogicalTimestamp, Unit: schema.Nanos, IsAdjustedToUTC: true}
+		}
+		return &coerced
+	}
+	coerced.Children = make([]*schema.SchemaElement, len(element.Children))
+	for i, child := range element.Children {
+		coerced.Children[i] = coerceInt96(child)
+	}
+	return &coerced
+}
+
+// int96Timestamps returns a copy of the schema in which TIMESTAMP columns
+// are INT96 columns, and the time unit of each of them.
+func int96Timestamps(element *schema.SchemaElement, units map[*schema.SchemaElement]schema.TimeUnit) *schema.SchemaElement {
+	replaced := *element
+	if element.IsLeaf() {
+		if unit, ok := timestampUnit(element); ok {
+			replaced.Type = schema.Int96
+			replaced.LogicalType = nil
+			replaced.ConvertedType = schema.NoConvertedType
+			units[&replaced] = unit
+		}
+		return &replaced
+	}
+	replaced.Children = make([]*schema.SchemaElement, len(element.Children))
+	for i, child := range element.Children {
+		replaced.Children[i] = int96Timestamps(child, units)
+	}
+	return &replaced
+}
+
+// timestampUnit returns the unit of an INT64 TIMESTAMP column.
+func timestampUnit(leaf *schema.SchemaElement) (schema.TimeUnit, bool) {
+	if leaf.Type != schema.Int64 {
+		return 0, false
+	}
+	if leaf.LogicalType != nil {
+		return leaf.LogicalType.Unit, leaf.LogicalType.Kind == schema.LogicalTimestamp
+	}
+	switch leaf.ConvertedType {
+	case schema.TimestampMillis:
+		return schema.Millis, true
+	case schema.TimestampMicros:
+		return schema.Micros, true
+	}
+	return 0, false
+}
+
+// newInt96Encoder returns the conversion of the values written to an INT96
+// column. It takes time.Time, and int64 timestamps of unit when the column
+// replaces a TIMESTAMP column.
+func newInt96Encoder(mode RebaseMode, unit schema.TimeUnit, hasUnit bool) func(any) (any, error) {
+	return func(value any) (any, error) {
+		switch v := value.(type) {
+		case time.Time:
+			return timeToInt96(v, mode)
+		case int64:
+			if !hasUnit {
+				break
+			}
+			// A duration only spans 292 years around the epoch.
+			switch unit {
+			case schema.Micros:
+				return timeToInt96(time.UnixMicro(v), mode)
+			case schema.Nanos:
+				return timeToInt96(time.Unix(0, v), mode)
+			}
+			return timeToInt96(time.UnixMilli(v), mode)
+		}
+		return value, nil
+	}
+}
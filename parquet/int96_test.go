@@ -0,0 +1,213 @@
+package parquet
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+func TestInt96Time(t *testing.T) {
+	testcases := map[string]struct {
+		value    [12]byte
+		mode     RebaseMode
+		expected time.Time
+	}{
+		"afternoon":          {value: [12]byte{0, 0xc0, 0xaf, 0xd6, 0x91, 0x36, 0, 0, 0x59, 0x68, 0x25, 0}, expected: time.Date(2000, time.January, 1, 16, 40, 0, 0, time.UTC)},
+		"unix epoch":         {value: int96Value(julianUnixEpoch, 0), expected: time.Unix(0, 0).UTC()},
+		"before unix epoch":  {value: int96Value(julianUnixEpoch-1, 1), expected: time.Date(1969, time.December, 31, 0, 0, 0, 1, time.UTC)},
+		"first gregorian":    {value: int96Value(gregorianSwitch, 0), mode: RebaseException, expected: time.Date(1582, time.October, 15, 0, 0, 0, 0, time.UTC)},
+		"corrected":          {value: int96Value(2086308, 0), expected: time.Date(1000, time.January, 6, 0, 0, 0, 0, time.UTC)},
+		"legacy":             {value: int96Value(2086308, 0), mode: RebaseLegacy, expected: time.Date(1000, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		"legacy last julian": {value: int96Value(gregorianSwitch-1, 0), mode: RebaseLegacy, expected: time.Date(1582, time.October, 4, 0, 0, 0, 0, time.UTC)},
+		"legacy gregorian":   {value: int96Value(julianUnixEpoch, 0), mode: RebaseLegacy, expected: time.Unix(0, 0).UTC()},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			got, err := int96ToTime(test.value, test.mode)
+			if err != nil {
+				t.Fatalf("unable to decode: %v", err)
+			}
+			if !got.Equal(test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, got)
+			}
+
+			value, err := timeToInt96(test.expected, test.mode)
+			if err != nil {
+				t.Fatalf("unable to encode: %v", err)
+			}
+			if value != test.value {
+				t.Errorf("expected %v, got %v", test.value, value)
+			}
+		})
+	}
+}
+
+func TestInt96TimeRebaseException(t *testing.T) {
+	if _, err := int96ToTime(int96Value(gregorianSwitch-1, 0), RebaseException); !errors.Is(err, ErrAmbiguousTimestamp) {
+		t.Errorf("expected ErrAmbiguousTimestamp on read, got %v", err)
+	}
+	if _, err := timeToInt96(time.Date(1582, time.October, 14, 23, 0, 0, 0, time.UTC), RebaseException); !errors.Is(err, ErrAmbiguousTimestamp) {
+		t.Errorf("expected ErrAmbiguousTimestamp on write, got %v", err)
+	}
+	if _, err := timeToInt96(time.Date(1582, time.October, 15, 1, 0, 0, 0, time.FixedZone("", 2*60*60)), RebaseException); !errors.Is(err, ErrAmbiguousTimestamp) {
+		t.Errorf("expected ErrAmbiguousTimestamp for a timestamp before the switch in UTC, got %v", err)
+	}
+}
+
+func TestInt96RebaseMode(t *testing.T) {
+	testcases := map[string]struct {
+		keyValues map[string]string
+		mode      RebaseMode
+		expected  RebaseMode
+	}{
+		"not spark":        {mode: RebaseException, expected: RebaseException},
+		"spark 2.4":        {keyValues: map[string]string{sparkVersionKey: "2.4.8"}, mode: RebaseException, expected: RebaseLegacy},
+		"spark 3.0":        {keyValues: map[string]string{sparkVersionKey: "3.0.3"}, expected: RebaseLegacy},
+		"spark 3.1":        {keyValues: map[string]string{sparkVersionKey: "3.1.0"}, mode: RebaseLegacy, expected: RebaseCorrected},
+		"spark 10.0":       {keyValues: map[string]string{sparkVersionKey: "10.0.0"}, mode: RebaseLegacy, expected: RebaseCorrected},
+		"spark legacy":     {keyValues: map[string]string{sparkVersionKey: "3.5.0", sparkLegacyInt96Key: ""}, expected: RebaseLegacy},
+		"legacy marker":    {keyValues: map[string]string{sparkLegacyInt96Key: ""}, expected: RebaseLegacy},
+		"unparsed version": {keyValues: map[string]string{sparkVersionKey: "unknown"}, mode: RebaseLegacy, expected: RebaseCorrected},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			if got := int96RebaseMode(test.keyValues, test.mode); got != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestReaderInt96AsTimestamp(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "testdata", "apache_examples", "alltypes_plain.parquet"))
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+	timestamp := time.Date(2009, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	reader := openTestFile(t, data, WithInt96AsTimestamp(true), WithColumns("id", "timestamp_col"))
+	leaf := reader.GetSchema().Children[1]
+	expectedLogical := &schema.LogicalType{Kind: schema.LogicalTimestamp, Unit: schema.Nanos, IsAdjustedToUTC: true}
+	if leaf.Type != schema.Int64 || !reflect.DeepEqual(leaf.LogicalType, expectedLogical) {
+		t.Errorf("expected an INT64 TIMESTAMP(NANOS) column, got %v %v", leaf.Type, leaf.LogicalType)
+	}
+	if got := reader.GetMeta().Schema().Children[10].Type; got != schema.Int96 {
+		t.Errorf("expected the file schema to keep INT96, got %v", got)
+	}
+	expected := Row{"id": int32(4), "timestamp_col": timestamp.UnixNano()}
+	if got := readAllRows(t, reader)[0]; !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	reader = openTestFile(t, data, WithInt96AsTimestamp(true), WithLogicalTypes(true), WithColumns("timestamp_col"))
+	if got := readAllRows(t, reader)[0]["timestamp_col"]; got != timestamp {
+		t.Errorf("expected %v, got %v", timestamp, got)
+	}
+
+	// Without either option values keep their physical type.
+	if got := readAllRows(t, openTestFile(t, data, WithColumns("timestamp_col")))[0]["timestamp_col"]; reflect.TypeOf(got) != reflect.TypeOf([12]byte{}) {
+		t.Errorf("expected [12]byte, got %T", got)
+	}
+}
+
+func TestWriterInt96Timestamps(t *testing.T) {
+	root := schema.NewSchema(
+		schema.NewLeaf("id", schema.Int64, schema.Required),
+		logicalLeaf("at", schema.Int64, &schema.LogicalType{Kind: schema.LogicalTimestamp, Unit: schema.Micros, IsAdjustedToUTC: true}),
+	)
+	old := time.Date(1000, time.January, 1, 12, 0, 0, 0, time.UTC)
+	recent := time.Date(2024, time.February, 29, 8, 30, 0, 5000, time.UTC)
+	rows := []Row{
+		{"id": int64(1), "at": old},
+		{"id": int64(2), "at": recent.UnixMicro()},
+		{"id": int64(3), "at": old.UnixMicro()},
+	}
+
+	testcases := map[string]struct {
+		mode     RebaseMode
+		read     []ParquetReaderOption
+		expected time.Time
+		legacy   bool
+	}{
+		"corrected":           {expected: old},
+		"legacy":              {mode: RebaseLegacy, expected: old, legacy: true},
+		"legacy as exception": {mode: RebaseLegacy, read: []ParquetReaderOption{WithInt96ReadRebase(RebaseException)}, expected: old, legacy: true},
+		"corrected as legacy": {read: []ParquetReaderOption{WithInt96ReadRebase(RebaseLegacy)}, expected: time.Date(999, time.December, 27, 12, 0, 0, 0, time.UTC)},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			var buffer bytes.Buffer
+			writer, err := NewWriter(&buffer, root, WithInt96Timestamps(true), WithInt96WriteRebase(test.mode))
+			if err != nil {
+				t.Fatalf("unable to create writer: %v", err)
+			}
+			for _, row := range rows {
+				if err := writer.Write(row); err != nil {
+					t.Fatalf("unable to write row: %v", err)
+				}
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatalf("unable to close writer: %v", err)
+			}
+			if root.Children[1].Type != schema.Int64 {
+				t.Errorf("expected the schema of the caller to be unchanged")
+			}
+
+			reader := openTestFile(t, buffer.Bytes(), append(test.read, WithLogicalTypes(true))...)
+			leaf := reader.GetMeta().Schema().Children[1]
+			if leaf.Type != schema.Int96 || leaf.LogicalType != nil {
+				t.Errorf("expected an INT96 column, got %v %v", leaf.Type, leaf.LogicalType)
+			}
+			if _, ok := reader.GetMeta().KeyValueMetadata()[sparkLegacyInt96Key]; ok != test.legacy {
+				t.Errorf("expected legacy marker %v, got %v", test.legacy, ok)
+			}
+			got := readAllRows(t, reader)
+			if at := got[0]["at"]; at != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, at)
+			}
+			if at := got[1]["at"]; at != recent {
+				t.Errorf("expected %v, got %v", recent, at)
+			}
+			if at := got[2]["at"]; at != test.expected {
+				t.Errorf("expected %v from microseconds, got %v", test.expected, at)
+			}
+		})
+	}
+}
+
+func TestWriterInt96RebaseException(t *testing.T) {
+	root := schema.NewSchema(schema.NewLeaf("at", schema.Int96, schema.Required))
+	writer, err := NewWriter(&bytes.Buffer{}, root, WithInt96WriteRebase(RebaseException))
+	if err != nil {
+		t.Fatalf("unable to create writer: %v", err)
+	}
+	if err := writer.Write(Row{"at": time.Date(1500, time.May, 1, 0, 0, 0, 0, time.UTC)}); !errors.Is(err, ErrAmbiguousTimestamp) {
+		t.Errorf("expected ErrAmbiguousTimestamp, got %v", err)
+	}
+	if err := writer.Write(Row{"at": int96Value(julianUnixEpoch, 0)}); err != nil {
+		t.Errorf("expected raw INT96 values to be written as they are, got %v", err)
+	}
+	if err := writer.Write(Row{"at": int64(0)}); err == nil {
+		t.Errorf("expected error writing int64 to an INT96 column without a unit, got nil error")
+	}
+}
+
+func int96Value(day uint32, nanos uint64) [12]byte {
+	var value [12]byte
+	for i := range 8 {
+		value[i] = byte(nanos >> (8 * i))
+	}
+	for i := range 4 {
+		value[8+i] = byte(day >> (8 * i))
+	}
+	return value
+}
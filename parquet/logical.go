@@ -0,0 +1,345 @@
+package parquet
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/RichardNooooh/parquet-go/geospatial"
+	"github.com/RichardNooooh/parquet-go/internal/float16"
+	"github.com/RichardNooooh/parquet-go/metadata"
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+// Decimal is a DECIMAL value, Unscaled × 10^-Scale.
+type Decimal struct {
+	Unscaled *big.Int
+	Scale    int32
+}
+
+// Rat returns the exact value of the decimal.
+func (d Decimal) Rat() *big.Rat {
+	denominator := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(d.Scale)), nil)
+	return new(big.Rat).SetFrac(d.Unscaled, denominator)
+}
+
+func (d Decimal) String() string {
+	digits := new(big.Int).Abs(d.Unscaled).String()
+	sign := ""
+	if d.Unscaled.Sign() < 0 {
+		sign = "-"
+	}
+	if d.Scale <= 0 {
+		return sign + digits + strings.Repeat("0", int(-d.Scale))
+	}
+	if len(digits) <= int(d.Scale) {
+		digits = strings.Repeat("0", int(d.Scale)-len(digits)+1) + digits
+	}
+	point := len(digits) - int(d.Scale)
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// Interval is an INTERVAL value: a number of months, days and milliseconds
+// that are added independently.
+type Interval struct {
+	Months       uint32
+	Days         uint32
+	Milliseconds uint32
+}
+
+// convertedLogicalTypes maps the converted types that have an equivalent
+// logical type. Legacy times and timestamps are adjusted to UTC.
+var convertedLogicalTypes = map[schema.ConvertedType]*schema.LogicalType{
+	schema.UTF8:            {Kind: schema.LogicalString},
+	schema.Enum:            {Kind: schema.LogicalEnum},
+	schema.JSON:            {Kind: schema.LogicalJSON},
+	schema.BSON:            {Kind: schema.LogicalBSON},
+	schema.Date:            {Kind: schema.LogicalDate},
+	schema.TimeMillis:      {Kind: schema.LogicalTime, Unit: schema.Millis, IsAdjustedToUTC: true},
+	schema.TimeMicros:      {Kind: schema.LogicalTime, Unit: schema.Micros, IsAdjustedToUTC: true},
+	schema.TimestampMillis: {Kind: schema.LogicalTimestamp, Unit: schema.Millis, IsAdjustedToUTC: true},
+	schema.TimestampMicros: {Kind: schema.LogicalTimestamp, Unit: schema.Micros, IsAdjustedToUTC: true},
+	schema.Int8:            {Kind: schema.LogicalInteger, BitWidth: 8, IsSigned: true},
+	schema.Int16:           {Kind: schema.LogicalInteger, BitWidth: 16, IsSigned: true},
+	schema.Int32Converted:  {Kind: schema.LogicalInteger, BitWidth: 32, IsSigned: true},
+	schema.Int64Converted:  {Kind: schema.LogicalInteger, BitWidth: 64, IsSigned: true},
+	schema.Uint8:           {Kind: schema.LogicalInteger, BitWidth: 8},
+	schema.Uint16:          {Kind: schema.LogicalInteger, BitWidth: 16},
+	schema.Uint32:          {Kind: schema.LogicalInteger, BitWidth: 32},
+	schema.Uint64:          {Kind: schema.LogicalInteger, BitWidth: 64},
+}
+
+// valueConverter converts a physical value of a leaf to its logical Go value.
+type valueConverter func(value any) (any, error)
+
+// newValueConverter returns the converter of a leaf, or nil when its values
+// are read as they are stored. INT96 columns have their own converter.
+func newValueConverter(leaf *schema.SchemaElement) (valueConverter, error) {
+	if logical := leafLogicalType(leaf); logical != nil {
+		return logicalConverter(leaf, logical)
+	}
+	if leaf.ConvertedType == schema.Interval {
+		if leaf.Type != schema.FixedLenByteArray || leaf.TypeLength != 12 {
+			return nil, invalidAnnotation(leaf, "INTERVAL")
+		}
+		return func(value any) (any, error) {
+			b := value.([]byte)
+			return Interval{
+				Months:       binary.LittleEndian.Uint32(b),
+				Days:         binary.LittleEndian.Uint32(b[4:]),
+				Milliseconds: binary.LittleEndian.Uint32(b[8:]),
+			}, nil
+		}, nil
+	}
+	return nil, nil
+}
+
+// LogicalStats returns a copy of the statistics of a column whose min and max
+// are the Go values of the column's logical or converted type, as read with
+// WithLogicalTypes. The min and max of INT96 columns keep their physical type.
+func LogicalStats(leaf *schema.SchemaElement, stats *metadata.Stats) (*metadata.Stats, error) {
+	if stats == nil {
+		return nil, nil
+	}
+	logical := *stats
+	if !stats.HasMinMax {
+		return &logical, nil
+	}
+	convert, err := newValueConverter(leaf)
+	if err != nil || convert == nil {
+		return &logical, err
+	}
+	if logical.Min, err = convert(stats.Min); err != nil {
+		return nil, err
+	}
+	if logical.Max, err = convert(stats.Max); err != nil {
+		return nil, err
+	}
+	return &logical, nil
+}
+
+// leafLogicalType returns the logical type of a leaf, or the equivalent of
+// its converted type. The logical type takes precedence.
+func leafLogicalType(leaf *schema.SchemaElement) *schema.LogicalType {
+	if leaf.LogicalType != nil {
+		return leaf.LogicalType
+	}
+	if leaf.ConvertedType == schema.Decimal {
+		return &schema.LogicalType{Kind: schema.LogicalDecimal, Scale: leaf.Scale, Precision: leaf.Precision}
+	}
+	return convertedLogicalTypes[leaf.ConvertedType]
+}
+
+func logicalConverter(leaf *schema.SchemaElement, logical *schema.LogicalType) (valueConverter, error) {
+	switch logical.Kind {
+	case schema.LogicalString, schema.LogicalEnum, schema.LogicalJSON:
+		if leaf.Type != schema.ByteArray {
+			return nil, invalidAnnotation(leaf, logical.Kind.String())
+		}
+		return func(value any) (any, error) { return string(value.([]byte)), nil }, nil
+
+	case schema.LogicalBSON:
+		if leaf.Type != schema.ByteArray {
+			return nil, invalidAnnotation(leaf, logical.Kind.String())
+		}
+		return nil, nil
+
+	case schema.LogicalUUID:
+		if leaf.Type != schema.FixedLenByteArray || leaf.TypeLength != 16 {
+			return nil, invalidAnnotation(leaf, logical.Kind.String())
+		}
+		return func(value any) (any, error) { return [16]byte(value.([]byte)), nil }, nil
+
+	case schema.LogicalFloat16:
+		if leaf.Type != schema.FixedLenByteArray || leaf.TypeLength != 2 {
+			return nil, invalidAnnotation(leaf, logical.Kind.String())
+		}
+		return func(value any) (any, error) {
+			return float16.ToFloat32(binary.LittleEndian.Uint16(value.([]byte))), nil
+		}, nil
+
+	case schema.LogicalDecimal:
+		scale := logical.Scale
+		switch leaf.Type {
+		case schema.Int32:
+			return func(value any) (any, error) { return Decimal{big.NewInt(int64(value.(int32))), scale}, nil }, nil
+		case schema.Int64:
+			return func(value any) (any, error) { return Decimal{big.NewInt(value.(int64)), scale}, nil }, nil
+		case schema.ByteArray, schema.FixedLenByteArray:
+			return func(value any) (any, error) { return Decimal{bigEndianInt(value.([]byte)), scale}, nil }, nil
+		}
+		return nil, invalidAnnotation(leaf, logical.Kind.String())
+
+	case schema.LogicalDate:
+		if leaf.Type != schema.Int32 {
+			return nil, invalidAnnotation(leaf, logical.Kind.String())
+		}
+		return func(value any) (any, error) {
+			return time.Date(1970, time.January, 1+int(value.(int32)), 0, 0, 0, 0, time.UTC), nil
+		}, nil
+
+	case schema.LogicalTime:
+		unit := unitDuration(logical.Unit)
+		switch {
+		case leaf.Type == schema.Int32 && logical.Unit == schema.Millis:
+			return func(value any) (any, error) { return time.Duration(value.(int32)) * unit, nil }, nil
+		case leaf.Type == schema.Int64 && logical.Unit != schema.Millis:
+			return func(value any) (any, error) { return time.Duration(value.(int64)) * unit, nil }, nil
+		}
+		return nil, invalidAnnotation(leaf, logical.String())
+
+	case schema.LogicalTimestamp:
+		if leaf.Type != schema.Int64 {
+			return nil, invalidAnnotation(leaf, logical.Kind.String())
+		}
+		switch logical.Unit {
+		case schema.Micros:
+			return func(value any) (any, error) { return time.UnixMicro(value.(int64)).UTC(), nil }, nil
+		case schema.Nanos:
+			return func(value any) (any, error) { return time.Unix(0, value.(int64)).UTC(), nil }, nil
+		}
+		return func(value any) (any, error) { return time.UnixMilli(value.(int64)).UTC(), nil }, nil
+
+	case schema.LogicalInteger:
+		return integerConverter(leaf, logical)
+
+	case schema.LogicalGeometry, schema.LogicalGeography:
+		if leaf.Type != schema.ByteArray {
+			return nil, invalidAnnotation(leaf, logical.Kind.String())
+		}
+		return func(value any) (any, error) { return geospatial.ParseWKB(value.([]byte)) }, nil
+	}
+	return nil, nil
+}
+
+// isLocalTimestamp reports whether a leaf holds timestamps not adjusted to
+// UTC, which are read as their wall clock in UTC.
+func isLocalTimestamp(leaf *schema.SchemaElement) bool {
+	logical := leafLogicalType(leaf)
+	return logical != nil && logical.Kind == schema.LogicalTimestamp && !logical.IsAdjustedToUTC
+}
+
+// wallClockIn moves the wall clock of the local timestamps returned by
+// convert from UTC to loc.
+func wallClockIn(convert valueConverter, loc *time.Location) valueConverter {
+	return func(value any) (any, error) {
+		v, err := convert(value)
+		if t, ok := v.(time.Time); ok {
+			v = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+		}
+		return v, err
+	}
+}
+
+// integerConverter narrows or reinterprets INTEGER values as the Go integer
+// type of their bit width and signedness.
+func integerConverter(leaf *schema.SchemaElement, logical *schema.LogicalType) (valueConverter, error) {
+	if leaf.Type != schema.Int32 && leaf.Type != schema.Int64 || logical.BitWidth == 64 && leaf.Type != schema.Int64 {
+		return nil, invalidAnnotation(leaf, logical.String())
+	}
+	toInt64 := func(value any) int64 {
+		if v, ok := value.(int32); ok {
+			return int64(v)
+		}
+		return value.(int64)
+	}
+	switch {
+	case logical.IsSigned && logical.BitWidth == 8:
+		return func(value any) (any, error) { return int8(toInt64(value)), nil }, nil
+	case logical.IsSigned && logical.BitWidth == 16:
+		return func(value any) (any, error) { return int16(toInt64(value)), nil }, nil
+	case logical.IsSigned && logical.BitWidth == 32:
+		return func(value any) (any, error) { return int32(toInt64(value)), nil }, nil
+	case logical.IsSigned && logical.BitWidth == 64:
+		return nil, nil
+	case logical.BitWidth == 8:
+		return func(value any) (any, error) { return uint8(toInt64(value)), nil }, nil
+	case logical.BitWidth == 16:
+		return func(value any) (any, error) { return uint16(toInt64(value)), nil }, nil
+	case logical.BitWidth == 32:
+		return func(value any) (any, error) { return uint32(toInt64(value)), nil }, nil
+	case logical.BitWidth == 64:
+		return func(value any) (any, error) { return uint64(toInt64(value)), nil }, nil
+	}
+	return nil, invalidAnnotation(leaf, logical.String())
+}
+
+func invalidAnnotation(leaf *schema.SchemaElement, annotation string) error {
+	return fmt.Errorf("%w: %s cannot annotate field %q of type %v", schema.ErrInvalidSchema, annotation, leaf.Name, leaf.Type)
+}
+
+func unitDuration(unit schema.TimeUnit) time.Duration {
+	switch unit {
+	case schema.Micros:
+		return time.Microsecond
+	case schema.Nanos:
+		return time.Nanosecond
+	}
+	return time.Millisecond
+}
+
+// bigEndianInt decodes a big-endian two's complement integer.
+func bigEndianInt(b []byte) *big.Int {
+	value := new(big.Int).SetBytes(b)
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		value.Sub(value, new(big.Int).Lsh(big.NewInt(1), uint(len(b)*8)))
+	}
+	return value
+}
+
+// convertRows replaces the physical values of the leaves with converters by
+// their logical values, in place.
+func convertRows(columns []*schema.Column, converters []valueConverter, rows []Row) error {
+	for i, column := range columns {
+		if converters[i] == nil {
+			continue
+		}
+		for _, row := range rows {
+			if err := convertValues(row, column.Nodes, converters[i]); err != nil {
+				return fmt.Errorf("column %q: %w", column.PathString(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// convertValues converts the values of container at the end of the path of
+// nodes, which is a leaf or a group.
+func convertValues(container map[string]any, nodes []*schema.SchemaElement, convert valueConverter) error {
+	node := nodes[0]
+	value, ok := container[node.Name]
+	if !ok || value == nil {
+		return nil
+	}
+	if node.Repetition == schema.Repeated {
+		list, _ := value.([]any)
+		for i, element := range list {
+			if len(nodes) == 1 {
+				converted, err := convert(element)
+				if err != nil {
+					return err
+				}
+				list[i] = converted
+			} else if group, ok := element.(map[string]any); ok {
+				if err := convertValues(group, nodes[1:], convert); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	if len(nodes) == 1 {
+		converted, err := convert(value)
+		if err != nil {
+			return err
+		}
+		container[node.Name] = converted
+		return nil
+	}
+	if group, ok := value.(map[string]any); ok {
+		return convertValues(group, nodes[1:], convert)
+	}
+	return nil
+}
@@ -0,0 +1,289 @@
+package parquet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+func logicalLeaf(name string, typ schema.Type, logical *schema.LogicalType) *schema.SchemaElement {
+	leaf := schema.NewLeaf(name, typ, schema.Required)
+	leaf.LogicalType = logical
+	return leaf
+}
+
+func convertedLeaf(name string, typ schema.Type, converted schema.ConvertedType) *schema.SchemaElement {
+	leaf := schema.NewLeaf(name, typ, schema.Required)
+	leaf.ConvertedType = converted
+	return leaf
+}
+
+func TestValueConverter(t *testing.T) {
+	uuid := []byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00}
+	decimalBytes := schema.NewLeaf("d", schema.FixedLenByteArray, schema.Required)
+	decimalBytes.TypeLength = 4
+	decimalBytes.ConvertedType, decimalBytes.Scale, decimalBytes.Precision = schema.Decimal, 3, 9
+	flba := func(leaf *schema.SchemaElement, length int32) *schema.SchemaElement {
+		leaf.TypeLength = length
+		return leaf
+	}
+
+	testcases := map[string]struct {
+		leaf     *schema.SchemaElement
+		value    any
+		expected any
+	}{
+		"string":            {leaf: logicalLeaf("s", schema.ByteArray, &schema.LogicalType{Kind: schema.LogicalString}), value: []byte("héllo"), expected: "héllo"},
+		"utf8":              {leaf: convertedLeaf("s", schema.ByteArray, schema.UTF8), value: []byte("x"), expected: "x"},
+		"enum":              {leaf: logicalLeaf("e", schema.ByteArray, &schema.LogicalType{Kind: schema.LogicalEnum}), value: []byte("RED"), expected: "RED"},
+		"json":              {leaf: convertedLeaf("j", schema.ByteArray, schema.JSON), value: []byte(`{"a":1}`), expected: `{"a":1}`},
+		"bson":              {leaf: logicalLeaf("b", schema.ByteArray, &schema.LogicalType{Kind: schema.LogicalBSON}), value: []byte{5, 0, 0, 0, 0}, expected: []byte{5, 0, 0, 0, 0}},
+		"plain bytes":       {leaf: schema.NewLeaf("p", schema.ByteArray, schema.Required), value: []byte("raw"), expected: []byte("raw")},
+		"decimal int32":     {leaf: logicalLeaf("d", schema.Int32, &schema.LogicalType{Kind: schema.LogicalDecimal, Scale: 2, Precision: 5}), value: int32(-12345), expected: Decimal{big.NewInt(-12345), 2}},
+		"decimal int64":     {leaf: logicalLeaf("d", schema.Int64, &schema.LogicalType{Kind: schema.LogicalDecimal, Scale: 4, Precision: 18}), value: int64(10), expected: Decimal{big.NewInt(10), 4}},
+		"decimal flba":      {leaf: decimalBytes, value: []byte{0xff, 0xff, 0xfe, 0x0c}, expected: Decimal{big.NewInt(-500), 3}},
+		"decimal bytes":     {leaf: logicalLeaf("d", schema.ByteArray, &schema.LogicalType{Kind: schema.LogicalDecimal, Scale: 0, Precision: 30}), value: []byte{0x01, 0x00}, expected: Decimal{big.NewInt(256), 0}},
+		"date":              {leaf: logicalLeaf("d", schema.Int32, &schema.LogicalType{Kind: schema.LogicalDate}), value: int32(-1), expected: time.Date(1969, time.December, 31, 0, 0, 0, 0, time.UTC)},
+		"legacy date":       {leaf: convertedLeaf("d", schema.Int32, schema.Date), value: int32(19000), expected: time.Date(2022, time.January, 8, 0, 0, 0, 0, time.UTC)},
+		"time millis":       {leaf: logicalLeaf("t", schema.Int32, &schema.LogicalType{Kind: schema.LogicalTime, Unit: schema.Millis, IsAdjustedToUTC: true}), value: int32(3723004), expected: time.Hour + 2*time.Minute + 3*time.Second + 4*time.Millisecond},
+		"time micros":       {leaf: convertedLeaf("t", schema.Int64, schema.TimeMicros), value: int64(1500), expected: 1500 * time.Microsecond},
+		"time nanos":        {leaf: logicalLeaf("t", schema.Int64, &schema.LogicalType{Kind: schema.LogicalTime, Unit: schema.Nanos}), value: int64(42), expected: 42 * time.Nanosecond},
+		"timestamp millis":  {leaf: convertedLeaf("ts", schema.Int64, schema.TimestampMillis), value: int64(-1), expected: time.Date(1969, time.December, 31, 23, 59, 59, 999000000, time.UTC)},
+		"timestamp micros":  {leaf: logicalLeaf("ts", schema.Int64, &schema.LogicalType{Kind: schema.LogicalTimestamp, Unit: schema.Micros, IsAdjustedToUTC: true}), value: int64(1700000000123456), expected: time.Date(2023, time.November, 14, 22, 13, 20, 123456000, time.UTC)},
+		"timestamp nanos":   {leaf: logicalLeaf("ts", schema.Int64, &schema.LogicalType{Kind: schema.LogicalTimestamp, Unit: schema.Nanos}), value: int64(1), expected: time.Date(1970, time.January, 1, 0, 0, 0, 1, time.UTC)},
+		"uuid":              {leaf: flba(logicalLeaf("u", schema.FixedLenByteArray, &schema.LogicalType{Kind: schema.LogicalUUID}), 16), value: uuid, expected: [16]byte(uuid)},
+		"interval":          {leaf: flba(convertedLeaf("i", schema.FixedLenByteArray, schema.Interval), 12), value: []byte{1, 0, 0, 0, 2, 0, 0, 0, 3, 0, 0, 0}, expected: Interval{Months: 1, Days: 2, Milliseconds: 3}},
+		"float16":           {leaf: flba(logicalLeaf("f", schema.FixedLenByteArray, &schema.LogicalType{Kind: schema.LogicalFloat16}), 2), value: []byte{0x00, 0xc1}, expected: float32(-2.5)},
+		"float16 subnormal": {leaf: flba(logicalLeaf("f", schema.FixedLenByteArray, &schema.LogicalType{Kind: schema.LogicalFloat16}), 2), value: []byte{0x01, 0x00}, expected: float32(5.9604645e-08)},
+		"int8":              {leaf: convertedLeaf("i", schema.Int32, schema.Int8), value: int32(-7), expected: int8(-7)},
+		"uint16":            {leaf: logicalLeaf("i", schema.Int32, &schema.LogicalType{Kind: schema.LogicalInteger, BitWidth: 16}), value: int32(65535), expected: uint16(65535)},
+		"uint32":            {leaf: convertedLeaf("i", schema.Int32, schema.Uint32), value: int32(-1), expected: uint32(4294967295)},
+		"uint64":            {leaf: convertedLeaf("i", schema.Int64, schema.Uint64), value: int64(-1), expected: uint64(18446744073709551615)},
+		"int64":             {leaf: logicalLeaf("i", schema.Int64, &schema.LogicalType{Kind: schema.LogicalInteger, BitWidth: 64, IsSigned: true}), value: int64(-1), expected: int64(-1)},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			convert, err := newValueConverter(test.leaf)
+			if err != nil {
+				t.Fatalf("unable to create converter: %v", err)
+			}
+			got := test.value
+			if convert != nil {
+				if got, err = convert(test.value); err != nil {
+					t.Fatalf("unable to convert: %v", err)
+				}
+			}
+			if !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("expected %v (%T), got %v (%T)", test.expected, test.expected, got, got)
+			}
+		})
+	}
+}
+
+func TestValueConverterInvalidAnnotations(t *testing.T) {
+	testcases := map[string]*schema.SchemaElement{
+		"string on int32":     logicalLeaf("s", schema.Int32, &schema.LogicalType{Kind: schema.LogicalString}),
+		"date on int64":       logicalLeaf("d", schema.Int64, &schema.LogicalType{Kind: schema.LogicalDate}),
+		"time millis int64":   logicalLeaf("t", schema.Int64, &schema.LogicalType{Kind: schema.LogicalTime, Unit: schema.Millis}),
+		"timestamp on int32":  logicalLeaf("t", schema.Int32, &schema.LogicalType{Kind: schema.LogicalTimestamp}),
+		"decimal on double":   logicalLeaf("d", schema.Double, &schema.LogicalType{Kind: schema.LogicalDecimal}),
+		"uuid of 8 bytes":     logicalLeaf("u", schema.FixedLenByteArray, &schema.LogicalType{Kind: schema.LogicalUUID}),
+		"int64 on int32":      logicalLeaf("i", schema.Int32, &schema.LogicalType{Kind: schema.LogicalInteger, BitWidth: 64, IsSigned: true}),
+		"interval on int64":   convertedLeaf("i", schema.Int64, schema.Interval),
+		"float16 on bytes":    logicalLeaf("f", schema.ByteArray, &schema.LogicalType{Kind: schema.LogicalFloat16}),
+		"integer of 12 bits":  logicalLeaf("i", schema.Int32, &schema.LogicalType{Kind: schema.LogicalInteger, BitWidth: 12}),
+		"uint8 on byte array": convertedLeaf("i", schema.ByteArray, schema.Uint8),
+	}
+
+	for name, leaf := range testcases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := newValueConverter(leaf); !errors.Is(err, schema.ErrInvalidSchema) {
+				t.Errorf("expected ErrInvalidSchema, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDecimalString(t *testing.T) {
+	testcases := map[string]struct {
+		decimal  Decimal
+		expected string
+	}{
+		"positive":       {decimal: Decimal{big.NewInt(12345), 2}, expected: "123.45"},
+		"negative":       {decimal: Decimal{big.NewInt(-5), 3}, expected: "-0.005"},
+		"zero scale":     {decimal: Decimal{big.NewInt(42), 0}, expected: "42"},
+		"negative scale": {decimal: Decimal{big.NewInt(42), -2}, expected: "4200"},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			if got := test.decimal.String(); got != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, got)
+			}
+		})
+	}
+
+	if rat := (Decimal{big.NewInt(-125), 2}).Rat(); rat.Cmp(big.NewRat(-5, 4)) != 0 {
+		t.Errorf("expected -5/4, got %v", rat)
+	}
+}
+
+func TestReaderLogicalTypes(t *testing.T) {
+	name := logicalLeaf("name", schema.ByteArray, &schema.LogicalType{Kind: schema.LogicalString})
+	name.Repetition = schema.Optional
+	day := logicalLeaf("day", schema.Int32, &schema.LogicalType{Kind: schema.LogicalDate})
+	root := schema.NewSchema(
+		schema.NewLeaf("id", schema.Int64, schema.Required),
+		name,
+		schema.NewGroup("events", schema.Repeated,
+			day,
+			logicalLeaf("at", schema.Int64, &schema.LogicalType{Kind: schema.LogicalTimestamp, Unit: schema.Micros, IsAdjustedToUTC: true}),
+		),
+	)
+
+	var buffer bytes.Buffer
+	writer, err := NewWriter(&buffer, root)
+	if err != nil {
+		t.Fatalf("unable to create writer: %v", err)
+	}
+	rows := []Row{
+		{"id": int64(1), "name": "ada", "events": []any{Row{"day": int32(1), "at": int64(1000000)}}},
+		{"id": int64(2), "events": []any{}},
+	}
+	for _, row := range rows {
+		if err := writer.Write(row); err != nil {
+			t.Fatalf("unable to write row: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("unable to close writer: %v", err)
+	}
+
+	data := buffer.Bytes()
+	reader := openTestFile(t, data, WithLogicalTypes(true), WithFilter(Eq("id", int64(1))))
+	expected := []Row{{
+		"id":     int64(1),
+		"name":   "ada",
+		"events": []any{map[string]any{"day": time.Date(1970, time.January, 2, 0, 0, 0, 0, time.UTC), "at": time.Date(1970, time.January, 1, 0, 0, 1, 0, time.UTC)}},
+	}}
+	if got := readAllRows(t, reader); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	// Without the option values keep their physical type.
+	if got := readAllRows(t, openTestFile(t, data))[0]["name"]; !reflect.DeepEqual(got, []byte("ada")) {
+		t.Errorf("expected physical bytes, got %v", got)
+	}
+}
+
+func TestReaderLogicalTypesInt96(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "testdata", "apache_examples", "alltypes_plain.parquet"))
+	if err != nil {
+		t.Fatalf("unable to read file: %v", err)
+	}
+	reader := openTestFile(t, data, WithLogicalTypes(true), WithColumns("id", "timestamp_col"))
+	rows := readAllRows(t, reader)
+	expected := Row{"id": int32(4), "timestamp_col": time.Date(2009, time.March, 1, 0, 0, 0, 0, time.UTC)}
+	if !reflect.DeepEqual(rows[0], expected) {
+		t.Errorf("expected %v, got %v", expected, rows[0])
+	}
+}
+
+func TestReaderLocalTimestamps(t *testing.T) {
+	root := schema.NewSchema(
+		logicalLeaf("local", schema.Int64, &schema.LogicalType{Kind: schema.LogicalTimestamp, Unit: schema.Millis}),
+		logicalLeaf("utc", schema.Int64, &schema.LogicalType{Kind: schema.LogicalTimestamp, Unit: schema.Millis, IsAdjustedToUTC: true}),
+	)
+	var buffer bytes.Buffer
+	writer, err := NewWriter(&buffer, root)
+	if err != nil {
+		t.Fatalf("unable to create writer: %v", err)
+	}
+	// 2024-03-10 12:30:00 as a wall clock, and as an instant.
+	millis := int64(1710073800000)
+	if err := writer.Write(Row{"local": millis, "utc": millis}); err != nil {
+		t.Fatalf("unable to write row: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("unable to close writer: %v", err)
+	}
+
+	zone := time.FixedZone("UTC+2", 2*60*60)
+	testcases := map[string]struct {
+		opts     []ParquetReaderOption
+		expected Row
+	}{
+		"utc wall clock": {
+			expected: Row{"local": time.Date(2024, time.March, 10, 12, 30, 0, 0, time.UTC), "utc": time.Date(2024, time.March, 10, 12, 30, 0, 0, time.UTC)},
+		},
+		"location": {
+			opts:     []ParquetReaderOption{WithTimestampLocation(zone)},
+			expected: Row{"local": time.Date(2024, time.March, 10, 12, 30, 0, 0, zone), "utc": time.Date(2024, time.March, 10, 12, 30, 0, 0, time.UTC)},
+		},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			reader := openTestFile(t, buffer.Bytes(), append(test.opts, WithLogicalTypes(true))...)
+			if got := readAllRows(t, reader)[0]; !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestLogicalStats(t *testing.T) {
+	root := schema.NewSchema(
+		logicalLeaf("price", schema.Int32, &schema.LogicalType{Kind: schema.LogicalDecimal, Scale: 2, Precision: 9}),
+		logicalLeaf("at", schema.Int64, &schema.LogicalType{Kind: schema.LogicalTimestamp, Unit: schema.Micros, IsAdjustedToUTC: true}),
+		schema.NewLeaf("id", schema.Int64, schema.Required),
+	)
+	var buffer bytes.Buffer
+	writer, err := NewWriter(&buffer, root)
+	if err != nil {
+		t.Fatalf("unable to create writer: %v", err)
+	}
+	for i, price := range []int32{1999, -250, 10000} {
+		if err := writer.Write(Row{"price": price, "at": int64(i) * 1000000, "id": int64(i)}); err != nil {
+			t.Fatalf("unable to write row: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("unable to close writer: %v", err)
+	}
+
+	testcases := map[string]struct {
+		column   int
+		min, max string
+	}{
+		"decimal":   {column: 0, min: "-2.50", max: "100.00"},
+		"timestamp": {column: 1, min: "1970-01-01 00:00:00 +0000 UTC", max: "1970-01-01 00:00:02 +0000 UTC"},
+		"physical":  {column: 2, min: "0", max: "2"},
+	}
+
+	reader := openTestFile(t, buffer.Bytes())
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			chunk := reader.GetMeta().RowGroup(0).Column(test.column)
+			stats, err := LogicalStats(chunk.Column().Leaf, chunk.Statistics())
+			if err != nil {
+				t.Fatalf("unable to convert statistics: %v", err)
+			}
+			if min, max := fmt.Sprint(stats.Min), fmt.Sprint(stats.Max); min != test.min || max != test.max {
+				t.Errorf("expected [%s, %s], got [%s, %s]", test.min, test.max, min, max)
+			}
+			if !stats.Trusted || !stats.HasMinMax {
+				t.Errorf("expected trusted statistics, got %+v", stats)
+			}
+		})
+	}
+}
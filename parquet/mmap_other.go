@@ -0,0 +1,14 @@
+//go:build !linux
+
+package parquet
+
+import (
+	"errors"
+	"os"
+)
+
+func mmap(*os.File, int64) ([]byte, error) {
+	return nil, errors.New("memory mapping is only supported on Linux")
+}
+
+func munmap([]byte) error { return nil }
@@ -0,0 +1,68 @@
+package parquet
+
+import (
+	"errors"
+	"io"
+	"os"
+)
+
+// OpenFile opens the Parquet file at path. On Linux the file is
+// memory-mapped, and values of uncompressed PLAIN or dictionary pages are
+// decoded without copying: BYTE_ARRAY and FIXED_LEN_BYTE_ARRAY values then
+// point into the read-only mapping, and must not be modified or used after
+// Close. Elsewhere, or when the file cannot be mapped, it is read with ReadAt.
+// Close closes the file.
+func OpenFile(path string, opts ...ParquetReaderOption) (*ParquetReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	fileStat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	var source io.ReaderAt = f
+	var closer io.Closer = f
+	if data, err := mmap(f, fileStat.Size()); err == nil {
+		mapped := &mappedFile{file: f, data: data}
+		source, closer = mapped, mapped
+	}
+	reader, err := Open(source, fileStat.Size(), opts...)
+	if err != nil {
+		closer.Close()
+		return nil, err
+	}
+	reader.closer = closer
+	return reader, nil
+}
+
+// mappedFile is a memory-mapped file. Reads of its ranges share the mapping
+// through Bytes.
+type mappedFile struct {
+	file *os.File
+	data []byte
+}
+
+func (m *mappedFile) Bytes() []byte { return m.data }
+
+func (m *mappedFile) ReadAt(p []byte, offset int64) (int, error) {
+	if offset < 0 {
+		return 0, errors.New("negative offset")
+	}
+	if offset >= int64(len(m.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, m.data[offset:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (m *mappedFile) Close() error {
+	err := munmap(m.data)
+	m.data = nil
+	return errors.Join(err, m.file.Close())
+}
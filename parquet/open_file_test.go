@@ -0,0 +1,95 @@
+package parquet
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"unsafe"
+)
+
+func TestOpenFile(t *testing.T) {
+	testcases := map[string]string{
+		"uncompressed": "",
+		"dictionary":   filepath.Join("apache_examples", "alltypes_plain.parquet"),
+		"snappy":       filepath.Join("apache_examples", "nested_maps.snappy.parquet"),
+	}
+
+	for name, testfile := range testcases {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join("..", "testdata", testfile)
+			if testfile == "" {
+				path = filepath.Join(t.TempDir(), "rows.parquet")
+				if err := os.WriteFile(path, writeTestFile(t, testRows(100)), 0o644); err != nil {
+					t.Fatalf("unable to write file: %v", err)
+				}
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("unable to read file: %v", err)
+			}
+			expectedReader, err := Open(bytes.NewReader(data), int64(len(data)))
+			if err != nil {
+				t.Fatalf("unable to open file: %v", err)
+			}
+			expected := readAllRows(t, expectedReader)
+
+			reader, err := OpenFile(path)
+			if err != nil {
+				t.Fatalf("unable to open file: %v", err)
+			}
+			if rows := readAllRows(t, reader); !reflect.DeepEqual(rows, expected) {
+				t.Errorf("rows of the mapped file differ from Open")
+			}
+			if err := reader.Close(); err != nil {
+				t.Errorf("unable to close: %v", err)
+			}
+			if err := reader.Close(); err != nil {
+				t.Errorf("expected a second Close to succeed, got %v", err)
+			}
+		})
+	}
+}
+
+func TestOpenFileZeroCopy(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("files are only mapped on Linux")
+	}
+	path := filepath.Join(t.TempDir(), "rows.parquet")
+	if err := os.WriteFile(path, writeTestFile(t, testRows(100)), 0o644); err != nil {
+		t.Fatalf("unable to write file: %v", err)
+	}
+	reader, err := OpenFile(path)
+	if err != nil {
+		t.Fatalf("unable to open file: %v", err)
+	}
+	defer reader.Close()
+	mapped, ok := reader.file.Reader.(*mappedFile)
+	if !ok {
+		t.Fatalf("expected a mapped file, got %T", reader.file.Reader)
+	}
+
+	start := uintptr(unsafe.Pointer(unsafe.SliceData(mapped.data)))
+	end := start + uintptr(len(mapped.data))
+	rows := readAllRows(t, reader)
+	name := rows[1]["name"].([]byte)
+	if p := uintptr(unsafe.Pointer(unsafe.SliceData(name))); p < start || p >= end {
+		t.Errorf("expected BYTE_ARRAY values to point into the mapping")
+	}
+}
+
+func TestOpenFileErrors(t *testing.T) {
+	if _, err := OpenFile(filepath.Join(t.TempDir(), "missing.parquet")); !os.IsNotExist(err) {
+		t.Errorf("expected a not exist error, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "empty.parquet")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatalf("unable to write file: %v", err)
+	}
+	if _, err := OpenFile(path); err == nil {
+		t.Errorf("expected error for an empty file, got nil error")
+	}
+}
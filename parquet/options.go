@@ -1,4 +1,359 @@
 package parquet
 
-type ParquetReaderOption struct{}
-type ParquetWriterOption struct{}
+import (
+	"time"
+
+	format "github.com/RichardNooooh/parquet-go/internal/format/gen-go/parquet"
+)
+
+type ParquetReaderOption func(*readerConfig)
+
+type readerConfig struct {
+	filter      Predicate
+	columns     []string
+	concurrency int
+	maxGap      int64
+	maxReadSize int64
+	prefetch    int
+	footerRead  int64
+	preallocate bool
+	logical     bool
+	// timestampLocation holds the wall clock of local timestamps.
+	timestampLocation *time.Location
+	// int96AsTimestamp exposes INT96 columns as TIMESTAMP(NANOS) columns.
+	int96AsTimestamp bool
+	int96Rebase      RebaseMode
+
+	footerKey    []byte
+	columnKeys   map[string][]byte
+	keyRetriever KeyRetriever
+	aadPrefix    []byte
+}
+
+func newReaderConfig(opts []ParquetReaderOption) *readerConfig {
+	config := &readerConfig{
+		concurrency: 1,
+		maxReadSize: defaultMaxReadSize,
+		prefetch:    1,
+		columnKeys:  make(map[string][]byte),
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+	return config
+}
+
+// WithColumns reads only the given columns. A path names a leaf column, such
+// as "a.b.c", or a group, which selects every leaf below it.
+func WithColumns(paths ...string) ParquetReaderOption {
+	return func(c *readerConfig) { c.columns = paths }
+}
+
+// WithConcurrency decodes up to workers column chunks at once, and lets
+// ReadAll read up to workers row groups at once. Rows are still returned in
+// file order. The default of one decodes everything on the calling goroutine.
+func WithConcurrency(workers int) ParquetReaderOption {
+	return func(c *readerConfig) { c.concurrency = workers }
+}
+
+// WithReadCoalescing merges the byte ranges needed from a row group when
+// they are at most maxGap bytes apart, as long as a merged read stays within
+// maxSize bytes. By default only touching ranges are merged, up to 64MiB.
+func WithReadCoalescing(maxGap, maxSize int64) ParquetReaderOption {
+	return func(c *readerConfig) { c.maxGap, c.maxReadSize = maxGap, maxSize }
+}
+
+// WithPrefetch issues up to window merged reads of a row group ahead of the
+// one being decoded. The default window is one.
+func WithPrefetch(window int) ParquetReaderOption {
+	return func(c *readerConfig) { c.prefetch = window }
+}
+
+// WithPreallocation sizes the buffers of column chunks read whole from their
+// size statistics before decoding them, instead of growing them page by
+// page. BYTE_ARRAY values are then copied into a single buffer per chunk,
+// which also releases the page data they were decoded from.
+func WithPreallocation(enabled bool) ParquetReaderOption {
+	return func(c *readerConfig) { c.preallocate = enabled }
+}
+
+// WithFooterReadSize reads the last bytes of the file in a single call when
+// opening it, such as 64KiB, and reads again only when the footer is larger.
+// By default the magic numbers, footer length and footer are read separately.
+func WithFooterReadSize(bytes int64) ParquetReaderOption {
+	return func(c *readerConfig) { c.footerRead = bytes }
+}
+
+// WithFooterKey decrypts the footer of an encrypted file, and the columns
+// encrypted with the footer key. It also verifies signed plaintext footers.
+func WithFooterKey(key []byte) ParquetReaderOption {
+	return func(c *readerConfig) { c.footerKey = key }
+}
+
+// WithColumnKey decrypts the column at the dotted path with key.
+func WithColumnKey(column string, key []byte) ParquetReaderOption {
+	return func(c *readerConfig) { c.columnKeys[column] = key }
+}
+
+// WithKeyRetriever looks up the keys not given with WithFooterKey or
+// WithColumnKey from the key metadata stored in the file.
+func WithKeyRetriever(retriever KeyRetriever) ParquetReaderOption {
+	return func(c *readerConfig) { c.keyRetriever = retriever }
+}
+
+// WithAADPrefix supplies the AAD prefix of an encrypted file, such as its
+// name, which files may be written without. A prefix stored in the file must
+// match it.
+func WithAADPrefix(prefix []byte) ParquetReaderOption {
+	return func(c *readerConfig) { c.aadPrefix = prefix }
+}
+
+// WithLogicalTypes returns values as the Go types of their logical or
+// converted type instead of their physical type: strings, Decimal, time.Time
+// for dates, timestamps and INT96, time.Duration for times, [16]byte for
+// UUIDs, Interval, float32 for FLOAT16, sized integers, geospatial.Geometry
+// for GEOMETRY and GEOGRAPHY, and Variant for VARIANT groups, shredded or
+// not. Timestamps not adjusted to UTC hold their wall clock in UTC, unless
+// WithTimestampLocation is set. Filters still take physical values.
+func WithLogicalTypes(enabled bool) ParquetReaderOption {
+	return func(c *readerConfig) { c.logical = enabled }
+}
+
+// WithTimestampLocation reads the timestamps not adjusted to UTC of columns
+// with WithLogicalTypes as their wall clock in loc, instead of in UTC.
+func WithTimestampLocation(loc *time.Location) ParquetReaderOption {
+	return func(c *readerConfig) { c.timestampLocation = loc }
+}
+
+// WithInt96AsTimestamp exposes INT96 columns as TIMESTAMP(NANOS) columns
+// adjusted to UTC: GetSchema shows them as such and their values are read as
+// int64 nanoseconds since the epoch, or time.Time with WithLogicalTypes.
+// Timestamps outside of the range of TIMESTAMP(NANOS), 1677 to 2262, fail to
+// read as int64. Filters still take [12]byte values.
+func WithInt96AsTimestamp(enabled bool) ParquetReaderOption {
+	return func(c *readerConfig) { c.int96AsTimestamp = enabled }
+}
+
+// WithInt96ReadRebase sets how INT96 timestamps before 1582-10-15 are read,
+// RebaseCorrected by default. Files written by Spark record their mode, which
+// takes precedence.
+func WithInt96ReadRebase(mode RebaseMode) ParquetReaderOption {
+	return func(c *readerConfig) { c.int96Rebase = mode }
+}
+
+// WithFilter skips row groups whose statistics show that no row can match the
+// predicate, and drops non-matching rows from the remaining ones.
+func WithFilter(predicate Predicate) ParquetReaderOption {
+	return func(c *readerConfig) { c.filter = predicate }
+}
+
+type ParquetWriterOption func(*writerConfig)
+
+type writerConfig struct {
+	pageSize       int
+	rowGroupRows   int64
+	distinctCount  bool
+	truncateLength int
+	createdBy      string
+	pageIndex      bool
+	bloomFilters   map[string]bloomFilterConfig
+	codec          format.CompressionCodec
+	concurrency    int
+	pipeline       bool
+	int96          bool
+	int96Rebase    RebaseMode
+
+	footerKey         []byte
+	footerKeyMetadata []byte
+	columnKeys        map[string]columnKeyConfig
+	algorithm         EncryptionAlgorithm
+	plaintextFooter   bool
+	aadPrefix         []byte
+	storeAADPrefix    bool
+}
+
+type columnKeyConfig struct {
+	key, keyMetadata []byte
+}
+
+type bloomFilterConfig struct {
+	fpp float64
+	ndv int64
+}
+
+const (
+	defaultPageSize       = 1 << 20
+	defaultRowGroupRows   = 1 << 20
+	defaultTruncateLength = 64
+	defaultCreatedBy      = "parquet-go version 0.1.0"
+	defaultMaxReadSize    = 64 << 20
+)
+
+func newWriterConfig(opts []ParquetWriterOption) *writerConfig {
+	config := &writerConfig{
+		pageSize:       defaultPageSize,
+		rowGroupRows:   defaultRowGroupRows,
+		truncateLength: defaultTruncateLength,
+		createdBy:      defaultCreatedBy,
+		pageIndex:      true,
+		bloomFilters:   make(map[string]bloomFilterConfig),
+		columnKeys:     make(map[string]columnKeyConfig),
+		codec:          format.CompressionCodec_UNCOMPRESSED,
+		concurrency:    1,
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+	return config
+}
+
+// WithPageSize sets the approximate number of encoded bytes after which a
+// data page is closed. Pages always end on a row boundary. NewWriter rejects
+// sizes that are not positive.
+func WithPageSize(bytes int) ParquetWriterOption {
+	return func(c *writerConfig) { c.pageSize = bytes }
+}
+
+// WithRowGroupSize sets the number of rows buffered before a row group is
+// written out. NewWriter rejects sizes that are not positive.
+func WithRowGroupSize(rows int64) ParquetWriterOption {
+	return func(c *writerConfig) { c.rowGroupRows = rows }
+}
+
+// WithDistinctCount enables the exact distinct_count statistic for pages and
+// column chunks.
+func WithDistinctCount(enabled bool) ParquetWriterOption {
+	return func(c *writerConfig) { c.distinctCount = enabled }
+}
+
+// WithStatisticsTruncateLength caps the length of BYTE_ARRAY min and max
+// statistics. Zero disables truncation.
+func WithStatisticsTruncateLength(bytes int) ParquetWriterOption {
+	return func(c *writerConfig) { c.truncateLength = bytes }
+}
+
+func WithCreatedBy(createdBy string) ParquetWriterOption {
+	return func(c *writerConfig) { c.createdBy = createdBy }
+}
+
+// WithPageIndex controls whether a ColumnIndex and OffsetIndex are written
+// for every column chunk. Page indexes are written by default.
+func WithPageIndex(enabled bool) ParquetWriterOption {
+	return func(c *writerConfig) { c.pageIndex = enabled }
+}
+
+// WithBloomFilter writes a bloom filter for the column at the dotted path,
+// sized for ndv distinct values per column chunk at a false positive
+// probability of fpp. An ndv of zero sizes each filter from the number of
+// distinct values actually written to the chunk.
+func WithBloomFilter(column string, fpp float64, ndv int64) ParquetWriterOption {
+	return func(c *writerConfig) { c.bloomFilters[column] = bloomFilterConfig{fpp: fpp, ndv: ndv} }
+}
+
+// CompressionCodec is a page compression codec supported by the writer.
+type CompressionCodec int
+
+const (
+	Uncompressed CompressionCodec = iota
+	Snappy
+	Gzip
+	LZ4Raw
+)
+
+func (c CompressionCodec) format() format.CompressionCodec {
+	switch c {
+	case Snappy:
+		return format.CompressionCodec_SNAPPY
+	case Gzip:
+		return format.CompressionCodec_GZIP
+	case LZ4Raw:
+		return format.CompressionCodec_LZ4_RAW
+	}
+	return format.CompressionCodec_UNCOMPRESSED
+}
+
+// WithCompression compresses data pages with the given codec.
+func WithCompression(codec CompressionCodec) ParquetWriterOption {
+	return func(c *writerConfig) { c.codec = codec.format() }
+}
+
+// WithEncodingConcurrency encodes and compresses up to workers column chunks
+// of a row group at once. The file is identical for any number of workers.
+func WithEncodingConcurrency(workers int) ParquetWriterOption {
+	return func(c *writerConfig) { c.concurrency = workers }
+}
+
+// WithPipelining encodes and writes a full row group in the background while
+// Write buffers the next one. Errors from the background are returned by the
+// following call to Write, Flush or Close.
+func WithPipelining(enabled bool) ParquetWriterOption {
+	return func(c *writerConfig) { c.pipeline = enabled }
+}
+
+// WithInt96Timestamps writes TIMESTAMP columns as legacy INT96 columns for
+// readers such as Hive and Impala that expect them. Their values may be
+// time.Time or int64 in the unit of the column.
+func WithInt96Timestamps(enabled bool) ParquetWriterOption {
+	return func(c *writerConfig) { c.int96 = enabled }
+}
+
+// WithInt96WriteRebase sets how INT96 timestamps before 1582-10-15 are
+// written, RebaseCorrected by default. RebaseLegacy writes them in the hybrid
+// Julian calendar and records it in the footer the way Spark does.
+func WithInt96WriteRebase(mode RebaseMode) ParquetWriterOption {
+	return func(c *writerConfig) { c.int96Rebase = mode }
+}
+
+// EncryptionAlgorithm is the cipher of an encrypted file.
+type EncryptionAlgorithm int
+
+const (
+	// AesGcm encrypts and authenticates every module with AES GCM.
+	AesGcm EncryptionAlgorithm = iota
+	// AesGcmCtr encrypts pages with AES CTR, which is faster but leaves their
+	// content unauthenticated, and every other module with AES GCM.
+	AesGcmCtr
+)
+
+func (a EncryptionAlgorithm) String() string {
+	if a == AesGcmCtr {
+		return "AES_GCM_CTR_V1"
+	}
+	return "AES_GCM_V1"
+}
+
+// WithEncryption encrypts the file with footerKey, an AES key of 16, 24 or
+// 32 bytes. keyMetadata is stored in the file to let readers retrieve the
+// key. Every column is encrypted with the footer key unless columns are
+// chosen with WithColumnEncryption.
+func WithEncryption(footerKey, keyMetadata []byte) ParquetWriterOption {
+	return func(c *writerConfig) { c.footerKey, c.footerKeyMetadata = footerKey, keyMetadata }
+}
+
+// WithColumnEncryption encrypts a column with its own key and key metadata,
+// or with the footer key when key is nil. Once a column is chosen, the
+// columns that are not are written in plaintext.
+func WithColumnEncryption(column string, key, keyMetadata []byte) ParquetWriterOption {
+	return func(c *writerConfig) { c.columnKeys[column] = columnKeyConfig{key: key, keyMetadata: keyMetadata} }
+}
+
+// WithEncryptionAlgorithm sets the cipher of an encrypted file, AesGcm by
+// default.
+func WithEncryptionAlgorithm(algorithm EncryptionAlgorithm) ParquetWriterOption {
+	return func(c *writerConfig) { c.algorithm = algorithm }
+}
+
+// WithPlaintextFooter leaves the footer of an encrypted file in plaintext,
+// signed with the footer key, so that readers without keys can read its
+// schema and plaintext columns.
+func WithPlaintextFooter(enabled bool) ParquetWriterOption {
+	return func(c *writerConfig) { c.plaintextFooter = enabled }
+}
+
+// WithEncryptionAADPrefix binds an encrypted file to prefix, such as a table
+// name and file path, so that it cannot be swapped for another file. When
+// store is false the prefix is left out of the file and readers must supply
+// it with WithAADPrefix.
+func WithEncryptionAADPrefix(prefix []byte, store bool) ParquetWriterOption {
+	return func(c *writerConfig) { c.aadPrefix, c.storeAADPrefix = prefix, store }
+}
@@ -0,0 +1,74 @@
+package parquet
+
+import (
+	"github.com/RichardNooooh/parquet-go/metadata"
+)
+
+// pageIndexes lazily loads the page indexes of one row group. The first
+// error is kept in err and later lookups return nothing.
+type pageIndexes struct {
+	reader        *ParquetReader
+	rowGroup      int
+	numRows       int64
+	columnIndexes map[int]*metadata.ColumnIndex
+	offsetIndexes map[int]*metadata.OffsetIndex
+	err           error
+}
+
+func newPageIndexes(reader *ParquetReader, rowGroup int) *pageIndexes {
+	return &pageIndexes{
+		reader:        reader,
+		rowGroup:      rowGroup,
+		numRows:       reader.meta.RowGroup(rowGroup).NumRows(),
+		columnIndexes: make(map[int]*metadata.ColumnIndex),
+		offsetIndexes: make(map[int]*metadata.OffsetIndex),
+	}
+}
+
+func (p *pageIndexes) columnIndex(column int) *metadata.ColumnIndex {
+	if columnIndex, ok := p.columnIndexes[column]; ok || p.err != nil {
+		return columnIndex
+	}
+	columnIndex, err := p.reader.ColumnIndex(p.rowGroup, column)
+	p.columnIndexes[column], p.err = columnIndex, err
+	return columnIndex
+}
+
+func (p *pageIndexes) offsetIndex(column int) *metadata.OffsetIndex {
+	if offsetIndex, ok := p.offsetIndexes[column]; ok || p.err != nil {
+		return offsetIndex
+	}
+	offsetIndex, err := p.reader.OffsetIndex(p.rowGroup, column)
+	p.offsetIndexes[column], p.err = offsetIndex, err
+	return offsetIndex
+}
+
+// selectPages returns the rows of the pages of a column that the predicate
+// cannot rule out, or all rows when the column has no usable page index.
+func (p *pageIndexes) selectPages(column int, predicate boundPredicate) rowRanges {
+	columnIndex, offsetIndex := p.columnIndex(column), p.offsetIndex(column)
+	if columnIndex == nil || offsetIndex == nil || columnIndex.NumPages() != offsetIndex.NumPages() {
+		return allRows(p.numRows)
+	}
+
+	ranges := rowRanges{}
+	for i, location := range offsetIndex.PageLocations {
+		bounds := columnIndexBounds(columnIndex, i)
+		if predicate.mightMatch(func(int) *valueBounds { return bounds }) {
+			ranges = ranges.add(location.FirstRowIndex, offsetIndex.LastRowIndex(i, p.numRows)+1)
+		}
+	}
+	return ranges
+}
+
+// columnIndexBounds converts the statistics of page i into value bounds. Page
+// bounds may be truncated and carry no exactness flags.
+func columnIndexBounds(columnIndex *metadata.ColumnIndex, i int) *valueBounds {
+	return &valueBounds{
+		min:       columnIndex.Min[i],
+		max:       columnIndex.Max[i],
+		hasMinMax: columnIndex.Trusted && !columnIndex.NullPages[i] && columnIndex.Min[i] != nil,
+		allNull:   columnIndex.NullPages[i],
+		noNulls:   columnIndex.HasNullCounts && i < len(columnIndex.NullCounts) && columnIndex.NullCounts[i] == 0,
+	}
+}
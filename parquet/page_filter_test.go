@@ -0,0 +1,130 @@
+package parquet
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// countingReaderAt counts the calls to ReadAt and the bytes they read.
+type countingReaderAt struct {
+	reader io.ReaderAt
+	mutex  sync.Mutex
+	calls  int
+	bytes  int64
+}
+
+func (r *countingReaderAt) ReadAt(p []byte, offset int64) (int, error) {
+	r.mutex.Lock()
+	r.calls++
+	r.bytes += int64(len(p))
+	r.mutex.Unlock()
+	return r.reader.ReadAt(p, offset)
+}
+
+func TestRowRanges(t *testing.T) {
+	a := rowRanges{{0, 10}, {20, 30}, {40, 50}}
+	b := rowRanges{{5, 25}, {45, 60}}
+
+	testcases := map[string]struct {
+		got, expected rowRanges
+	}{
+		"union":             {got: a.union(b), expected: rowRanges{{0, 30}, {40, 60}}},
+		"intersection":      {got: a.intersection(b), expected: rowRanges{{5, 10}, {20, 25}, {45, 50}}},
+		"union empty":       {got: a.union(rowRanges{}), expected: a},
+		"intersect empty":   {got: a.intersection(rowRanges{}), expected: rowRanges{}},
+		"adjacent merge":    {got: rowRanges{}.add(0, 5).add(5, 8), expected: rowRanges{{0, 8}}},
+		"empty range":       {got: rowRanges{}.add(3, 3), expected: rowRanges{}},
+		"intersect touched": {got: rowRanges{{0, 5}}.intersection(rowRanges{{5, 9}}), expected: rowRanges{}},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			if !slices.Equal(test.got, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, test.got)
+			}
+		})
+	}
+}
+
+func TestPageFilterReadsOverlappingPages(t *testing.T) {
+	data := writeTestFile(t, testRows(1000), WithPageSize(256))
+
+	full := &countingReaderAt{reader: bytes.NewReader(data)}
+	reader, err := Open(full, int64(len(data)))
+	if err != nil {
+		t.Fatalf("unable to open file: %v", err)
+	}
+	readAllRows(t, reader)
+
+	filtered := &countingReaderAt{reader: bytes.NewReader(data)}
+	reader, err = Open(filtered, int64(len(data)), WithFilter(Eq("id", 500)))
+	if err != nil {
+		t.Fatalf("unable to open file: %v", err)
+	}
+	rows := readAllRows(t, reader)
+	if len(rows) != 1 || rows[0]["id"] != int64(500) {
+		t.Fatalf("expected the row with id 500, got %v", rows)
+	}
+	if filtered.bytes*4 > full.bytes {
+		t.Errorf("expected far fewer bytes read with a point lookup, got %d of %d", filtered.bytes, full.bytes)
+	}
+}
+
+func TestPageFilterMatchesFullScan(t *testing.T) {
+	f, err := os.Open(filepath.Join("..", "testdata", "apache_examples", "alltypes_tiny_pages_plain.parquet"))
+	if err != nil {
+		t.Fatalf("unable to open file: %v", err)
+	}
+	defer f.Close()
+	fileStat, err := f.Stat()
+	if err != nil {
+		t.Fatalf("unable to get filestat: %v", err)
+	}
+	reader, err := Open(f, fileStat.Size())
+	if err != nil {
+		t.Fatalf("unable to open file: %v", err)
+	}
+	allRows := readAllRows(t, reader)
+
+	testcases := map[string]Predicate{
+		"point lookup":  Eq("id", 122),
+		"range":         And(GtEq("id", 1000), Lt("id", 1200)),
+		"other column":  And(Eq("int_col", 3), Lt("id", 500)),
+		"in":            In("string_col", "2", "5"),
+		"empty not in":  Not(In("string_col")),
+		"or":            Or(Lt("id", 50), Gt("bigint_col", 80)),
+		"no page index": Eq("timestamp_col", [12]byte{}),
+		"not":           Not(GtEq("id", 20)),
+		"unmatched":     Gt("id", 100000),
+	}
+
+	for name, filter := range testcases {
+		t.Run(name, func(t *testing.T) {
+			reader, err := Open(f, fileStat.Size(), WithFilter(filter))
+			if err != nil {
+				t.Fatalf("unable to open file: %v", err)
+			}
+			bound, err := filter.bind(reader.GetMeta())
+			if err != nil {
+				t.Fatalf("unable to bind filter: %v", err)
+			}
+			var expected []Row
+			for _, row := range allRows {
+				if bound.matches(row) {
+					expected = append(expected, row)
+				}
+			}
+
+			rows := readAllRows(t, reader)
+			if !reflect.DeepEqual(rows, expected) {
+				t.Errorf("expected %d rows, got %d", len(expected), len(rows))
+			}
+		})
+	}
+}
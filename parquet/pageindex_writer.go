@@ -0,0 +1,96 @@
+package parquet
+
+import (
+	format "github.com/RichardNooooh/parquet-go/internal/format/gen-go/parquet"
+	"github.com/RichardNooooh/parquet-go/internal/stats"
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+// pageIndexBuilder collects the ColumnIndex and OffsetIndex entries of a
+// column chunk while its pages are encoded.
+type pageIndexBuilder struct {
+	compare     stats.Comparator
+	columnIndex *format.ColumnIndex
+	offsetIndex *format.OffsetIndex
+	mins, maxs  []any
+}
+
+func newPageIndexBuilder(leaf *schema.SchemaElement) *pageIndexBuilder {
+	b := &pageIndexBuilder{
+		compare:     stats.ComparatorOf(leaf),
+		offsetIndex: format.NewOffsetIndex(),
+	}
+	if b.compare != nil {
+		// Columns without a defined sort order, such as INT96, only get an
+		// OffsetIndex.
+		b.columnIndex = format.NewColumnIndex()
+		b.columnIndex.NullCounts = []int64{}
+	}
+	return b
+}
+
+func (b *pageIndexBuilder) addPage(accumulator *stats.Accumulator, statistics *format.Statistics, sizeStats *format.SizeStatistics, location *format.PageLocation, numValues int) {
+	b.offsetIndex.PageLocations = append(b.offsetIndex.PageLocations, location)
+	if sizeStats == nil {
+		sizeStats = &format.SizeStatistics{}
+	}
+	if sizeStats.IsSetUnencodedByteArrayDataBytes() {
+		b.offsetIndex.UnencodedByteArrayDataBytes = append(b.offsetIndex.UnencodedByteArrayDataBytes, sizeStats.GetUnencodedByteArrayDataBytes())
+	}
+	if b.columnIndex == nil {
+		return
+	}
+
+	minValue, maxValue, ok := accumulator.MinMax()
+	isNullPage := int64(numValues) == accumulator.NullCount()
+	if !ok && !isNullPage {
+		// Pages of only NaN have no bounds to store, which the ColumnIndex
+		// cannot express.
+		b.columnIndex = nil
+		return
+	}
+
+	columnIndex := b.columnIndex
+	columnIndex.NullPages = append(columnIndex.NullPages, isNullPage)
+	columnIndex.NullCounts = append(columnIndex.NullCounts, accumulator.NullCount())
+	columnIndex.RepetitionLevelHistograms = append(columnIndex.RepetitionLevelHistograms, sizeStats.GetRepetitionLevelHistogram()...)
+	columnIndex.DefinitionLevelHistograms = append(columnIndex.DefinitionLevelHistograms, sizeStats.GetDefinitionLevelHistogram()...)
+	if isNullPage {
+		columnIndex.MinValues = append(columnIndex.MinValues, []byte{})
+		columnIndex.MaxValues = append(columnIndex.MaxValues, []byte{})
+		return
+	}
+	columnIndex.MinValues = append(columnIndex.MinValues, statistics.MinValue)
+	columnIndex.MaxValues = append(columnIndex.MaxValues, statistics.MaxValue)
+	b.mins = append(b.mins, minValue)
+	b.maxs = append(b.maxs, maxValue)
+}
+
+func (b *pageIndexBuilder) build() (*format.ColumnIndex, *format.OffsetIndex) {
+	if b.columnIndex != nil {
+		b.columnIndex.BoundaryOrder = b.boundaryOrder()
+	}
+	return b.columnIndex, b.offsetIndex
+}
+
+// boundaryOrder checks whether the bounds of the non-null pages are sorted.
+func (b *pageIndexBuilder) boundaryOrder() format.BoundaryOrder {
+	ascending, descending := true, true
+	for i := 1; i < len(b.mins); i++ {
+		minOrder := b.compare(b.mins[i-1], b.mins[i])
+		maxOrder := b.compare(b.maxs[i-1], b.maxs[i])
+		if minOrder > 0 || maxOrder > 0 {
+			ascending = false
+		}
+		if minOrder < 0 || maxOrder < 0 {
+			descending = false
+		}
+	}
+	switch {
+	case ascending:
+		return format.BoundaryOrder_ASCENDING
+	case descending:
+		return format.BoundaryOrder_DESCENDING
+	}
+	return format.BoundaryOrder_UNORDERED
+}
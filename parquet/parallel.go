@@ -0,0 +1,63 @@
+package parquet
+
+import (
+	"context"
+	"sync"
+)
+
+// forEach calls fn for every index in [0, n). It runs calls on their own
+// goroutines while holding one of the slots, so at most cap(slots) run at
+// once, or runs them in order on the calling goroutine when slots is nil.
+// The first error cancels the context passed to the remaining calls and is
+// returned once all started calls have finished.
+func forEach(ctx context.Context, n int, slots chan struct{}, fn func(ctx context.Context, i int) error) error {
+	if slots == nil {
+		for i := range n {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := fn(ctx, i); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	for i := range n {
+		acquired := false
+		select {
+		case slots <- struct{}{}:
+			acquired = true
+		case <-ctx.Done():
+		}
+		if err := ctx.Err(); err != nil {
+			if acquired {
+				<-slots
+			}
+			fail(err)
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			if err := fn(ctx, i); err != nil {
+				fail(err)
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
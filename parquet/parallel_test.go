@@ -0,0 +1,138 @@
+package parquet
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestForEach(t *testing.T) {
+	testcases := map[string]struct {
+		workers int
+		failAt  int
+	}{
+		"sequential":       {workers: 0, failAt: -1},
+		"parallel":         {workers: 3, failAt: -1},
+		"sequential error": {workers: 0, failAt: 5},
+		"parallel error":   {workers: 3, failAt: 5},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			var slots chan struct{}
+			if test.workers > 0 {
+				slots = make(chan struct{}, test.workers)
+			}
+			var active, maxActive atomic.Int32
+			results := make([]int, 20)
+			err := forEach(context.Background(), len(results), slots, func(ctx context.Context, i int) error {
+				n := active.Add(1)
+				defer active.Add(-1)
+				for {
+					m := maxActive.Load()
+					if n <= m || maxActive.CompareAndSwap(m, n) {
+						break
+					}
+				}
+				if i == test.failAt {
+					return fmt.Errorf("task %d failed", i)
+				}
+				results[i] = i * i
+				return nil
+			})
+
+			if test.failAt >= 0 {
+				if err == nil || err.Error() != fmt.Sprintf("task %d failed", test.failAt) {
+					t.Errorf("expected the error of task %d, got %v", test.failAt, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for i, result := range results {
+				if result != i*i {
+					t.Errorf("expected result %d at %d, got %d", i*i, i, result)
+				}
+			}
+			if limit := int32(max(test.workers, 1)); maxActive.Load() > limit {
+				t.Errorf("expected at most %d tasks at once, got %d", limit, maxActive.Load())
+			}
+		})
+	}
+}
+
+func TestConcurrentReadMatchesSequential(t *testing.T) {
+	data := writeTestFile(t, testRows(500), WithRowGroupSize(50), WithPageSize(256))
+
+	testcases := map[string][]ParquetReaderOption{
+		"all columns": nil,
+		"filtered":    {WithFilter(Or(Lt("id", 120), GtEq("score", 300.0)))},
+		"projected":   {WithColumns("name", "tags"), WithFilter(NotEq("name", "b"))},
+	}
+
+	for name, opts := range testcases {
+		t.Run(name, func(t *testing.T) {
+			reader, err := Open(bytes.NewReader(data), int64(len(data)), opts...)
+			if err != nil {
+				t.Fatalf("unable to open file: %v", err)
+			}
+			expected := readAllRows(t, reader)
+
+			for _, workers := range []int{1, 2, 8} {
+				reader, err := Open(bytes.NewReader(data), int64(len(data)), append(opts, WithConcurrency(workers))...)
+				if err != nil {
+					t.Fatalf("unable to open file: %v", err)
+				}
+				rows, err := reader.ReadAll(context.Background())
+				if err != nil {
+					t.Fatalf("unable to read rows with %d workers: %v", workers, err)
+				}
+				if !reflect.DeepEqual(rows, expected) {
+					t.Errorf("%d workers: expected %d rows in file order, got %d", workers, len(expected), len(rows))
+				}
+			}
+		})
+	}
+}
+
+func TestConcurrentReadErrors(t *testing.T) {
+	data := writeTestFile(t, testRows(500), WithRowGroupSize(50))
+
+	// Corrupt the first page header of the name column in row group 3.
+	corrupted := bytes.Clone(data)
+	offset := readTestFooter(t, data).RowGroups[3].Columns[1].MetaData.DataPageOffset
+	copy(corrupted[offset:], bytes.Repeat([]byte{0xFF}, 16))
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	testcases := map[string]struct {
+		data []byte
+		ctx  context.Context
+		err  error
+	}{
+		"corrupt page": {data: corrupted, ctx: context.Background()},
+		"canceled":     {data: data, ctx: canceled, err: context.Canceled},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			reader, err := Open(bytes.NewReader(test.data), int64(len(test.data)), WithConcurrency(4))
+			if err != nil {
+				t.Fatalf("unable to open file: %v", err)
+			}
+			rows, err := reader.ReadAll(test.ctx)
+			if err == nil || rows != nil {
+				t.Fatalf("expected an error, got %d rows", len(rows))
+			}
+			if test.err != nil && !errors.Is(err, test.err) {
+				t.Errorf("expected %v, got %v", test.err, err)
+			}
+		})
+	}
+}
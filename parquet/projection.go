@@ -0,0 +1,99 @@
+package parquet
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+// projectColumns selects the columns named by paths, or all columns when
+// paths is nil. A path selects the leaf column it names or every leaf below
+// the group it names.
+func projectColumns(columns []*schema.Column, paths []string) ([]*schema.Column, error) {
+	if paths == nil {
+		return columns, nil
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no columns selected")
+	}
+
+	selected := make([]bool, len(columns))
+	for _, path := range paths {
+		found := false
+		for i, column := range columns {
+			if column.PathString() == path || isGroupPath(column, path) {
+				selected[i], found = true, true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("column %q not found", path)
+		}
+	}
+
+	var projected []*schema.Column
+	for i, column := range columns {
+		if selected[i] {
+			projected = append(projected, column)
+		}
+	}
+	return projected, nil
+}
+
+// isGroupPath reports whether path names one of the groups on the column's
+// path rather than a prefix of a field name that contains dots.
+func isGroupPath(column *schema.Column, path string) bool {
+	for i := range column.Path[:len(column.Path)-1] {
+		if strings.Join(column.Path[:i+1], ".") == path {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeColumns adds the extra columns missing from columns, keeping file
+// order.
+func mergeColumns(columns, extra []*schema.Column) []*schema.Column {
+	merged := slices.Clone(columns)
+	for _, column := range extra {
+		if !slices.Contains(merged, column) {
+			merged = append(merged, column)
+		}
+	}
+	if len(merged) == len(columns) {
+		return columns
+	}
+	slices.SortFunc(merged, func(a, b *schema.Column) int { return a.Index - b.Index })
+	return merged
+}
+
+// projectRow copies the fields of an assembled row that belong to the
+// projected schema.
+func projectRow(projected *schema.SchemaElement, row Row) Row {
+	return Row(projectGroup(projected, row))
+}
+
+func projectGroup(group *schema.SchemaElement, values map[string]any) map[string]any {
+	projected := make(map[string]any, len(group.Children))
+	for _, child := range group.Children {
+		value, ok := values[child.Name]
+		if !ok {
+			continue
+		}
+		if child.IsLeaf() || value == nil {
+			projected[child.Name] = value
+			continue
+		}
+		if list, ok := value.([]any); ok && child.Repetition == schema.Repeated {
+			elements := make([]any, len(list))
+			for i, element := range list {
+				elements[i] = projectGroup(child, element.(map[string]any))
+			}
+			projected[child.Name] = elements
+			continue
+		}
+		projected[child.Name] = projectGroup(child, value.(map[string]any))
+	}
+	return projected
+}
@@ -0,0 +1,150 @@
+package parquet
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestProjection(t *testing.T) {
+	rows := testRows(10)
+	data := writeTestFile(t, rows)
+
+	testcases := map[string]struct {
+		columns []string
+		filter  Predicate
+		fields  []string
+		index   int
+		row     Row
+	}{
+		"leaf": {
+			columns: []string{"score"},
+			fields:  []string{"score"},
+			index:   2,
+			row:     Row{"score": 8.0},
+		},
+		"nested leaf": {
+			columns: []string{"tags.key"},
+			fields:  []string{"tags"},
+			index:   2,
+			row:     Row{"tags": []any{map[string]any{"key": []byte("x")}, map[string]any{"key": []byte("y")}}},
+		},
+		"group": {
+			columns: []string{"id", "tags"},
+			fields:  []string{"id", "tags"},
+			index:   2,
+			row:     Row{"id": int64(2), "tags": []any{map[string]any{"key": []byte("x")}, map[string]any{"key": []byte("y")}}},
+		},
+		"filter on other column": {
+			columns: []string{"name"},
+			filter:  GtEq("id", 2),
+			fields:  []string{"name"},
+			row:     Row{"name": []byte("c")},
+		},
+		"filter on nested column": {
+			columns: []string{"id"},
+			filter:  Eq("tags.key", "x"),
+			fields:  []string{"id"},
+			index:   1,
+			row:     Row{"id": int64(2)},
+		},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			opts := []ParquetReaderOption{WithColumns(test.columns...)}
+			if test.filter != nil {
+				opts = append(opts, WithFilter(test.filter))
+			}
+			reader, err := Open(bytes.NewReader(data), int64(len(data)), opts...)
+			if err != nil {
+				t.Fatalf("unable to open file: %v", err)
+			}
+
+			var fields []string
+			for _, child := range reader.GetSchema().Children {
+				fields = append(fields, child.Name)
+			}
+			if !reflect.DeepEqual(fields, test.fields) {
+				t.Errorf("expected schema fields %v, got %v", test.fields, fields)
+			}
+
+			got := readAllRows(t, reader)
+			if len(got) <= test.index || !reflect.DeepEqual(got[test.index], test.row) {
+				t.Errorf("expected row %d to be %v, got %v", test.index, test.row, got)
+			}
+		})
+	}
+}
+
+func TestProjectionPartialNestedGroup(t *testing.T) {
+	f, err := os.Open(filepath.Join("..", "testdata", "apache_examples", "nested_maps.snappy.parquet"))
+	if err != nil {
+		t.Fatalf("unable to open file: %v", err)
+	}
+	defer f.Close()
+	fileStat, err := f.Stat()
+	if err != nil {
+		t.Fatalf("unable to get filestat: %v", err)
+	}
+	reader, err := Open(f, fileStat.Size(), WithColumns("a.key_value.value.key_value.key"))
+	if err != nil {
+		t.Fatalf("unable to open file: %v", err)
+	}
+
+	rows := readAllRows(t, reader)
+	expected := Row{"a": map[string]any{"key_value": []any{
+		map[string]any{"value": map[string]any{"key_value": []any{
+			map[string]any{"key": int32(1)},
+			map[string]any{"key": int32(2)},
+		}}},
+	}}}
+	if len(rows) != 6 || !reflect.DeepEqual(rows[0], expected) {
+		t.Errorf("expected 6 rows starting with %v, got %v", expected, rows)
+	}
+	if columns := reader.GetSchema().Columns(); len(columns) != 1 || columns[0].MaxRepetitionLevel != 2 {
+		t.Errorf("expected a single column repeated twice, got %v", columns)
+	}
+}
+
+func TestProjectionReadsSelectedChunks(t *testing.T) {
+	data := writeTestFile(t, testRows(1000))
+
+	full := &countingReaderAt{reader: bytes.NewReader(data)}
+	reader, err := Open(full, int64(len(data)))
+	if err != nil {
+		t.Fatalf("unable to open file: %v", err)
+	}
+	readAllRows(t, reader)
+
+	projected := &countingReaderAt{reader: bytes.NewReader(data)}
+	reader, err = Open(projected, int64(len(data)), WithColumns("id"))
+	if err != nil {
+		t.Fatalf("unable to open file: %v", err)
+	}
+	readAllRows(t, reader)
+
+	if projected.bytes*2 > full.bytes {
+		t.Errorf("expected far fewer bytes read for one column, got %d of %d", projected.bytes, full.bytes)
+	}
+}
+
+func TestProjectionErrors(t *testing.T) {
+	data := writeTestFile(t, testRows(10))
+
+	testcases := map[string][]string{
+		"unknown column": {"missing"},
+		"partial name":   {"ta"},
+		"no columns":     {},
+	}
+
+	for name, columns := range testcases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Open(bytes.NewReader(data), int64(len(data)), WithColumns(columns...)); err == nil {
+				t.Errorf("expected an error for columns %v", columns)
+			}
+		})
+	}
+}
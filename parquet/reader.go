@@ -1,25 +1,318 @@
 package parquet
 
 import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/RichardNooooh/parquet-go/internal/file"
 	"github.com/RichardNooooh/parquet-go/metadata"
 	"github.com/RichardNooooh/parquet-go/schema"
-	"io"
 )
 
-type ParquetReader struct{}
+type ParquetReader struct {
+	file   *file.FileReader
+	meta   *metadata.FileMeta
+	config *readerConfig
+	filter boundPredicate
+	// decryption is nil unless the file is encrypted.
+	decryption *fileDecryption
+	// schema and columns describe the projected columns. readColumns adds
+	// the columns only read to evaluate the filter, in file order.
+	schema      *schema.SchemaElement
+	columns     []*schema.Column
+	readColumns []*schema.Column
+	// converters holds the logical type converter of each projected column,
+	// and is nil unless WithLogicalTypes or WithInt96AsTimestamp is set.
+	converters []valueConverter
+	// variants holds the paths of the VARIANT groups read as Variant values
+	// with WithLogicalTypes, and variantConverters their converters.
+	variants          [][]*schema.SchemaElement
+	variantConverters []valueConverter
+	// workers bounds the column chunks decoded at once, and is nil when
+	// decoding sequentially.
+	workers chan struct{}
+	// rowGroups lists the row groups left after filtering, in file order.
+	rowGroups []int
+	pruned    int
 
-func NewReader() {
+	// ReadRow state: rows of the current row group not yet returned and the
+	// position of the next row group in rowGroups.
+	rows         []Row
+	nextRowGroup int
 
+	// closer releases the file opened by OpenFile.
+	closer io.Closer
 }
 
 func Open(r io.ReaderAt, size int64, opts ...ParquetReaderOption) (*ParquetReader, error) {
-	return nil, nil
+	config := newReaderConfig(opts)
+	fileReader := file.NewReader(r, size)
+	fileMetadata, decryption, err := readFooter(context.Background(), fileReader, config)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := metadata.NewFileMeta(fileMetadata)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := &ParquetReader{file: fileReader, meta: meta, config: config, decryption: decryption}
+	if reader.columns, err = projectColumns(meta.Columns(), reader.config.columns); err != nil {
+		return nil, err
+	}
+	if reader.config.concurrency > 1 {
+		reader.workers = make(chan struct{}, reader.config.concurrency)
+	}
+	reader.schema = meta.Schema()
+	if reader.config.columns != nil {
+		reader.schema = reader.schema.Project(reader.columns)
+	}
+	if reader.config.int96AsTimestamp {
+		reader.schema = coerceInt96(reader.schema)
+	}
+	reader.readColumns = reader.columns
+	if reader.config.logical || reader.config.int96AsTimestamp {
+		rebase := int96RebaseMode(meta.KeyValueMetadata(), reader.config.int96Rebase)
+		reader.converters = make([]valueConverter, len(reader.columns))
+		for i, column := range reader.columns {
+			if column.Leaf.Type == schema.Int96 {
+				reader.converters[i] = newInt96Converter(rebase, reader.config.logical)
+			} else if reader.config.logical && !inVariant(column) {
+				if reader.converters[i], err = newValueConverter(column.Leaf); err != nil {
+					return nil, err
+				}
+				if reader.config.timestampLocation != nil && isLocalTimestamp(column.Leaf) {
+					reader.converters[i] = wallClockIn(reader.converters[i], reader.config.timestampLocation)
+				}
+			}
+		}
+	}
+	if reader.config.logical {
+		for _, path := range variantPaths(reader.schema, nil) {
+			converter, err := newVariantConverter(path[len(path)-1])
+			if err != nil {
+				return nil, err
+			}
+			if converter != nil {
+				reader.variants = append(reader.variants, path)
+				reader.variantConverters = append(reader.variantConverters, converter)
+			}
+		}
+	}
+	if reader.config.filter != nil {
+		if reader.filter, err = reader.config.filter.bind(meta); err != nil {
+			return nil, err
+		}
+		reader.readColumns = mergeColumns(reader.columns, reader.filter.appendColumns(nil))
+	}
+	for i := range meta.NumRowGroups() {
+		if reader.filter != nil && !reader.filter.mightMatch(rowGroupBounds(reader, i)) {
+			reader.pruned++
+			continue
+		}
+		reader.rowGroups = append(reader.rowGroups, i)
+	}
+	return reader, nil
+}
+
+func (r *ParquetReader) GetMeta() *metadata.FileMeta { return r.meta }
+
+// GetSchema returns the schema of the rows read, which only holds the
+// projected columns when reading a subset of them.
+func (r *ParquetReader) GetSchema() *schema.SchemaElement { return r.schema }
+
+// RowGroups returns the indexes of the row groups that the filter could not
+// rule out, or of all row groups without a filter.
+func (r *ParquetReader) RowGroups() []int { return r.rowGroups }
+
+// PrunedRowGroups returns the number of row groups skipped by the filter.
+func (r *ParquetReader) PrunedRowGroups() int { return r.pruned }
+
+// ReadRowGroup reads and assembles the rows of a row group, keeping only the
+// rows that match the filter.
+func (r *ParquetReader) ReadRowGroup(i int) ([]Row, error) {
+	return r.readRowGroup(context.Background(), i)
+}
+
+// ReadRowGroupContext is ReadRowGroup with a context that stops decoding
+// when canceled.
+func (r *ParquetReader) ReadRowGroupContext(ctx context.Context, i int) ([]Row, error) {
+	return r.readRowGroup(ctx, i)
+}
+
+// ReadAll reads the matching rows of all row groups left after filtering, in
+// file order. With WithConcurrency, row groups are read concurrently and the
+// first error cancels the others.
+func (r *ParquetReader) ReadAll(ctx context.Context) ([]Row, error) {
+	var slots chan struct{}
+	if r.workers != nil {
+		slots = make(chan struct{}, cap(r.workers))
+	}
+	rowGroups := make([][]Row, len(r.rowGroups))
+	err := forEach(ctx, len(r.rowGroups), slots, func(ctx context.Context, i int) error {
+		rows, err := r.readRowGroup(ctx, r.rowGroups[i])
+		rowGroups[i] = rows
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []Row
+	for _, rowGroup := range rowGroups {
+		rows = append(rows, rowGroup...)
+	}
+	return rows, nil
+}
+
+// ReadRow returns the next matching row of the row groups left after
+// filtering, or io.EOF once all of them have been read.
+func (r *ParquetReader) ReadRow() (Row, error) {
+	for len(r.rows) == 0 {
+		if r.nextRowGroup >= len(r.rowGroups) {
+			return nil, io.EOF
+		}
+		rows, err := r.readRowGroup(context.Background(), r.rowGroups[r.nextRowGroup])
+		if err != nil {
+			return nil, err
+		}
+		r.rows = rows
+		r.nextRowGroup++
+	}
+	row := r.rows[0]
+	r.rows = r.rows[1:]
+	return row, nil
 }
 
-func (r *ParquetReader) GetMeta() *metadata.FileMeta { return nil }
+func (r *ParquetReader) readRowGroup(ctx context.Context, i int) ([]Row, error) {
+	if i < 0 || i >= r.meta.NumRowGroups() {
+		return nil, fmt.Errorf("row group %d out of range [0, %d)", i, r.meta.NumRowGroups())
+	}
+	indexes := newPageIndexes(r, i)
+	ranges := allRows(indexes.numRows)
+	// pageRanges limits the pages read to those holding selected rows, and
+	// is nil when every row is selected.
+	var pageRanges rowRanges
+	if r.filter != nil {
+		ranges = r.filter.selectRows(indexes)
+		if indexes.err != nil {
+			return nil, fmt.Errorf("row group %d: %w", i, indexes.err)
+		}
+		if ranges.numRows() == 0 {
+			return nil, nil
+		}
+		if ranges.numRows() < indexes.numRows {
+			pageRanges = ranges
+		}
+	}
 
-func (r *ParquetReader) GetSchema() *schema.SchemaElement { return nil }
+	// Plan the reads of all columns up front so that they can be coalesced,
+	// and so that concurrent decoding only reads the page index cache.
+	reads := make([][]chunkRead, len(r.readColumns))
+	var plan []file.Range
+	for j, column := range r.readColumns {
+		columnReads, err := r.planColumnChunk(indexes, column.Index, pageRanges)
+		if err != nil {
+			return nil, fmt.Errorf("row group %d: %w", i, err)
+		}
+		reads[j] = columnReads
+		for _, read := range columnReads {
+			plan = append(plan, read.Range)
+		}
+	}
+	source := file.NewPrefetcher(ctx, r.file, plan, r.config.maxGap, r.config.maxReadSize, r.config.prefetch)
 
-// func (*ParquetReader) ReadRowGroup(i uint32) []byte { return nil }
+	values := make([]*columnValues, len(r.readColumns))
+	err := forEach(ctx, len(r.readColumns), r.workers, func(ctx context.Context, j int) error {
+		chunk, err := r.readColumnChunk(ctx, source, i, r.readColumns[j].Index, reads[j])
+		values[j] = chunk
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("row group %d: %w", i, err)
+	}
 
-func (*ParquetReader) Close() error { return nil }
+	rows, err := assembleRows(r.readColumns, values, ranges, indexes.numRows)
+	if err != nil {
+		return nil, fmt.Errorf("row group %d: %w", i, err)
+	}
+	if r.filter != nil {
+		matching := rows[:0]
+		for _, row := range rows {
+			if r.filter.matches(row) {
+				if len(r.readColumns) != len(r.columns) {
+					row = projectRow(r.schema, row)
+				}
+				matching = append(matching, row)
+			}
+		}
+		rows = matching
+	}
+	if r.converters != nil {
+		if err := convertRows(r.columns, r.converters, rows); err != nil {
+			return nil, fmt.Errorf("row group %d: %w", i, err)
+		}
+	}
+	if err := convertVariants(r.variants, r.variantConverters, rows); err != nil {
+		return nil, fmt.Errorf("row group %d: %w", i, err)
+	}
+	return rows, nil
+}
+
+// Close closes the file when the reader was created by OpenFile. Readers
+// created by Open leave closing the io.ReaderAt to the caller.
+func (r *ParquetReader) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+	err := r.closer.Close()
+	r.closer = nil
+	return err
+}
+
+// ColumnIndex loads the page statistics of a column chunk. It returns nil
+// when the chunk was written without a page index.
+func (r *ParquetReader) ColumnIndex(rowGroup, column int) (*metadata.ColumnIndex, error) {
+	chunk, err := r.columnChunk(rowGroup, column)
+	if err != nil {
+		return nil, err
+	}
+	decryptor, err := r.decryption.decryptor(rowGroup, column)
+	if err != nil {
+		return nil, err
+	}
+	columnIndex, err := file.GetColumnIndex(context.Background(), r.file, chunk.Format(), decryptor)
+	if err != nil || columnIndex == nil {
+		return nil, err
+	}
+	return r.meta.DecodeColumnIndex(column, columnIndex), nil
+}
+
+// OffsetIndex loads the page locations of a column chunk. It returns nil when
+// the chunk was written without a page index.
+func (r *ParquetReader) OffsetIndex(rowGroup, column int) (*metadata.OffsetIndex, error) {
+	chunk, err := r.columnChunk(rowGroup, column)
+	if err != nil {
+		return nil, err
+	}
+	decryptor, err := r.decryption.decryptor(rowGroup, column)
+	if err != nil {
+		return nil, err
+	}
+	offsetIndex, err := file.GetOffsetIndex(context.Background(), r.file, chunk.Format(), decryptor)
+	if err != nil || offsetIndex == nil {
+		return nil, err
+	}
+	return metadata.NewOffsetIndex(offsetIndex), nil
+}
+
+func (r *ParquetReader) columnChunk(rowGroup, column int) (*metadata.ColumnChunkMeta, error) {
+	if rowGroup < 0 || rowGroup >= r.meta.NumRowGroups() {
+		return nil, fmt.Errorf("row group %d out of range [0, %d)", rowGroup, r.meta.NumRowGroups())
+	}
+	if column < 0 || column >= len(r.meta.Columns()) {
+		return nil, fmt.Errorf("column %d out of range [0, %d)", column, len(r.meta.Columns()))
+	}
+	return r.meta.RowGroup(rowGroup).Column(column), nil
+}
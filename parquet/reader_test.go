@@ -0,0 +1,316 @@
+package parquet
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/RichardNooooh/parquet-go/internal/decoder"
+	"github.com/RichardNooooh/parquet-go/internal/file"
+	format "github.com/RichardNooooh/parquet-go/internal/format/gen-go/parquet"
+	"github.com/RichardNooooh/parquet-go/metadata"
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+func readAllRows(t *testing.T, reader *ParquetReader) []Row {
+	t.Helper()
+	var rows []Row
+	for {
+		row, err := reader.ReadRow()
+		if errors.Is(err, io.EOF) {
+			return rows
+		}
+		if err != nil {
+			t.Fatalf("unable to read row: %v", err)
+		}
+		rows = append(rows, row)
+	}
+}
+
+func TestReaderStatisticsRoundTrip(t *testing.T) {
+	data := writeTestFile(t, testRows(10))
+	reader, err := Open(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("unable to open file: %v", err)
+	}
+	defer reader.Close()
+
+	testcases := map[string]struct {
+		column   int
+		min, max any
+	}{
+		"id":    {column: 0, min: int64(0), max: int64(9)},
+		"score": {column: 2, min: 1.0, max: 10.0},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			stats := reader.GetMeta().RowGroup(0).Column(test.column).Statistics()
+			if !stats.Trusted || stats.Deprecated || stats.Min != test.min || stats.Max != test.max {
+				t.Errorf("expected trusted [%v, %v], got %+v", test.min, test.max, stats)
+			}
+		})
+	}
+}
+
+func TestReaderPageIndex(t *testing.T) {
+	f, err := os.Open(filepath.Join("..", "testdata", "apache_examples", "alltypes_tiny_pages_plain.parquet"))
+	if err != nil {
+		t.Fatalf("unable to open file: %v", err)
+	}
+	defer f.Close()
+	fileStat, err := f.Stat()
+	if err != nil {
+		t.Fatalf("unable to get filestat: %v", err)
+	}
+	reader, err := Open(f, fileStat.Size())
+	if err != nil {
+		t.Fatalf("unable to open file: %v", err)
+	}
+
+	columnIndex, err := reader.ColumnIndex(0, 11)
+	if err != nil {
+		t.Fatalf("unable to read column index: %v", err)
+	}
+	if !columnIndex.Trusted || columnIndex.BoundaryOrder != metadata.Ascending || columnIndex.NumPages() != 325 {
+		t.Fatalf("unexpected column index for year: %+v", columnIndex)
+	}
+	if columnIndex.Min[0] != int32(2009) || columnIndex.Max[324] != int32(2010) || columnIndex.NullCounts[0] != 0 {
+		t.Errorf("expected years in [2009, 2010], got %v..%v", columnIndex.Min[0], columnIndex.Max[324])
+	}
+
+	offsetIndex, err := reader.OffsetIndex(0, 11)
+	if err != nil {
+		t.Fatalf("unable to read offset index: %v", err)
+	}
+	if last := offsetIndex.LastRowIndex(offsetIndex.NumPages()-1, reader.GetMeta().NumRows()); last != 7299 {
+		t.Errorf("expected last page to end on row 7299, got %d", last)
+	}
+
+	if columnIndex, err := reader.ColumnIndex(0, 10); err != nil || columnIndex != nil {
+		t.Errorf("expected no column index for timestamp_col, got %v, %v", columnIndex, err)
+	}
+	if _, err := reader.ColumnIndex(1, 0); err == nil {
+		t.Errorf("expected out of range error, got nil error")
+	}
+}
+
+func TestReaderRowsRoundTrip(t *testing.T) {
+	rows := testRows(25)
+	data := writeTestFile(t, rows, WithPageSize(64), WithRowGroupSize(10))
+	reader, err := Open(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("unable to open file: %v", err)
+	}
+	defer reader.Close()
+
+	got := readAllRows(t, reader)
+	if len(got) != len(rows) {
+		t.Fatalf("expected %d rows, got %d", len(rows), len(got))
+	}
+	for i, row := range rows {
+		expected := Row{"id": row["id"], "name": nil, "score": row["score"], "tags": []any{}}
+		if name, ok := row["name"].(string); ok {
+			expected["name"] = []byte(name)
+		}
+		if _, ok := row["tags"]; ok {
+			expected["tags"] = []any{map[string]any{"key": []byte("x")}, map[string]any{"key": []byte("y")}}
+		}
+		if !reflect.DeepEqual(got[i], expected) {
+			t.Errorf("row %d: expected %v, got %v", i, expected, got[i])
+		}
+	}
+}
+
+func TestReaderTestdataFiles(t *testing.T) {
+	testcases := map[string]struct {
+		file  string
+		rows  int
+		first map[string]any
+	}{
+		"dictionary": {
+			file:  filepath.Join("apache_examples", "alltypes_plain.parquet"),
+			rows:  8,
+			first: map[string]any{"id": int32(4), "bool_col": true, "string_col": []byte("0")},
+		},
+		"snappy": {
+			file:  filepath.Join("timestored_examples", "iris.parquet"),
+			rows:  150,
+			first: map[string]any{"sepal.length": 5.1, "variety": []byte("Setosa")},
+		},
+		"nested maps": {
+			file: filepath.Join("apache_examples", "nested_maps.snappy.parquet"),
+			rows: 6,
+			first: map[string]any{"b": int32(1), "a": map[string]any{"key_value": []any{
+				map[string]any{"key": []byte("a"), "value": map[string]any{"key_value": []any{
+					map[string]any{"key": int32(1), "value": true},
+					map[string]any{"key": int32(2), "value": false},
+				}}},
+			}}},
+		},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("..", "testdata", test.file))
+			if err != nil {
+				t.Fatalf("unable to open file: %v", err)
+			}
+			defer f.Close()
+			fileStat, err := f.Stat()
+			if err != nil {
+				t.Fatalf("unable to get filestat: %v", err)
+			}
+			reader, err := Open(f, fileStat.Size())
+			if err != nil {
+				t.Fatalf("unable to open file: %v", err)
+			}
+
+			rows := readAllRows(t, reader)
+			if len(rows) != test.rows {
+				t.Fatalf("expected %d rows, got %d", test.rows, len(rows))
+			}
+			for column, value := range test.first {
+				if !reflect.DeepEqual(rows[0][column], value) {
+					t.Errorf("column %q: expected %v, got %v", column, value, rows[0][column])
+				}
+			}
+		})
+	}
+}
+
+func TestReaderCoalescesReads(t *testing.T) {
+	data := writeTestFile(t, testRows(1000), WithRowGroupSize(500))
+
+	testcases := map[string]struct {
+		opts  []ParquetReaderOption
+		reads int
+	}{
+		"adjacent chunks":        {reads: 2},
+		"projection":             {opts: []ParquetReaderOption{WithColumns("id", "score")}, reads: 4},
+		"projection with gaps":   {opts: []ParquetReaderOption{WithColumns("id", "score"), WithReadCoalescing(1<<20, 1<<20)}, reads: 2},
+		"size limit":             {opts: []ParquetReaderOption{WithReadCoalescing(0, 1024)}, reads: 8},
+		"prefetch window":        {opts: []ParquetReaderOption{WithReadCoalescing(0, 1024), WithPrefetch(4)}, reads: 8},
+		"concurrent with window": {opts: []ParquetReaderOption{WithConcurrency(4), WithPrefetch(2)}, reads: 2},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			counter := &countingReaderAt{reader: bytes.NewReader(data)}
+			reader, err := Open(counter, int64(len(data)), test.opts...)
+			if err != nil {
+				t.Fatalf("unable to open file: %v", err)
+			}
+			footerReads := counter.calls
+
+			if rows := readAllRows(t, reader); len(rows) != 1000 {
+				t.Fatalf("expected 1000 rows, got %d", len(rows))
+			}
+			if reads := counter.calls - footerReads; reads != test.reads {
+				t.Errorf("expected %d reads, got %d", test.reads, reads)
+			}
+		})
+	}
+}
+
+func TestReaderFooterReadSize(t *testing.T) {
+	data := writeTestFile(t, testRows(1000), WithRowGroupSize(500))
+
+	testcases := map[string]struct {
+		opts  []ParquetReaderOption
+		reads int
+	}{
+		"separate reads": {reads: 4},
+		"footer in tail": {opts: []ParquetReaderOption{WithFooterReadSize(64 << 10)}, reads: 1},
+		"footer larger":  {opts: []ParquetReaderOption{WithFooterReadSize(16)}, reads: 2},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			counter := &countingReaderAt{reader: bytes.NewReader(data)}
+			reader, err := Open(counter, int64(len(data)), test.opts...)
+			if err != nil {
+				t.Fatalf("unable to open file: %v", err)
+			}
+			if counter.calls != test.reads {
+				t.Errorf("expected %d reads, got %d", test.reads, counter.calls)
+			}
+			if rows := readAllRows(t, reader); len(rows) != 1000 {
+				t.Errorf("expected 1000 rows, got %d", len(rows))
+			}
+		})
+	}
+}
+
+func TestReaderCorruptPages(t *testing.T) {
+	column := schema.NewSchema(schema.NewLeaf("x", schema.Int32, schema.Optional)).Columns()[0]
+	// An optional column has one definition level per value, stored with a
+	// 4-byte length prefix in v1 pages.
+	levels := []byte{2, 0, 0, 0, 1 << 1, 1}
+	values := []byte{1, 0, 0, 0}
+	dataPage := func(numValues int32, data []byte) *file.Page {
+		header := format.NewPageHeader()
+		header.Type = format.PageType_DATA_PAGE
+		header.UncompressedPageSize = int32(len(data))
+		header.CompressedPageSize = int32(len(data))
+		header.DataPageHeader = &format.DataPageHeader{
+			NumValues:               numValues,
+			Encoding:                format.Encoding_PLAIN,
+			DefinitionLevelEncoding: format.Encoding_RLE,
+			RepetitionLevelEncoding: format.Encoding_RLE,
+		}
+		return &file.Page{Header: header, Data: data}
+	}
+	dataPageV2 := func(numValues int32, data []byte) *file.Page {
+		header := format.NewPageHeader()
+		header.Type = format.PageType_DATA_PAGE_V2
+		header.UncompressedPageSize = int32(len(data))
+		header.CompressedPageSize = int32(len(data))
+		header.DataPageHeaderV2 = &format.DataPageHeaderV2{
+			NumValues:                  numValues,
+			NumRows:                    numValues,
+			Encoding:                   format.Encoding_PLAIN,
+			DefinitionLevelsByteLength: int32(len(levels) - 4),
+		}
+		return &file.Page{Header: header, Data: data}
+	}
+	dictionaryPage := func(numValues int32, data []byte) *file.Page {
+		header := format.NewPageHeader()
+		header.Type = format.PageType_DICTIONARY_PAGE
+		header.UncompressedPageSize = int32(len(data))
+		header.CompressedPageSize = int32(len(data))
+		header.DictionaryPageHeader = &format.DictionaryPageHeader{NumValues: numValues, Encoding: format.Encoding_PLAIN}
+		return &file.Page{Header: header, Data: data}
+	}
+
+	for _, page := range []*file.Page{dataPage(1, append(levels, values...)), dataPageV2(1, append(levels[4:], values...))} {
+		d := &pageDecoder{column: column, codec: format.CompressionCodec_UNCOMPRESSED, chunkSize: 1 << 10, numValues: 1}
+		if err := d.decode(page, &columnValues{}); err != nil {
+			t.Fatalf("unable to decode valid page: %v", err)
+		}
+	}
+
+	testcases := map[string]*file.Page{
+		"negative values":            dataPage(-1, append(levels, values...)),
+		"values beyond chunk":        dataPage(math.MaxInt32, append(levels, values...)),
+		"negative values v2":         dataPageV2(-1, append(levels[4:], values...)),
+		"values beyond chunk v2":     dataPageV2(math.MaxInt32, append(levels[4:], values...)),
+		"negative dictionary":        dictionaryPage(-1, values),
+		"dictionary beyond page":     dictionaryPage(math.MaxInt32, values),
+		"overflowing bit-packed run": dataPage(1, []byte{11, 0, 0, 0, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01, 0xff}),
+	}
+
+	for name, page := range testcases {
+		t.Run(name, func(t *testing.T) {
+			d := &pageDecoder{column: column, codec: format.CompressionCodec_UNCOMPRESSED, chunkSize: 1 << 10, numValues: 1}
+			if err := d.decode(page, &columnValues{}); !errors.Is(err, decoder.ErrCorruptData) {
+				t.Errorf("expected %v, got %v", decoder.ErrCorruptData, err)
+			}
+		})
+	}
+}
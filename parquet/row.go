@@ -0,0 +1,6 @@
+package parquet
+
+// Row is a single record keyed by top-level field name. Groups are nested
+// Rows (or map[string]any), repeated fields are slices and null values are
+// nil or absent.
+type Row map[string]any
@@ -0,0 +1,75 @@
+package parquet
+
+// rowRange is a half-open range [start, end) of rows within a row group.
+type rowRange struct {
+	start, end int64
+}
+
+// rowRanges is a sorted list of disjoint, non-adjacent row ranges.
+type rowRanges []rowRange
+
+func allRows(numRows int64) rowRanges {
+	if numRows == 0 {
+		return rowRanges{}
+	}
+	return rowRanges{{0, numRows}}
+}
+
+func (r rowRanges) numRows() int64 {
+	var n int64
+	for _, rowRange := range r {
+		n += rowRange.end - rowRange.start
+	}
+	return n
+}
+
+// add appends a range that starts at or after the end of the last one.
+func (r rowRanges) add(start, end int64) rowRanges {
+	if start >= end {
+		return r
+	}
+	if n := len(r); n > 0 && r[n-1].end >= start {
+		r[n-1].end = max(r[n-1].end, end)
+		return r
+	}
+	return append(r, rowRange{start, end})
+}
+
+// overlaps reports whether any range intersects [start, end).
+func (r rowRanges) overlaps(start, end int64) bool {
+	for _, rowRange := range r {
+		if rowRange.start < end && start < rowRange.end {
+			return true
+		}
+	}
+	return false
+}
+
+func (r rowRanges) union(other rowRanges) rowRanges {
+	result := make(rowRanges, 0, len(r)+len(other))
+	i, j := 0, 0
+	for i < len(r) || j < len(other) {
+		if j == len(other) || (i < len(r) && r[i].start <= other[j].start) {
+			result = result.add(r[i].start, r[i].end)
+			i++
+		} else {
+			result = result.add(other[j].start, other[j].end)
+			j++
+		}
+	}
+	return result
+}
+
+func (r rowRanges) intersection(other rowRanges) rowRanges {
+	result := rowRanges{}
+	i, j := 0, 0
+	for i < len(r) && j < len(other) {
+		result = result.add(max(r[i].start, other[j].start), min(r[i].end, other[j].end))
+		if r[i].end < other[j].end {
+			i++
+		} else {
+			j++
+		}
+	}
+	return result
+}
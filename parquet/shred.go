@@ -0,0 +1,149 @@
+package parquet
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+// columnBuffer holds the shredded levels and non-null values of one leaf
+// column for the row group being written.
+type columnBuffer struct {
+	column           *schema.Column
+	repetitionLevels []int32
+	definitionLevels []int32
+	values           []any
+	// encode, when set, converts the values of the caller before they are
+	// checked against the physical type.
+	encode func(any) (any, error)
+}
+
+func newColumnBuffer(column *schema.Column, encode func(any) (any, error)) *columnBuffer {
+	return &columnBuffer{column: column, encode: encode}
+}
+
+func (b *columnBuffer) reset() {
+	b.repetitionLevels = b.repetitionLevels[:0]
+	b.definitionLevels = b.definitionLevels[:0]
+	b.values = b.values[:0]
+}
+
+func (b *columnBuffer) truncate(levels, values int) {
+	b.repetitionLevels = b.repetitionLevels[:levels]
+	b.definitionLevels = b.definitionLevels[:levels]
+	b.values = b.values[:values]
+}
+
+// shredRow appends the levels and values of row to the buffer.
+func (b *columnBuffer) shredRow(row Row) error {
+	return b.shred(row[b.column.Nodes[0].Name], 0, 0, 0, 0)
+}
+
+func (b *columnBuffer) shred(value any, depth int, rep, def, repDepth int32) error {
+	node := b.column.Nodes[depth]
+	switch node.Repetition {
+	case schema.Repeated:
+		elements, err := toSlice(value)
+		if err != nil {
+			return fmt.Errorf("repeated field %q: %w", node.Name, err)
+		}
+		if len(elements) == 0 {
+			b.appendNull(rep, def)
+			return nil
+		}
+		for i, element := range elements {
+			r := rep
+			if i > 0 {
+				r = repDepth + 1
+			}
+			if err := b.shredContent(element, depth, r, def+1, repDepth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	case schema.Optional:
+		if value == nil {
+			b.appendNull(rep, def)
+			return nil
+		}
+		return b.shredContent(value, depth, rep, def+1, repDepth)
+	}
+
+	if value == nil {
+		return fmt.Errorf("required field %q is missing", node.Name)
+	}
+	return b.shredContent(value, depth, rep, def, repDepth)
+}
+
+func (b *columnBuffer) shredContent(value any, depth int, rep, def, repDepth int32) error {
+	node := b.column.Nodes[depth]
+	if node.IsLeaf() {
+		if value == nil {
+			return fmt.Errorf("field %q has a null element", node.Name)
+		}
+		if b.encode != nil {
+			encoded, err := b.encode(value)
+			if err != nil {
+				return fmt.Errorf("field %q: %w", node.Name, err)
+			}
+			value = encoded
+		}
+		physical, err := toPhysical(node, value)
+		if err != nil {
+			return err
+		}
+		b.repetitionLevels = append(b.repetitionLevels, rep)
+		b.definitionLevels = append(b.definitionLevels, def)
+		b.values = append(b.values, physical)
+		return nil
+	}
+
+	if variant, ok := value.(Variant); ok && isVariant(node) {
+		metadata, encoded, err := variant.Encode()
+		if err != nil {
+			return fmt.Errorf("variant %q: %w", node.Name, err)
+		}
+		value = map[string]any{"metadata": metadata, "value": encoded}
+	}
+	group, ok := toGroup(value)
+	if !ok {
+		return fmt.Errorf("group %q cannot hold %T", node.Name, value)
+	}
+	child := b.column.Nodes[depth+1]
+	return b.shred(group[child.Name], depth+1, rep, def, repDepth)
+}
+
+func (b *columnBuffer) appendNull(rep, def int32) {
+	b.repetitionLevels = append(b.repetitionLevels, rep)
+	b.definitionLevels = append(b.definitionLevels, def)
+}
+
+func toGroup(value any) (map[string]any, bool) {
+	switch v := value.(type) {
+	case Row:
+		return v, true
+	case map[string]any:
+		return v, true
+	}
+	group, err := structGroup(value)
+	return group, err == nil
+}
+
+func toSlice(value any) ([]any, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []any:
+		return v, nil
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("expected a slice, got %T", value)
+	}
+	elements := make([]any, rv.Len())
+	for i := range elements {
+		elements[i] = rv.Index(i).Interface()
+	}
+	return elements, nil
+}
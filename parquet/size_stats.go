@@ -0,0 +1,110 @@
+package parquet
+
+import (
+	format "github.com/RichardNooooh/parquet-go/internal/format/gen-go/parquet"
+	"github.com/RichardNooooh/parquet-go/metadata"
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+// newSizeStatistics returns empty size statistics for a column, with a
+// histogram for each level whose maximum is not zero. It returns nil for
+// columns that would have none of them.
+func newSizeStatistics(column *schema.Column) *format.SizeStatistics {
+	statistics := &format.SizeStatistics{}
+	empty := true
+	if column.Leaf.Type == schema.ByteArray {
+		statistics.UnencodedByteArrayDataBytes = new(int64)
+		empty = false
+	}
+	if column.MaxRepetitionLevel > 0 {
+		statistics.RepetitionLevelHistogram = make([]int64, column.MaxRepetitionLevel+1)
+		empty = false
+	}
+	if column.MaxDefinitionLevel > 0 {
+		statistics.DefinitionLevelHistogram = make([]int64, column.MaxDefinitionLevel+1)
+		empty = false
+	}
+	if empty {
+		return nil
+	}
+	return statistics
+}
+
+// pageSizeStatistics computes the size statistics of a page of a column
+// buffer.
+func pageSizeStatistics(buffer *columnBuffer, p page) *format.SizeStatistics {
+	statistics := newSizeStatistics(buffer.column)
+	if statistics == nil {
+		return nil
+	}
+	if statistics.UnencodedByteArrayDataBytes != nil {
+		for _, value := range buffer.values[p.valueStart:p.valueEnd] {
+			*statistics.UnencodedByteArrayDataBytes += int64(len(value.([]byte)))
+		}
+	}
+	if statistics.RepetitionLevelHistogram != nil {
+		for _, level := range buffer.repetitionLevels[p.levelStart:p.levelEnd] {
+			statistics.RepetitionLevelHistogram[level]++
+		}
+	}
+	if statistics.DefinitionLevelHistogram != nil {
+		for _, level := range buffer.definitionLevels[p.levelStart:p.levelEnd] {
+			statistics.DefinitionLevelHistogram[level]++
+		}
+	}
+	return statistics
+}
+
+// addSizeStatistics adds the statistics of a page to those of its chunk.
+func addSizeStatistics(chunk, page *format.SizeStatistics) {
+	if chunk == nil || page == nil {
+		return
+	}
+	if chunk.UnencodedByteArrayDataBytes != nil {
+		*chunk.UnencodedByteArrayDataBytes += page.GetUnencodedByteArrayDataBytes()
+	}
+	for i, count := range page.RepetitionLevelHistogram {
+		chunk.RepetitionLevelHistogram[i] += count
+	}
+	for i, count := range page.DefinitionLevelHistogram {
+		chunk.DefinitionLevelHistogram[i] += count
+	}
+}
+
+// maxPreallocatedValues and maxPreallocatedBytes cap the buffers sized from
+// the footer, beyond which they grow as values are read.
+const (
+	maxPreallocatedValues = 1 << 20
+	maxPreallocatedBytes  = 1 << 24
+)
+
+// preallocateColumnValues sizes the buffers of a column chunk read whole
+// from its size statistics: its levels and values, and for BYTE_ARRAY
+// columns one buffer that its values are copied into. Counts that its pages
+// cannot hold, taking at least a bit per level, are ignored.
+func preallocateColumnValues(chunk *metadata.ColumnChunkMeta, values *columnValues) {
+	column := chunk.Column()
+	numLevels := chunk.NumValues()
+	numValues := numLevels
+	statistics := chunk.SizeStatistics()
+	if statistics != nil {
+		if n, ok := statistics.NumNonNullValues(column); ok {
+			numValues = n
+		}
+	}
+	if numValues < 0 || numValues > numLevels || numLevels/8 > chunk.TotalUncompressedSize() {
+		return
+	}
+	numLevels = min(numLevels, maxPreallocatedValues)
+	numValues = min(numValues, maxPreallocatedValues)
+	if column.MaxRepetitionLevel > 0 {
+		values.repetitionLevels = make([]int32, 0, numLevels)
+	}
+	values.definitionLevels = make([]int32, 0, numLevels)
+	values.values = make([]any, 0, numValues)
+	if statistics != nil && statistics.HasUnencodedByteArrayDataBytes {
+		if size := statistics.UnencodedByteArrayDataBytes; size > 0 {
+			values.data = make([]byte, 0, min(size, maxPreallocatedBytes))
+		}
+	}
+}
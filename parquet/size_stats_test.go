@@ -0,0 +1,182 @@
+package parquet
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	format "github.com/RichardNooooh/parquet-go/internal/format/gen-go/parquet"
+)
+
+func TestWriterSizeStatistics(t *testing.T) {
+	data := writeTestFile(t, testRows(30), WithPageIndex(true), WithPageSize(64))
+	reader, err := Open(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("unable to open file: %v", err)
+	}
+	defer reader.Close()
+
+	testcases := map[string]struct {
+		column     int
+		hasSize    bool
+		bytes      int64
+		repetition []int64
+		definition []int64
+	}{
+		"required": {column: 0},
+		"optional": {column: 1, hasSize: true, bytes: 20, definition: []int64{10, 20}},
+		"double":   {column: 2, hasSize: true, definition: []int64{0, 30}},
+		"repeated": {column: 3, hasSize: true, bytes: 30, repetition: []int64{30, 15}, definition: []int64{15, 30}},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			statistics := reader.GetMeta().RowGroup(0).Column(test.column).SizeStatistics()
+			if !test.hasSize {
+				if statistics != nil {
+					t.Fatalf("expected no size statistics, got %+v", statistics)
+				}
+				return
+			}
+			if statistics == nil {
+				t.Fatalf("expected size statistics, got nil")
+			}
+			if statistics.UnencodedByteArrayDataBytes != test.bytes {
+				t.Errorf("expected %d bytes, got %d", test.bytes, statistics.UnencodedByteArrayDataBytes)
+			}
+			if !reflect.DeepEqual(statistics.RepetitionLevelHistogram, test.repetition) {
+				t.Errorf("expected repetition histogram %v, got %v", test.repetition, statistics.RepetitionLevelHistogram)
+			}
+			if !reflect.DeepEqual(statistics.DefinitionLevelHistogram, test.definition) {
+				t.Errorf("expected definition histogram %v, got %v", test.definition, statistics.DefinitionLevelHistogram)
+			}
+
+			columnIndex, err := reader.ColumnIndex(0, test.column)
+			if err != nil {
+				t.Fatalf("unable to read column index: %v", err)
+			}
+			offsetIndex, err := reader.OffsetIndex(0, test.column)
+			if err != nil {
+				t.Fatalf("unable to read offset index: %v", err)
+			}
+			if offsetIndex.NumPages() < 2 {
+				t.Fatalf("expected several pages, got %d", offsetIndex.NumPages())
+			}
+			if sum := sumHistograms(columnIndex.RepetitionLevelHistograms); !reflect.DeepEqual(sum, test.repetition) {
+				t.Errorf("expected page repetition histograms to add up to %v, got %v", test.repetition, sum)
+			}
+			if sum := sumHistograms(columnIndex.DefinitionLevelHistograms); !reflect.DeepEqual(sum, test.definition) {
+				t.Errorf("expected page definition histograms to add up to %v, got %v", test.definition, sum)
+			}
+			var pageBytes int64
+			for _, size := range offsetIndex.UnencodedByteArrayDataBytes {
+				pageBytes += size
+			}
+			if pageBytes != test.bytes {
+				t.Errorf("expected page sizes to add up to %d, got %d", test.bytes, pageBytes)
+			}
+		})
+	}
+}
+
+func sumHistograms(histograms [][]int64) []int64 {
+	var sum []int64
+	for _, histogram := range histograms {
+		if sum == nil {
+			sum = make([]int64, len(histogram))
+		}
+		for i, count := range histogram {
+			sum[i] += count
+		}
+	}
+	return sum
+}
+
+func TestReaderPreallocation(t *testing.T) {
+	rows := testRows(100)
+	data := writeTestFile(t, rows, WithPageIndex(true), WithPageSize(128), WithRowGroupSize(40))
+
+	testcases := map[string][]ParquetReaderOption{
+		"whole chunks":   {WithPreallocation(true)},
+		"partial chunks": {WithPreallocation(true), WithFilter(Lt("id", 10))},
+		"projected":      {WithPreallocation(true), WithColumns("name", "tags.key")},
+	}
+
+	for name, opts := range testcases {
+		t.Run(name, func(t *testing.T) {
+			expected := readRows(t, data, opts[1:]...)
+			if got := readRows(t, data, opts...); !reflect.DeepEqual(got, expected) {
+				t.Errorf("expected %v, got %v", expected, got)
+			}
+		})
+	}
+}
+
+func readRows(t *testing.T, data []byte, opts ...ParquetReaderOption) []Row {
+	t.Helper()
+	reader, err := Open(bytes.NewReader(data), int64(len(data)), opts...)
+	if err != nil {
+		t.Fatalf("unable to open file: %v", err)
+	}
+	defer reader.Close()
+	return readAllRows(t, reader)
+}
+
+func TestPreallocateColumnValues(t *testing.T) {
+	data := writeTestFile(t, testRows(30))
+
+	testcases := map[string]struct {
+		column                    int
+		forge                     func(meta *format.ColumnMetaData)
+		levels, repetition, count int
+		bytes                     int
+	}{
+		"required": {column: 0, levels: 30, count: 30},
+		"optional": {column: 1, levels: 30, count: 20, bytes: 20},
+		"repeated": {column: 3, levels: 45, repetition: 45, count: 30, bytes: 30},
+		"more values than the chunk holds": {
+			column: 0,
+			forge:  func(meta *format.ColumnMetaData) { meta.NumValues = 1 << 40 },
+		},
+		"huge chunk": {
+			column: 0,
+			forge: func(meta *format.ColumnMetaData) {
+				meta.NumValues, meta.TotalUncompressedSize = 1<<40, 1<<40
+			},
+			levels: maxPreallocatedValues, count: maxPreallocatedValues,
+		},
+		"huge byte arrays": {
+			column: 1,
+			forge: func(meta *format.ColumnMetaData) {
+				*meta.SizeStatistics.UnencodedByteArrayDataBytes = 1 << 40
+			},
+			levels: 30, count: 20, bytes: maxPreallocatedBytes,
+		},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			reader, err := Open(bytes.NewReader(data), int64(len(data)))
+			if err != nil {
+				t.Fatalf("unable to open file: %v", err)
+			}
+			defer reader.Close()
+
+			chunk := reader.GetMeta().RowGroup(0).Column(test.column)
+			if test.forge != nil {
+				test.forge(chunk.Format().MetaData)
+			}
+			values := &columnValues{}
+			preallocateColumnValues(chunk, values)
+			if cap(values.definitionLevels) != test.levels || cap(values.repetitionLevels) != test.repetition {
+				t.Errorf("expected %d levels, got %d and %d", test.levels, cap(values.definitionLevels), cap(values.repetitionLevels))
+			}
+			if cap(values.values) != test.count {
+				t.Errorf("expected %d values, got %d", test.count, cap(values.values))
+			}
+			if cap(values.data) != test.bytes {
+				t.Errorf("expected %d bytes, got %d", test.bytes, cap(values.data))
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package parquet
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+// structField is an exported field of a struct and the options of its
+// parquet tag, such as `parquet:"embedding,float16"`. The name defaults to
+// the name of the field, and fields tagged "-" are skipped.
+type structField struct {
+	index   int
+	name    string
+	float16 bool
+}
+
+var structFieldCache sync.Map
+
+// structFields returns the fields of a struct type that are written.
+func structFields(typ reflect.Type) ([]structField, error) {
+	if cached, ok := structFieldCache.Load(typ); ok {
+		return cached.([]structField), nil
+	}
+	var fields []structField
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		tag := field.Tag.Get("parquet")
+		if !field.IsExported() || tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		f := structField{index: i, name: name}
+		for option := range strings.SplitSeq(options, ",") {
+			switch option {
+			case "":
+			case "float16":
+				f.float16 = true
+			default:
+				return nil, fmt.Errorf("field %s of %v has unknown parquet tag option %q", field.Name, typ, option)
+			}
+		}
+		fields = append(fields, f)
+	}
+	structFieldCache.Store(typ, fields)
+	return fields, nil
+}
+
+// SchemaOf derives a schema from the fields of a struct and their parquet
+// tags. Pointers are optional fields and slices repeated ones, except for
+// []byte, which is BYTE_ARRAY like string. Nested structs are groups, and
+// float32 and float64 fields tagged float16 are FLOAT16.
+func SchemaOf(model any) (*schema.SchemaElement, error) {
+	typ := reflect.TypeOf(model)
+	if typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: schema of %T, which is not a struct", schema.ErrInvalidSchema, model)
+	}
+	children, err := structChildren(typ)
+	if err != nil {
+		return nil, err
+	}
+	return schema.NewSchema(children...), nil
+}
+
+func structChildren(typ reflect.Type) ([]*schema.SchemaElement, error) {
+	fields, err := structFields(typ)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", schema.ErrInvalidSchema, err)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("%w: %v has no exported fields", schema.ErrInvalidSchema, typ)
+	}
+	children := make([]*schema.SchemaElement, len(fields))
+	for i, field := range fields {
+		if children[i], err = structNode(field, typ.Field(field.index).Type); err != nil {
+			return nil, err
+		}
+	}
+	return children, nil
+}
+
+func structNode(field structField, typ reflect.Type) (*schema.SchemaElement, error) {
+	repetition := schema.Required
+	switch {
+	case typ.Kind() == reflect.Pointer:
+		repetition, typ = schema.Optional, typ.Elem()
+	case typ.Kind() == reflect.Slice && typ.Elem().Kind() != reflect.Uint8:
+		repetition, typ = schema.Repeated, typ.Elem()
+	}
+	if typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Slice && typ.Elem().Kind() != reflect.Uint8 {
+		return nil, fmt.Errorf("%w: field %q of type %v nests pointers or slices", schema.ErrInvalidSchema, field.name, typ)
+	}
+	if typ.Kind() == reflect.Struct {
+		children, err := structChildren(typ)
+		if err != nil {
+			return nil, err
+		}
+		return schema.NewGroup(field.name, repetition, children...), nil
+	}
+
+	leaf, err := structLeaf(field.name, typ, repetition)
+	if err != nil {
+		return nil, err
+	}
+	if field.float16 {
+		if leaf.Type != schema.Float && leaf.Type != schema.Double {
+			return nil, fmt.Errorf("%w: field %q of type %v cannot be FLOAT16", schema.ErrInvalidSchema, field.name, typ)
+		}
+		leaf.Type, leaf.TypeLength = schema.FixedLenByteArray, 2
+		leaf.LogicalType = &schema.LogicalType{Kind: schema.LogicalFloat16}
+	}
+	return leaf, nil
+}
+
+func structLeaf(name string, typ reflect.Type, repetition schema.Repetition) (*schema.SchemaElement, error) {
+	integer := func(typ schema.Type, bitWidth int8, signed bool) *schema.SchemaElement {
+		leaf := schema.NewLeaf(name, typ, repetition)
+		if bitWidth < 32 || !signed {
+			leaf.LogicalType = &schema.LogicalType{Kind: schema.LogicalInteger, BitWidth: bitWidth, IsSigned: signed}
+		}
+		return leaf
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		return schema.NewLeaf(name, schema.Boolean, repetition), nil
+	case reflect.Int8:
+		return integer(schema.Int32, 8, true), nil
+	case reflect.Int16:
+		return integer(schema.Int32, 16, true), nil
+	case reflect.Int32:
+		return integer(schema.Int32, 32, true), nil
+	case reflect.Int, reflect.Int64:
+		return integer(schema.Int64, 64, true), nil
+	case reflect.Uint8:
+		return integer(schema.Int32, 8, false), nil
+	case reflect.Uint16:
+		return integer(schema.Int32, 16, false), nil
+	case reflect.Uint32:
+		return integer(schema.Int32, 32, false), nil
+	case reflect.Uint64:
+		return integer(schema.Int64, 64, false), nil
+	case reflect.Float32:
+		return schema.NewLeaf(name, schema.Float, repetition), nil
+	case reflect.Float64:
+		return schema.NewLeaf(name, schema.Double, repetition), nil
+	case reflect.String:
+		leaf := schema.NewLeaf(name, schema.ByteArray, repetition)
+		leaf.LogicalType = &schema.LogicalType{Kind: schema.LogicalString}
+		return leaf, nil
+	case reflect.Slice:
+		return schema.NewLeaf(name, schema.ByteArray, repetition), nil
+	case reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 && typ.Len() > 0 {
+			leaf := schema.NewLeaf(name, schema.FixedLenByteArray, repetition)
+			leaf.TypeLength = int32(typ.Len())
+			return leaf, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: field %q has unsupported type %v", schema.ErrInvalidSchema, name, typ)
+}
+
+// structGroup returns the values of the fields of a struct, or of a
+// non-nil pointer to one, keyed by their parquet names. Nil pointers are
+// null values.
+func structGroup(value any) (map[string]any, error) {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, errNotStruct
+	}
+	fields, err := structFields(rv.Type())
+	if err != nil {
+		return nil, err
+	}
+	group := make(map[string]any, len(fields))
+	for _, field := range fields {
+		fieldValue := rv.Field(field.index)
+		if fieldValue.Kind() == reflect.Pointer {
+			if fieldValue.IsNil() {
+				group[field.name] = nil
+				continue
+			}
+			fieldValue = fieldValue.Elem()
+		}
+		group[field.name] = fieldValue.Interface()
+	}
+	return group, nil
+}
+
+var errNotStruct = errors.New("not a struct")
+
+// WriteStruct writes a struct as a row, reading its fields like SchemaOf
+// describes them.
+func (w *ParquetWriter) WriteStruct(model any) error {
+	group, err := structGroup(model)
+	if errors.Is(err, errNotStruct) {
+		return fmt.Errorf("cannot write %T, which is not a struct", model)
+	}
+	if err != nil {
+		return err
+	}
+	return w.Write(Row(group))
+}
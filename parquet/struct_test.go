@@ -0,0 +1,219 @@
+package parquet
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+type featureLocation struct {
+	Shard  int32
+	Offset *int64 `parquet:"offset"`
+}
+
+type feature struct {
+	ID        int64     `parquet:"id"`
+	Name      string    `parquet:"name"`
+	Score     float32   `parquet:"score,float16"`
+	Embedding []float32 `parquet:"embedding,float16"`
+	Location  *featureLocation
+	Checksum  [4]byte `parquet:"checksum"`
+	internal  int
+	Ignored   string `parquet:"-"`
+}
+
+func TestSchemaOf(t *testing.T) {
+	root, err := SchemaOf(&feature{})
+	if err != nil {
+		t.Fatalf("unable to derive schema: %v", err)
+	}
+
+	expected := map[string]struct {
+		typ        schema.Type
+		repetition schema.Repetition
+		length     int32
+		kind       schema.LogicalKind
+	}{
+		"id":        {typ: schema.Int64, repetition: schema.Required},
+		"name":      {typ: schema.ByteArray, repetition: schema.Required, kind: schema.LogicalString},
+		"score":     {typ: schema.FixedLenByteArray, repetition: schema.Required, length: 2, kind: schema.LogicalFloat16},
+		"embedding": {typ: schema.FixedLenByteArray, repetition: schema.Repeated, length: 2, kind: schema.LogicalFloat16},
+		"checksum":  {typ: schema.FixedLenByteArray, repetition: schema.Required, length: 4},
+	}
+	if len(root.Children) != 6 {
+		t.Fatalf("expected 6 fields, got %d", len(root.Children))
+	}
+	for _, child := range root.Children {
+		if child.Name == "Location" {
+			if child.Repetition != schema.Optional || len(child.Children) != 2 || child.Children[1].Name != "offset" || child.Children[1].Repetition != schema.Optional {
+				t.Errorf("expected an optional Location group with an optional offset, got %+v", child)
+			}
+			continue
+		}
+		want, ok := expected[child.Name]
+		if !ok {
+			t.Errorf("unexpected field %q", child.Name)
+			continue
+		}
+		var kind schema.LogicalKind
+		if child.LogicalType != nil {
+			kind = child.LogicalType.Kind
+		}
+		if child.Type != want.typ || child.Repetition != want.repetition || child.TypeLength != want.length || kind != want.kind {
+			t.Errorf("field %q: expected %+v, got %v %v length %d logical %v", child.Name, want, child.Type, child.Repetition, child.TypeLength, kind)
+		}
+	}
+}
+
+func TestSchemaOfErrors(t *testing.T) {
+	testcases := map[string]any{
+		"not a struct":  42,
+		"no fields":     struct{ hidden int }{},
+		"nested slices": struct{ Values [][]int32 }{},
+		"unsupported":   struct{ Values map[string]int }{},
+		"float16 string": struct {
+			Name string `parquet:"name,float16"`
+		}{},
+		"unknown option": struct {
+			Name string `parquet:"name,zstd"`
+		}{},
+	}
+
+	for name, model := range testcases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := SchemaOf(model); err == nil {
+				t.Errorf("expected an error for %T", model)
+			}
+		})
+	}
+}
+
+// writeFeatures writes 30 features in row groups of 10, where the scores of
+// row group g range from g to g+0.9 and every seventh score is NaN.
+func writeFeatures(t *testing.T) []byte {
+	t.Helper()
+	root, err := SchemaOf(feature{})
+	if err != nil {
+		t.Fatalf("unable to derive schema: %v", err)
+	}
+	var buffer bytes.Buffer
+	writer, err := NewWriter(&buffer, root, WithRowGroupSize(10), WithPageIndex(true))
+	if err != nil {
+		t.Fatalf("unable to create writer: %v", err)
+	}
+	for i := range 30 {
+		offset := int64(i * 100)
+		f := feature{
+			ID:        int64(i),
+			Name:      "feature",
+			Score:     float32(i/10) + float32(i%10)/10,
+			Embedding: []float32{float32(i), -0.5, 1.0 / 3},
+			Location:  &featureLocation{Shard: int32(i % 3), Offset: &offset},
+			Checksum:  [4]byte{byte(i)},
+		}
+		if i%7 == 6 {
+			f.Score = float32(math.NaN())
+		}
+		if i == 0 {
+			f.Location = nil
+		}
+		if err := writer.WriteStruct(f); err != nil {
+			t.Fatalf("unable to write feature: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("unable to close writer: %v", err)
+	}
+	return buffer.Bytes()
+}
+
+func TestWriteStruct(t *testing.T) {
+	data := writeFeatures(t)
+	reader, err := Open(bytes.NewReader(data), int64(len(data)), WithLogicalTypes(true))
+	if err != nil {
+		t.Fatalf("unable to open file: %v", err)
+	}
+	defer reader.Close()
+
+	rows := readAllRows(t, reader)
+	if len(rows) != 30 {
+		t.Fatalf("expected 30 rows, got %d", len(rows))
+	}
+	// 1/3 is not a half precision float and rounds to the nearest one.
+	expected := []any{float32(12), float32(-0.5), float32(0.33325195)}
+	if !reflect.DeepEqual(rows[12]["embedding"], expected) {
+		t.Errorf("expected embedding %v, got %v", expected, rows[12]["embedding"])
+	}
+	if score := rows[12]["score"]; score != float32(1.2001953) {
+		t.Errorf("expected score 1.2001953, got %v", score)
+	}
+	if score, ok := rows[6]["score"].(float32); !ok || !math.IsNaN(float64(score)) {
+		t.Errorf("expected NaN score, got %v", rows[6]["score"])
+	}
+	if rows[0]["Location"] != nil {
+		t.Errorf("expected null location, got %v", rows[0]["Location"])
+	}
+	location, _ := rows[5]["Location"].(map[string]any)
+	if location == nil || location["Shard"] != int32(2) || location["offset"] != int64(500) {
+		t.Errorf("expected location {2 500}, got %v", rows[5]["Location"])
+	}
+
+	if err := (&ParquetWriter{}).WriteStruct(42); err == nil {
+		t.Errorf("expected an error writing an int")
+	}
+}
+
+func TestFilterFloat16(t *testing.T) {
+	data := writeFeatures(t)
+
+	testcases := map[string]struct {
+		filter Predicate
+		pruned int
+		rows   int
+	}{
+		"less than":      {filter: Lt("score", float32(1)), pruned: 2, rows: 9},
+		"greater than":   {filter: GtEq("score", 2.5), pruned: 2, rows: 4},
+		"equal":          {filter: Eq("score", float32(0.5)), pruned: 2, rows: 1},
+		"nowhere":        {filter: Gt("score", float32(100)), pruned: 3, rows: 0},
+		"negative":       {filter: Lt("score", float32(-1)), pruned: 3, rows: 0},
+		"not equal":      {filter: NotEq("score", float32(0.5)), pruned: 0, rows: 29},
+		"negated bounds": {filter: Not(Lt("score", float32(2))), pruned: 2, rows: 8},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			reader, err := Open(bytes.NewReader(data), int64(len(data)), WithFilter(test.filter))
+			if err != nil {
+				t.Fatalf("unable to open file: %v", err)
+			}
+			defer reader.Close()
+
+			if reader.PrunedRowGroups() != test.pruned {
+				t.Errorf("expected %d pruned row groups, got %d", test.pruned, reader.PrunedRowGroups())
+			}
+			if rows := readAllRows(t, reader); len(rows) != test.rows {
+				t.Errorf("expected %d rows, got %d", test.rows, len(rows))
+			}
+		})
+	}
+}
+
+func TestWriterFloat16Statistics(t *testing.T) {
+	data := writeFeatures(t)
+	reader, err := Open(bytes.NewReader(data), int64(len(data)), WithLogicalTypes(true))
+	if err != nil {
+		t.Fatalf("unable to open file: %v", err)
+	}
+	defer reader.Close()
+
+	statistics := reader.GetMeta().RowGroup(0).Column(2).Statistics()
+	if !statistics.HasMinMax {
+		t.Fatalf("expected min and max for scores")
+	}
+	if !bytes.Equal(statistics.Min.([]byte), []byte{0x00, 0x80}) || !bytes.Equal(statistics.Max.([]byte), []byte{0x33, 0x3b}) {
+		t.Errorf("expected min -0 and max 0.9, got %x and %x", statistics.Min, statistics.Max)
+	}
+}
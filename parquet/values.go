@@ -0,0 +1,158 @@
+package parquet
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/RichardNooooh/parquet-go/internal/float16"
+	"github.com/RichardNooooh/parquet-go/internal/stats"
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+// toPhysical converts a Go value supplied by the caller into the Go type used
+// for the leaf's physical type.
+func toPhysical(leaf *schema.SchemaElement, value any) (any, error) {
+	switch leaf.Type {
+	case schema.Boolean:
+		if v, ok := value.(bool); ok {
+			return v, nil
+		}
+	case schema.Int32:
+		if v, ok, err := toInteger(leaf, value); ok {
+			if err != nil {
+				return nil, err
+			}
+			return int32(v), nil
+		}
+	case schema.Int64:
+		if v, ok, err := toInteger(leaf, value); ok {
+			if err != nil {
+				return nil, err
+			}
+			return v, nil
+		}
+	case schema.Int96:
+		if v, ok := value.([12]byte); ok {
+			return v, nil
+		}
+	case schema.Float:
+		switch v := value.(type) {
+		case float32:
+			return v, nil
+		case float64:
+			return float32(v), nil
+		}
+	case schema.Double:
+		switch v := value.(type) {
+		case float32:
+			return float64(v), nil
+		case float64:
+			return v, nil
+		}
+	case schema.ByteArray:
+		if v, ok := toBytes(value); ok {
+			return v, nil
+		}
+	case schema.FixedLenByteArray:
+		if stats.IsFloat16(leaf) {
+			switch v := value.(type) {
+			case float32:
+				return binary.LittleEndian.AppendUint16(nil, float16.FromFloat32(v)), nil
+			case float64:
+				return binary.LittleEndian.AppendUint16(nil, float16.FromFloat64(v)), nil
+			}
+		}
+		if v, ok := toBytes(value); ok {
+			if len(v) != int(leaf.TypeLength) {
+				return nil, fmt.Errorf("field %q expects %d bytes, got %d", leaf.Name, leaf.TypeLength, len(v))
+			}
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("field %q of type %v cannot hold %T", leaf.Name, leaf.Type, value)
+}
+
+// toInteger converts an integer value for an INT32 or INT64 leaf, checking it
+// against the range of the leaf's integer type. Unsigned values above the
+// signed maximum are stored as their two's complement bits.
+func toInteger(leaf *schema.SchemaElement, value any) (int64, bool, error) {
+	minValue, maxValue := integerRange(leaf)
+	var inRange bool
+	v, ok := toInt64(value)
+	if ok {
+		inRange = v >= minValue && (v < 0 || uint64(v) <= maxValue)
+	} else if u, ok := value.(uint64); ok {
+		v, inRange = int64(u), u <= maxValue
+	} else {
+		return 0, false, nil
+	}
+	if !inRange {
+		return 0, true, fmt.Errorf("field %q of type %v cannot hold %v, which is out of range [%d, %d]", leaf.Name, leaf.Type, value, minValue, maxValue)
+	}
+	return v, true, nil
+}
+
+// integerRange returns the smallest and largest values of the integer type of
+// an INT32 or INT64 leaf, which may be narrower than its physical type.
+func integerRange(leaf *schema.SchemaElement) (int64, uint64) {
+	bitWidth := 32
+	if leaf.Type == schema.Int64 {
+		bitWidth = 64
+	}
+	if leaf.LogicalType != nil && leaf.LogicalType.Kind == schema.LogicalInteger {
+		if width := int(leaf.LogicalType.BitWidth); width > 0 && width < bitWidth {
+			bitWidth = width
+		}
+	} else {
+		switch leaf.ConvertedType {
+		case schema.Int8, schema.Uint8:
+			bitWidth = 8
+		case schema.Int16, schema.Uint16:
+			bitWidth = 16
+		}
+	}
+	if stats.SortOrderOf(leaf) == stats.Unsigned {
+		return 0, math.MaxUint64 >> (64 - bitWidth)
+	}
+	return -1 << (bitWidth - 1), 1<<(bitWidth-1) - 1
+}
+
+func toInt64(value any) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	}
+	return 0, false
+}
+
+func toBytes(value any) ([]byte, bool) {
+	switch v := value.(type) {
+	case []byte:
+		return v, true
+	case string:
+		return []byte(v), true
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Array && rv.Type().Elem().Kind() == reflect.Uint8 {
+		bytes := make([]byte, rv.Len())
+		reflect.Copy(reflect.ValueOf(bytes), rv)
+		return bytes, true
+	}
+	return nil, false
+}
@@ -0,0 +1,104 @@
+package parquet
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+func TestToPhysicalIntegerRange(t *testing.T) {
+	integer := func(typ schema.Type, bitWidth int8, signed bool) *schema.SchemaElement {
+		leaf := schema.NewLeaf("i", typ, schema.Required)
+		leaf.LogicalType = &schema.LogicalType{Kind: schema.LogicalInteger, BitWidth: bitWidth, IsSigned: signed}
+		return leaf
+	}
+	converted := func(typ schema.Type, convertedType schema.ConvertedType) *schema.SchemaElement {
+		leaf := schema.NewLeaf("c", typ, schema.Required)
+		leaf.ConvertedType = convertedType
+		return leaf
+	}
+	signed := schema.NewLeaf("i", schema.Int32, schema.Required)
+	signed64 := schema.NewLeaf("l", schema.Int64, schema.Required)
+
+	testcases := map[string]struct {
+		leaf     *schema.SchemaElement
+		value    any
+		expected any
+		invalid  bool
+	}{
+		"signed max":            {leaf: signed, value: int64(2147483647), expected: int32(2147483647)},
+		"signed min":            {leaf: signed, value: -2147483648, expected: int32(-2147483648)},
+		"signed overflow":       {leaf: signed, value: int64(3_000_000_000), invalid: true},
+		"signed uint32":         {leaf: signed, value: uint32(3_000_000_000), invalid: true},
+		"unsigned large":        {leaf: integer(schema.Int32, 32, false), value: int64(3_000_000_000), expected: int32(-1294967296)},
+		"unsigned max":          {leaf: integer(schema.Int32, 32, false), value: uint32(4294967295), expected: int32(-1)},
+		"unsigned negative":     {leaf: integer(schema.Int32, 32, false), value: -1, invalid: true},
+		"unsigned overflow":     {leaf: integer(schema.Int32, 32, false), value: int64(4294967296), invalid: true},
+		"converted uint32":      {leaf: converted(schema.Int32, schema.Uint32), value: int64(3_000_000_000), expected: int32(-1294967296)},
+		"converted negative":    {leaf: converted(schema.Int32, schema.Uint32), value: int32(-5), invalid: true},
+		"int8 max":              {leaf: integer(schema.Int32, 8, true), value: 127, expected: int32(127)},
+		"int8 min":              {leaf: integer(schema.Int32, 8, true), value: -128, expected: int32(-128)},
+		"int8 overflow":         {leaf: integer(schema.Int32, 8, true), value: int32(300), invalid: true},
+		"int8 underflow":        {leaf: integer(schema.Int32, 8, true), value: -129, invalid: true},
+		"uint8 max":             {leaf: integer(schema.Int32, 8, false), value: uint8(255), expected: int32(255)},
+		"uint8 overflow":        {leaf: integer(schema.Int32, 8, false), value: 256, invalid: true},
+		"int16 overflow":        {leaf: integer(schema.Int32, 16, true), value: 32768, invalid: true},
+		"uint16 max":            {leaf: integer(schema.Int32, 16, false), value: 65535, expected: int32(65535)},
+		"converted int8":        {leaf: converted(schema.Int32, schema.Int8), value: 128, invalid: true},
+		"converted uint16":      {leaf: converted(schema.Int32, schema.Uint16), value: 65536, invalid: true},
+		"int64 min":             {leaf: signed64, value: int64(math.MinInt64), expected: int64(math.MinInt64)},
+		"int64 uint64":          {leaf: signed64, value: uint64(math.MaxInt64), expected: int64(math.MaxInt64)},
+		"int64 uint64 overflow": {leaf: signed64, value: uint64(math.MaxInt64 + 1), invalid: true},
+		"uint64 max":            {leaf: integer(schema.Int64, 64, false), value: uint64(math.MaxUint64), expected: int64(-1)},
+		"uint64 negative":       {leaf: integer(schema.Int64, 64, false), value: int64(-1), invalid: true},
+		"converted uint64":      {leaf: converted(schema.Int64, schema.Uint64), value: -1, invalid: true},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			value, err := toPhysical(test.leaf, test.value)
+			if test.invalid {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unable to convert %v: %v", test.value, err)
+			}
+			if value != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, value)
+			}
+		})
+	}
+}
+
+func TestToPhysicalFloat16(t *testing.T) {
+	leaf := schema.NewLeaf("f", schema.FixedLenByteArray, schema.Required)
+	leaf.TypeLength = 2
+	leaf.LogicalType = &schema.LogicalType{Kind: schema.LogicalFloat16}
+
+	testcases := map[string]struct {
+		value    any
+		expected []byte
+	}{
+		"float32": {value: float32(1.5), expected: []byte{0x00, 0x3e}},
+		"float64": {value: 1.5, expected: []byte{0x00, 0x3e}},
+		// Rounded to a float32 first, the double would tie and round down.
+		"double rounding": {value: 1 + 1.0/2048 + math.Ldexp(1, -40), expected: []byte{0x01, 0x3c}},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			value, err := toPhysical(leaf, test.value)
+			if err != nil {
+				t.Fatalf("unable to convert %v: %v", test.value, err)
+			}
+			if !bytes.Equal(value.([]byte), test.expected) {
+				t.Errorf("expected %x, got %x", test.expected, value)
+			}
+		})
+	}
+}
@@ -0,0 +1,716 @@
+package parquet
+
+import (
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"slices"
+	"time"
+)
+
+// ErrInvalidVariant is returned for Variant values whose encoding is corrupt
+// or that cannot be encoded.
+var ErrInvalidVariant = errors.New("invalid variant")
+
+// VariantKind is the type of a Variant value.
+type VariantKind int
+
+const (
+	VariantNull VariantKind = iota
+	VariantBoolean
+	VariantInt8
+	VariantInt16
+	VariantInt32
+	VariantInt64
+	VariantFloat
+	VariantDouble
+	VariantDecimal4
+	VariantDecimal8
+	VariantDecimal16
+	VariantDate
+	VariantTime
+	VariantTimestamp
+	VariantTimestampNTZ
+	VariantTimestampNanos
+	VariantTimestampNanosNTZ
+	VariantBinary
+	VariantString
+	VariantUUID
+	VariantObject
+	VariantArray
+)
+
+var variantKindNames = [...]string{
+	"NULL", "BOOLEAN", "INT8", "INT16", "INT32", "INT64", "FLOAT", "DOUBLE",
+	"DECIMAL4", "DECIMAL8", "DECIMAL16", "DATE", "TIME", "TIMESTAMP",
+	"TIMESTAMP_NTZ", "TIMESTAMP_NANOS", "TIMESTAMP_NANOS_NTZ", "BINARY",
+	"STRING", "UUID", "OBJECT", "ARRAY",
+}
+
+func (k VariantKind) String() string {
+	if k >= 0 && int(k) < len(variantKindNames) {
+		return variantKindNames[k]
+	}
+	return fmt.Sprintf("VariantKind(%d)", int(k))
+}
+
+// Variant is a value of the VARIANT logical type: a primitive, an array or an
+// object whose type is only known at run time.
+type Variant struct {
+	Kind VariantKind
+	// Value holds the value of primitive kinds: bool, int8, int16, int32,
+	// int64, float32, float64, Decimal, time.Time in UTC for dates and
+	// timestamps, time.Duration for times, []byte, string and [16]byte for
+	// UUIDs. Timestamps without time zone hold their wall clock in UTC.
+	Value any
+	// Elements holds the elements of an array.
+	Elements []Variant
+	// Fields holds the fields of an object, sorted by name.
+	Fields []VariantField
+}
+
+type VariantField struct {
+	Name  string
+	Value Variant
+}
+
+// NewVariant returns the Variant of a Go value. It takes the types of
+// Variant.Value, int, []any and map[string]any, as well as Variant values.
+// Timestamps with sub-microsecond precision are TIMESTAMP_NANOS.
+func NewVariant(value any) (Variant, error) {
+	switch v := value.(type) {
+	case nil:
+		return Variant{Kind: VariantNull}, nil
+	case Variant:
+		return v, nil
+	case bool:
+		return Variant{Kind: VariantBoolean, Value: v}, nil
+	case int8:
+		return Variant{Kind: VariantInt8, Value: v}, nil
+	case int16:
+		return Variant{Kind: VariantInt16, Value: v}, nil
+	case int32:
+		return Variant{Kind: VariantInt32, Value: v}, nil
+	case int64:
+		return Variant{Kind: VariantInt64, Value: v}, nil
+	case int:
+		return Variant{Kind: VariantInt64, Value: int64(v)}, nil
+	case float32:
+		return Variant{Kind: VariantFloat, Value: v}, nil
+	case float64:
+		return Variant{Kind: VariantDouble, Value: v}, nil
+	case Decimal:
+		return Variant{Kind: decimalKind(v), Value: v}, nil
+	case time.Time:
+		if v.Nanosecond()%1000 != 0 {
+			return Variant{Kind: VariantTimestampNanos, Value: v.UTC()}, nil
+		}
+		return Variant{Kind: VariantTimestamp, Value: v.UTC()}, nil
+	case time.Duration:
+		return Variant{Kind: VariantTime, Value: v}, nil
+	case []byte:
+		return Variant{Kind: VariantBinary, Value: v}, nil
+	case string:
+		return Variant{Kind: VariantString, Value: v}, nil
+	case [16]byte:
+		return Variant{Kind: VariantUUID, Value: v}, nil
+	case []any:
+		elements := make([]Variant, len(v))
+		for i, element := range v {
+			var err error
+			if elements[i], err = NewVariant(element); err != nil {
+				return Variant{}, err
+			}
+		}
+		return Variant{Kind: VariantArray, Elements: elements}, nil
+	case map[string]any:
+		fields := make([]VariantField, 0, len(v))
+		for name, element := range v {
+			field, err := NewVariant(element)
+			if err != nil {
+				return Variant{}, err
+			}
+			fields = append(fields, VariantField{Name: name, Value: field})
+		}
+		slices.SortFunc(fields, compareFields)
+		return Variant{Kind: VariantObject, Fields: fields}, nil
+	}
+	return Variant{}, fmt.Errorf("%w: unsupported type %T", ErrInvalidVariant, value)
+}
+
+// decimalKind returns the smallest decimal kind that holds d.
+func decimalKind(d Decimal) VariantKind {
+	switch digits := len(new(big.Int).Abs(d.Unscaled).String()); {
+	case digits <= 9:
+		return VariantDecimal4
+	case digits <= 18:
+		return VariantDecimal8
+	}
+	return VariantDecimal16
+}
+
+// Field returns the value of the field of an object with the given name.
+func (v Variant) Field(name string) (Variant, bool) {
+	i, ok := slices.BinarySearchFunc(v.Fields, name, func(field VariantField, name string) int {
+		return cmp.Compare(field.Name, name)
+	})
+	if !ok {
+		return Variant{}, false
+	}
+	return v.Fields[i].Value, true
+}
+
+func compareFields(a, b VariantField) int { return cmp.Compare(a.Name, b.Name) }
+
+// Basic types and primitive type identifiers of the Variant binary encoding.
+const (
+	variantPrimitive = iota
+	variantShortString
+	variantObject
+	variantArray
+)
+
+const (
+	variantVersion    = 1
+	variantSortedFlag = 1 << 4
+	maxShortString    = 63
+	variantUUIDLength = 16
+)
+
+var variantPrimitiveKinds = [...]VariantKind{
+	0: VariantNull, 1: VariantBoolean, 2: VariantBoolean, 3: VariantInt8,
+	4: VariantInt16, 5: VariantInt32, 6: VariantInt64, 7: VariantDouble,
+	8: VariantDecimal4, 9: VariantDecimal8, 10: VariantDecimal16,
+	11: VariantDate, 12: VariantTimestamp, 13: VariantTimestampNTZ,
+	14: VariantFloat, 15: VariantBinary, 16: VariantString, 17: VariantTime,
+	18: VariantTimestampNanos, 19: VariantTimestampNanosNTZ, 20: VariantUUID,
+}
+
+// variantPrimitiveIDs is the inverse of variantPrimitiveKinds, with true as
+// the identifier of booleans.
+var variantPrimitiveIDs = func() map[VariantKind]byte {
+	ids := make(map[VariantKind]byte, len(variantPrimitiveKinds))
+	for id, kind := range variantPrimitiveKinds {
+		if _, ok := ids[kind]; !ok {
+			ids[kind] = byte(id)
+		}
+	}
+	return ids
+}()
+
+// DecodeVariant decodes a Variant from its metadata and value buffers.
+func DecodeVariant(metadata, value []byte) (Variant, error) {
+	keys, err := decodeVariantMetadata(metadata)
+	if err != nil {
+		return Variant{}, err
+	}
+	return decodeVariantValue(keys, value)
+}
+
+// decodeVariantMetadata returns the dictionary of field names of a Variant.
+func decodeVariantMetadata(b []byte) ([]string, error) {
+	if len(b) == 0 {
+		return nil, fmt.Errorf("%w: empty metadata", ErrInvalidVariant)
+	}
+	if version := b[0] & 0x0f; version != variantVersion {
+		return nil, fmt.Errorf("%w: unsupported metadata version %d", ErrInvalidVariant, version)
+	}
+	offsetSize := int(b[0]>>6) + 1
+	size, ok := readVariantUint(b, 1, offsetSize)
+	if !ok || size > (len(b)-1)/offsetSize {
+		return nil, fmt.Errorf("%w: truncated metadata", ErrInvalidVariant)
+	}
+	offsets := 1 + offsetSize
+	data := offsets + (size+1)*offsetSize
+	if data > len(b) {
+		return nil, fmt.Errorf("%w: truncated metadata", ErrInvalidVariant)
+	}
+	keys := make([]string, size)
+	start, _ := readVariantUint(b, offsets, offsetSize)
+	for i := range keys {
+		end, _ := readVariantUint(b, offsets+(i+1)*offsetSize, offsetSize)
+		if start > end || data+end > len(b) {
+			return nil, fmt.Errorf("%w: invalid metadata offset", ErrInvalidVariant)
+		}
+		keys[i] = string(b[data+start : data+end])
+		start = end
+	}
+	return keys, nil
+}
+
+func decodeVariantValue(keys []string, b []byte) (Variant, error) {
+	if len(b) == 0 {
+		return Variant{}, fmt.Errorf("%w: empty value", ErrInvalidVariant)
+	}
+	header := b[0] >> 2
+	switch b[0] & 3 {
+	case variantPrimitive:
+		return decodeVariantPrimitive(header, b[1:])
+	case variantShortString:
+		if int(header) >= len(b) {
+			return Variant{}, fmt.Errorf("%w: truncated string", ErrInvalidVariant)
+		}
+		return Variant{Kind: VariantString, Value: string(b[1 : 1+header])}, nil
+	case variantObject:
+		return decodeVariantObject(keys, header, b)
+	}
+	return decodeVariantArray(keys, header, b)
+}
+
+func decodeVariantPrimitive(id byte, b []byte) (Variant, error) {
+	if int(id) >= len(variantPrimitiveKinds) {
+		return Variant{}, fmt.Errorf("%w: unknown primitive type %d", ErrInvalidVariant, id)
+	}
+	kind := variantPrimitiveKinds[id]
+	truncated := fmt.Errorf("%w: truncated %v", ErrInvalidVariant, kind)
+	fixed := func(size int) bool { return len(b) >= size }
+	switch kind {
+	case VariantNull:
+		return Variant{Kind: kind}, nil
+	case VariantBoolean:
+		return Variant{Kind: kind, Value: id == 1}, nil
+	case VariantInt8:
+		if !fixed(1) {
+			return Variant{}, truncated
+		}
+		return Variant{Kind: kind, Value: int8(b[0])}, nil
+	case VariantInt16:
+		if !fixed(2) {
+			return Variant{}, truncated
+		}
+		return Variant{Kind: kind, Value: int16(binary.LittleEndian.Uint16(b))}, nil
+	case VariantInt32, VariantDate:
+		if !fixed(4) {
+			return Variant{}, truncated
+		}
+		v := int32(binary.LittleEndian.Uint32(b))
+		if kind == VariantDate {
+			return Variant{Kind: kind, Value: time.Date(1970, time.January, 1+int(v), 0, 0, 0, 0, time.UTC)}, nil
+		}
+		return Variant{Kind: kind, Value: v}, nil
+	case VariantFloat:
+		if !fixed(4) {
+			return Variant{}, truncated
+		}
+		return Variant{Kind: kind, Value: math.Float32frombits(binary.LittleEndian.Uint32(b))}, nil
+	case VariantInt64, VariantDouble, VariantTime, VariantTimestamp, VariantTimestampNTZ, VariantTimestampNanos, VariantTimestampNanosNTZ:
+		if !fixed(8) {
+			return Variant{}, truncated
+		}
+		v := int64(binary.LittleEndian.Uint64(b))
+		switch kind {
+		case VariantDouble:
+			return Variant{Kind: kind, Value: math.Float64frombits(uint64(v))}, nil
+		case VariantTime:
+			return Variant{Kind: kind, Value: time.Duration(v) * time.Microsecond}, nil
+		case VariantTimestamp, VariantTimestampNTZ:
+			return Variant{Kind: kind, Value: time.UnixMicro(v).UTC()}, nil
+		case VariantTimestampNanos, VariantTimestampNanosNTZ:
+			return Variant{Kind: kind, Value: time.Unix(0, v).UTC()}, nil
+		}
+		return Variant{Kind: kind, Value: v}, nil
+	case VariantDecimal4, VariantDecimal8, VariantDecimal16:
+		size := variantDecimalSize(kind)
+		if !fixed(1 + size) {
+			return Variant{}, truncated
+		}
+		unscaled := make([]byte, size)
+		for i := range size {
+			unscaled[i] = b[size-i]
+		}
+		return Variant{Kind: kind, Value: Decimal{bigEndianInt(unscaled), int32(b[0])}}, nil
+	case VariantBinary, VariantString:
+		if !fixed(4) {
+			return Variant{}, truncated
+		}
+		length := binary.LittleEndian.Uint32(b)
+		if uint64(length) > uint64(len(b)-4) {
+			return Variant{}, truncated
+		}
+		data := b[4 : 4+length]
+		if kind == VariantString {
+			return Variant{Kind: kind, Value: string(data)}, nil
+		}
+		return Variant{Kind: kind, Value: slices.Clone(data)}, nil
+	}
+	if !fixed(variantUUIDLength) {
+		return Variant{}, truncated
+	}
+	return Variant{Kind: kind, Value: [16]byte(b[:variantUUIDLength])}, nil
+}
+
+func decodeVariantObject(keys []string, header byte, b []byte) (Variant, error) {
+	offsetSize := int(header&3) + 1
+	idSize := int(header>>2&3) + 1
+	countSize := 1
+	if header>>4&1 == 1 {
+		countSize = 4
+	}
+	count, ok := readVariantUint(b, 1, countSize)
+	if !ok || count > len(b) {
+		return Variant{}, fmt.Errorf("%w: truncated object", ErrInvalidVariant)
+	}
+	ids := 1 + countSize
+	offsets := ids + count*idSize
+	values := offsets + (count+1)*offsetSize
+	if values > len(b) {
+		return Variant{}, fmt.Errorf("%w: truncated object", ErrInvalidVariant)
+	}
+	end, _ := readVariantUint(b, offsets+count*offsetSize, offsetSize)
+	if values+end > len(b) {
+		return Variant{}, fmt.Errorf("%w: truncated object", ErrInvalidVariant)
+	}
+	data := b[values : values+end]
+
+	var fields []VariantField
+	if count > 0 {
+		fields = make([]VariantField, count)
+	}
+	for i := range fields {
+		id, _ := readVariantUint(b, ids+i*idSize, idSize)
+		if id >= len(keys) {
+			return Variant{}, fmt.Errorf("%w: unknown field id %d", ErrInvalidVariant, id)
+		}
+		offset, _ := readVariantUint(b, offsets+i*offsetSize, offsetSize)
+		if offset >= len(data) {
+			return Variant{}, fmt.Errorf("%w: invalid field offset", ErrInvalidVariant)
+		}
+		value, err := decodeVariantValue(keys, data[offset:])
+		if err != nil {
+			return Variant{}, err
+		}
+		fields[i] = VariantField{Name: keys[id], Value: value}
+	}
+	if !slices.IsSortedFunc(fields, compareFields) {
+		slices.SortFunc(fields, compareFields)
+	}
+	return Variant{Kind: VariantObject, Fields: fields}, nil
+}
+
+func decodeVariantArray(keys []string, header byte, b []byte) (Variant, error) {
+	offsetSize := int(header&3) + 1
+	countSize := 1
+	if header>>2&1 == 1 {
+		countSize = 4
+	}
+	count, ok := readVariantUint(b, 1, countSize)
+	if !ok || count > len(b) {
+		return Variant{}, fmt.Errorf("%w: truncated array", ErrInvalidVariant)
+	}
+	offsets := 1 + countSize
+	values := offsets + (count+1)*offsetSize
+	if values > len(b) {
+		return Variant{}, fmt.Errorf("%w: truncated array", ErrInvalidVariant)
+	}
+
+	var elements []Variant
+	if count > 0 {
+		elements = make([]Variant, count)
+	}
+	start, _ := readVariantUint(b, offsets, offsetSize)
+	for i := range elements {
+		end, _ := readVariantUint(b, offsets+(i+1)*offsetSize, offsetSize)
+		if start >= end || values+end > len(b) {
+			return Variant{}, fmt.Errorf("%w: invalid element offset", ErrInvalidVariant)
+		}
+		element, err := decodeVariantValue(keys, b[values+start:values+end])
+		if err != nil {
+			return Variant{}, err
+		}
+		elements[i] = element
+		start = end
+	}
+	return Variant{Kind: VariantArray, Elements: elements}, nil
+}
+
+// readVariantUint reads a little-endian unsigned integer of size bytes at
+// offset.
+func readVariantUint(b []byte, offset, size int) (int, bool) {
+	if offset < 0 || offset+size > len(b) {
+		return 0, false
+	}
+	var value int
+	for i := size - 1; i >= 0; i-- {
+		value = value<<8 | int(b[offset+i])
+	}
+	return value, true
+}
+
+// Encode returns the metadata and value buffers of the Variant. The metadata
+// holds the sorted names of every field of the value.
+func (v Variant) Encode() (metadata, value []byte, err error) {
+	names := make(map[string]struct{})
+	v.collectNames(names)
+	keys := make([]string, 0, len(names))
+	for name := range names {
+		keys = append(keys, name)
+	}
+	slices.Sort(keys)
+	ids := make(map[string]int, len(keys))
+	for i, key := range keys {
+		ids[key] = i
+	}
+
+	if value, err = v.appendValue(nil, ids); err != nil {
+		return nil, nil, err
+	}
+	return encodeVariantMetadata(keys), value, nil
+}
+
+func (v Variant) collectNames(names map[string]struct{}) {
+	for _, field := range v.Fields {
+		names[field.Name] = struct{}{}
+		field.Value.collectNames(names)
+	}
+	for _, element := range v.Elements {
+		element.collectNames(names)
+	}
+}
+
+func encodeVariantMetadata(keys []string) []byte {
+	var total int
+	for _, key := range keys {
+		total += len(key)
+	}
+	offsetSize := variantUintSize(max(total, len(keys)))
+	b := []byte{variantVersion | variantSortedFlag | byte(offsetSize-1)<<6}
+	b = appendVariantUint(b, len(keys), offsetSize)
+	offset := 0
+	b = appendVariantUint(b, offset, offsetSize)
+	for _, key := range keys {
+		offset += len(key)
+		b = appendVariantUint(b, offset, offsetSize)
+	}
+	for _, key := range keys {
+		b = append(b, key...)
+	}
+	return b
+}
+
+func (v Variant) appendValue(b []byte, ids map[string]int) ([]byte, error) {
+	switch v.Kind {
+	case VariantObject:
+		return v.appendObject(b, ids)
+	case VariantArray:
+		return v.appendArray(b, ids)
+	case VariantString:
+		if s, ok := v.Value.(string); ok && len(s) <= maxShortString {
+			b = append(b, byte(len(s))<<2|variantShortString)
+			return append(b, s...), nil
+		}
+	}
+
+	id, ok := variantPrimitiveIDs[v.Kind]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown kind %v", ErrInvalidVariant, v.Kind)
+	}
+	mismatch := fmt.Errorf("%w: %v value cannot hold %T", ErrInvalidVariant, v.Kind, v.Value)
+	if v.Kind == VariantNull {
+		return append(b, id<<2), nil
+	}
+	if v.Kind == VariantBoolean {
+		value, ok := v.Value.(bool)
+		if !ok {
+			return nil, mismatch
+		}
+		if !value {
+			id++
+		}
+		return append(b, id<<2), nil
+	}
+
+	b = append(b, id<<2)
+	switch value := v.Value.(type) {
+	case int8:
+		if v.Kind == VariantInt8 {
+			return append(b, byte(value)), nil
+		}
+	case int16:
+		if v.Kind == VariantInt16 {
+			return binary.LittleEndian.AppendUint16(b, uint16(value)), nil
+		}
+	case int32:
+		if v.Kind == VariantInt32 {
+			return binary.LittleEndian.AppendUint32(b, uint32(value)), nil
+		}
+	case int64:
+		if v.Kind == VariantInt64 {
+			return binary.LittleEndian.AppendUint64(b, uint64(value)), nil
+		}
+	case float32:
+		if v.Kind == VariantFloat {
+			return binary.LittleEndian.AppendUint32(b, math.Float32bits(value)), nil
+		}
+	case float64:
+		if v.Kind == VariantDouble {
+			return binary.LittleEndian.AppendUint64(b, math.Float64bits(value)), nil
+		}
+	case Decimal:
+		return appendVariantDecimal(b, v.Kind, value)
+	case time.Time:
+		return appendVariantTime(b, v.Kind, value)
+	case time.Duration:
+		if v.Kind == VariantTime {
+			return binary.LittleEndian.AppendUint64(b, uint64(value/time.Microsecond)), nil
+		}
+	case []byte:
+		if v.Kind == VariantBinary {
+			b = binary.LittleEndian.AppendUint32(b, uint32(len(value)))
+			return append(b, value...), nil
+		}
+	case string:
+		if v.Kind == VariantString {
+			b = binary.LittleEndian.AppendUint32(b, uint32(len(value)))
+			return append(b, value...), nil
+		}
+	case [16]byte:
+		if v.Kind == VariantUUID {
+			return append(b, value[:]...), nil
+		}
+	}
+	return nil, mismatch
+}
+
+func appendVariantDecimal(b []byte, kind VariantKind, d Decimal) ([]byte, error) {
+	size := variantDecimalSize(kind)
+	if size == 0 || d.Unscaled == nil {
+		return nil, fmt.Errorf("%w: %v value cannot hold a decimal", ErrInvalidVariant, kind)
+	}
+	if d.Scale < 0 || d.Scale > 38 {
+		return nil, fmt.Errorf("%w: decimal scale %d out of range", ErrInvalidVariant, d.Scale)
+	}
+	magnitude := d.Unscaled
+	if magnitude.Sign() < 0 {
+		magnitude = new(big.Int).Not(magnitude)
+	}
+	if magnitude.BitLen() >= size*8 {
+		return nil, fmt.Errorf("%w: decimal %v does not fit in %v", ErrInvalidVariant, d, kind)
+	}
+	b = append(b, byte(d.Scale))
+	// Two's complement in little-endian order.
+	unscaled := new(big.Int).Set(d.Unscaled)
+	if unscaled.Sign() < 0 {
+		unscaled.Add(unscaled, new(big.Int).Lsh(big.NewInt(1), uint(size*8)))
+	}
+	bigEndian := unscaled.FillBytes(make([]byte, size))
+	for i := size - 1; i >= 0; i-- {
+		b = append(b, bigEndian[i])
+	}
+	return b, nil
+}
+
+// variantDecimalSize returns the size of the unscaled value of a decimal
+// kind, or 0 for other kinds.
+func variantDecimalSize(kind VariantKind) int {
+	switch kind {
+	case VariantDecimal4:
+		return 4
+	case VariantDecimal8:
+		return 8
+	case VariantDecimal16:
+		return 16
+	}
+	return 0
+}
+
+func appendVariantTime(b []byte, kind VariantKind, t time.Time) ([]byte, error) {
+	switch kind {
+	case VariantDate:
+		days := t.Unix() / secondsPerDay
+		if t.Unix() < 0 && t.Unix()%secondsPerDay != 0 {
+			days--
+		}
+		return binary.LittleEndian.AppendUint32(b, uint32(int32(days))), nil
+	case VariantTimestamp, VariantTimestampNTZ:
+		return binary.LittleEndian.AppendUint64(b, uint64(t.UnixMicro())), nil
+	case VariantTimestampNanos, VariantTimestampNanosNTZ:
+		return binary.LittleEndian.AppendUint64(b, uint64(t.UnixNano())), nil
+	}
+	return nil, fmt.Errorf("%w: %v value cannot hold a time.Time", ErrInvalidVariant, kind)
+}
+
+func (v Variant) appendObject(b []byte, ids map[string]int) ([]byte, error) {
+	fields := v.Fields
+	if !slices.IsSortedFunc(fields, compareFields) {
+		fields = slices.SortedFunc(slices.Values(fields), compareFields)
+	}
+	var data []byte
+	offsets := make([]int, 0, len(fields)+1)
+	maxID := 0
+	for i, field := range fields {
+		if i > 0 && fields[i-1].Name == field.Name {
+			return nil, fmt.Errorf("%w: duplicate field %q", ErrInvalidVariant, field.Name)
+		}
+		maxID = max(maxID, ids[field.Name])
+		offsets = append(offsets, len(data))
+		var err error
+		if data, err = field.Value.appendValue(data, ids); err != nil {
+			return nil, err
+		}
+	}
+	offsets = append(offsets, len(data))
+
+	offsetSize, idSize := variantUintSize(len(data)), variantUintSize(maxID)
+	countSize, large := 1, byte(0)
+	if len(fields) > 0xff {
+		countSize, large = 4, 1
+	}
+	header := byte(offsetSize-1) | byte(idSize-1)<<2 | large<<4
+	b = append(b, header<<2|variantObject)
+	b = appendVariantUint(b, len(fields), countSize)
+	for _, field := range fields {
+		b = appendVariantUint(b, ids[field.Name], idSize)
+	}
+	for _, offset := range offsets {
+		b = appendVariantUint(b, offset, offsetSize)
+	}
+	return append(b, data...), nil
+}
+
+func (v Variant) appendArray(b []byte, ids map[string]int) ([]byte, error) {
+	var data []byte
+	offsets := make([]int, 0, len(v.Elements)+1)
+	for _, element := range v.Elements {
+		offsets = append(offsets, len(data))
+		var err error
+		if data, err = element.appendValue(data, ids); err != nil {
+			return nil, err
+		}
+	}
+	offsets = append(offsets, len(data))
+
+	offsetSize := variantUintSize(len(data))
+	countSize, large := 1, byte(0)
+	if len(v.Elements) > 0xff {
+		countSize, large = 4, 1
+	}
+	b = append(b, (byte(offsetSize-1)|large<<2)<<2|variantArray)
+	b = appendVariantUint(b, len(v.Elements), countSize)
+	for _, offset := range offsets {
+		b = appendVariantUint(b, offset, offsetSize)
+	}
+	return append(b, data...), nil
+}
+
+// variantUintSize returns the number of bytes needed to hold n.
+func variantUintSize(n int) int {
+	switch {
+	case n <= 0xff:
+		return 1
+	case n <= 0xffff:
+		return 2
+	case n <= 0xffffff:
+		return 3
+	}
+	return 4
+}
+
+func appendVariantUint(b []byte, value, size int) []byte {
+	for i := range size {
+		b = append(b, byte(value>>(8*i)))
+	}
+	return b
+}
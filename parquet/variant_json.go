@@ -0,0 +1,221 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxVariantDecimalDigits is the precision of DECIMAL16 values.
+const maxVariantDecimalDigits = 38
+
+// MarshalJSON encodes the Variant as JSON. Decimals are numbers, binary
+// values base64 strings, and dates, times, timestamps and UUIDs strings in
+// their ISO 8601 and canonical forms. NaN and infinities are strings.
+func (v Variant) MarshalJSON() ([]byte, error) {
+	return v.appendJSON(nil)
+}
+
+func (v Variant) appendJSON(b []byte) ([]byte, error) {
+	switch v.Kind {
+	case VariantObject:
+		b = append(b, '{')
+		for i, field := range v.Fields {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = strconv.AppendQuote(b, field.Name)
+			b = append(b, ':')
+			var err error
+			if b, err = field.Value.appendJSON(b); err != nil {
+				return nil, err
+			}
+		}
+		return append(b, '}'), nil
+	case VariantArray:
+		b = append(b, '[')
+		for i, element := range v.Elements {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			var err error
+			if b, err = element.appendJSON(b); err != nil {
+				return nil, err
+			}
+		}
+		return append(b, ']'), nil
+	}
+
+	switch value := v.Value.(type) {
+	case nil:
+		return append(b, "null"...), nil
+	case float32:
+		return appendJSONFloat(b, float64(value), 32), nil
+	case float64:
+		return appendJSONFloat(b, value, 64), nil
+	case Decimal:
+		return append(b, value.String()...), nil
+	case time.Time:
+		return strconv.AppendQuote(b, formatVariantTime(v.Kind, value)), nil
+	case time.Duration:
+		midnight := time.Time{}.Add(value)
+		return strconv.AppendQuote(b, midnight.Format("15:04:05.999999")), nil
+	case []byte:
+		return strconv.AppendQuote(b, base64.StdEncoding.EncodeToString(value)), nil
+	case [16]byte:
+		return strconv.AppendQuote(b, formatUUID(value)), nil
+	}
+	data, err := json.Marshal(v.Value)
+	if err != nil {
+		return nil, err
+	}
+	return append(b, data...), nil
+}
+
+func appendJSONFloat(b []byte, value float64, bitSize int) []byte {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return strconv.AppendQuote(b, strconv.FormatFloat(value, 'g', -1, bitSize))
+	}
+	return strconv.AppendFloat(b, value, 'g', -1, bitSize)
+}
+
+func formatVariantTime(kind VariantKind, t time.Time) string {
+	switch kind {
+	case VariantDate:
+		return t.Format(time.DateOnly)
+	case VariantTimestampNTZ, VariantTimestampNanosNTZ:
+		return t.Format("2006-01-02T15:04:05.999999999")
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+func formatUUID(u [16]byte) string {
+	var b strings.Builder
+	for i, c := range u {
+		if i == 4 || i == 6 || i == 8 || i == 10 {
+			b.WriteByte('-')
+		}
+		fmt.Fprintf(&b, "%02x", c)
+	}
+	return b.String()
+}
+
+// UnmarshalJSON decodes a Variant from JSON. Integers become the smallest
+// integer kind that holds them, numbers with a fraction decimals when they
+// have at most 38 digits, and other numbers doubles. Objects cannot repeat
+// a field.
+func (v *Variant) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	value, err := decodeJSONVariant(decoder)
+	if err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return fmt.Errorf("%w: trailing data after JSON value", ErrInvalidVariant)
+	}
+	*v = value
+	return nil
+}
+
+func decodeJSONVariant(decoder *json.Decoder) (Variant, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return Variant{}, err
+	}
+	switch token := token.(type) {
+	case nil:
+		return Variant{Kind: VariantNull}, nil
+	case bool:
+		return Variant{Kind: VariantBoolean, Value: token}, nil
+	case string:
+		return Variant{Kind: VariantString, Value: token}, nil
+	case json.Number:
+		return jsonNumberVariant(token)
+	case json.Delim:
+		if token == '[' {
+			var elements []Variant
+			for decoder.More() {
+				element, err := decodeJSONVariant(decoder)
+				if err != nil {
+					return Variant{}, err
+				}
+				elements = append(elements, element)
+			}
+			_, err := decoder.Token()
+			return Variant{Kind: VariantArray, Elements: elements}, err
+		}
+
+		var fields []VariantField
+		for decoder.More() {
+			name, err := decoder.Token()
+			if err != nil {
+				return Variant{}, err
+			}
+			value, err := decodeJSONVariant(decoder)
+			if err != nil {
+				return Variant{}, err
+			}
+			fields = append(fields, VariantField{Name: name.(string), Value: value})
+		}
+		if _, err := decoder.Token(); err != nil {
+			return Variant{}, err
+		}
+		slices.SortStableFunc(fields, compareFields)
+		for i := 1; i < len(fields); i++ {
+			if fields[i-1].Name == fields[i].Name {
+				return Variant{}, fmt.Errorf("%w: duplicate field %q", ErrInvalidVariant, fields[i].Name)
+			}
+		}
+		return Variant{Kind: VariantObject, Fields: fields}, nil
+	}
+	return Variant{}, fmt.Errorf("%w: unexpected JSON token %v", ErrInvalidVariant, token)
+}
+
+func jsonNumberVariant(number json.Number) (Variant, error) {
+	text := number.String()
+	if !strings.ContainsAny(text, "eE") {
+		integer, fraction, hasFraction := strings.Cut(text, ".")
+		if !hasFraction {
+			if v, err := strconv.ParseInt(text, 10, 64); err == nil {
+				return smallestIntVariant(v), nil
+			}
+		}
+		digits := strings.TrimLeft(strings.TrimPrefix(integer, "-")+fraction, "0")
+		if len(digits) <= maxVariantDecimalDigits {
+			unscaled, ok := new(big.Int).SetString(strings.TrimPrefix(integer, "-")+fraction, 10)
+			if ok {
+				if strings.HasPrefix(integer, "-") {
+					unscaled.Neg(unscaled)
+				}
+				decimal := Decimal{unscaled, int32(len(fraction))}
+				return Variant{Kind: decimalKind(decimal), Value: decimal}, nil
+			}
+		}
+	}
+	v, err := number.Float64()
+	if err != nil {
+		return Variant{}, fmt.Errorf("%w: %v", ErrInvalidVariant, err)
+	}
+	return Variant{Kind: VariantDouble, Value: v}, nil
+}
+
+func smallestIntVariant(v int64) Variant {
+	switch {
+	case v >= math.MinInt8 && v <= math.MaxInt8:
+		return Variant{Kind: VariantInt8, Value: int8(v)}
+	case v >= math.MinInt16 && v <= math.MaxInt16:
+		return Variant{Kind: VariantInt16, Value: int16(v)}
+	case v >= math.MinInt32 && v <= math.MaxInt32:
+		return Variant{Kind: VariantInt32, Value: int32(v)}
+	}
+	return Variant{Kind: VariantInt64, Value: v}
+}
@@ -0,0 +1,276 @@
+package parquet
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+// isVariant reports whether a node is a group annotated as VARIANT.
+func isVariant(node *schema.SchemaElement) bool {
+	return !node.IsLeaf() && node.LogicalType != nil && node.LogicalType.Kind == schema.LogicalVariant
+}
+
+// variantPaths returns the paths from the root of the VARIANT groups of a
+// schema, which are converted as a whole.
+func variantPaths(group *schema.SchemaElement, parents []*schema.SchemaElement) [][]*schema.SchemaElement {
+	var paths [][]*schema.SchemaElement
+	for _, child := range group.Children {
+		path := append(slices.Clip(parents), child)
+		switch {
+		case isVariant(child):
+			paths = append(paths, path)
+		case !child.IsLeaf():
+			paths = append(paths, variantPaths(child, path)...)
+		}
+	}
+	return paths
+}
+
+// inVariant reports whether a column is part of a VARIANT group.
+func inVariant(column *schema.Column) bool {
+	return slices.ContainsFunc(column.Nodes, isVariant)
+}
+
+// newVariantConverter returns the converter of a VARIANT group to Variant,
+// or nil when its metadata is not read.
+func newVariantConverter(node *schema.SchemaElement) (valueConverter, error) {
+	metadata := node.Child("metadata")
+	if metadata == nil {
+		return nil, nil
+	}
+	if !metadata.IsLeaf() || metadata.Type != schema.ByteArray || metadata.Repetition != schema.Required {
+		return nil, fmt.Errorf("%w: metadata of VARIANT %q must be a required binary field", schema.ErrInvalidSchema, node.Name)
+	}
+	shredded, err := newShreddedVariant(node)
+	if err != nil {
+		return nil, err
+	}
+	return func(value any) (any, error) {
+		group := value.(map[string]any)
+		keys, err := decodeVariantMetadata(group["metadata"].([]byte))
+		if err != nil {
+			return nil, err
+		}
+		variant, ok, err := shredded.read(group, keys)
+		if err != nil || !ok {
+			return Variant{Kind: VariantNull}, err
+		}
+		return variant, nil
+	}, nil
+}
+
+// shreddedVariant reads a VARIANT group, or an element or field of its
+// shredded value, from its value and typed_value fields.
+type shreddedVariant struct {
+	// typed is nil when the group has no typed_value. Otherwise kind and
+	// convert read its primitive values, element the elements of a shredded
+	// array and fields the fields of a shredded object.
+	typed   *schema.SchemaElement
+	kind    VariantKind
+	convert valueConverter
+	element *shreddedVariant
+	fields  []shreddedField
+}
+
+type shreddedField struct {
+	name     string
+	shredded *shreddedVariant
+}
+
+func newShreddedVariant(node *schema.SchemaElement) (*shreddedVariant, error) {
+	if value := node.Child("value"); value != nil && (!value.IsLeaf() || value.Type != schema.ByteArray) {
+		return nil, fmt.Errorf("%w: value of variant %q must be a binary field", schema.ErrInvalidSchema, node.Name)
+	}
+	typed := node.Child("typed_value")
+	if typed == nil {
+		return &shreddedVariant{}, nil
+	}
+	shredded := &shreddedVariant{typed: typed}
+	var err error
+	switch {
+	case typed.IsLeaf():
+		if shredded.kind, err = shreddedVariantKind(typed); err != nil {
+			return nil, err
+		}
+		shredded.convert, err = newValueConverter(typed)
+		return shredded, err
+
+	case typed.LogicalType != nil && typed.LogicalType.Kind == schema.LogicalList || typed.ConvertedType == schema.List:
+		if len(typed.Children) != 1 || typed.Children[0].Repetition != schema.Repeated || len(typed.Children[0].Children) != 1 {
+			return nil, fmt.Errorf("%w: shredded array %q must be a three-level list", schema.ErrInvalidSchema, node.Name)
+		}
+		shredded.element, err = newShreddedVariant(typed.Children[0].Children[0])
+		return shredded, err
+	}
+
+	for _, child := range typed.Children {
+		if child.IsLeaf() {
+			return nil, fmt.Errorf("%w: shredded field %q of variant %q must be a group", schema.ErrInvalidSchema, child.Name, node.Name)
+		}
+		field, err := newShreddedVariant(child)
+		if err != nil {
+			return nil, err
+		}
+		shredded.fields = append(shredded.fields, shreddedField{child.Name, field})
+	}
+	return shredded, nil
+}
+
+// shreddedVariantKind returns the kind of the values of a shredded
+// primitive.
+func shreddedVariantKind(leaf *schema.SchemaElement) (VariantKind, error) {
+	logical := leafLogicalType(leaf)
+	kind := schema.LogicalUnknown
+	if logical != nil {
+		kind = logical.Kind
+	}
+	switch {
+	case leaf.Type == schema.Boolean && logical == nil:
+		return VariantBoolean, nil
+	case leaf.Type == schema.Float && logical == nil:
+		return VariantFloat, nil
+	case leaf.Type == schema.Double && logical == nil:
+		return VariantDouble, nil
+	case leaf.Type == schema.Int32 && logical == nil:
+		return VariantInt32, nil
+	case leaf.Type == schema.Int64 && logical == nil:
+		return VariantInt64, nil
+	case leaf.Type == schema.ByteArray && logical == nil:
+		return VariantBinary, nil
+	case leaf.Type == schema.ByteArray && kind == schema.LogicalString:
+		return VariantString, nil
+	case leaf.Type == schema.FixedLenByteArray && kind == schema.LogicalUUID:
+		return VariantUUID, nil
+	case leaf.Type == schema.Int32 && kind == schema.LogicalDate:
+		return VariantDate, nil
+	case leaf.Type == schema.Int64 && kind == schema.LogicalTime && logical.Unit == schema.Micros:
+		return VariantTime, nil
+
+	case kind == schema.LogicalInteger && logical.IsSigned:
+		switch {
+		case logical.BitWidth == 8 && leaf.Type == schema.Int32:
+			return VariantInt8, nil
+		case logical.BitWidth == 16 && leaf.Type == schema.Int32:
+			return VariantInt16, nil
+		case logical.BitWidth == 32 && leaf.Type == schema.Int32:
+			return VariantInt32, nil
+		case logical.BitWidth == 64 && leaf.Type == schema.Int64:
+			return VariantInt64, nil
+		}
+
+	case kind == schema.LogicalDecimal:
+		switch leaf.Type {
+		case schema.Int32:
+			return VariantDecimal4, nil
+		case schema.Int64:
+			return VariantDecimal8, nil
+		case schema.FixedLenByteArray:
+			return VariantDecimal16, nil
+		}
+
+	case leaf.Type == schema.Int64 && kind == schema.LogicalTimestamp:
+		switch {
+		case logical.Unit == schema.Micros && logical.IsAdjustedToUTC:
+			return VariantTimestamp, nil
+		case logical.Unit == schema.Micros:
+			return VariantTimestampNTZ, nil
+		case logical.Unit == schema.Nanos && logical.IsAdjustedToUTC:
+			return VariantTimestampNanos, nil
+		case logical.Unit == schema.Nanos:
+			return VariantTimestampNanosNTZ, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: field %q of type %v cannot hold a shredded variant value", schema.ErrInvalidSchema, leaf.Name, leaf.Type)
+}
+
+// read returns the Variant of a group, and false when it is missing: only
+// fields of shredded objects can be missing. A group with both a value and
+// a shredded object holds a partially shredded object whose other fields
+// are in value.
+func (s *shreddedVariant) read(group map[string]any, keys []string) (Variant, bool, error) {
+	value, _ := group["value"].([]byte)
+	var typed any
+	if s.typed != nil {
+		typed = group[s.typed.Name]
+	}
+	if typed == nil {
+		if value == nil {
+			return Variant{}, false, nil
+		}
+		variant, err := decodeVariantValue(keys, value)
+		return variant, true, err
+	}
+
+	switch {
+	case s.typed.IsLeaf():
+		if s.convert != nil {
+			var err error
+			if typed, err = s.convert(typed); err != nil {
+				return Variant{}, false, err
+			}
+		}
+		return Variant{Kind: s.kind, Value: typed}, true, nil
+
+	case s.element != nil:
+		list, _ := typed.(map[string]any)[s.typed.Children[0].Name].([]any)
+		elementName := s.typed.Children[0].Children[0].Name
+		elements := make([]Variant, len(list))
+		for i, element := range list {
+			elementGroup, _ := element.(map[string]any)[elementName].(map[string]any)
+			variant, ok, err := s.element.read(elementGroup, keys)
+			if err != nil {
+				return Variant{}, false, err
+			}
+			if !ok {
+				variant = Variant{Kind: VariantNull}
+			}
+			elements[i] = variant
+		}
+		return Variant{Kind: VariantArray, Elements: elements}, true, nil
+	}
+
+	typedFields := typed.(map[string]any)
+	var fields []VariantField
+	for _, field := range s.fields {
+		fieldGroup, _ := typedFields[field.name].(map[string]any)
+		variant, ok, err := field.shredded.read(fieldGroup, keys)
+		if err != nil {
+			return Variant{}, false, fmt.Errorf("field %q: %w", field.name, err)
+		}
+		if ok {
+			fields = append(fields, VariantField{Name: field.name, Value: variant})
+		}
+	}
+	if value != nil {
+		residual, err := decodeVariantValue(keys, value)
+		if err != nil {
+			return Variant{}, false, err
+		}
+		if residual.Kind != VariantObject {
+			return Variant{}, false, fmt.Errorf("%w: partially shredded object holds a %v value", ErrInvalidVariant, residual.Kind)
+		}
+		for _, field := range residual.Fields {
+			if slices.ContainsFunc(fields, func(shredded VariantField) bool { return shredded.Name == field.Name }) {
+				return Variant{}, false, fmt.Errorf("%w: field %q is both shredded and not", ErrInvalidVariant, field.Name)
+			}
+		}
+		fields = append(fields, residual.Fields...)
+	}
+	slices.SortFunc(fields, compareFields)
+	return Variant{Kind: VariantObject, Fields: fields}, true, nil
+}
+
+// convertVariants replaces the groups at the end of paths by their Variant
+// values, in place.
+func convertVariants(paths [][]*schema.SchemaElement, converters []valueConverter, rows []Row) error {
+	for i, path := range paths {
+		for _, row := range rows {
+			if err := convertValues(row, path, converters[i]); err != nil {
+				return fmt.Errorf("variant %q: %w", path[len(path)-1].Name, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,373 @@
+package parquet
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+func mustVariant(t *testing.T, value any) Variant {
+	t.Helper()
+	variant, err := NewVariant(value)
+	if err != nil {
+		t.Fatalf("unable to create variant: %v", err)
+	}
+	return variant
+}
+
+func TestVariantRoundTrip(t *testing.T) {
+	manyFields := make(map[string]any)
+	for i := range 300 {
+		manyFields[strings.Repeat("f", i%5+1)+string(rune('a'+i%26))+string(rune('a'+i/26))] = int64(i)
+	}
+	testcases := map[string]Variant{
+		"null":               {Kind: VariantNull},
+		"true":               {Kind: VariantBoolean, Value: true},
+		"false":              {Kind: VariantBoolean, Value: false},
+		"int8":               {Kind: VariantInt8, Value: int8(-5)},
+		"int16":              {Kind: VariantInt16, Value: int16(-300)},
+		"int32":              {Kind: VariantInt32, Value: int32(1 << 20)},
+		"int64":              {Kind: VariantInt64, Value: int64(-1 << 40)},
+		"float":              {Kind: VariantFloat, Value: float32(1.5)},
+		"double":             {Kind: VariantDouble, Value: math.Pi},
+		"decimal4":           {Kind: VariantDecimal4, Value: Decimal{big.NewInt(-12345), 2}},
+		"decimal8":           {Kind: VariantDecimal8, Value: Decimal{big.NewInt(1 << 50), 10}},
+		"decimal16":          {Kind: VariantDecimal16, Value: Decimal{new(big.Int).Lsh(big.NewInt(-1), 100), 38}},
+		"date":               {Kind: VariantDate, Value: time.Date(1969, time.December, 31, 0, 0, 0, 0, time.UTC)},
+		"time":               {Kind: VariantTime, Value: 13*time.Hour + 5*time.Microsecond},
+		"timestamp":          {Kind: VariantTimestamp, Value: time.Date(2024, time.May, 1, 12, 0, 0, 1000, time.UTC)},
+		"timestamp ntz":      {Kind: VariantTimestampNTZ, Value: time.Date(1900, time.May, 1, 12, 0, 0, 0, time.UTC)},
+		"timestamp nanos":    {Kind: VariantTimestampNanos, Value: time.Date(2024, time.May, 1, 12, 0, 0, 1, time.UTC)},
+		"timestamp nanos tz": {Kind: VariantTimestampNanosNTZ, Value: time.Date(2024, time.May, 1, 12, 0, 0, 1, time.UTC)},
+		"binary":             {Kind: VariantBinary, Value: []byte{0, 1, 2}},
+		"short string":       {Kind: VariantString, Value: "hello"},
+		"long string":        {Kind: VariantString, Value: strings.Repeat("x", 100)},
+		"uuid":               {Kind: VariantUUID, Value: [16]byte{0x12, 0x34, 15: 0xff}},
+		"empty object":       {Kind: VariantObject},
+		"empty array":        {Kind: VariantArray},
+		"nested":             mustVariant(t, map[string]any{"b": []any{int8(1), "two", nil}, "a": map[string]any{"c": true, "a": 1.5}}),
+		"large object":       mustVariant(t, manyFields),
+		"large array":        mustVariant(t, make([]any, 300)),
+		"long offsets":       mustVariant(t, []any{strings.Repeat("x", 70000), "y"}),
+	}
+
+	for name, variant := range testcases {
+		t.Run(name, func(t *testing.T) {
+			metadata, value, err := variant.Encode()
+			if err != nil {
+				t.Fatalf("unable to encode: %v", err)
+			}
+			got, err := DecodeVariant(metadata, value)
+			if err != nil {
+				t.Fatalf("unable to decode: %v", err)
+			}
+			if !reflect.DeepEqual(got, variant) {
+				t.Errorf("expected %v, got %v", variant, got)
+			}
+		})
+	}
+}
+
+func TestVariantEncoding(t *testing.T) {
+	// {"b": 5, "a": "x"} with field values in the order of the dictionary.
+	metadata := []byte{0x11, 2, 0, 1, 2, 'a', 'b'}
+	value := []byte{0x02, 2, 0, 1, 0, 2, 4, 0x05, 'x', 0x0c, 5}
+	expected := Variant{Kind: VariantObject, Fields: []VariantField{
+		{Name: "a", Value: Variant{Kind: VariantString, Value: "x"}},
+		{Name: "b", Value: Variant{Kind: VariantInt8, Value: int8(5)}},
+	}}
+
+	got, err := DecodeVariant(metadata, value)
+	if err != nil {
+		t.Fatalf("unable to decode: %v", err)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if field, ok := got.Field("b"); !ok || field.Value != int8(5) {
+		t.Errorf("expected field b to be 5, got %v", field)
+	}
+	encodedMetadata, encodedValue, err := expected.Encode()
+	if err != nil {
+		t.Fatalf("unable to encode: %v", err)
+	}
+	if !bytes.Equal(encodedMetadata, metadata) || !bytes.Equal(encodedValue, value) {
+		t.Errorf("expected %x %x, got %x %x", metadata, value, encodedMetadata, encodedValue)
+	}
+
+	// Objects may list their values in any order.
+	unordered := []byte{0x02, 2, 0, 1, 2, 0, 4, 0x0c, 5, 0x05, 'x'}
+	if got, err := DecodeVariant(metadata, unordered); err != nil || !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v (%v)", expected, got, err)
+	}
+}
+
+func TestVariantInvalid(t *testing.T) {
+	metadata := []byte{0x01, 0, 0}
+	testcases := map[string]struct {
+		metadata, value []byte
+	}{
+		"empty metadata":     {metadata: nil, value: []byte{0}},
+		"metadata version":   {metadata: []byte{0x02, 0, 0}, value: []byte{0}},
+		"truncated metadata": {metadata: []byte{0x01, 5, 0}, value: []byte{0}},
+		"empty value":        {metadata: metadata},
+		"unknown primitive":  {metadata: metadata, value: []byte{21 << 2}},
+		"truncated int64":    {metadata: metadata, value: []byte{6 << 2, 1, 2}},
+		"truncated string":   {metadata: metadata, value: []byte{5<<2 | 1, 'a'}},
+		"long string":        {metadata: metadata, value: []byte{16 << 2, 0xff, 0, 0, 0, 'a'}},
+		"unknown field":      {metadata: metadata, value: []byte{0x02, 1, 0, 0, 1, 0}},
+		"truncated array":    {metadata: metadata, value: []byte{0x03, 3, 0}},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := DecodeVariant(test.metadata, test.value); !errors.Is(err, ErrInvalidVariant) {
+				t.Errorf("expected ErrInvalidVariant, got %v", err)
+			}
+		})
+	}
+
+	for name, variant := range map[string]Variant{
+		"mismatched value":   {Kind: VariantInt32, Value: int64(1)},
+		"decimal overflow":   {Kind: VariantDecimal4, Value: Decimal{big.NewInt(1 << 40), 0}},
+		"duplicate field":    {Kind: VariantObject, Fields: []VariantField{{Name: "a"}, {Name: "a"}}},
+		"unknown kind":       {Kind: VariantKind(99)},
+		"nested invalid":     {Kind: VariantArray, Elements: []Variant{{Kind: VariantString, Value: 1}}},
+		"date of a duration": {Kind: VariantDate, Value: time.Second},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := variant.Encode(); !errors.Is(err, ErrInvalidVariant) {
+				t.Errorf("expected ErrInvalidVariant, got %v", err)
+			}
+		})
+	}
+}
+
+func TestVariantJSON(t *testing.T) {
+	testcases := map[string]struct {
+		json     string
+		expected Variant
+		output   string
+	}{
+		"null":     {json: "null", expected: Variant{Kind: VariantNull}},
+		"boolean":  {json: "true", expected: Variant{Kind: VariantBoolean, Value: true}},
+		"int8":     {json: "-12", expected: Variant{Kind: VariantInt8, Value: int8(-12)}},
+		"int16":    {json: "1000", expected: Variant{Kind: VariantInt16, Value: int16(1000)}},
+		"int32":    {json: "100000", expected: Variant{Kind: VariantInt32, Value: int32(100000)}},
+		"int64":    {json: "10000000000", expected: Variant{Kind: VariantInt64, Value: int64(10000000000)}},
+		"big int":  {json: "100000000000000000000", expected: Variant{Kind: VariantDecimal16, Value: Decimal{new(big.Int).Exp(big.NewInt(10), big.NewInt(20), nil), 0}}},
+		"decimal":  {json: "-1.50", expected: Variant{Kind: VariantDecimal4, Value: Decimal{big.NewInt(-150), 2}}},
+		"exponent": {json: "1e3", expected: Variant{Kind: VariantDouble, Value: 1000.0}, output: "1000"},
+		"string":   {json: `"a\"b"`, expected: Variant{Kind: VariantString, Value: `a"b`}},
+		"array":    {json: `[1,"x",[]]`, expected: Variant{Kind: VariantArray, Elements: []Variant{{Kind: VariantInt8, Value: int8(1)}, {Kind: VariantString, Value: "x"}, {Kind: VariantArray}}}},
+		"object": {json: `{"b":{},"a":null}`, output: `{"a":null,"b":{}}`, expected: Variant{Kind: VariantObject, Fields: []VariantField{
+			{Name: "a", Value: Variant{Kind: VariantNull}},
+			{Name: "b", Value: Variant{Kind: VariantObject}},
+		}}},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			var got Variant
+			if err := got.UnmarshalJSON([]byte(test.json)); err != nil {
+				t.Fatalf("unable to decode JSON: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, got)
+			}
+			output, err := got.MarshalJSON()
+			if err != nil {
+				t.Fatalf("unable to encode JSON: %v", err)
+			}
+			expected := test.output
+			if expected == "" {
+				expected = test.json
+			}
+			if string(output) != expected {
+				t.Errorf("expected %s, got %s", expected, output)
+			}
+		})
+	}
+
+	for _, invalid := range []string{`{"a":1,"a":2}`, `[1`, `1 2`} {
+		var got Variant
+		if err := got.UnmarshalJSON([]byte(invalid)); err == nil {
+			t.Errorf("expected error decoding %s, got nil error", invalid)
+		}
+	}
+}
+
+func TestVariantMarshalJSONTypes(t *testing.T) {
+	variant := Variant{Kind: VariantObject, Fields: []VariantField{
+		{Name: "binary", Value: Variant{Kind: VariantBinary, Value: []byte("hi")}},
+		{Name: "date", Value: Variant{Kind: VariantDate, Value: time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC)}},
+		{Name: "nan", Value: Variant{Kind: VariantDouble, Value: math.NaN()}},
+		{Name: "ntz", Value: Variant{Kind: VariantTimestampNTZ, Value: time.Date(2024, time.March, 5, 1, 2, 3, 4000, time.UTC)}},
+		{Name: "time", Value: Variant{Kind: VariantTime, Value: time.Hour + 500*time.Millisecond}},
+		{Name: "ts", Value: Variant{Kind: VariantTimestamp, Value: time.Date(2024, time.March, 5, 1, 2, 3, 0, time.UTC)}},
+		{Name: "uuid", Value: Variant{Kind: VariantUUID, Value: [16]byte{0xf8, 0x1d, 15: 1}}},
+	}}
+	expected := `{"binary":"aGk=","date":"2024-03-05","nan":"NaN","ntz":"2024-03-05T01:02:03.000004",` +
+		`"time":"01:00:00.5","ts":"2024-03-05T01:02:03Z","uuid":"f81d0000-0000-0000-0000-000000000001"}`
+	got, err := variant.MarshalJSON()
+	if err != nil {
+		t.Fatalf("unable to encode JSON: %v", err)
+	}
+	if string(got) != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+}
+
+func variantSchema(typed *schema.SchemaElement) *schema.SchemaElement {
+	children := []*schema.SchemaElement{
+		schema.NewLeaf("metadata", schema.ByteArray, schema.Required),
+		schema.NewLeaf("value", schema.ByteArray, schema.Optional),
+	}
+	if typed != nil {
+		children = append(children, typed)
+	}
+	group := schema.NewGroup("v", schema.Optional, children...)
+	group.LogicalType = &schema.LogicalType{Kind: schema.LogicalVariant}
+	return schema.NewSchema(schema.NewLeaf("id", schema.Int64, schema.Required), group)
+}
+
+func writeVariantFile(t *testing.T, root *schema.SchemaElement, rows []Row) []byte {
+	t.Helper()
+	var buffer bytes.Buffer
+	writer, err := NewWriter(&buffer, root)
+	if err != nil {
+		t.Fatalf("unable to create writer: %v", err)
+	}
+	for _, row := range rows {
+		if err := writer.Write(row); err != nil {
+			t.Fatalf("unable to write row: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("unable to close writer: %v", err)
+	}
+	return buffer.Bytes()
+}
+
+func TestReaderVariant(t *testing.T) {
+	variant := mustVariant(t, map[string]any{"name": "ada", "tags": []any{"x", int64(7)}})
+	data := writeVariantFile(t, variantSchema(nil), []Row{
+		{"id": int64(1), "v": variant},
+		{"id": int64(2), "v": nil},
+	})
+
+	expected := []Row{{"id": int64(1), "v": variant}, {"id": int64(2), "v": nil}}
+	if got := readAllRows(t, openTestFile(t, data, WithLogicalTypes(true))); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	// Without the option the group holds the encoded buffers.
+	metadata, value, _ := variant.Encode()
+	raw := readAllRows(t, openTestFile(t, data))[0]["v"]
+	if expected := map[string]any{"metadata": metadata, "value": value}; !reflect.DeepEqual(raw, expected) {
+		t.Errorf("expected %v, got %v", expected, raw)
+	}
+
+	// Reading the value without the metadata leaves it encoded.
+	if got := readAllRows(t, openTestFile(t, data, WithLogicalTypes(true), WithColumns("v.value")))[0]["v"]; !reflect.DeepEqual(got, map[string]any{"value": value}) {
+		t.Errorf("expected the encoded value, got %v", got)
+	}
+}
+
+func TestReaderShreddedVariant(t *testing.T) {
+	shreddedField := func(name string, typed *schema.SchemaElement) *schema.SchemaElement {
+		return schema.NewGroup(name, schema.Required, schema.NewLeaf("value", schema.ByteArray, schema.Optional), typed)
+	}
+	str := logicalLeaf("typed_value", schema.ByteArray, &schema.LogicalType{Kind: schema.LogicalString})
+	str.Repetition = schema.Optional
+	element := schema.NewGroup("element", schema.Required,
+		schema.NewLeaf("value", schema.ByteArray, schema.Optional),
+		schema.NewLeaf("typed_value", schema.Int64, schema.Optional),
+	)
+	list := schema.NewGroup("typed_value", schema.Optional, schema.NewGroup("list", schema.Repeated, element))
+	list.LogicalType = &schema.LogicalType{Kind: schema.LogicalList}
+	typed := schema.NewGroup("typed_value", schema.Optional,
+		shreddedField("name", str),
+		shreddedField("scores", list),
+	)
+	root := variantSchema(typed)
+
+	// Every row shares the dictionary of the fully encoded objects.
+	full := mustVariant(t, map[string]any{"name": "ada", "scores": []any{int64(1), "two"}, "extra": true})
+	metadata, _, err := full.Encode()
+	if err != nil {
+		t.Fatalf("unable to encode: %v", err)
+	}
+	keys, _ := decodeVariantMetadata(metadata)
+	ids := make(map[string]int)
+	for i, key := range keys {
+		ids[key] = i
+	}
+	encode := func(value any) []byte {
+		encoded, err := mustVariant(t, value).appendValue(nil, ids)
+		if err != nil {
+			t.Fatalf("unable to encode: %v", err)
+		}
+		return encoded
+	}
+	missing := map[string]any{"value": nil, "typed_value": nil}
+
+	rows := []Row{
+		// A partially shredded object: extra is not shredded and scores
+		// has an element of another type.
+		{"id": int64(1), "v": map[string]any{"metadata": metadata, "value": encode(map[string]any{"extra": true}), "typed_value": map[string]any{
+			"name": map[string]any{"value": nil, "typed_value": []byte("ada")},
+			"scores": map[string]any{"value": nil, "typed_value": map[string]any{"list": []any{
+				map[string]any{"element": map[string]any{"value": nil, "typed_value": int64(1)}},
+				map[string]any{"element": map[string]any{"value": encode("two"), "typed_value": nil}},
+			}}},
+		}}},
+		// A field of another type and a missing field.
+		{"id": int64(2), "v": map[string]any{"metadata": metadata, "value": nil, "typed_value": map[string]any{
+			"name":   map[string]any{"value": encode(int64(5)), "typed_value": nil},
+			"scores": missing,
+		}}},
+		// A value that is not an object.
+		{"id": int64(3), "v": map[string]any{"metadata": metadata, "value": encode("plain"), "typed_value": nil}},
+		// A variant null, and a null group.
+		{"id": int64(4), "v": map[string]any{"metadata": metadata, "value": encode(nil), "typed_value": nil}},
+		{"id": int64(5), "v": nil},
+	}
+	data := writeVariantFile(t, root, rows)
+
+	expected := []Row{
+		{"id": int64(1), "v": full},
+		{"id": int64(2), "v": mustVariant(t, map[string]any{"name": int64(5)})},
+		{"id": int64(3), "v": mustVariant(t, "plain")},
+		{"id": int64(4), "v": Variant{Kind: VariantNull}},
+		{"id": int64(5), "v": nil},
+	}
+	got := readAllRows(t, openTestFile(t, data, WithLogicalTypes(true)))
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestShreddedVariantInvalidSchema(t *testing.T) {
+	testcases := map[string]*schema.SchemaElement{
+		"unsigned integer": logicalLeaf("typed_value", schema.Int32, &schema.LogicalType{Kind: schema.LogicalInteger, BitWidth: 8}),
+		"time millis":      logicalLeaf("typed_value", schema.Int32, &schema.LogicalType{Kind: schema.LogicalTime, Unit: schema.Millis}),
+		"int96":            schema.NewLeaf("typed_value", schema.Int96, schema.Optional),
+		"leaf field":       schema.NewGroup("typed_value", schema.Optional, schema.NewLeaf("a", schema.Int64, schema.Optional)),
+	}
+
+	for name, typed := range testcases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewWriter(&bytes.Buffer{}, variantSchema(typed)); !errors.Is(err, schema.ErrInvalidSchema) {
+				t.Errorf("expected ErrInvalidSchema, got %v", err)
+			}
+		})
+	}
+}
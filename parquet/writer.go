@@ -1,11 +1,457 @@
 package parquet
 
-type ParquetWriter struct{}
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
 
-func NewWriter() {
+	"github.com/RichardNooooh/parquet-go/internal/bloom"
+	"github.com/RichardNooooh/parquet-go/internal/encryption"
+	format "github.com/RichardNooooh/parquet-go/internal/format/gen-go/parquet"
+	"github.com/RichardNooooh/parquet-go/internal/stats"
+	"github.com/RichardNooooh/parquet-go/internal/thriftio"
+	"github.com/RichardNooooh/parquet-go/schema"
+	thrift "github.com/apache/thrift/lib/go/thrift"
+)
 
+var ErrWriterClosed = errors.New("parquet writer is closed")
+
+var parquetMagic = []byte("PAR1")
+
+type ParquetWriter struct {
+	writer    *positionWriter
+	schema    *schema.SchemaElement
+	columns   []*schema.Column
+	config    *writerConfig
+	buffers   []*columnBuffer
+	numRows   int64
+	totalRows int64
+	rowGroups []*format.RowGroup
+	// chunkIndexes holds the page indexes and bloom filters of every written
+	// column chunk, aligned with rowGroups, until they are written before the
+	// footer.
+	chunkIndexes [][]chunkIndex
+	closed       bool
+	// encryption is nil for files that are not encrypted.
+	encryption *fileEncryption
+	// encoders holds the conversion of the values of each column before
+	// shredding, or nil.
+	encoders []func(any) (any, error)
+
+	// workers bounds the column chunks encoded at once, and is nil when
+	// encoding sequentially.
+	workers chan struct{}
+	// pending receives the result of the row group written in the
+	// background, whose buffers become spare once it is done.
+	pending chan error
+	spare   []*columnBuffer
+	// err is the first error from writing a row group. The file is
+	// incomplete after it, so every later call returns it.
+	err error
+}
+
+type chunkIndex struct {
+	columnIndex *format.ColumnIndex
+	offsetIndex *format.OffsetIndex
+	bloomFilter *bloom.Filter
+}
+
+func NewWriter(w io.Writer, root *schema.SchemaElement, opts ...ParquetWriterOption) (*ParquetWriter, error) {
+	if err := validateSchema(root); err != nil {
+		return nil, err
+	}
+	config := newWriterConfig(opts)
+	if config.pageSize <= 0 {
+		return nil, fmt.Errorf("page size must be positive, got %d", config.pageSize)
+	}
+	if config.rowGroupRows <= 0 {
+		return nil, fmt.Errorf("row group size must be positive, got %d", config.rowGroupRows)
+	}
+	units := make(map[*schema.SchemaElement]schema.TimeUnit)
+	if config.int96 {
+		root = int96Timestamps(root, units)
+	}
+	if err := validateBloomFilters(root.Columns(), config.bloomFilters); err != nil {
+		return nil, err
+	}
+	fileEncryption, err := newFileEncryption(root.Columns(), config)
+	if err != nil {
+		return nil, err
+	}
+
+	writer := &ParquetWriter{
+		writer:     &positionWriter{writer: w},
+		schema:     root,
+		columns:    root.Columns(),
+		config:     config,
+		encryption: fileEncryption,
+	}
+	writer.encoders = make([]func(any) (any, error), len(writer.columns))
+	for i, column := range writer.columns {
+		if column.Leaf.Type == schema.Int96 {
+			unit, ok := units[column.Leaf]
+			writer.encoders[i] = newInt96Encoder(config.int96Rebase, unit, ok)
+		} else if isGeospatial(column.Leaf) {
+			writer.encoders[i] = encodeGeometry
+		}
+	}
+	writer.buffers = newColumnBuffers(writer.columns, writer.encoders)
+	if config.concurrency > 1 {
+		writer.workers = make(chan struct{}, config.concurrency)
+	}
+
+	if _, err := writer.writer.Write(fileEncryption.magic()); err != nil {
+		return nil, fmt.Errorf("unable to write header magic: %w", err)
+	}
+	return writer, nil
+}
+
+// Write buffers a row, writing out a row group once enough rows are buffered.
+func (w *ParquetWriter) Write(row Row) error {
+	if w.closed {
+		return ErrWriterClosed
+	}
+	if w.err != nil {
+		return w.err
+	}
+
+	levels := make([]int, len(w.buffers))
+	values := make([]int, len(w.buffers))
+	for i, buffer := range w.buffers {
+		levels[i], values[i] = len(buffer.definitionLevels), len(buffer.values)
+	}
+	for _, buffer := range w.buffers {
+		if err := buffer.shredRow(row); err != nil {
+			for i, buffer := range w.buffers {
+				buffer.truncate(levels[i], values[i])
+			}
+			return err
+		}
+	}
+	w.numRows++
+
+	if w.numRows >= w.config.rowGroupRows {
+		if w.config.pipeline {
+			return w.flushInBackground()
+		}
+		return w.Flush()
+	}
+	return nil
+}
+
+// Flush writes the buffered rows as a row group, after waiting for any row
+// group still being written in the background.
+func (w *ParquetWriter) Flush() error {
+	if w.closed {
+		return ErrWriterClosed
+	}
+	if err := w.wait(); err != nil {
+		return err
+	}
+	if w.numRows == 0 {
+		return nil
+	}
+
+	w.err = w.writeRowGroup(w.buffers, w.numRows)
+	w.numRows = 0
+	return w.err
+}
+
+// flushInBackground hands the buffered rows to a goroutine that writes them
+// as a row group, and continues buffering into the spare buffers.
+func (w *ParquetWriter) flushInBackground() error {
+	if err := w.wait(); err != nil {
+		return err
+	}
+	buffers, numRows := w.buffers, w.numRows
+	if w.spare != nil {
+		w.buffers, w.spare = w.spare, nil
+	} else {
+		w.buffers = newColumnBuffers(w.columns, w.encoders)
+	}
+	w.numRows = 0
+
+	pending := make(chan error, 1)
+	w.pending = pending
+	go func() {
+		pending <- w.writeRowGroup(buffers, numRows)
+	}()
+	w.spare = buffers
+	return nil
+}
+
+// wait blocks until the row group written in the background, if any, is
+// done and returns the writer's error.
+func (w *ParquetWriter) wait() error {
+	if w.pending != nil {
+		if err := <-w.pending; err != nil {
+			w.err = err
+		}
+		w.pending = nil
+	}
+	return w.err
+}
+
+// writeRowGroup encodes the columns of a row group, concurrently when
+// configured, and writes them in schema order. The buffers are reset once
+// written.
+func (w *ParquetWriter) writeRowGroup(buffers []*columnBuffer, numRows int64) error {
+	ctx := context.Background()
+	chunks := make([]*encodedChunk, len(buffers))
+	err := forEach(ctx, len(buffers), w.workers, func(ctx context.Context, i int) error {
+		chunk, err := w.encodeColumnChunk(ctx, buffers[i], w.encryption.encryptor(len(w.rowGroups), i))
+		if err != nil {
+			return fmt.Errorf("unable to encode column %q: %w", buffers[i].column.PathString(), err)
+		}
+		chunks[i] = chunk
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	rowGroup := format.NewRowGroup()
+	rowGroupOffset := w.writer.position
+	chunkIndexes := make([]chunkIndex, 0, len(buffers))
+	for i, buffer := range buffers {
+		chunk := chunks[i]
+		offset := w.writer.position
+		if _, err := w.writer.Write(chunk.data); err != nil {
+			return fmt.Errorf("unable to write column %q: %w", buffer.column.PathString(), err)
+		}
+		chunk.metadata.DataPageOffset += offset
+		if chunk.offsetIndex != nil {
+			for _, location := range chunk.offsetIndex.PageLocations {
+				location.Offset += offset
+			}
+		}
+		chunkIndexes = append(chunkIndexes, chunkIndex{chunk.columnIndex, chunk.offsetIndex, chunk.bloomFilter})
+
+		rowGroup.Columns = append(rowGroup.Columns, &format.ColumnChunk{
+			FileOffset: offset,
+			MetaData:   chunk.metadata,
+		})
+		rowGroup.TotalByteSize += chunk.metadata.TotalUncompressedSize
+		buffer.reset()
+	}
+
+	totalCompressedSize := w.writer.position - rowGroupOffset
+	ordinal := int16(len(w.rowGroups))
+	rowGroup.NumRows = numRows
+	rowGroup.FileOffset = &rowGroupOffset
+	rowGroup.TotalCompressedSize = &totalCompressedSize
+	rowGroup.Ordinal = &ordinal
+	w.rowGroups = append(w.rowGroups, rowGroup)
+	w.chunkIndexes = append(w.chunkIndexes, chunkIndexes)
+	w.totalRows += numRows
+	return nil
+}
+
+// Close flushes any buffered rows and writes the footer. It does not close
+// the underlying io.Writer.
+func (w *ParquetWriter) Close() error {
+	if w.closed {
+		return ErrWriterClosed
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	w.closed = true
+
+	if err := w.writeBloomFilters(); err != nil {
+		return err
+	}
+	if err := w.writePageIndexes(); err != nil {
+		return err
+	}
+	ctx := context.Background()
+	if err := w.encryption.encryptColumnMetadata(ctx, w.rowGroups); err != nil {
+		return err
+	}
+
+	fileMetadata := format.NewFileMetaData()
+	fileMetadata.Version = 1
+	fileMetadata.Schema = schema.ToFormat(w.schema)
+	fileMetadata.NumRows = w.totalRows
+	fileMetadata.RowGroups = w.rowGroups
+	fileMetadata.CreatedBy = &w.config.createdBy
+	fileMetadata.ColumnOrders = typeDefinedColumnOrders(w.columns)
+	if w.config.int96Rebase == RebaseLegacy && slices.ContainsFunc(w.columns, isInt96) {
+		legacy := ""
+		fileMetadata.KeyValueMetadata = []*format.KeyValue{{Key: sparkLegacyInt96Key, Value: &legacy}}
+	}
+
+	var footer []byte
+	var err error
+	if w.encryption != nil {
+		footer, err = w.encryption.encodeFooter(ctx, fileMetadata)
+	} else {
+		footer, err = thriftio.Encode(ctx, fileMetadata)
+	}
+	if err != nil {
+		return err
+	}
+	footer = binary.LittleEndian.AppendUint32(footer, uint32(len(footer)))
+	footer = append(footer, w.encryption.magic()...)
+	if _, err := w.writer.Write(footer); err != nil {
+		return fmt.Errorf("unable to write footer: %w", err)
+	}
+	return nil
+}
+
+// writeBloomFilters writes the bloom filter header and bitset of every column
+// chunk that has one, and records their locations in the column metadata.
+func (w *ParquetWriter) writeBloomFilters() error {
+	ctx := context.Background()
+	for i, rowGroup := range w.rowGroups {
+		for j, chunk := range rowGroup.Columns {
+			filter := w.chunkIndexes[i][j].bloomFilter
+			if filter == nil {
+				continue
+			}
+			header := &format.BloomFilterHeader{
+				NumBytes:    int32(len(filter.Bytes())),
+				Algorithm:   &format.BloomFilterAlgorithm{BLOCK: format.NewSplitBlockAlgorithm()},
+				Hash:        &format.BloomFilterHash{XXHASH: format.NewXxHash()},
+				Compression: &format.BloomFilterCompression{UNCOMPRESSED: format.NewUncompressed()},
+			}
+			encryptor := w.encryption.encryptor(i, j)
+			offset, headerLength, err := w.writeStruct(ctx, header, encryptor, encryption.BloomFilterHeader)
+			if err != nil {
+				return fmt.Errorf("unable to write bloom filter header: %w", err)
+			}
+			bitset := filter.Bytes()
+			if encryptor != nil {
+				if bitset, err = encryptor.Encrypt(encryption.BloomFilterBitset, 0, bitset); err != nil {
+					return fmt.Errorf("unable to encrypt bloom filter: %w", err)
+				}
+			}
+			if _, err := w.writer.Write(bitset); err != nil {
+				return fmt.Errorf("unable to write bloom filter: %w", err)
+			}
+			length := headerLength + int32(len(bitset))
+			chunk.MetaData.BloomFilterOffset, chunk.MetaData.BloomFilterLength = &offset, &length
+		}
+	}
+	return nil
 }
 
-func (*ParquetWriter) Write(record string) error { return nil }
+// writePageIndexes writes the column indexes of all row groups followed by
+// their offset indexes, and records their locations in the column chunks.
+func (w *ParquetWriter) writePageIndexes() error {
+	ctx := context.Background()
+	for i, rowGroup := range w.rowGroups {
+		for j, chunk := range rowGroup.Columns {
+			columnIndex := w.chunkIndexes[i][j].columnIndex
+			if columnIndex == nil {
+				continue
+			}
+			offset, length, err := w.writeStruct(ctx, columnIndex, w.encryption.encryptor(i, j), encryption.ColumnIndex)
+			if err != nil {
+				return fmt.Errorf("unable to write column index: %w", err)
+			}
+			chunk.ColumnIndexOffset, chunk.ColumnIndexLength = &offset, &length
+		}
+	}
+	for i, rowGroup := range w.rowGroups {
+		for j, chunk := range rowGroup.Columns {
+			offsetIndex := w.chunkIndexes[i][j].offsetIndex
+			if offsetIndex == nil {
+				continue
+			}
+			offset, length, err := w.writeStruct(ctx, offsetIndex, w.encryption.encryptor(i, j), encryption.OffsetIndex)
+			if err != nil {
+				return fmt.Errorf("unable to write offset index: %w", err)
+			}
+			chunk.OffsetIndexOffset, chunk.OffsetIndexLength = &offset, &length
+		}
+	}
+	return nil
+}
 
-func (*ParquetWriter) Close() error { return nil }
+// writeStruct writes a thrift struct, as an encrypted module of the given
+// type when encryptor is not nil, and returns its offset and length.
+func (w *ParquetWriter) writeStruct(ctx context.Context, value thrift.TStruct, encryptor *encryption.Encryptor, module encryption.ModuleType) (int64, int32, error) {
+	data, err := thriftio.Encode(ctx, value)
+	if err != nil {
+		return 0, 0, err
+	}
+	if encryptor != nil {
+		if data, err = encryptor.Encrypt(module, 0, data); err != nil {
+			return 0, 0, err
+		}
+	}
+	offset := w.writer.position
+	if _, err := w.writer.Write(data); err != nil {
+		return 0, 0, err
+	}
+	return offset, int32(len(data)), nil
+}
+
+func newColumnBuffers(columns []*schema.Column, encoders []func(any) (any, error)) []*columnBuffer {
+	buffers := make([]*columnBuffer, len(columns))
+	for i, column := range columns {
+		buffers[i] = newColumnBuffer(column, encoders[i])
+	}
+	return buffers
+}
+
+func isInt96(column *schema.Column) bool { return column.Leaf.Type == schema.Int96 }
+
+func validateSchema(root *schema.SchemaElement) error {
+	if root == nil || root.IsLeaf() || len(root.Children) == 0 {
+		return fmt.Errorf("%w: root must be a group with at least one field", schema.ErrInvalidSchema)
+	}
+	for _, column := range root.Columns() {
+		leaf := column.Leaf
+		if leaf.Type == schema.FixedLenByteArray && leaf.TypeLength <= 0 {
+			return fmt.Errorf("%w: field %q needs a positive type length", schema.ErrInvalidSchema, column.PathString())
+		}
+		if isGeospatial(leaf) && leaf.Type != schema.ByteArray {
+			return invalidAnnotation(leaf, leaf.LogicalType.Kind.String())
+		}
+		if leaf.LogicalType != nil && leaf.LogicalType.Kind == schema.LogicalFloat16 && !stats.IsFloat16(leaf) {
+			return invalidAnnotation(leaf, leaf.LogicalType.Kind.String())
+		}
+	}
+	for _, path := range variantPaths(root, nil) {
+		variant := path[len(path)-1]
+		if variant.Child("metadata") == nil || variant.Child("value") == nil {
+			return fmt.Errorf("%w: VARIANT %q needs metadata and value fields", schema.ErrInvalidSchema, variant.Name)
+		}
+		if _, err := newVariantConverter(variant); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateBloomFilters(columns []*schema.Column, bloomFilters map[string]bloomFilterConfig) error {
+	for path, config := range bloomFilters {
+		if config.fpp <= 0 || config.fpp >= 1 {
+			return fmt.Errorf("bloom filter of %q needs a false positive probability in (0, 1), got %v", path, config.fpp)
+		}
+		index := slices.IndexFunc(columns, func(column *schema.Column) bool { return column.PathString() == path })
+		if index < 0 {
+			return fmt.Errorf("bloom filter column %q not found", path)
+		}
+		if columns[index].Leaf.Type == schema.Boolean {
+			return fmt.Errorf("bloom filter column %q is a boolean", path)
+		}
+	}
+	return nil
+}
+
+type positionWriter struct {
+	writer   io.Writer
+	position int64
+}
+
+func (w *positionWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	w.position += int64(n)
+	return n, err
+}
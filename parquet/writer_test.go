@@ -0,0 +1,442 @@
+package parquet
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/RichardNooooh/parquet-go/internal/file"
+	format "github.com/RichardNooooh/parquet-go/internal/format/gen-go/parquet"
+	"github.com/RichardNooooh/parquet-go/internal/thriftio"
+	"github.com/RichardNooooh/parquet-go/metadata"
+	"github.com/RichardNooooh/parquet-go/schema"
+)
+
+func testSchema() *schema.SchemaElement {
+	name := schema.NewLeaf("name", schema.ByteArray, schema.Optional)
+	name.LogicalType = &schema.LogicalType{Kind: schema.LogicalString}
+	return schema.NewSchema(
+		schema.NewLeaf("id", schema.Int64, schema.Required),
+		name,
+		schema.NewLeaf("score", schema.Double, schema.Optional),
+		schema.NewGroup("tags", schema.Repeated,
+			schema.NewLeaf("key", schema.ByteArray, schema.Required),
+		),
+	)
+}
+
+func testRows(n int) []Row {
+	rows := make([]Row, n)
+	for i := range rows {
+		row := Row{"id": int64(i), "score": float64(n - i)}
+		if i%3 != 0 {
+			row["name"] = string(rune('a' + i%26))
+		}
+		if i%2 == 0 {
+			row["tags"] = []any{Row{"key": "x"}, Row{"key": "y"}}
+		}
+		rows[i] = row
+	}
+	return rows
+}
+
+func writeTestFile(t *testing.T, rows []Row, opts ...ParquetWriterOption) []byte {
+	t.Helper()
+	var buffer bytes.Buffer
+	writer, err := NewWriter(&buffer, testSchema(), opts...)
+	if err != nil {
+		t.Fatalf("unable to create writer: %v", err)
+	}
+	for _, row := range rows {
+		if err := writer.Write(row); err != nil {
+			t.Fatalf("unable to write row: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("unable to close writer: %v", err)
+	}
+	return buffer.Bytes()
+}
+
+func readTestFooter(t *testing.T, data []byte) *format.FileMetaData {
+	t.Helper()
+	fileMetadata, err := file.GetFileMetadata(context.Background(), file.NewReader(bytes.NewReader(data), int64(len(data))))
+	if err != nil {
+		t.Fatalf("unable to read footer: %v", err)
+	}
+	return fileMetadata
+}
+
+func TestWriterColumnChunkStatistics(t *testing.T) {
+	data := writeTestFile(t, testRows(100), WithRowGroupSize(60), WithDistinctCount(true))
+	fileMetadata := readTestFooter(t, data)
+
+	if fileMetadata.NumRows != 100 || len(fileMetadata.RowGroups) != 2 {
+		t.Fatalf("expected 100 rows in 2 row groups, got %d rows in %d", fileMetadata.NumRows, len(fileMetadata.RowGroups))
+	}
+	if len(fileMetadata.ColumnOrders) != 4 || !fileMetadata.ColumnOrders[0].IsSetTYPE_ORDER() {
+		t.Fatalf("expected a TypeDefinedOrder per column, got %v", fileMetadata.ColumnOrders)
+	}
+
+	columns := fileMetadata.RowGroups[0].Columns
+	id := columns[0].MetaData.Statistics
+	if binary.LittleEndian.Uint64(id.MinValue) != 0 || binary.LittleEndian.Uint64(id.MaxValue) != 59 {
+		t.Errorf("expected id range [0, 59], got %x..%x", id.MinValue, id.MaxValue)
+	}
+	if id.GetNullCount() != 0 || id.GetDistinctCount() != 60 || !bytes.Equal(id.Min, id.MinValue) {
+		t.Errorf("unexpected id statistics: %v", id)
+	}
+
+	name := columns[1].MetaData.Statistics
+	if name.GetNullCount() != 20 || string(name.MinValue) != "a" || string(name.MaxValue) != "z" {
+		t.Errorf("unexpected name statistics: %v", name)
+	}
+	if name.IsSetMin() || name.IsSetMax() {
+		t.Errorf("deprecated min/max must not be written for strings")
+	}
+
+	tags := columns[3].MetaData.Statistics
+	if tags.GetNullCount() != 30 || tags.GetDistinctCount() != 2 {
+		t.Errorf("unexpected tags statistics: %v", tags)
+	}
+}
+
+func TestWriterPageStatistics(t *testing.T) {
+	data := writeTestFile(t, testRows(100), WithPageSize(64))
+	fileMetadata := readTestFooter(t, data)
+
+	metadata := fileMetadata.RowGroups[0].Columns[0].MetaData
+	offset := metadata.DataPageOffset
+	end := offset + metadata.TotalCompressedSize
+	var pages, values int64
+	previousMax := int64(-1)
+	for offset < end {
+		header := format.NewPageHeader()
+		n, err := thriftio.Decode(context.Background(), data[offset:end], header)
+		if err != nil {
+			t.Fatalf("unable to decode page header: %v", err)
+		}
+		statistics := header.DataPageHeader.Statistics
+		pageMin := int64(binary.LittleEndian.Uint64(statistics.MinValue))
+		pageMax := int64(binary.LittleEndian.Uint64(statistics.MaxValue))
+		if pageMin != previousMax+1 || pageMax-pageMin+1 != int64(header.DataPageHeader.NumValues) {
+			t.Errorf("page %d: unexpected range [%d, %d] for %d values", pages, pageMin, pageMax, header.DataPageHeader.NumValues)
+		}
+		previousMax = pageMax
+		values += int64(header.DataPageHeader.NumValues)
+		pages++
+		offset += int64(n) + int64(header.CompressedPageSize)
+	}
+
+	if pages < 2 || values != 100 {
+		t.Errorf("expected several pages holding 100 values, got %d pages with %d values", pages, values)
+	}
+}
+
+func TestWriterRejectsInvalidRows(t *testing.T) {
+	testcases := map[string]Row{
+		"missingRequired": {"name": "a"},
+		"wrongType":       {"id": "a"},
+		"notRepeated":     {"id": int64(1), "tags": Row{"key": "x"}},
+		"nullElement":     {"id": int64(1), "tags": []any{Row{}}},
+	}
+
+	for name, row := range testcases {
+		t.Run(name, func(t *testing.T) {
+			var buffer bytes.Buffer
+			writer, err := NewWriter(&buffer, testSchema())
+			if err != nil {
+				t.Fatalf("unable to create writer: %v", err)
+			}
+			if err := writer.Write(row); err == nil {
+				t.Fatalf("expected error, got nil error")
+			}
+			if err := writer.Write(Row{"id": int64(1)}); err != nil {
+				t.Fatalf("unable to write valid row after error: %v", err)
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatalf("unable to close writer: %v", err)
+			}
+			if rows := readTestFooter(t, buffer.Bytes()).NumRows; rows != 1 {
+				t.Errorf("expected 1 row, got %d", rows)
+			}
+		})
+	}
+}
+
+func TestWriterPageIndex(t *testing.T) {
+	rows := testRows(100)
+	for i := 10; i < 80; i++ {
+		delete(rows[i], "name")
+	}
+	data := writeTestFile(t, rows, WithPageSize(64), WithRowGroupSize(80))
+	reader, err := Open(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("unable to open file: %v", err)
+	}
+
+	for rowGroup := range reader.GetMeta().NumRowGroups() {
+		numRows := reader.GetMeta().RowGroup(rowGroup).NumRows()
+		for column := range reader.GetMeta().Columns() {
+			offsetIndex, err := reader.OffsetIndex(rowGroup, column)
+			if err != nil || offsetIndex == nil {
+				t.Fatalf("expected offset index for %d/%d, got %v", rowGroup, column, err)
+			}
+			columnIndex, err := reader.ColumnIndex(rowGroup, column)
+			if err != nil || columnIndex == nil {
+				t.Fatalf("expected column index for %d/%d, got %v", rowGroup, column, err)
+			}
+			if columnIndex.NumPages() != offsetIndex.NumPages() || offsetIndex.NumPages() < 2 {
+				t.Fatalf("expected matching page counts, got %d and %d", columnIndex.NumPages(), offsetIndex.NumPages())
+			}
+
+			var rows int64
+			for i, location := range offsetIndex.PageLocations {
+				header := format.NewPageHeader()
+				n, err := thriftio.Decode(context.Background(), data[location.Offset:], header)
+				if err != nil {
+					t.Fatalf("no page header at %d: %v", location.Offset, err)
+				}
+				if int64(n)+int64(header.CompressedPageSize) != int64(location.CompressedPageSize) || location.FirstRowIndex != rows {
+					t.Fatalf("unexpected page location %+v", location)
+				}
+				rows = offsetIndex.LastRowIndex(i, numRows) + 1
+			}
+			if rows != numRows {
+				t.Errorf("expected pages to cover %d rows, got %d", numRows, rows)
+			}
+		}
+	}
+
+	id, err := reader.ColumnIndex(0, 0)
+	if err != nil {
+		t.Fatalf("unable to read column index: %v", err)
+	}
+	if id.BoundaryOrder != metadata.Ascending || id.Min[0] != int64(0) || id.Max[id.NumPages()-1] != int64(79) {
+		t.Errorf("unexpected id column index: %+v", id)
+	}
+	score, err := reader.ColumnIndex(0, 2)
+	if err != nil {
+		t.Fatalf("unable to read column index: %v", err)
+	}
+	if score.BoundaryOrder != metadata.Descending {
+		t.Errorf("expected descending scores, got %v", score.BoundaryOrder)
+	}
+
+	name, err := reader.ColumnIndex(0, 1)
+	if err != nil {
+		t.Fatalf("unable to read column index: %v", err)
+	}
+	var nullPages int
+	for i, isNullPage := range name.NullPages {
+		if isNullPage {
+			nullPages++
+			if name.Min[i] != nil || name.NullCounts[i] == 0 {
+				t.Errorf("unexpected null page %d: %v, %d nulls", i, name.Min[i], name.NullCounts[i])
+			}
+		}
+	}
+	if nullPages == 0 {
+		t.Errorf("expected null pages for rows 10 to 79")
+	}
+}
+
+func TestWriterWithoutPageIndex(t *testing.T) {
+	data := writeTestFile(t, testRows(10), WithPageIndex(false))
+	for _, chunk := range readTestFooter(t, data).RowGroups[0].Columns {
+		if chunk.IsSetColumnIndexOffset() || chunk.IsSetOffsetIndexOffset() {
+			t.Errorf("expected no page index, got %v", chunk)
+		}
+	}
+}
+
+func TestWriterBloomFilter(t *testing.T) {
+	rows := testRows(100)
+	data := writeTestFile(t, rows, WithRowGroupSize(50),
+		WithBloomFilter("id", 0.01, 0), WithBloomFilter("tags.key", 0.01, 1000))
+	fileMetadata := readTestFooter(t, data)
+
+	for i, rowGroup := range fileMetadata.RowGroups {
+		for j, chunk := range rowGroup.Columns {
+			hasFilter := j == 0 || j == 3
+			if chunk.MetaData.IsSetBloomFilterOffset() != hasFilter || chunk.MetaData.IsSetBloomFilterLength() != hasFilter {
+				t.Errorf("row group %d, column %d: expected bloom filter %v, got offset %v", i, j, hasFilter, chunk.MetaData.BloomFilterOffset)
+			}
+		}
+		if length := rowGroup.Columns[3].MetaData.GetBloomFilterLength(); length < 2048 {
+			t.Errorf("expected a filter sized for 1000 values, got %d bytes", length)
+		}
+	}
+
+	reader, err := Open(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("unable to open file: %v", err)
+	}
+	falsePositives := 0
+	for i := range 2 {
+		filter, err := reader.BloomFilter(i, 0)
+		if err != nil || filter == nil {
+			t.Fatalf("expected a bloom filter, got %v", err)
+		}
+		for id := range 1000 {
+			contained := filter.MightContain(id)
+			if id >= i*50 && id < (i+1)*50 && !contained {
+				t.Errorf("row group %d: expected id %d to be contained", i, id)
+			}
+			if id >= 100 && contained {
+				falsePositives++
+			}
+		}
+	}
+	if falsePositives > 50 {
+		t.Errorf("expected about 1%% false positives, got %d of 1800", falsePositives)
+	}
+
+	filter, err := reader.BloomFilter(0, 3)
+	if err != nil || filter == nil || !filter.MightContain("x") || !filter.MightContain("y") {
+		t.Errorf("expected tags.key filter to contain x and y, got %v", err)
+	}
+}
+
+func TestWriterRejectsInvalidBloomFilters(t *testing.T) {
+	root := schema.NewSchema(
+		schema.NewLeaf("id", schema.Int64, schema.Required),
+		schema.NewLeaf("flag", schema.Boolean, schema.Optional),
+	)
+
+	testcases := map[string]ParquetWriterOption{
+		"unknown column": WithBloomFilter("missing", 0.01, 0),
+		"boolean column": WithBloomFilter("flag", 0.01, 0),
+		"invalid fpp":    WithBloomFilter("id", 0, 100),
+	}
+
+	for name, option := range testcases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewWriter(&bytes.Buffer{}, root, option); err == nil {
+				t.Errorf("expected error, got nil error")
+			}
+		})
+	}
+}
+
+func TestWriterRejectsInvalidSizes(t *testing.T) {
+	testcases := map[string]ParquetWriterOption{
+		"zero page size":          WithPageSize(0),
+		"negative page size":      WithPageSize(-1),
+		"zero row group size":     WithRowGroupSize(0),
+		"negative row group size": WithRowGroupSize(-1),
+	}
+
+	for name, option := range testcases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewWriter(&bytes.Buffer{}, testSchema(), option); err == nil {
+				t.Errorf("expected error, got nil error")
+			}
+		})
+	}
+}
+
+func TestWriterSplitPages(t *testing.T) {
+	column := schema.NewSchema(schema.NewGroup("tags", schema.Repeated, schema.NewLeaf("key", schema.Int32, schema.Optional))).Columns()[0]
+	buffer := &columnBuffer{
+		column: column,
+		// Three rows: [1, 2], [], [null].
+		repetitionLevels: []int32{0, 1, 0, 0},
+		definitionLevels: []int32{2, 2, 0, 1},
+		values:           []any{int32(1), int32(2)},
+	}
+
+	testcases := map[string]struct {
+		pageSize int
+		expected []page
+	}{
+		"one page":     {pageSize: 1 << 20, expected: []page{{levelEnd: 4, valueEnd: 2}}},
+		"page per row": {pageSize: 1, expected: []page{{levelEnd: 2, valueEnd: 2}, {levelStart: 2, levelEnd: 3, valueStart: 2, valueEnd: 2}, {levelStart: 3, levelEnd: 4, valueStart: 2, valueEnd: 2}}},
+		"zero size":    {pageSize: 0, expected: []page{{levelEnd: 2, valueEnd: 2}, {levelStart: 2, levelEnd: 3, valueStart: 2, valueEnd: 2}, {levelStart: 3, levelEnd: 4, valueStart: 2, valueEnd: 2}}},
+	}
+
+	for name, test := range testcases {
+		t.Run(name, func(t *testing.T) {
+			w := &ParquetWriter{config: &writerConfig{pageSize: test.pageSize}}
+			if pages := w.splitPages(buffer); !reflect.DeepEqual(pages, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, pages)
+			}
+		})
+	}
+}
+
+func TestWriterDeterministicOutput(t *testing.T) {
+	rows := testRows(2000)
+	codecs := map[string]CompressionCodec{
+		"uncompressed": Uncompressed,
+		"snappy":       Snappy,
+		"gzip":         Gzip,
+		"lz4 raw":      LZ4Raw,
+	}
+
+	for name, codec := range codecs {
+		t.Run(name, func(t *testing.T) {
+			options := []ParquetWriterOption{WithCompression(codec), WithRowGroupSize(300), WithPageSize(512)}
+			expected := writeTestFile(t, rows, options...)
+			if got := readTestFooter(t, expected).RowGroups[0].Columns[1].MetaData.Codec; got != codec.format() {
+				t.Errorf("expected codec %v, got %v", codec.format(), got)
+			}
+
+			for _, workers := range []int{2, 8} {
+				for _, pipeline := range []bool{false, true} {
+					data := writeTestFile(t, rows, append(options, WithEncodingConcurrency(workers), WithPipelining(pipeline))...)
+					if !bytes.Equal(data, expected) {
+						t.Errorf("%d workers, pipelining %v: output differs from sequential encoding", workers, pipeline)
+					}
+				}
+			}
+
+			reader, err := Open(bytes.NewReader(expected), int64(len(expected)))
+			if err != nil {
+				t.Fatalf("unable to open file: %v", err)
+			}
+			if got := readAllRows(t, reader); len(got) != len(rows) || got[1999]["id"] != int64(1999) {
+				t.Errorf("expected %d rows back, got %d", len(rows), len(got))
+			}
+		})
+	}
+}
+
+type failingWriter struct {
+	remaining int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.remaining {
+		return 0, errors.New("disk full")
+	}
+	w.remaining -= len(p)
+	return len(p), nil
+}
+
+func TestWriterPipeliningError(t *testing.T) {
+	writer, err := NewWriter(&failingWriter{remaining: 1000}, testSchema(), WithRowGroupSize(100), WithPipelining(true))
+	if err != nil {
+		t.Fatalf("unable to create writer: %v", err)
+	}
+
+	var writeErr error
+	for _, row := range testRows(1000) {
+		if writeErr = writer.Write(row); writeErr != nil {
+			break
+		}
+	}
+	if writeErr == nil {
+		writeErr = writer.Close()
+	}
+	if writeErr == nil || !strings.HasSuffix(writeErr.Error(), "disk full") {
+		t.Errorf("expected the background write error, got %v", writeErr)
+	}
+	if err := writer.Write(testRows(1)[0]); err != writeErr {
+		t.Errorf("expected later writes to fail with %v, got %v", writeErr, err)
+	}
+}
@@ -0,0 +1,82 @@
+package schema
+
+import "strings"
+
+// Column describes a leaf of the schema tree together with the levels needed
+// to shred and assemble its values.
+type Column struct {
+	Index              int
+	Path               []string
+	Nodes              []*SchemaElement // from the root's child down to the leaf
+	Leaf               *SchemaElement
+	MaxDefinitionLevel int16
+	MaxRepetitionLevel int16
+}
+
+func (c *Column) PathString() string { return strings.Join(c.Path, ".") }
+
+// Columns lists the leaves of the schema in depth-first order, which is also
+// the order of column chunks in each row group.
+func (e *SchemaElement) Columns() []*Column {
+	var columns []*Column
+	var walk func(node *SchemaElement, nodes []*SchemaElement, def, rep int16)
+	walk = func(node *SchemaElement, nodes []*SchemaElement, def, rep int16) {
+		switch node.Repetition {
+		case Optional:
+			def++
+		case Repeated:
+			def++
+			rep++
+		}
+		nodes = append(nodes[:len(nodes):len(nodes)], node)
+		if node.IsLeaf() {
+			path := make([]string, len(nodes))
+			for i, n := range nodes {
+				path[i] = n.Name
+			}
+			columns = append(columns, &Column{
+				Index:              len(columns),
+				Path:               path,
+				Nodes:              nodes,
+				Leaf:               node,
+				MaxDefinitionLevel: def,
+				MaxRepetitionLevel: rep,
+			})
+			return
+		}
+		for _, child := range node.Children {
+			walk(child, nodes, def, rep)
+		}
+	}
+	for _, child := range e.Children {
+		walk(child, nil, 0, 0)
+	}
+	return columns
+}
+
+// Project returns a copy of the schema tree that keeps only the given
+// columns of e and the groups leading to them.
+func (e *SchemaElement) Project(columns []*Column) *SchemaElement {
+	kept := make(map[*SchemaElement]bool)
+	for _, column := range columns {
+		for _, node := range column.Nodes {
+			kept[node] = true
+		}
+	}
+
+	var project func(node *SchemaElement) *SchemaElement
+	project = func(node *SchemaElement) *SchemaElement {
+		projected := *node
+		if node.IsLeaf() {
+			return &projected
+		}
+		projected.Children = []*SchemaElement{}
+		for _, child := range node.Children {
+			if kept[child] {
+				projected.Children = append(projected.Children, project(child))
+			}
+		}
+		return &projected
+	}
+	return project(e)
+}
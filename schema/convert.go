@@ -0,0 +1,235 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+
+	format "github.com/RichardNooooh/parquet-go/internal/format/gen-go/parquet"
+)
+
+var ErrInvalidSchema = errors.New("invalid schema")
+
+// FromFormat rebuilds the schema tree from the depth-first list of elements
+// stored in the file footer.
+func FromFormat(elements []*format.SchemaElement) (*SchemaElement, error) {
+	if len(elements) == 0 {
+		return nil, fmt.Errorf("%w: no schema elements", ErrInvalidSchema)
+	}
+	root, next, err := fromFormat(elements, 0)
+	if err != nil {
+		return nil, err
+	}
+	if next != len(elements) {
+		return nil, fmt.Errorf("%w: %d trailing schema elements", ErrInvalidSchema, len(elements)-next)
+	}
+	return root, nil
+}
+
+func fromFormat(elements []*format.SchemaElement, i int) (*SchemaElement, int, error) {
+	if i >= len(elements) {
+		return nil, i, fmt.Errorf("%w: schema ends early", ErrInvalidSchema)
+	}
+	src := elements[i]
+	e := &SchemaElement{
+		Name:       src.GetName(),
+		TypeLength: src.GetTypeLength(),
+		Scale:      src.GetScale(),
+		Precision:  src.GetPrecision(),
+		FieldID:    src.FieldID,
+	}
+	if src.IsSetRepetitionType() {
+		e.Repetition = Repetition(src.GetRepetitionType())
+	}
+	if src.IsSetConvertedType() {
+		e.ConvertedType = ConvertedType(src.GetConvertedType() + 1)
+	}
+	if src.IsSetLogicalType() {
+		e.LogicalType = logicalTypeFromFormat(src.GetLogicalType())
+	}
+
+	if !src.IsSetNumChildren() {
+		if !src.IsSetType() {
+			return nil, i, fmt.Errorf("%w: leaf %q has no physical type", ErrInvalidSchema, e.Name)
+		}
+		e.Type = Type(src.GetType())
+		return e, i + 1, nil
+	}
+
+	numChildren := int(src.GetNumChildren())
+	if numChildren < 0 || numChildren > len(elements)-i-1 {
+		return nil, i, fmt.Errorf("%w: group %q has %d children", ErrInvalidSchema, e.Name, numChildren)
+	}
+	e.Children = make([]*SchemaElement, 0, numChildren)
+	next := i + 1
+	for range numChildren {
+		child, n, err := fromFormat(elements, next)
+		if err != nil {
+			return nil, i, err
+		}
+		e.Children = append(e.Children, child)
+		next = n
+	}
+	return e, next, nil
+}
+
+// ToFormat flattens the schema tree into the depth-first list of elements
+// stored in the file footer.
+func ToFormat(root *SchemaElement) []*format.SchemaElement {
+	var elements []*format.SchemaElement
+	var walk func(e *SchemaElement, isRoot bool)
+	walk = func(e *SchemaElement, isRoot bool) {
+		dst := format.NewSchemaElement()
+		dst.Name = e.Name
+		if !isRoot {
+			repetition := format.FieldRepetitionType(e.Repetition)
+			dst.RepetitionType = &repetition
+		}
+		if e.IsLeaf() {
+			typ := format.Type(e.Type)
+			dst.Type = &typ
+			if e.Type == FixedLenByteArray {
+				typeLength := e.TypeLength
+				dst.TypeLength = &typeLength
+			}
+		} else {
+			numChildren := int32(len(e.Children))
+			dst.NumChildren = &numChildren
+		}
+		if e.ConvertedType != NoConvertedType {
+			converted := format.ConvertedType(e.ConvertedType - 1)
+			dst.ConvertedType = &converted
+		}
+		if e.ConvertedType == Decimal || (e.LogicalType != nil && e.LogicalType.Kind == LogicalDecimal) {
+			scale, precision := e.Scale, e.Precision
+			dst.Scale = &scale
+			dst.Precision = &precision
+		}
+		dst.FieldID = e.FieldID
+		if e.LogicalType != nil {
+			dst.LogicalType = logicalTypeToFormat(e.LogicalType)
+		}
+		elements = append(elements, dst)
+		for _, child := range e.Children {
+			walk(child, false)
+		}
+	}
+	walk(root, true)
+	return elements
+}
+
+func logicalTypeFromFormat(src *format.LogicalType) *LogicalType {
+	switch {
+	case src.IsSetSTRING():
+		return &LogicalType{Kind: LogicalString}
+	case src.IsSetMAP():
+		return &LogicalType{Kind: LogicalMap}
+	case src.IsSetLIST():
+		return &LogicalType{Kind: LogicalList}
+	case src.IsSetENUM():
+		return &LogicalType{Kind: LogicalEnum}
+	case src.IsSetDECIMAL():
+		return &LogicalType{Kind: LogicalDecimal, Scale: src.DECIMAL.Scale, Precision: src.DECIMAL.Precision}
+	case src.IsSetDATE():
+		return &LogicalType{Kind: LogicalDate}
+	case src.IsSetTIME():
+		return &LogicalType{Kind: LogicalTime, Unit: timeUnitFromFormat(src.TIME.Unit), IsAdjustedToUTC: src.TIME.IsAdjustedToUTC}
+	case src.IsSetTIMESTAMP():
+		return &LogicalType{Kind: LogicalTimestamp, Unit: timeUnitFromFormat(src.TIMESTAMP.Unit), IsAdjustedToUTC: src.TIMESTAMP.IsAdjustedToUTC}
+	case src.IsSetINTEGER():
+		return &LogicalType{Kind: LogicalInteger, BitWidth: src.INTEGER.BitWidth, IsSigned: src.INTEGER.IsSigned}
+	case src.IsSetUNKNOWN():
+		return &LogicalType{Kind: LogicalUnknown}
+	case src.IsSetJSON():
+		return &LogicalType{Kind: LogicalJSON}
+	case src.IsSetBSON():
+		return &LogicalType{Kind: LogicalBSON}
+	case src.IsSetUUID():
+		return &LogicalType{Kind: LogicalUUID}
+	case src.IsSetFLOAT16():
+		return &LogicalType{Kind: LogicalFloat16}
+	case src.IsSetVARIANT():
+		return &LogicalType{Kind: LogicalVariant}
+	case src.IsSetGEOMETRY():
+		return &LogicalType{Kind: LogicalGeometry, CRS: src.GEOMETRY.GetCrs()}
+	case src.IsSetGEOGRAPHY():
+		return &LogicalType{Kind: LogicalGeography, CRS: src.GEOGRAPHY.GetCrs(), Algorithm: EdgeInterpolationAlgorithm(src.GEOGRAPHY.GetAlgorithm())}
+	}
+	return nil
+}
+
+func logicalTypeToFormat(src *LogicalType) *format.LogicalType {
+	dst := format.NewLogicalType()
+	switch src.Kind {
+	case LogicalString:
+		dst.STRING = format.NewStringType()
+	case LogicalMap:
+		dst.MAP = format.NewMapType()
+	case LogicalList:
+		dst.LIST = format.NewListType()
+	case LogicalEnum:
+		dst.ENUM = format.NewEnumType()
+	case LogicalDecimal:
+		dst.DECIMAL = &format.DecimalType{Scale: src.Scale, Precision: src.Precision}
+	case LogicalDate:
+		dst.DATE = format.NewDateType()
+	case LogicalTime:
+		dst.TIME = &format.TimeType{IsAdjustedToUTC: src.IsAdjustedToUTC, Unit: timeUnitToFormat(src.Unit)}
+	case LogicalTimestamp:
+		dst.TIMESTAMP = &format.TimestampType{IsAdjustedToUTC: src.IsAdjustedToUTC, Unit: timeUnitToFormat(src.Unit)}
+	case LogicalInteger:
+		dst.INTEGER = &format.IntType{BitWidth: src.BitWidth, IsSigned: src.IsSigned}
+	case LogicalUnknown:
+		dst.UNKNOWN = format.NewNullType()
+	case LogicalJSON:
+		dst.JSON = format.NewJsonType()
+	case LogicalBSON:
+		dst.BSON = format.NewBsonType()
+	case LogicalUUID:
+		dst.UUID = format.NewUUIDType()
+	case LogicalFloat16:
+		dst.FLOAT16 = format.NewFloat16Type()
+	case LogicalVariant:
+		dst.VARIANT = format.NewVariantType()
+	case LogicalGeometry:
+		dst.GEOMETRY = format.NewGeometryType()
+		if src.CRS != "" {
+			crs := src.CRS
+			dst.GEOMETRY.Crs = &crs
+		}
+	case LogicalGeography:
+		dst.GEOGRAPHY = format.NewGeographyType()
+		if src.CRS != "" {
+			crs := src.CRS
+			dst.GEOGRAPHY.Crs = &crs
+		}
+		if src.Algorithm != Spherical {
+			dst.GEOGRAPHY.Algorithm = format.EdgeInterpolationAlgorithmPtr(format.EdgeInterpolationAlgorithm(src.Algorithm))
+		}
+	}
+	return dst
+}
+
+func timeUnitFromFormat(src *format.TimeUnit) TimeUnit {
+	switch {
+	case src == nil:
+		return Millis
+	case src.IsSetMICROS():
+		return Micros
+	case src.IsSetNANOS():
+		return Nanos
+	}
+	return Millis
+}
+
+func timeUnitToFormat(src TimeUnit) *format.TimeUnit {
+	dst := format.NewTimeUnit()
+	switch src {
+	case Micros:
+		dst.MICROS = format.NewMicroSeconds()
+	case Nanos:
+		dst.NANOS = format.NewNanoSeconds()
+	default:
+		dst.MILLIS = format.NewMilliSeconds()
+	}
+	return dst
+}
@@ -1,6 +1,255 @@
 package schema
 
-type SchemaElement struct{}
-type LogicalType struct{}
-type ConvertedType struct{}
-type Type struct{}
+import "fmt"
+
+// Type is the physical type of a leaf column.
+type Type int32
+
+const (
+	Boolean Type = iota
+	Int32
+	Int64
+	Int96
+	Float
+	Double
+	ByteArray
+	FixedLenByteArray
+)
+
+func (t Type) String() string {
+	switch t {
+	case Boolean:
+		return "BOOLEAN"
+	case Int32:
+		return "INT32"
+	case Int64:
+		return "INT64"
+	case Int96:
+		return "INT96"
+	case Float:
+		return "FLOAT"
+	case Double:
+		return "DOUBLE"
+	case ByteArray:
+		return "BYTE_ARRAY"
+	case FixedLenByteArray:
+		return "FIXED_LEN_BYTE_ARRAY"
+	}
+	return fmt.Sprintf("Type(%d)", int32(t))
+}
+
+type Repetition int32
+
+const (
+	Required Repetition = iota
+	Optional
+	Repeated
+)
+
+func (r Repetition) String() string {
+	switch r {
+	case Required:
+		return "REQUIRED"
+	case Optional:
+		return "OPTIONAL"
+	case Repeated:
+		return "REPEATED"
+	}
+	return fmt.Sprintf("Repetition(%d)", int32(r))
+}
+
+// ConvertedType is the deprecated annotation that predates LogicalType. The
+// zero value means the element has no converted type.
+type ConvertedType int32
+
+const (
+	NoConvertedType ConvertedType = iota
+	UTF8
+	Map
+	MapKeyValue
+	List
+	Enum
+	Decimal
+	Date
+	TimeMillis
+	TimeMicros
+	TimestampMillis
+	TimestampMicros
+	Uint8
+	Uint16
+	Uint32
+	Uint64
+	Int8
+	Int16
+	Int32Converted
+	Int64Converted
+	JSON
+	BSON
+	Interval
+)
+
+var convertedTypeNames = [...]string{
+	"NONE", "UTF8", "MAP", "MAP_KEY_VALUE", "LIST", "ENUM", "DECIMAL", "DATE",
+	"TIME_MILLIS", "TIME_MICROS", "TIMESTAMP_MILLIS", "TIMESTAMP_MICROS",
+	"UINT_8", "UINT_16", "UINT_32", "UINT_64", "INT_8", "INT_16", "INT_32", "INT_64",
+	"JSON", "BSON", "INTERVAL",
+}
+
+func (c ConvertedType) String() string {
+	if c >= 0 && int(c) < len(convertedTypeNames) {
+		return convertedTypeNames[c]
+	}
+	return fmt.Sprintf("ConvertedType(%d)", int32(c))
+}
+
+type LogicalKind int32
+
+const (
+	LogicalString LogicalKind = iota
+	LogicalMap
+	LogicalList
+	LogicalEnum
+	LogicalDecimal
+	LogicalDate
+	LogicalTime
+	LogicalTimestamp
+	LogicalInteger
+	LogicalUnknown
+	LogicalJSON
+	LogicalBSON
+	LogicalUUID
+	LogicalFloat16
+	LogicalVariant
+	LogicalGeometry
+	LogicalGeography
+)
+
+var logicalKindNames = [...]string{
+	"STRING", "MAP", "LIST", "ENUM", "DECIMAL", "DATE", "TIME", "TIMESTAMP",
+	"INTEGER", "UNKNOWN", "JSON", "BSON", "UUID", "FLOAT16", "VARIANT",
+	"GEOMETRY", "GEOGRAPHY",
+}
+
+func (k LogicalKind) String() string {
+	if k >= 0 && int(k) < len(logicalKindNames) {
+		return logicalKindNames[k]
+	}
+	return fmt.Sprintf("LogicalKind(%d)", int32(k))
+}
+
+type TimeUnit int32
+
+const (
+	Millis TimeUnit = iota
+	Micros
+	Nanos
+)
+
+func (u TimeUnit) String() string {
+	switch u {
+	case Millis:
+		return "MILLIS"
+	case Micros:
+		return "MICROS"
+	case Nanos:
+		return "NANOS"
+	}
+	return fmt.Sprintf("TimeUnit(%d)", int32(u))
+}
+
+// LogicalType annotates a schema element with how its physical values should
+// be interpreted. Only the fields relevant to Kind are meaningful.
+type LogicalType struct {
+	Kind LogicalKind
+
+	// DECIMAL
+	Scale     int32
+	Precision int32
+
+	// TIME and TIMESTAMP
+	Unit            TimeUnit
+	IsAdjustedToUTC bool
+
+	// INTEGER
+	BitWidth int8
+	IsSigned bool
+
+	// GEOMETRY and GEOGRAPHY
+	CRS string
+	// GEOGRAPHY
+	Algorithm EdgeInterpolationAlgorithm
+}
+
+// EdgeInterpolationAlgorithm is how the edges of GEOGRAPHY values are
+// interpolated between their vertices.
+type EdgeInterpolationAlgorithm int32
+
+const (
+	Spherical EdgeInterpolationAlgorithm = iota
+	Vincenty
+	Thomas
+	Andoyer
+	Karney
+)
+
+func (a EdgeInterpolationAlgorithm) String() string {
+	if a >= Spherical && a <= Karney {
+		return [...]string{"SPHERICAL", "VINCENTY", "THOMAS", "ANDOYER", "KARNEY"}[a]
+	}
+	return fmt.Sprintf("EdgeInterpolationAlgorithm(%d)", int32(a))
+}
+
+func (l *LogicalType) String() string {
+	switch l.Kind {
+	case LogicalDecimal:
+		return fmt.Sprintf("DECIMAL(%d,%d)", l.Precision, l.Scale)
+	case LogicalTime, LogicalTimestamp:
+		return fmt.Sprintf("%v(%v,%t)", l.Kind, l.Unit, l.IsAdjustedToUTC)
+	case LogicalInteger:
+		return fmt.Sprintf("INTEGER(%d,%t)", l.BitWidth, l.IsSigned)
+	}
+	return l.Kind.String()
+}
+
+// SchemaElement is a node of the schema tree. The root is a group whose
+// children are the top-level fields of the file.
+type SchemaElement struct {
+	Name          string
+	Type          Type
+	TypeLength    int32
+	Repetition    Repetition
+	ConvertedType ConvertedType
+	LogicalType   *LogicalType
+	Scale         int32
+	Precision     int32
+	FieldID       *int32
+	Children      []*SchemaElement
+}
+
+// IsLeaf reports whether the element is a primitive column rather than a group.
+func (e *SchemaElement) IsLeaf() bool { return e.Children == nil }
+
+// Child returns the direct child with the given name, or nil.
+func (e *SchemaElement) Child(name string) *SchemaElement {
+	for _, child := range e.Children {
+		if child.Name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+func NewSchema(children ...*SchemaElement) *SchemaElement {
+	return NewGroup("schema", Required, children...)
+}
+
+func NewGroup(name string, repetition Repetition, children ...*SchemaElement) *SchemaElement {
+	if children == nil {
+		children = []*SchemaElement{}
+	}
+	return &SchemaElement{Name: name, Repetition: repetition, Children: children}
+}
+
+func NewLeaf(name string, typ Type, repetition Repetition) *SchemaElement {
+	return &SchemaElement{Name: name, Type: typ, Repetition: repetition}
+}